@@ -0,0 +1,179 @@
+package cacher
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// AccessOption 访问记录选项
+	AccessOption struct {
+		Rate   float64                 //采样比例，取值 (0,1]，按缓存键采样，被采样的缓存键记录所有访问。等于0时为1
+		Prefix func(key string) string //从缓存键提取前缀，为空时取最后一个冒号及之前的部分，例如 user:42 的前缀为 user:
+	}
+	// AccessRecord 一条访问记录
+	AccessRecord struct {
+		Time   time.Time //访问时间
+		Prefix string    //缓存键前缀
+		Type   EventType //EventHit、EventMiss 或 EventSet
+		Size   int       //缓存数据的字节数，缓存数据不是字符串或字节切片时为0
+	}
+	// AccessRecorder 访问记录器，通过事件记录命中、未命中和写入缓存，用于离线分析保留时长和工作集大小。
+	// 每条记录一行，以制表符分隔：Unix 微秒时间戳、缓存键前缀、类型（h 命中，m 未命中，s 写入）、字节数
+	AccessRecorder struct {
+		mu     sync.Mutex
+		w      *bufio.Writer
+		opt    AccessOption
+		err    error
+		cancel func()
+	}
+)
+
+// accessEscaper 替换缓存键前缀中的分隔符
+var accessEscaper = strings.NewReplacer("\t", " ", "\n", " ")
+
+// WithAccessRate 设置访问记录的采样比例
+func WithAccessRate(rate float64) func(opt *AccessOption) {
+	return func(opt *AccessOption) {
+		opt.Rate = rate
+	}
+}
+
+// WithAccessPrefix 设置从缓存键提取前缀的方法
+func WithAccessPrefix(prefix func(key string) string) func(opt *AccessOption) {
+	return func(opt *AccessOption) {
+		opt.Prefix = prefix
+	}
+}
+
+// RecordAccess 开始记录缓存访问，写入 w。调用 AccessRecorder.Stop 停止记录
+func (c *Cacher) RecordAccess(w io.Writer, optFns ...func(opt *AccessOption)) *AccessRecorder {
+	opt := AccessOption{Rate: 1, Prefix: keyPrefix}
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	if opt.Rate <= 0 || opt.Rate > 1 {
+		opt.Rate = 1
+	}
+	if opt.Prefix == nil {
+		opt.Prefix = keyPrefix
+	}
+	r := &AccessRecorder{w: bufio.NewWriter(w), opt: opt}
+	r.cancel = c.OnEvent(r.record)
+	return r
+}
+
+// record 记录命中、未命中和写入事件
+func (r *AccessRecorder) record(ev Event) {
+	var code byte
+	switch ev.Type {
+	case EventHit:
+		code = 'h'
+	case EventMiss:
+		code = 'm'
+	case EventSet:
+		code = 's'
+	default:
+		return
+	}
+	if r.opt.Rate < 1 && !sampled(ev.Key, r.opt.Rate) {
+		return
+	}
+	prefix := accessEscaper.Replace(r.opt.Prefix(ev.Key))
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err != nil {
+		return
+	}
+	buf := make([]byte, 0, 48+len(prefix))
+	buf = strconv.AppendInt(buf, ev.Time.UnixMicro(), 10)
+	buf = append(buf, '\t')
+	buf = append(buf, prefix...)
+	buf = append(buf, '\t', code, '\t')
+	buf = strconv.AppendInt(buf, int64(ev.Size), 10)
+	buf = append(buf, '\n')
+	_, r.err = r.w.Write(buf)
+}
+
+// Stop 停止记录并写出缓冲的记录，返回写入时的第一个错误
+func (r *AccessRecorder) Stop() error {
+	r.cancel()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	return r.err
+}
+
+// ReadAccess 读取 AccessRecorder 写入的访问记录，每读取一条调用一次 fn，fn 返回错误时停止读取
+func ReadAccess(r io.Reader, fn func(AccessRecord) error) error {
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		fields := strings.Split(scanner.Text(), "\t")
+		if len(fields) != 4 || len(fields[2]) != 1 {
+			return fmt.Errorf("第 %d 行访问记录格式错误", line)
+		}
+		micro, err := strconv.ParseInt(fields[0], 10, 64)
+		if err != nil {
+			return fmt.Errorf("第 %d 行访问记录时间格式错误：%w", line, err)
+		}
+		size, err := strconv.Atoi(fields[3])
+		if err != nil {
+			return fmt.Errorf("第 %d 行访问记录字节数格式错误：%w", line, err)
+		}
+		rec := AccessRecord{Time: time.UnixMicro(micro), Prefix: fields[1], Size: size}
+		switch fields[2][0] {
+		case 'h':
+			rec.Type = EventHit
+		case 'm':
+			rec.Type = EventMiss
+		case 's':
+			rec.Type = EventSet
+		default:
+			return fmt.Errorf("第 %d 行访问记录类型错误：%s", line, fields[2])
+		}
+		if err := fn(rec); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// keyPrefix 取缓存键最后一个冒号及之前的部分，没有冒号时返回整个缓存键
+func keyPrefix(key string) string {
+	if i := strings.LastIndexByte(key, ':'); i >= 0 {
+		return key[:i+1]
+	}
+	return key
+}
+
+// sampled 按缓存键的哈希值采样，同一个缓存键的结果总是相同
+func sampled(key string, rate float64) bool {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return float64(h.Sum32()) < rate*(1<<32)
+}
+
+// sizeOf 字符串、字节切片类型的缓存数据的字节数，其他类型返回0
+func sizeOf(v reflect.Value) int {
+	if !v.IsValid() {
+		return 0
+	}
+	if v.Kind() == reflect.String || isBytes(v.Type()) {
+		return v.Len()
+	}
+	return 0
+}
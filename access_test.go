@@ -0,0 +1,95 @@
+package cacher_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/carteruu/cacher"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacher_RecordAccess(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	var buf bytes.Buffer
+	rec := c.RecordAccess(&buf)
+	query := func() (interface{}, error) { return "value", nil }
+	var v string
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(ctx, "user:42", query, &v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	//停止后不再记录
+	if _, err := c.Get(ctx, "user:43", query, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []cacher.AccessRecord
+	if err := cacher.ReadAccess(&buf, func(r cacher.AccessRecord) error {
+		got = append(got, r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	want := []cacher.AccessRecord{
+		{Prefix: "user:", Type: cacher.EventMiss},
+		{Prefix: "user:", Type: cacher.EventSet, Size: 5},
+		{Prefix: "user:", Type: cacher.EventHit, Size: 5},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("records = %+v, want %+v", got, want)
+	}
+	for i := range want {
+		if got[i].Prefix != want[i].Prefix || got[i].Type != want[i].Type || got[i].Size != want[i].Size || got[i].Time.IsZero() {
+			t.Errorf("records[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestCacher_RecordAccess_Sample(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	var buf bytes.Buffer
+	rec := c.RecordAccess(&buf, cacher.WithAccessRate(0.25), cacher.WithAccessPrefix(func(key string) string {
+		return key
+	}))
+	var v string
+	for i := 0; i < 400; i++ {
+		key := "k" + strconv.Itoa(i)
+		if _, err := c.Get(ctx, key, func() (interface{}, error) { return "v", nil }, &v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+	keys := make(map[string]int)
+	if err := cacher.ReadAccess(&buf, func(r cacher.AccessRecord) error {
+		keys[r.Prefix]++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) < 50 || len(keys) > 150 {
+		t.Errorf("sampled keys = %d, want about 100", len(keys))
+	}
+	for key, n := range keys {
+		if n != 2 {
+			t.Errorf("records of %s = %d, want miss and set", key, n)
+		}
+	}
+}
+
+func TestReadAccess_Error(t *testing.T) {
+	for _, data := range []string{"1\tuser:\th", "x\tuser:\th\t0", "1\tuser:\tx\t0"} {
+		if err := cacher.ReadAccess(strings.NewReader(data), func(cacher.AccessRecord) error { return nil }); err == nil {
+			t.Errorf("ReadAccess(%q) want error", data)
+		}
+	}
+}
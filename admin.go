@@ -0,0 +1,40 @@
+package cacher
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+// AdminHandler 运维接口，挂载到内部管理端口，不要暴露到公网：
+//   - GET 返回 DebugState 的 JSON，包括最近的内部错误；查询参数 prefix 不为空时，只返回缓存键以 prefix 开头的错误
+//   - POST 路径以 /disable 结尾时调用 Disable 关闭缓存，以 /enable 结尾时调用 Enable 恢复，返回设置后的 DebugState
+//
+// 例如 http.Handle("/debug/cacher/", http.StripPrefix("/debug/cacher", c.AdminHandler()))
+func (c *Cacher) AdminHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			switch {
+			case strings.HasSuffix(req.URL.Path, "/disable"):
+				c.Disable()
+			case strings.HasSuffix(req.URL.Path, "/enable"):
+				c.Enable()
+			default:
+				http.NotFound(w, req)
+				return
+			}
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+			return
+		}
+		state := c.DebugState()
+		if prefix := req.URL.Query().Get("prefix"); prefix != "" {
+			state.Errors = c.RecentErrors(prefix)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(state)
+	})
+}
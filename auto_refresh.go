@@ -0,0 +1,68 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// AutoRefresh 在后台按固定间隔刷新热点缓存键：立即调用查询方法并写入缓存，之后每隔 interval 重新查询并覆盖缓存，
+// 缓存过期后的第一个请求不需要等待查询。与 Pin 不同，刷新间隔由调用方指定，也不会在存储库中固定缓存键。
+// interval 必须小于缓存保留时长，否则缓存会在刷新前过期。刷新出错时通过 EventError 事件发布，并在 interval 的1/10后重试。
+// 已自动刷新的缓存键再次调用时，替换查询方法、间隔和选项。通过 StopRefresh 或 Close 停止
+func (c *Cacher) AutoRefresh(ctx context.Context, key string, queryFunc func() (interface{}, error), interval time.Duration, optFns ...func(opt *Option)) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	if queryFunc == nil {
+		return ErrNilQueryFunc
+	}
+	if interval <= 0 {
+		return errors.New("刷新间隔 interval 必须大于0")
+	}
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if err := opt.Valid(); err != nil {
+		return err
+	}
+	key, err := c.buildKey(ctx, key, opt)
+	if err != nil {
+		return err
+	}
+	if opt.Expire == 0 {
+		opt.Expire = c.defaultExpire()
+	}
+	if interval >= opt.Expire {
+		return errors.New("刷新间隔 interval 必须小于缓存保留时长")
+	}
+	if err := c.reload(ctx, key, queryFunc, opt); err != nil {
+		return err
+	}
+	c.refreshes.schedule(key, interval, func(retry func(d time.Duration)) {
+		if err := c.reload(detach(ctx), key, queryFunc, opt); err != nil {
+			c.emitError("auto-refresh", key, err)
+			retry(interval / 10)
+		}
+	})
+	return nil
+}
+
+// StopRefresh 停止缓存键的自动刷新，缓存保留到过期，不会删除
+func (c *Cacher) StopRefresh(ctx context.Context, key string, optFns ...func(opt *Option)) error {
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	key, err := c.buildKey(ctx, key, opt)
+	if err != nil {
+		return err
+	}
+	c.refreshes.stop(key)
+	return nil
+}
@@ -0,0 +1,63 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacher_AutoRefresh(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(cacher.NewMemoryRepo(), time.Minute)
+	defer c.Close()
+	var loads int32
+	query := func() (interface{}, error) {
+		return atomic.AddInt32(&loads, 1), nil
+	}
+	if err := c.AutoRefresh(ctx, "hot", query, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("loads after AutoRefresh = %d, want 1", n)
+	}
+
+	//按间隔刷新，读取时不调用查询方法
+	time.Sleep(70 * time.Millisecond)
+	var n int32
+	useCache, err := c.Get(ctx, "hot", func() (interface{}, error) { return nil, notNeedCall }, &n)
+	if err != nil || !useCache || n < 3 {
+		t.Errorf("Get() after refresh = %v, %v, %d, want refreshed value", useCache, err, n)
+	}
+
+	if err := c.StopRefresh(ctx, "hot"); err != nil {
+		t.Fatal(err)
+	}
+	stopped := atomic.LoadInt32(&loads)
+	time.Sleep(50 * time.Millisecond)
+	if got := atomic.LoadInt32(&loads); got != stopped {
+		t.Errorf("loads after StopRefresh = %d, want %d", got, stopped)
+	}
+
+	//间隔不小于保留时长时缓存会在刷新前过期
+	if err := c.AutoRefresh(ctx, "slow", query, time.Minute); err == nil {
+		t.Error("AutoRefresh() with interval >= expire should fail")
+	}
+	if err := c.AutoRefresh(ctx, "zero", query, 0); err == nil {
+		t.Error("AutoRefresh() with zero interval should fail")
+	}
+
+	//Close 停止所有自动刷新
+	if err := c.AutoRefresh(ctx, "hot", query, 20*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	stopped = atomic.LoadInt32(&loads)
+	time.Sleep(50 * time.Millisecond)
+	if got := atomic.LoadInt32(&loads); got != stopped {
+		t.Errorf("loads after Close = %d, want %d", got, stopped)
+	}
+}
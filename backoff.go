@@ -0,0 +1,88 @@
+package cacher
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrBackoff 查询方法连续出错，处于退避期间，本次没有调用查询方法，见 Option.ErrorBackoff。
+// 返回的错误同时包装了最后一次查询错误，可以用 errors.Is 判断
+var ErrBackoff = errors.New("查询方法连续出错，退避期间不调用查询方法")
+
+// backoffMaxShift 退避时长最多为 Option.ErrorBackoff 的 2^backoffMaxShift 倍
+const backoffMaxShift = 6
+
+type (
+	// keyBackoffs 按缓存键记录查询方法连续出错的次数和退避截止时间
+	keyBackoffs struct {
+		mu      sync.Mutex
+		entries map[string]backoffEntry
+	}
+	backoffEntry struct {
+		failures int
+		until    time.Time //退避截止时间
+		err      error     //最后一次查询错误
+	}
+	// backoffError 退避期间返回的错误
+	backoffError struct {
+		err error
+	}
+)
+
+func (e *backoffError) Error() string {
+	return ErrBackoff.Error() + "：" + e.err.Error()
+}
+
+func (e *backoffError) Unwrap() error {
+	return e.err
+}
+
+func (e *backoffError) Is(target error) bool {
+	return target == ErrBackoff
+}
+
+// check 缓存键处于退避期间时，返回包装了最后一次查询错误的 ErrBackoff
+func (b *keyBackoffs) check(key string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	entry, ok := b.entries[key]
+	if !ok || !time.Now().Before(entry.until) {
+		return nil
+	}
+	return &backoffError{err: entry.err}
+}
+
+// fail 记录一次查询错误，退避时长从 base 开始，每次连续出错翻倍
+func (b *keyBackoffs) fail(key string, base time.Duration, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	now := time.Now()
+	if b.entries == nil {
+		b.entries = make(map[string]backoffEntry)
+	}
+	//条目较多时顺带清理已退避足够久的条目，避免无限增长
+	if len(b.entries) >= 1024 {
+		for k, e := range b.entries {
+			if now.Sub(e.until) >= base<<backoffMaxShift {
+				delete(b.entries, k)
+			}
+		}
+	}
+	entry := b.entries[key]
+	shift := entry.failures
+	if shift > backoffMaxShift {
+		shift = backoffMaxShift
+	}
+	entry.failures++
+	entry.until = now.Add(base << shift)
+	entry.err = err
+	b.entries[key] = entry
+}
+
+// reset 查询成功，清除退避记录
+func (b *keyBackoffs) reset(key string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.entries, key)
+}
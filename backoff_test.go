@@ -0,0 +1,58 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_ErrorBackoff(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute, func(opt *cacher.Option) {
+		opt.ErrorBackoff = 20 * time.Millisecond
+	})
+	loadErr := errors.New("load error")
+	calls := 0
+	fail := func() (interface{}, error) {
+		calls++
+		return nil, loadErr
+	}
+	var v string
+
+	steps := []struct {
+		name        string
+		wait        time.Duration
+		query       func() (interface{}, error)
+		wantBackoff bool
+		wantCalls   int
+	}{
+		{name: "第一次出错", query: fail, wantCalls: 1},
+		{name: "退避期间不调用查询方法", query: fail, wantBackoff: true, wantCalls: 1},
+		{name: "退避结束后再次出错", wait: 25 * time.Millisecond, query: fail, wantCalls: 2},
+		{name: "连续出错退避时长翻倍", wait: 25 * time.Millisecond, query: fail, wantBackoff: true, wantCalls: 2},
+		{name: "翻倍后的退避结束", wait: 30 * time.Millisecond, query: func() (interface{}, error) { calls++; return "v", nil }, wantCalls: 3},
+	}
+	for _, step := range steps {
+		time.Sleep(step.wait)
+		_, err := c.Get(ctx, "k", step.query, &v)
+		if got := errors.Is(err, cacher.ErrBackoff); got != step.wantBackoff {
+			t.Errorf("%s: Get() = %v, want backoff %v", step.name, err, step.wantBackoff)
+		}
+		if step.wantBackoff && !errors.Is(err, loadErr) {
+			t.Errorf("%s: Get() = %v, want wrapped load error", step.name, err)
+		}
+		if calls != step.wantCalls {
+			t.Errorf("%s: calls = %d, want %d", step.name, calls, step.wantCalls)
+		}
+	}
+
+	//查询成功后重置
+	if err := c.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "k", fail, &v); !errors.Is(err, loadErr) || errors.Is(err, cacher.ErrBackoff) {
+		t.Errorf("Get() after reset = %v, want load error", err)
+	}
+}
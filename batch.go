@@ -0,0 +1,72 @@
+package cacher
+
+import "context"
+
+// BatchRepo 可选的存储库接口，在一次往返中读取、保存、删除多个缓存，例如 Redis 的 MGET、管道和 memcached 的 get_multi。
+// Cacher 通过类型断言检测，GetMulti、Del 和 StagedSet.Commit 使用该接口，未实现时逐个调用 Repo 的方法。
+// 与 MultiSetRepo 不同，MSet 不要求原子性
+type BatchRepo interface {
+	// MGet 读取多个缓存，结果与 keys 一一对应，缓存不存在时对应的结果为 nil
+	MGet(ctx context.Context, keys ...string) ([]interface{}, error)
+	// MSet 保存多个缓存，RepoEntry.Expire 的含义同 Repo.Set，为 0 时永不过期
+	MSet(ctx context.Context, entries ...RepoEntry) error
+	// MDel 删除多个缓存
+	MDel(ctx context.Context, keys ...string) error
+}
+
+// DelMulti 删除多个缓存，同 Del
+//
+// Deprecated: Del 已支持删除多个缓存，使用 Del
+func (c *Cacher) DelMulti(ctx context.Context, keys ...string) error {
+	return c.Del(ctx, keys...)
+}
+
+// storeMulti 保存多个缓存，同 store。存储库实现了 BatchRepo 时一次保存，有淘汰优先级时逐个保存
+func (c *Cacher) storeMulti(ctx context.Context, entries []RepoEntry, opt Option) (err error) {
+	if c.Disabled() {
+		//同 store，缓存关闭时删除旧的缓存
+		keys := make([]string, len(entries))
+		for i, entry := range entries {
+			keys[i] = entry.Key
+		}
+		return c.repo.Del(ctx, keys...)
+	}
+	batch, ok := c.repo.(BatchRepo)
+	if !ok || opt.Priority != PriorityNormal || len(entries) < 2 {
+		for _, entry := range entries {
+			if err := c.store(ctx, entry.Key, entry.Value, entry.Expire, opt); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	keys := make([]string, len(entries))
+	for i, entry := range entries {
+		keys[i] = entry.Key
+	}
+	ctx, span := c.startSpan(ctx, SpanSet, keys...)
+	defer func() { span.end(false, err) }()
+	kept := make([]RepoEntry, 0, len(entries))
+	for _, entry := range entries {
+		var err error
+		if entry.Value, err = encodeValue(entry.Value, opt); err != nil {
+			return err
+		}
+		ok, err := c.checkQuota(ctx, entry.Key, entry.Value, entry.Expire, opt)
+		if err != nil {
+			return err
+		}
+		if ok {
+			kept = append(kept, entry)
+		}
+	}
+	if err := batch.MSet(ctx, kept...); err != nil {
+		return err
+	}
+	for _, entry := range kept {
+		if err := c.stored(ctx, entry.Key, entry.Value, entry.Expire, opt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
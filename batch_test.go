@@ -0,0 +1,136 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// repoBatch 实现 BatchRepo，记录每个方法的调用次数
+type repoBatch struct {
+	*repoMap
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func newRepoBatch() *repoBatch {
+	return &repoBatch{repoMap: newRepoMap(nil), calls: make(map[string]int)}
+}
+
+func (r *repoBatch) count(method string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls[method]++
+}
+
+func (r *repoBatch) Get(ctx context.Context, key string) (interface{}, error) {
+	r.count("Get")
+	return r.repoMap.Get(ctx, key)
+}
+
+func (r *repoBatch) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	r.count("Set")
+	return r.repoMap.Set(ctx, key, value, expire)
+}
+
+func (r *repoBatch) Del(ctx context.Context, keys ...string) error {
+	r.count("Del")
+	return r.repoMap.Del(ctx, keys...)
+}
+
+func (r *repoBatch) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	r.count("MGet")
+	vals := make([]interface{}, len(keys))
+	for i, key := range keys {
+		vals[i], _ = r.repoMap.Get(ctx, key)
+	}
+	return vals, nil
+}
+
+func (r *repoBatch) MSet(ctx context.Context, entries ...cacher.RepoEntry) error {
+	r.count("MSet")
+	for _, entry := range entries {
+		_ = r.repoMap.Set(ctx, entry.Key, entry.Value, entry.Expire)
+	}
+	return nil
+}
+
+func (r *repoBatch) MDel(ctx context.Context, keys ...string) error {
+	r.count("MDel")
+	return r.repoMap.Del(ctx, keys...)
+}
+
+func TestCacher_BatchRepo(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoBatch()
+	c := cacher.New(repo, time.Minute)
+
+	var m map[string]string
+	err := c.GetMulti(ctx, []string{"a", "b", "c"}, func(missing []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"a": "1", "b": "2", "c": "3"}, nil
+	}, &m)
+	if want := map[string]string{"a": "1", "b": "2", "c": "3"}; err != nil || !reflect.DeepEqual(m, want) {
+		t.Fatalf("GetMulti() = %v, %v, want %v", m, err, want)
+	}
+	if want := map[string]int{"MGet": 1, "MSet": 1}; !reflect.DeepEqual(repo.calls, want) {
+		t.Errorf("calls after GetMulti = %v, want %v", repo.calls, want)
+	}
+
+	staged := c.PrepareSet()
+	for _, key := range []string{"d", "e"} {
+		if err := staged.Set(ctx, key, key); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := staged.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if repo.calls["MSet"] != 2 || repo.calls["Set"] != 0 {
+		t.Errorf("calls after Commit = %v, want one more MSet", repo.calls)
+	}
+
+	if err := c.Del(ctx, "a", "b", "d"); err != nil {
+		t.Fatal(err)
+	}
+	if repo.calls["MDel"] != 1 || repo.calls["Del"] != 0 {
+		t.Errorf("calls after Del = %v, want one MDel", repo.calls)
+	}
+	for key, want := range map[string]bool{"a": false, "b": false, "c": true, "d": false, "e": true} {
+		if got, _ := repo.repoMap.Get(ctx, key); (got != nil) != want {
+			t.Errorf("%s cached = %v, want %v", key, got != nil, want)
+		}
+	}
+}
+
+// repoDelCalls 记录每次调用 Del 的缓存键
+type repoDelCalls struct {
+	*repoMap
+	dels [][]string
+}
+
+func (r *repoDelCalls) Del(ctx context.Context, keys ...string) error {
+	r.dels = append(r.dels, keys)
+	return r.repoMap.Del(ctx, keys...)
+}
+
+func TestCacher_Del_Multi(t *testing.T) {
+	ctx := context.Background()
+	repo := &repoDelCalls{repoMap: newRepoMap(map[string]interface{}{"a": "1", "b": "2", "c": "3"})}
+	c := cacher.New(repo, time.Minute)
+	if err := c.Del(ctx); err != nil || len(repo.dels) != 0 {
+		t.Errorf("Del() without keys = %v, calls %v", err, repo.dels)
+	}
+	if err := c.Del(ctx, "a", "b", "a"); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"a", "cacher:dep:a", "b", "cacher:dep:b"}}
+	if !reflect.DeepEqual(repo.dels, want) {
+		t.Errorf("Del() calls = %q, want %q", repo.dels, want)
+	}
+	if len(repo.data) != 1 || repo.data["c"] == nil {
+		t.Errorf("data after Del() = %v", repo.data)
+	}
+}
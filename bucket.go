@@ -0,0 +1,72 @@
+package cacher
+
+import "time"
+
+// BucketOption 时间分桶选项
+type BucketOption struct {
+	Location *time.Location   //对齐使用的时区，为空时使用 time.Local。按天分桶时，桶从该时区的零点开始
+	Offset   time.Duration    //对齐偏移量，例如按天分桶、每天4点切换时为 4*time.Hour
+	Now      func() time.Time //当前时间，为空时使用 time.Now
+}
+
+// bucketLayout 桶开始时间的格式
+const bucketLayout = "20060102T150405"
+
+// Bucketed 在缓存键后追加当前时间所在的时间桶，例如 Bucketed("rank", 5*time.Minute) 返回 "rank:20220102T150500"。
+// 适合看板、排行榜等周期性数据，进入下一个时间桶后自然使用新的缓存，不需要主动失效。
+// 缓存保留时长一般设置为 size，使过期的时间桶尽快清理。size 小于等于0时原样返回 key
+func Bucketed(key string, size time.Duration, optFns ...func(opt *BucketOption)) string {
+	if size <= 0 {
+		return key
+	}
+	start := BucketStart(size, optFns...)
+	return key + ":" + start.Format(bucketLayout)
+}
+
+// BucketStart 获取当前时间所在时间桶的开始时间
+func BucketStart(size time.Duration, optFns ...func(opt *BucketOption)) time.Time {
+	opt := BucketOption{Location: time.Local, Now: time.Now}
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if opt.Location == nil {
+		opt.Location = time.Local
+	}
+	if opt.Now == nil {
+		opt.Now = time.Now
+	}
+	now := opt.Now().In(opt.Location)
+	if size <= 0 {
+		return now
+	}
+	//按时区的本地时间对齐
+	_, zone := now.Zone()
+	local := now.UnixNano() + int64(zone)*int64(time.Second) - int64(opt.Offset)
+	start := local - mod(local, int64(size))
+	return time.Unix(0, start-int64(zone)*int64(time.Second)+int64(opt.Offset)).In(opt.Location)
+}
+
+// WithBucketLocation 设置对齐使用的时区
+func WithBucketLocation(loc *time.Location) func(opt *BucketOption) {
+	return func(opt *BucketOption) {
+		opt.Location = loc
+	}
+}
+
+// WithBucketOffset 设置对齐偏移量
+func WithBucketOffset(offset time.Duration) func(opt *BucketOption) {
+	return func(opt *BucketOption) {
+		opt.Offset = offset
+	}
+}
+
+// mod 取模，结果总是非负数
+func mod(a, b int64) int64 {
+	m := a % b
+	if m < 0 {
+		m += b
+	}
+	return m
+}
@@ -0,0 +1,35 @@
+package cacher_test
+
+import (
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestBucketed(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	now := time.Date(2022, 1, 2, 15, 7, 30, 0, shanghai)
+	nowFn := func(opt *cacher.BucketOption) {
+		opt.Now = func() time.Time { return now }
+	}
+	tests := []struct {
+		name   string
+		size   time.Duration
+		optFns []func(opt *cacher.BucketOption)
+		want   string
+	}{
+		{name: "5分钟", size: 5 * time.Minute, optFns: []func(opt *cacher.BucketOption){cacher.WithBucketLocation(shanghai)}, want: "rank:20220102T150500"},
+		{name: "按天，本地零点对齐", size: 24 * time.Hour, optFns: []func(opt *cacher.BucketOption){cacher.WithBucketLocation(shanghai)}, want: "rank:20220102T000000"},
+		{name: "按天，UTC 零点对齐", size: 24 * time.Hour, optFns: []func(opt *cacher.BucketOption){cacher.WithBucketLocation(time.UTC)}, want: "rank:20220102T000000"},
+		{name: "按天，4点切换", size: 24 * time.Hour, optFns: []func(opt *cacher.BucketOption){cacher.WithBucketLocation(shanghai), cacher.WithBucketOffset(4 * time.Hour)}, want: "rank:20220102T040000"},
+		{name: "按小时，UTC", size: time.Hour, optFns: []func(opt *cacher.BucketOption){cacher.WithBucketLocation(time.UTC)}, want: "rank:20220102T070000"},
+		{name: "不分桶", size: 0, want: "rank"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cacher.Bucketed("rank", tt.size, append([]func(opt *cacher.BucketOption){nowFn}, tt.optFns...)...); got != tt.want {
+				t.Errorf("Bucketed() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
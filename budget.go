@@ -0,0 +1,47 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+var (
+	// ErrBudgetExceeded 超过 Option.Budget 时间预算，且没有旧数据
+	ErrBudgetExceeded = errors.New("超过读取缓存的时间预算")
+	// ErrStale 超过 Option.Budget 时间预算，或按 Option.Degrade 降级，目标变量 v 已赋值为旧数据
+	ErrStale = errors.New("未能读取最新数据，返回旧数据")
+)
+
+// stalePrefix 旧数据的缓存键前缀
+const stalePrefix = "cacher:stale:"
+
+// staleKey 旧数据的缓存键
+func staleKey(key string) string {
+	return stalePrefix + key
+}
+
+// stale 读取旧数据赋值给目标变量，返回 ErrStale；没有旧数据时返回 cause。
+// 超过时间预算时 ctx 已超时，读取旧数据使用不会取消的 context
+func (c *Cacher) stale(ctx context.Context, key string, cause error, to reflect.Value, toType reflect.Type, opt Option) (bool, error) {
+	if opt.StaleExpire <= 0 {
+		return false, cause
+	}
+	data := c.staleData(detach(ctx), key)
+	if data == nil {
+		return false, cause
+	}
+	if err := c.assign(reflect.ValueOf(data), to, toType, opt); err != nil {
+		return false, err
+	}
+	return true, ErrStale
+}
+
+// staleData 读取旧数据，没有旧数据或读取出错时返回 nil
+func (c *Cacher) staleData(ctx context.Context, key string) interface{} {
+	data, err := c.repo.Get(ctx, staleKey(key))
+	if err != nil || data == nil || cachedError(key, data) != nil {
+		return nil
+	}
+	return data
+}
@@ -0,0 +1,47 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_Budget(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, 20*time.Millisecond, func(opt *cacher.Option) {
+		opt.Jitter = -1
+		opt.Budget = 20 * time.Millisecond
+		opt.StaleExpire = time.Minute
+	})
+	slow := func(v string) func() (interface{}, error) {
+		return func() (interface{}, error) {
+			time.Sleep(50 * time.Millisecond)
+			return v, nil
+		}
+	}
+	var v string
+	//没有旧数据
+	if _, err := c.Get(ctx, "k", slow("v1"), &v); !errors.Is(err, cacher.ErrBudgetExceeded) {
+		t.Fatalf("Get() error = %v, want ErrBudgetExceeded", err)
+	}
+	//等待后台查询写入缓存
+	time.Sleep(40 * time.Millisecond)
+	if ok, err := c.Peek(ctx, "k", &v); err != nil || !ok || v != "v1" {
+		t.Fatalf("Peek() = %v, %v, v = %v, want background load written", ok, err, v)
+	}
+	//缓存过期，返回旧数据
+	time.Sleep(30 * time.Millisecond)
+	v = ""
+	useCache, err := c.Get(ctx, "k", slow("v2"), &v)
+	if !errors.Is(err, cacher.ErrStale) || !useCache || v != "v1" {
+		t.Errorf("Get() = %v, %v, v = %v, want stale v1", useCache, err, v)
+	}
+	//时间预算内完成
+	v = ""
+	if _, err := c.Get(ctx, "fast", func() (interface{}, error) { return "f", nil }, &v); err != nil || v != "f" {
+		t.Errorf("Get() = %v, v = %v", err, v)
+	}
+}
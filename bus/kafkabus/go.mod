@@ -0,0 +1,16 @@
+module github.com/carteruu/cacher/bus/kafkabus
+
+go 1.18
+
+replace github.com/carteruu/cacher => ../..
+
+require (
+	github.com/carteruu/cacher v0.0.0-00010101000000-000000000000
+	github.com/segmentio/kafka-go v0.4.38
+)
+
+require (
+	github.com/klauspost/compress v1.15.9 // indirect
+	github.com/pierrec/lz4/v4 v4.1.15 // indirect
+	golang.org/x/sync v0.0.0-20220923202941-7f9b1623fab7 // indirect
+)
@@ -0,0 +1,48 @@
+// Package kafkabus 基于 Kafka 的缓存失效消息总线
+package kafkabus
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/segmentio/kafka-go"
+)
+
+// Bus 基于 Kafka 的失效消息总线
+type Bus struct {
+	writer *kafka.Writer
+	reader kafka.ReaderConfig
+}
+
+var _ cacher.InvalidationBus = (*Bus)(nil)
+
+// New 创建失效消息总线。
+// 失效消息需要广播到所有实例，所以每个实例的 reader.GroupID 必须不同，或者不设置 GroupID
+func New(writer *kafka.Writer, reader kafka.ReaderConfig) *Bus {
+	return &Bus{writer: writer, reader: reader}
+}
+
+// Publish 发布失效消息
+func (b *Bus) Publish(ctx context.Context, keys ...string) error {
+	data, err := cacher.MarshalInvalidation(keys)
+	if err != nil {
+		return err
+	}
+	return b.writer.WriteMessages(ctx, kafka.Message{Value: data})
+}
+
+// Subscribe 订阅失效消息，阻塞直到 ctx 结束或读取出错
+func (b *Bus) Subscribe(ctx context.Context, fn func(keys []string)) error {
+	reader := kafka.NewReader(b.reader)
+	defer reader.Close()
+	for {
+		msg, err := reader.ReadMessage(ctx)
+		if err != nil {
+			return err
+		}
+		keys, err := cacher.UnmarshalInvalidation(msg.Value)
+		if err != nil {
+			continue
+		}
+		fn(keys)
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/carteruu/cacher/bus/natsbus
+
+go 1.18
+
+replace github.com/carteruu/cacher => ../..
+
+require (
+	github.com/carteruu/cacher v0.0.0-00010101000000-000000000000
+	github.com/nats-io/nats.go v1.20.0
+)
+
+require (
+	github.com/nats-io/nkeys v0.3.0 // indirect
+	github.com/nats-io/nuid v1.0.1 // indirect
+	golang.org/x/crypto v0.0.0-20210314154223-e6e6c4f2bb5b // indirect
+	golang.org/x/sync v0.0.0-20220923202941-7f9b1623fab7 // indirect
+)
@@ -0,0 +1,53 @@
+// Package natsbus 基于 NATS 的缓存失效消息总线
+package natsbus
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/nats-io/nats.go"
+)
+
+// DefaultSubject 默认的主题
+const DefaultSubject = "cacher.invalidation"
+
+// Bus 基于 NATS 的失效消息总线
+type Bus struct {
+	conn    *nats.Conn
+	subject string
+}
+
+var _ cacher.InvalidationBus = (*Bus)(nil)
+
+// New 创建失效消息总线，subject 为空时使用 DefaultSubject
+func New(conn *nats.Conn, subject string) *Bus {
+	if subject == "" {
+		subject = DefaultSubject
+	}
+	return &Bus{conn: conn, subject: subject}
+}
+
+// Publish 发布失效消息
+func (b *Bus) Publish(_ context.Context, keys ...string) error {
+	data, err := cacher.MarshalInvalidation(keys)
+	if err != nil {
+		return err
+	}
+	return b.conn.Publish(b.subject, data)
+}
+
+// Subscribe 订阅失效消息，阻塞直到 ctx 结束
+func (b *Bus) Subscribe(ctx context.Context, fn func(keys []string)) error {
+	sub, err := b.conn.Subscribe(b.subject, func(msg *nats.Msg) {
+		keys, err := cacher.UnmarshalInvalidation(msg.Data)
+		if err != nil {
+			return
+		}
+		fn(keys)
+	})
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+	<-ctx.Done()
+	return ctx.Err()
+}
@@ -0,0 +1,62 @@
+// Package redisbus 基于 Redis 发布订阅的缓存失效消息总线
+package redisbus
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/redis/go-redis/v9"
+)
+
+// DefaultChannel 默认的发布订阅频道
+const DefaultChannel = "cacher:invalidation"
+
+// Bus 基于 Redis 发布订阅的失效消息总线
+type Bus struct {
+	client  redis.UniversalClient
+	channel string
+}
+
+var _ cacher.InvalidationBus = (*Bus)(nil)
+
+// New 创建失效消息总线，channel 为空时使用 DefaultChannel
+func New(client redis.UniversalClient, channel string) *Bus {
+	if channel == "" {
+		channel = DefaultChannel
+	}
+	return &Bus{client: client, channel: channel}
+}
+
+// Publish 发布失效消息
+func (b *Bus) Publish(ctx context.Context, keys ...string) error {
+	data, err := cacher.MarshalInvalidation(keys)
+	if err != nil {
+		return err
+	}
+	return b.client.Publish(ctx, b.channel, data).Err()
+}
+
+// Subscribe 订阅失效消息，阻塞直到 ctx 结束或订阅出错
+func (b *Bus) Subscribe(ctx context.Context, fn func(keys []string)) error {
+	sub := b.client.Subscribe(ctx, b.channel)
+	defer sub.Close()
+	//等待订阅确认，尽早暴露连接错误
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			keys, err := cacher.UnmarshalInvalidation([]byte(msg.Payload))
+			if err != nil {
+				continue
+			}
+			fn(keys)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package redisbus_test
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/carteruu/cacher/bus/redisbus"
+	"github.com/redis/go-redis/v9"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBus(t *testing.T) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	defer client.Close()
+	bus := redisbus.New(client, "")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	got := make(chan []string, 1)
+	go bus.Subscribe(ctx, func(keys []string) { got <- keys })
+	//等待订阅生效
+	deadline := time.Now().Add(time.Second)
+	for mr.PubSubNumSub(redisbus.DefaultChannel)[redisbus.DefaultChannel] == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("subscribe timeout")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := bus.Publish(ctx, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case keys := <-got:
+		if !reflect.DeepEqual(keys, []string{"a", "b"}) {
+			t.Errorf("keys = %v, want [a b]", keys)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("message not delivered")
+	}
+}
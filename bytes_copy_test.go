@@ -0,0 +1,39 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestCacher_CopyBytes(t *testing.T) {
+	tests := []struct {
+		name       string
+		shareBytes bool
+		wantData   string
+	}{
+		{name: "默认复制", shareBytes: false, wantData: "abc"},
+		{name: "不复制", shareBytes: true, wantData: "xbc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := []byte("abc")
+			c := cacher.New(newRepoMap(map[string]interface{}{"k": buf}), 10*time.Second)
+			var v []byte
+			_, err := c.GetWithOption(context.Background(), "k", func() (interface{}, error) {
+				return nil, notNeedCall
+			}, &v, func(opt *cacher.Option) {
+				opt.ShareBytes = tt.shareBytes
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			//模拟驱动复用缓冲区
+			buf[0] = 'x'
+			if string(v) != tt.wantData {
+				t.Errorf("v = %s, want %s", v, tt.wantData)
+			}
+		})
+	}
+}
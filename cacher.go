@@ -3,10 +3,13 @@ package cacher
 import (
 	"context"
 	"errors"
+	"fmt"
 	"golang.org/x/sync/singleflight"
 	"math/rand"
 	"reflect"
 	"strconv"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -14,9 +17,35 @@ type (
 	// Cacher 缓存
 	Cacher struct {
 		repo     Repo                       //
-		expire   time.Duration              //缓存保留时长
+		tunables atomic.Value               //*tunables，缓存保留时长和默认选项，见 Tune
+		base     Option                     //New 设置的默认选项，Reload 在它的基础上应用配置
 		sf       singleflight.Group         //
-		typeConv map[typePair]TypeConverter //
+		typeConv map[typePair]TypeConverter //注册的转换器，第一次注册时创建，内置转换器见 builtinConv
+		convMu   sync.RWMutex               //保护 typeConv
+		events   eventBus                   //事件监听器
+		bus      InvalidationBus            //失效消息总线
+
+		invalidators []Invalidator //外部缓存失效钩子
+		flights      flightCache   //进程内短时缓存的查询结果
+		stats        *stats        //运行状态计数
+		typeTTLs     typeTTLs      //目标类型的默认缓存保留时长
+		zsets        localZSets    //进程内的有序集合，存储库未实现 ZRepo 时使用
+		leases       localLeases   //进程内的租约，存储库未实现 NXRepo 时使用
+		herds        herdDetector  //重复调用查询方法的检测
+		decoded      decodedCache  //进程内缓存的解码结果，见 Option.DecodeCache
+		backoffs     keyBackoffs   //查询方法连续出错的退避记录，见 Option.ErrorBackoff
+		workingSet   atomic.Value  //*workingSet，工作集大小的估计，见 TrackWorkingSet
+		quotas       quotaTable    //命名空间的配额和用量，见 SetNamespaceQuota
+		pins         timerTable    //固定的缓存键的刷新定时器，见 Pin
+		refreshes    timerTable    //自动刷新的缓存键的定时器，见 AutoRefresh
+		entities     entityTable   //实体的缓存键模板，见 RegisterEntity
+		waiters      keyWaiters    //每个缓存键等待查询结果的 goroutine 数量
+		multiFlights multiFlight   //GetMulti 正在查询的缓存键，见 GetMulti
+		dependLocks  keyLocks      //依赖索引的更新锁，见 addDependentTo
+		metrics      Metrics       //缓存指标的钩子，见 SetMetrics
+		tracer       Tracer        //链路跟踪的钩子，见 SetTracer
+		disabled     int32         //为1时缓存已关闭，见 Disable
+		recentErrors errorRing     //最近的内部错误，见 RecentErrors
 	}
 	// Repo 存储库接口，通过实现该接口，可以支持不同类型的存储方式
 	Repo interface {
@@ -24,9 +53,10 @@ type (
 		//缓存不存在时，需要返回 nil,nil
 		Get(ctx context.Context, key string) (interface{}, error)
 		// Set 保存
+		//expire 为保留时长，为 0 时永不过期。命名空间的代数（见 newNamespaceGen）和依赖索引（见 addDependentTo）依赖这一约定，不能把 0 当作立即过期或使用默认时长
 		Set(ctx context.Context, key string, value interface{}, expire time.Duration) error
-		// Del 删除
-		Del(ctx context.Context, key ...string) error
+		// Del 删除多个缓存键，缓存键不存在时忽略
+		Del(ctx context.Context, keys ...string) error
 	}
 	// TypeConverter 类型转换器
 	TypeConverter struct {
@@ -35,10 +65,48 @@ type (
 		Fn      func(src interface{}) (interface{}, error)
 	}
 	Option struct {
-		Expire         time.Duration   //缓存保留时长
-		NilData        interface{}     //空缓存数据
+		Expire         time.Duration   //缓存保留时长，等于0时使用 Cacher 的默认保留时长
+		NilData        interface{}     //空缓存数据，为 nil 时保存空缓存的标记，读取时赋值目标类型的零值
 		NilCacheExpire time.Duration   //空缓存保留时长。小于等于0时，不保存空缓存
 		Converters     []TypeConverter //转换器
+		Namespace      string          //命名空间，可以通过 Cacher.BumpNamespace 使命名空间下的所有缓存失效
+		DependsOn      []string        //依赖的缓存键，被依赖的缓存删除时，级联删除本缓存
+		Tags           []string        //缓存标签，传递给外部缓存失效钩子 Invalidator
+		ErrCacheExpire time.Duration   //查询错误的缓存保留时长，保留期间直接返回 CachedError。小于等于0时，不缓存查询错误
+		Jitter         float64         //保留时长随机增加的最大比例，避免缓存雪崩。等于0时为 0.1，小于0时不增加
+		OnNil          NilAction       //查询数据为空时的处理方式
+		Codec          Codec           //编解码器，为空时使用 JSON。设置后字节数据没有对应的转换器时用它解码为目标类型，保存前用它把结构体、切片、map 等编码为字节切片，不依赖存储库的编码方式
+		CodecFallback  bool            //字符串、字节切片数据没有对应的转换器时，用编解码器（Codec 为空时为 JSON）解码为结构体、切片、数组、map，不需要为每个类型注册转换器。默认返回 ErrUnsupportedConversion，设置了 Codec 或类型标签声明了编解码器时总是解码
+		LegacyConvert  bool            //允许有损的数值类型转换（溢出、截断、整数转字符串），兼容旧版本行为
+		StrictConvert  bool            //注册的转换器或单次调用的 Converters 与内置的类型转换冲突时返回 ErrConverterShadowed，而不是悄悄改变转换结果。应当在 New 中设置
+		ShareBytes     bool            //目标是字节切片时，直接使用存储库返回的字节切片，不复制
+		TargetType     interface{}     //目标变量 v 指向接口时，转换的目标类型
+		FlightCache    time.Duration   //查询结果在进程内的保留时长，平滑查询完成后紧接着到达的相同请求。小于等于0时不保留
+		HerdWindow     time.Duration   //同一个缓存键在该时长内重复调用查询方法时，触发 EventHerd 事件。小于等于0时不检测
+		Revalidate     float64         //命中缓存时，异步重新查询并与缓存数据比较的比例，取值 [0,1]。不一致时触发 EventMismatch 事件并更正缓存
+		Budget         time.Duration   //整个读取流程的时间预算，包括读取缓存、查询数据、转换和写入缓存。超时后返回旧数据和 ErrStale，没有旧数据时返回 ErrBudgetExceeded，可以通过 Degrade.LoaderTimeout 修改。小于等于0时不限制
+		StaleExpire    time.Duration   //缓存过期后旧数据的保留时长，用于超时时返回旧数据。小于等于0时不保留旧数据
+		BinaryNumbers  bool            //整数、浮点数、布尔类型的查询数据编码为定长二进制后保存，而不是由存储库格式化为十进制字符串。读取时总是识别定长二进制数值，开启前后的缓存可以共存
+		KeepCompressed bool            //读取时不自动解压字符串、字节切片类型的 gzip、zstd 等压缩数据。默认自动解压，兼容由其他系统压缩保存的旧数据
+		WriteMode      WriteMode       //Cacher.Write 修改数据源时处理缓存的模式，默认为 WriteAround
+		RefreshLock    time.Duration   //重建缓存时写入该保留时长的占位符并在重建期间续期，其他实例看到占位符时返回旧数据，不调用查询方法。需要同时设置 StaleExpire，没有旧数据时仍然调用查询方法。小于等于0时不写入
+		ErrorBackoff   time.Duration   //同一个缓存键的查询方法出错后，在该时长内不再调用查询方法，直接返回 ErrBackoff；连续出错时退避时长翻倍，最多为64倍，查询成功后重置。只在进程内生效。小于等于0时不退避
+		KeyHMAC        []byte          //缓存键的 HMAC 密钥，设置后存储库中使用缓存键的 HMAC-SHA256 代替原始缓存键，Del 使用相同的处理。应当在 New 中设置，不应在单次调用中修改
+		KeyPolicy      *KeyPolicy      //缓存键的规范化和校验规则，不符合时返回 *KeyError，为空时不处理。应当在 New 中设置，不应在单次调用中修改
+		OnDecodeError  DecodeAction    //缓存数据无法转换为目标类型（数据损坏或旧格式）时的处理方式，默认返回错误
+		Priority       Priority        //缓存的淘汰优先级，存储库实现了 PriorityRepo 时生效，默认为 PriorityNormal
+		MeasureSize    bool            //缓存数据不是字符串或字节切片时，按编解码器编码后计算字节数，用于 Event.Size 和 Result.Size。每次读写多一次编码
+		DecodeCache    bool            //命中缓存时，在进程内缓存字符串、字节切片解码后的结构体、切片、map，相同的缓存数据不再解码。目标变量与其他调用方共享引用类型的数据，不能修改
+		Degrade        Degradation     //读取缓存出错、写入缓存出错、无法转换、查询出错、查询超时时的降级策略，零值保持原有行为
+
+		ShouldCache func(v interface{}) bool                 //判断查询数据是否需要保存缓存，为空时都保存
+		Transform   func(v interface{}) (interface{}, error) //保存缓存前转换查询数据，在 ShouldCache 之前调用
+		Validate    func(v interface{}) bool                 //校验读取到的缓存数据（转换后的目标值），返回 false 时删除缓存并重新查询
+		Migrate     func(v interface{}) (interface{}, error) //OnDecodeError 为 DecodeMigrate 时，将无法转换的缓存数据升级为新格式，升级后的数据写回缓存
+
+		plans  *planCache                                              //类型转换方式的缓存，由 KeyTemplate 设置
+		lease  func(key string, toType reflect.Type, opt Option) error //代替查询方法获取租约，由 GetOrLease 设置
+		result *Result                                                 //单次读取的结果信息，由 WithResult 设置
 	}
 	typePair struct {
 		DstType reflect.Type
@@ -110,30 +178,36 @@ var (
 	}
 )
 
-func New(repo Repo, expire time.Duration) *Cacher {
+// New 创建 Cacher。optFns 设置所有调用的默认选项，例如默认的空缓存策略，每次调用时仍然可以覆盖
+func New(repo Repo, expire time.Duration, optFns ...func(opt *Option)) *Cacher {
 	if expire <= 0 {
 		panic(errors.New("缓存保存时长 expire 必须大于0"))
 	}
-	cache := Cacher{
-		repo:     repo,
-		expire:   expire,
-		sf:       singleflight.Group{},
-		typeConv: make(map[typePair]TypeConverter, len(typeConverters)),
-	}
-	for _, conv := range typeConverters {
-		if err := cache.RegisterConverter(conv); err != nil {
-			panic(err)
+	defaults := Option{}
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&defaults)
 		}
 	}
+	if err := defaults.Valid(); err != nil {
+		panic(err)
+	}
+	cache := Cacher{
+		repo:  repo,
+		base:  defaults,
+		sf:    singleflight.Group{},
+		stats: &stats{},
+	}
+	cache.tunables.Store(&tunables{expire: expire, defaults: defaults})
 	return &cache
 }
 
-// RegisterConverter 注册类型转换器
+// RegisterConverter 注册类型转换器，同一对类型已注册转换器时覆盖。开启 Option.StrictConvert 时，与内置的类型转换冲突返回 ErrConverterShadowed
 func (c *Cacher) RegisterConverter(converter TypeConverter) error {
-	if converter.SrcType == nil || converter.DstType == nil || converter.Fn == nil {
-		return errors.New("转换器错误")
+	if err := checkConverter(converter, c.options().StrictConvert); err != nil {
+		return err
 	}
-	c.typeConv[typePair{SrcType: reflect.TypeOf(converter.SrcType), DstType: reflect.TypeOf(converter.DstType)}] = converter
+	c.addConverter(pairOf(converter), converter, true)
 	return nil
 }
 
@@ -145,142 +219,645 @@ func (c *Cacher) Get(
 	queryFn func() (interface{}, error),
 	v interface{},
 ) (bool, error) {
-	return c.GetWithOption(ctx, key, queryFn, v, nil)
+	return c.GetWithOption(ctx, key, queryFn, v)
 }
 
+// GetWithOption 同 Get，可以通过 optFns 修改本次调用的选项，optFns 按顺序执行
 func (c *Cacher) GetWithOption(
 	ctx context.Context,
 	key string,
 	queryFunc func() (interface{}, error),
 	v interface{},
-	optFn func(opt *Option)) (useCache bool, _ error) {
+	optFns ...func(opt *Option)) (useCache bool, err error) {
 	if key == "" {
-		return false, errors.New("缓存键 key 不能为空字符串")
+		return false, ErrEmptyKey
 	}
 	if queryFunc == nil {
-		return false, errors.New("查询方法 queryFunc 不能为空")
+		return false, ErrNilQueryFunc
 	}
 
-	opt := Option{Expire: c.expire}
-	if optFn != nil {
-		optFn(&opt)
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
 	}
 	if err := opt.Valid(); err != nil {
 		return false, err
 	}
+	if opt.Budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opt.Budget)
+		defer cancel()
+	}
+	if c.Disabled() {
+		return false, c.bypass(ctx, key, queryFunc, v, opt)
+	}
+	key, err = c.buildKey(ctx, key, opt)
+	if err != nil {
+		return false, err
+	}
+	ctx, span := c.startSpan(ctx, SpanGet, key)
+	defer func() { span.end(useCache, err) }()
 
-	to := indirect(reflect.ValueOf(v))
-	toType, _ := indirectType(to.Type())
-
-	if toType.Kind() == reflect.Interface {
-		toType, _ = indirectType(reflect.TypeOf(to.Interface()))
-		oldTo := to
-		to = reflect.New(reflect.TypeOf(to.Interface())).Elem()
-		defer func() {
-			oldTo.Set(to)
-		}()
+	to, toType, finish, err := target(v, opt.TargetType)
+	if err != nil {
+		return false, err
+	}
+	defer finish()
+	if err := checkTypeTag(toType); err != nil {
+		return false, err
+	}
+	if opt.Expire == 0 {
+		opt.Expire = c.typeExpire(toType)
+	}
+	if opt.NilData != nil && toType != nil && !reflect.TypeOf(opt.NilData).ConvertibleTo(toType) {
+		return false, &OptionError{Field: "NilData", Reason: "空缓存数据的类型与目标类型不匹配"}
 	}
 
+	c.trackKey(key)
 	//查询缓存
 	cacheData, err := c.repo.Get(ctx, key)
 	//查询缓存错误
 	if err != nil {
-		return false, err
+		ok, hit, err := c.degrade(ctx, key, FailRepoRead, err, to, toType, opt)
+		if !ok {
+			return hit, err
+		}
+		cacheData = nil
+	}
+	if err := cachedError(key, cacheData); err != nil {
+		c.stats.hit()
+		c.emit(Event{Type: EventHit, Key: key})
+		c.observeHit(key, reflect.Value{}, reflect.Value{}, toType, opt)
+		opt.report(key, true, 0)
+		return true, err
 	}
 	from := reflect.ValueOf(cacheData)
 	useCache = true
-	if !from.IsValid() {
+	if !from.IsValid() && opt.FlightCache > 0 {
+		//进程内短时缓存的查询结果
+		if val, ok := c.flights.get(key); ok {
+			from = reflect.ValueOf(val)
+		}
+	}
+	if from.IsValid() && (opt.Validate != nil || opt.OnDecodeError != DecodeReturnError || opt.Degrade.Decode != DegradeDefault) {
+		//先赋值，转换失败时按 OnDecodeError 处理，仍然失败时按 Degrade.Decode 处理
+		if err := c.assign(from, to, toType, opt); err != nil {
+			if from, err = c.recoverDecode(ctx, key, from, to, toType, err, opt); err != nil {
+				ok, hit, err := c.degrade(ctx, key, FailDecode, err, to, toType, opt)
+				if !ok {
+					return hit, err
+				}
+				to.Set(reflect.Zero(to.Type()))
+				from = reflect.Value{}
+			}
+		}
+		if from.IsValid() && (opt.Validate == nil || opt.Validate(to.Interface())) {
+			size := c.measure(from, opt)
+			c.stats.hit()
+			c.emit(Event{Type: EventHit, Key: key, Size: size})
+			c.observeHit(key, from, to, toType, opt)
+			c.revalidateAsync(ctx, key, from, queryFunc, toType, opt)
+			opt.report(key, true, size)
+			return true, nil
+		}
+		if from.IsValid() {
+			//校验缓存数据，校验不通过时当作没有缓存，删除后重新查询
+			to.Set(reflect.Zero(to.Type()))
+			c.flights.del(key)
+			if err := c.repo.Del(ctx, key); err != nil {
+				return false, err
+			}
+			c.emit(Event{Type: EventDel, Key: key})
+			from = reflect.Value{}
+		}
+	}
+	size := 0
+	hit := from.IsValid()
+	if hit {
+		size = c.measure(from, opt)
+		c.stats.hit()
+		c.emit(Event{Type: EventHit, Key: key, Size: size})
+		c.revalidateAsync(ctx, key, from, queryFunc, toType, opt)
+	} else {
 		//没有缓存
-		sfVal, err, _ := c.sf.Do(key, func() (interface{}, error) {
-			//调用传入的查询数据的方法，查询数据
-			queryData, err := queryFunc()
-			if err != nil {
-				return nil, err
+		c.stats.miss()
+		c.emit(Event{Type: EventMiss, Key: key})
+		c.observeMiss(key)
+		//有时间预算时，超时返回后查询结果仍然写入缓存
+		storeCtx := ctx
+		if opt.Budget > 0 {
+			storeCtx = detach(ctx)
+		}
+		load := func() (interface{}, error) {
+			if opt.lease != nil {
+				//由租约持有者计算数据，见 GetOrLease
+				return nil, opt.lease(key, toType, opt)
 			}
-			//查询数据为空
-			if queryData == nil {
-				//设置空缓存
-				if !opt.isCacheNil() {
-					return nil, nil
+			if opt.ErrorBackoff > 0 {
+				if err := c.backoffs.check(key); err != nil {
+					return nil, &loadFailure{err: err}
 				}
-				nilFrom := reflect.ValueOf(opt.NilData)
-				if !nilFrom.IsValid() {
-					nilFrom = reflect.Zero(toType)
+			}
+			if opt.RefreshLock > 0 {
+				if ok, end := c.beginRefresh(storeCtx, key, opt.RefreshLock); ok {
+					defer end()
+				} else if data := c.staleData(storeCtx, key); data != nil {
+					//其他实例正在重建缓存，返回旧数据
+					return staleValue{data: data}, nil
 				}
-				if err := c.repo.Set(ctx, key, nilFrom.Interface(), opt.NilCacheExpire); err != nil {
-					return nil, err
+			}
+			if opt.HerdWindow > 0 && c.herds.observe(key, opt.HerdWindow) {
+				c.stats.herd()
+				c.emit(Event{Type: EventHerd, Key: key})
+			}
+			//调用传入的查询数据的方法，查询数据；正在被 GetMulti 查询时等待其结果
+			queryData, owned, err := c.loadShared(storeCtx, key, queryFunc)
+			if opt.ErrorBackoff > 0 {
+				if err != nil {
+					c.backoffs.fail(key, opt.ErrorBackoff, err)
+				} else {
+					c.backoffs.reset(key)
+				}
+			}
+			if err != nil {
+				if opt.ErrCacheExpire > 0 {
+					if setErr := c.storeError(storeCtx, key, err, opt.withJitter(opt.ErrCacheExpire)); setErr != nil {
+						c.emitError("error-cache", key, setErr)
+					}
 				}
-				return nilFrom.Interface(), nil
+				return nil, &loadFailure{err: err}
 			}
-			//设置缓存
-			//缓存时长,加一个小于 十分之一缓存时间 的随机数，避免缓存雪崩
-			cacheExpire := opt.Expire + time.Duration(rand.Int63n(int64(opt.Expire)/10))
-			if err := c.repo.Set(ctx, key, queryData, cacheExpire); err != nil {
-				return nil, err
+			if !owned {
+				//查询数据已由 GetMulti 保存
+				data, _, _, err := c.prepareSave(queryData, toType, opt)
+				return data, err
 			}
-			return queryData, nil
-		})
+			return c.save(storeCtx, key, queryData, toType, opt)
+		}
+		var sfVal interface{}
+		leave := c.waiters.enter(key)
+		defer leave()
+		if opt.Budget > 0 {
+			//时间预算内没有查询完成时，查询继续在后台执行并写入缓存，本次调用返回旧数据或超时错误
+			select {
+			case r := <-c.sf.DoChan(key, load):
+				sfVal, err = r.Val, r.Err
+			case <-ctx.Done():
+				_, hit, err := c.degrade(ctx, key, FailLoaderTimeout, ErrBudgetExceeded, to, toType, opt)
+				return hit, err
+			}
+		} else {
+			sfVal, err, _ = c.sf.Do(key, load)
+		}
 		if err != nil {
+			var lf *loadFailure
+			if errors.As(err, &lf) {
+				_, hit, err := c.degrade(ctx, key, loaderFailure(lf.err), lf.err, to, toType, opt)
+				return hit, err
+			}
 			return false, err
 		}
 		if sfVal == nil {
 			return false, nil
 		}
-		from = reflect.ValueOf(sfVal)
-		useCache = false
+		if stale, ok := sfVal.(staleValue); ok {
+			from = reflect.ValueOf(stale.data)
+		} else {
+			from = reflect.ValueOf(sfVal)
+			useCache = false
+			if opt.FlightCache > 0 {
+				c.flights.set(key, sfVal, opt.FlightCache)
+			}
+		}
 	}
-	//先使用option的转换器
-	fromType, _ := indirectType(from.Type())
-	for _, conv := range opt.Converters {
-		if fromType == reflect.TypeOf(conv.SrcType) && toType == reflect.TypeOf(conv.DstType) {
-			val, err := conv.Fn(from.Interface())
-			if err != nil {
-				return false, err
+	if useCache && opt.DecodeCache {
+		err = c.assignDecoded(key, from, to, toType, opt)
+	} else {
+		err = c.assign(from, to, toType, opt)
+	}
+	if err != nil {
+		return false, err
+	}
+	if hit {
+		c.observeHit(key, from, to, toType, opt)
+	}
+	if opt.result != nil && size == 0 {
+		size = c.measure(from, opt)
+	}
+	opt.report(key, useCache, size)
+	return useCache, nil
+}
+
+// prepareSave 按 Option 处理查询数据，返回赋值给目标变量的数据。store 为 true 时需要以保留时长 expire 保存该数据。
+// 保存空缓存时返回 Option.NilData，没有 NilData 时返回空缓存的标记，由 assign 赋值零值
+func (c *Cacher) prepareSave(queryData interface{}, toType reflect.Type, opt Option) (data interface{}, expire time.Duration, store bool, err error) {
+	//查询数据为空
+	if queryData == nil {
+		if opt.OnNil == NilNotFound {
+			return nil, 0, false, ErrNotFound
+		}
+		//设置空缓存
+		if !opt.isCacheNil() {
+			return nil, 0, false, nil
+		}
+		nilFrom := reflect.ValueOf(opt.NilData)
+		if !nilFrom.IsValid() {
+			if toType == nil {
+				//目标类型未知，无法生成空缓存数据
+				return nil, 0, false, nil
 			}
-			if val != nil {
-				to.Set(reflect.ValueOf(val))
-			} else {
-				to.Set(reflect.Zero(to.Type()))
+			//没有空缓存数据时保存空缓存的标记，读取时赋值目标类型的零值
+			return nilCacheMarker, opt.withJitter(opt.nilCacheExpire()), true, nil
+		}
+		return nilFrom.Interface(), opt.withJitter(opt.nilCacheExpire()), true, nil
+	}
+	if opt.Transform != nil {
+		if queryData, err = opt.Transform(queryData); err != nil {
+			return nil, 0, false, err
+		}
+	}
+	if opt.ShouldCache != nil && !opt.ShouldCache(queryData) {
+		return queryData, 0, false, nil
+	}
+	return queryData, opt.withJitter(opt.Expire), true, nil
+}
+
+// save 保存查询数据，返回赋值给目标变量的数据。查询数据为空时按 Option 处理空缓存
+func (c *Cacher) save(ctx context.Context, key string, queryData interface{}, toType reflect.Type, opt Option) (interface{}, error) {
+	data, expire, store, err := c.prepareSave(queryData, toType, opt)
+	if err != nil || !store {
+		return data, err
+	}
+	//设置缓存
+	if err := c.store(ctx, key, data, expire, opt); err != nil {
+		if ok, _, err := c.degrade(ctx, key, FailRepoWrite, err, reflect.Value{}, toType, opt); !ok {
+			return nil, err
+		}
+	}
+	return data, nil
+}
+
+// store 保存缓存，并登记缓存的依赖
+func (c *Cacher) store(ctx context.Context, key string, value interface{}, expire time.Duration, opt Option) (err error) {
+	if c.Disabled() {
+		//缓存关闭时不写入，删除旧的缓存，避免 Write 修改数据源后恢复缓存时读到旧数据
+		return c.repo.Del(ctx, key)
+	}
+	ctx, span := c.startSpan(ctx, SpanSet, key)
+	defer func() { span.end(false, err) }()
+	value, err = encodeValue(value, opt)
+	if err != nil {
+		return err
+	}
+	if ok, err := c.checkQuota(ctx, key, value, expire, opt); !ok || err != nil {
+		//超出命名空间的配额，不保存缓存
+		return err
+	}
+	if err := c.set(ctx, key, value, expire, opt.Priority); err != nil {
+		return err
+	}
+	return c.stored(ctx, key, value, expire, opt)
+}
+
+// stored 缓存保存后，发布事件、保存旧数据副本，并登记缓存的依赖。显式写入时由调用方通知外部缓存失效，见 invalidateWritten
+func (c *Cacher) stored(ctx context.Context, key string, value interface{}, expire time.Duration, opt Option) error {
+	c.emit(Event{Type: EventSet, Key: key, Size: c.measure(reflect.ValueOf(value), opt)})
+	if opt.StaleExpire > 0 {
+		if err := c.repo.Set(ctx, staleKey(key), value, expire+opt.StaleExpire); err != nil {
+			c.emitError("stale-copy", key, err)
+		}
+	}
+	dependsOn := opt.DependsOn
+	if (len(opt.KeyHMAC) > 0 || opt.KeyPolicy != nil) && len(dependsOn) > 0 {
+		dependsOn = make([]string, len(opt.DependsOn))
+		for i, dep := range opt.DependsOn {
+			var err error
+			if dependsOn[i], err = repoKey(dep, opt); err != nil {
+				return err
 			}
-			return useCache, nil
+		}
+	}
+	return c.addDependent(ctx, key, dependsOn, expire)
+}
+
+// assign 将缓存数据 from 转换为 toType 类型后赋值给 to
+func (c *Cacher) assign(from, to reflect.Value, toType reflect.Type, opt Option) error {
+	if !from.IsValid() {
+		return ErrNilCached
+	}
+	if isNilMarker(from) {
+		return assignNil(to, toType, opt)
+	}
+	if !opt.KeepCompressed {
+		var err error
+		if from, err = decompress(from); err != nil {
+			return err
+		}
+	}
+	if number, ok := decodeNumber(from, toType); ok {
+		from = number
+	}
+	if toType == nil {
+		//目标是 nil 接口，且没有指定目标类型，直接赋值原始数据
+		if !from.Type().AssignableTo(to.Type()) {
+			return &ConversionError{From: from.Type(), To: to.Type()}
+		}
+		if !opt.ShareBytes && isBytes(from.Type()) && !from.IsNil() {
+			from = reflect.ValueOf(append([]byte(nil), from.Bytes()...)).Convert(from.Type())
+		}
+		to.Set(from)
+		return nil
+	}
+	if from.Kind() == reflect.Ptr && from.IsNil() {
+		return ErrNilCached
+	}
+	fromType, _ := indirectType(from.Type())
+	pair := typePair{SrcType: fromType, DstType: toType}
+	plan, ok := opt.plans.load(pair)
+	if !ok {
+		plan = c.resolvePlan(from, pair, opt)
+		opt.plans.store(pair, plan)
+	}
+	switch plan.kind {
+	case planConverter:
+		return setConverted(to, plan.conv, from)
+	case planCodec:
+		return setDecoded(to, toType, from, opt)
+	case planConvert:
+		if !from.CanConvert(toType) {
+			break
+		}
+		if !opt.LegacyConvert {
+			if err := checkLossless(from, toType); err != nil {
+				return err
+			}
+		}
+		converted := from.Convert(toType)
+		if !opt.ShareBytes && isBytes(toType) && !converted.IsNil() {
+			//存储库返回的字节切片可能被驱动复用，复制后再赋值
+			converted = reflect.ValueOf(append([]byte(nil), converted.Bytes()...)).Convert(toType)
+		}
+		to.Set(converted)
+		return nil
+	}
+	return &ConversionError{From: fromType, To: toType}
+}
+
+// resolvePlan 确定把 from 转换为 pair.DstType 类型的方式
+func (c *Cacher) resolvePlan(from reflect.Value, pair typePair, opt Option) convertPlan {
+	//先使用option的转换器
+	for _, conv := range opt.Converters {
+		if pair.SrcType == reflect.TypeOf(conv.SrcType) && pair.DstType == reflect.TypeOf(conv.DstType) {
+			return convertPlan{kind: planConverter, conv: conv}
 		}
 	}
 	//再尝试类型转换
-	if from.CanConvert(toType) {
-		to.Set(from.Convert(toType))
-		return useCache, nil
+	if from.CanConvert(pair.DstType) {
+		return convertPlan{kind: planConvert}
 	}
-	//最后尝试注册的类型转换器
-	if conv, ok := c.typeConv[typePair{SrcType: fromType, DstType: toType}]; ok {
-		val, err := conv.Fn(from.Interface())
-		if err != nil {
-			return false, err
+	//再尝试注册的类型转换器
+	if conv, ok := c.converter(pair); ok {
+		return convertPlan{kind: planConverter, conv: conv}
+	}
+	//最后使用编解码器解码字节数据
+	if isEncoded(pair.SrcType) && isCodecType(pair.DstType) && opt.decodeFallback(pair.DstType) {
+		return convertPlan{kind: planCodec}
+	}
+	return convertPlan{}
+}
+
+// setConverted 使用转换器转换 from，并赋值给 to
+func setConverted(to reflect.Value, conv TypeConverter, from reflect.Value) error {
+	val, err := conv.Fn(from.Interface())
+	if err != nil {
+		return &ConversionError{From: from.Type(), To: to.Type(), Err: err}
+	}
+	if val != nil {
+		rv := reflect.ValueOf(val)
+		if !rv.Type().AssignableTo(to.Type()) {
+			return &ConversionError{From: from.Type(), To: to.Type(), Err: fmt.Errorf("转换器返回的类型 %v 无法赋值", rv.Type())}
 		}
-		if val != nil {
-			to.Set(reflect.ValueOf(val))
-		} else {
-			to.Set(reflect.Zero(to.Type()))
+		to.Set(rv)
+	} else {
+		to.Set(reflect.Zero(to.Type()))
+	}
+	return nil
+}
+
+// Del 删除一个或多个缓存，同时级联删除依赖这些缓存的缓存。
+// 存储库实现了 BatchRepo 时调用一次 MDel，否则调用一次 Repo.Del 删除所有缓存键
+func (c *Cacher) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	opt := c.options()
+	repoKeys := make([]string, len(keys))
+	for i, key := range keys {
+		var err error
+		if repoKeys[i], err = repoKey(key, opt); err != nil {
+			return err
 		}
-		return useCache, nil
 	}
-	return false, errors.New("不支持的类型转换")
+	return c.delMulti(ctx, repoKeys)
 }
 
-// Del 删除缓存
-func (c *Cacher) Del(ctx context.Context, key string) error {
-	return c.repo.Del(ctx, key)
+// del 删除存储库中的缓存键 key，同时级联删除依赖该缓存的缓存
+func (c *Cacher) del(ctx context.Context, key string) error {
+	return c.delMulti(ctx, []string{key})
 }
 
+// delMulti 删除存储库中的多个缓存键，同时级联删除依赖这些缓存的缓存
+func (c *Cacher) delMulti(ctx context.Context, keys []string) (err error) {
+	ctx, span := c.startSpan(ctx, SpanDel, keys...)
+	defer func() { span.end(false, err) }()
+	var all []string
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		dependents, err := c.dependents(ctx, key)
+		if err != nil {
+			return err
+		}
+		for _, k := range dependents {
+			if !seen[k] {
+				seen[k] = true
+				all = append(all, k)
+			}
+		}
+	}
+	keys = all
+	delKeys := make([]string, 0, len(keys)*2)
+	for _, k := range keys {
+		delKeys = append(delKeys, k, dependIndexKey(k))
+	}
+	if batch, ok := c.repo.(BatchRepo); ok {
+		if err := batch.MDel(ctx, delKeys...); err != nil {
+			return err
+		}
+	} else if err := c.repo.Del(ctx, delKeys...); err != nil {
+		return err
+	}
+	c.quotas.release(keys...)
+	c.flights.del(keys...)
+	for _, k := range keys {
+		c.emit(Event{Type: EventDel, Key: k})
+	}
+	if err := c.invalidate(ctx, keys, nil); err != nil {
+		return err
+	}
+	if c.bus != nil {
+		return c.bus.Publish(ctx, keys...)
+	}
+	return nil
+}
+
+// Valid 校验选项，选项错误时返回 *OptionError，可以通过 errors.Is(err, ErrInvalidOption) 判断
 func (o Option) Valid() error {
-	if o.Expire <= 0 {
-		return errors.New("expire need bigger 0")
+	if o.Expire < 0 {
+		return &OptionError{Field: "Expire", Reason: "不能小于0"}
+	}
+	if o.NilCacheExpire < 0 {
+		return &OptionError{Field: "NilCacheExpire", Reason: "不能小于0"}
+	}
+	if o.OnNil < NilDefault || o.OnNil > NilNotFound {
+		return &OptionError{Field: "OnNil", Reason: "不支持的处理方式"}
+	}
+	if o.OnDecodeError < DecodeReturnError || o.OnDecodeError > DecodeMigrate {
+		return &OptionError{Field: "OnDecodeError", Reason: "不支持的处理方式"}
+	}
+	if o.OnDecodeError == DecodeMigrate && o.Migrate == nil {
+		return &OptionError{Field: "Migrate", Reason: "OnDecodeError 为 DecodeMigrate 时不能为空"}
+	}
+	if o.Priority < PriorityLow || o.Priority > PriorityHigh {
+		return &OptionError{Field: "Priority", Reason: "不支持的优先级"}
+	}
+	if o.WriteMode < WriteAround || o.WriteMode > WriteBehind {
+		return &OptionError{Field: "WriteMode", Reason: "不支持的写入模式"}
+	}
+	if o.NilData != nil && o.NilCacheExpire == 0 && o.OnNil != NilCache {
+		return &OptionError{Field: "NilData", Reason: "设置了空缓存数据，但 NilCacheExpire 为0，空缓存不会保存"}
+	}
+	if o.ErrCacheExpire < 0 {
+		return &OptionError{Field: "ErrCacheExpire", Reason: "不能小于0"}
+	}
+	if o.Jitter > 1 || o.Jitter != o.Jitter {
+		return &OptionError{Field: "Jitter", Reason: "不能大于1"}
+	}
+	if o.Budget < 0 {
+		return &OptionError{Field: "Budget", Reason: "不能小于0"}
+	}
+	if o.StaleExpire < 0 {
+		return &OptionError{Field: "StaleExpire", Reason: "不能小于0"}
+	}
+	if o.RefreshLock > 0 && o.StaleExpire <= 0 {
+		return &OptionError{Field: "RefreshLock", Reason: "需要同时设置 StaleExpire，否则其他实例没有旧数据可以返回"}
+	}
+	if o.Revalidate < 0 || o.Revalidate > 1 || o.Revalidate != o.Revalidate {
+		return &OptionError{Field: "Revalidate", Reason: "取值范围为 [0,1]"}
+	}
+	if err := o.Degrade.valid(o); err != nil {
+		return err
+	}
+	for i, conv := range o.Converters {
+		if err := checkConverter(conv, o.StrictConvert); err != nil {
+			return &OptionError{Field: fmt.Sprintf("Converters[%d]", i), Reason: err.Error()}
+		}
+	}
+	for i, dep := range o.DependsOn {
+		if dep == "" {
+			return &OptionError{Field: fmt.Sprintf("DependsOn[%d]", i), Reason: "依赖的缓存键不能为空字符串"}
+		}
 	}
 	return nil
 }
 
+// clone 复制选项，切片字段在追加元素时不会修改原选项
+func (o Option) clone() Option {
+	o.Converters = o.Converters[:len(o.Converters):len(o.Converters)]
+	o.DependsOn = o.DependsOn[:len(o.DependsOn):len(o.DependsOn)]
+	o.Tags = o.Tags[:len(o.Tags):len(o.Tags)]
+	return o
+}
+
+// withJitter 缓存时长加一个随机数，避免同时写入的缓存同时过期，引起缓存雪崩
+func (o Option) withJitter(expire time.Duration) time.Duration {
+	jitter := o.Jitter
+	if jitter == 0 {
+		jitter = 0.1
+	}
+	n := int64(float64(expire) * jitter)
+	if n <= 0 {
+		return expire
+	}
+	return expire + time.Duration(rand.Int63n(n))
+}
+
 //是否保存空缓存
 func (o Option) isCacheNil() bool {
-	return o.NilCacheExpire > 0
+	return o.nilCacheExpire() > 0
+}
+
+// nilCacheExpire 空缓存保留时长
+func (o Option) nilCacheExpire() time.Duration {
+	if o.OnNil == NilCache && o.NilCacheExpire == 0 {
+		return o.Expire
+	}
+	return o.NilCacheExpire
+}
+
+// target 解析目标变量 v，返回用于赋值的 to 及目标类型 toType，赋值完成后需要调用 finish。
+// v 指向接口时，目标类型依次取 targetType 的类型、接口当前值的类型；都没有时 toType 为 nil，赋值原始数据。
+// v 不是可以赋值的非空指针时，返回错误
+func target(v interface{}, targetType interface{}) (to reflect.Value, toType reflect.Type, finish func(), _ error) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return to, nil, nil, &ConversionError{To: reflect.TypeOf(v), Err: fmt.Errorf("%w: 必须是非空指针", ErrInvalidTarget)}
+	}
+	to = rv
+	for to.Kind() == reflect.Ptr {
+		if to.IsNil() {
+			if !to.CanSet() {
+				return to, nil, nil, &ConversionError{To: reflect.TypeOf(v), Err: fmt.Errorf("%w: 包含无法赋值的空指针", ErrInvalidTarget)}
+			}
+			to.Set(reflect.New(to.Type().Elem()))
+		}
+		to = to.Elem()
+	}
+	if !to.CanSet() {
+		return to, nil, nil, &ConversionError{To: reflect.TypeOf(v), Err: fmt.Errorf("%w: 无法赋值", ErrInvalidTarget)}
+	}
+	if to.Kind() != reflect.Interface {
+		return to, to.Type(), func() {}, nil
+	}
+
+	var concrete reflect.Type
+	switch {
+	case targetType != nil:
+		concrete = reflect.TypeOf(targetType)
+	case !to.IsNil():
+		concrete = reflect.TypeOf(to.Interface())
+	default:
+		return to, nil, func() {}, nil
+	}
+	if !concrete.AssignableTo(to.Type()) {
+		return to, nil, nil, &ConversionError{From: concrete, To: to.Type(), Err: fmt.Errorf("%w: 目标类型无法赋值", ErrInvalidTarget)}
+	}
+	oldTo := to
+	holder := reflect.New(concrete).Elem()
+	inner := holder
+	for inner.Kind() == reflect.Ptr {
+		inner.Set(reflect.New(inner.Type().Elem()))
+		inner = inner.Elem()
+	}
+	return inner, inner.Type(), func() {
+		oldTo.Set(holder)
+	}, nil
+}
+
+// isBytes 是否字节切片类型
+func isBytes(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
 }
 
 func indirect(reflectValue reflect.Value) reflect.Value {
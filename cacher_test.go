@@ -1,8 +1,11 @@
 package cacher_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
+	"time"
 )
 
 var (
@@ -65,3 +68,46 @@ type (
 		Address address `json:"address"`
 	}
 )
+
+// repoMap 基于 map 的测试存储库
+type repoMap struct {
+	mu     sync.Mutex
+	data   map[string]interface{}
+	getErr error
+	delErr error
+}
+
+func newRepoMap(data map[string]interface{}) *repoMap {
+	if data == nil {
+		data = make(map[string]interface{})
+	}
+	return &repoMap{data: data}
+}
+
+func (r *repoMap) Get(ctx context.Context, key string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.getErr != nil {
+		return nil, r.getErr
+	}
+	return r.data[key], nil
+}
+
+func (r *repoMap) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data[key] = value
+	return nil
+}
+
+func (r *repoMap) Del(ctx context.Context, keys ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.delErr != nil {
+		return r.delErr
+	}
+	for _, key := range keys {
+		delete(r.data, key)
+	}
+	return nil
+}
@@ -0,0 +1,197 @@
+// Package cacherbench 缓存压测工具：按配置的读写比例和键分布压测任意存储库，
+// 统计命中率、延迟分位数和内存分配，用于比较不同存储库和验证调优效果
+package cacherbench
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/carteruu/cacher"
+	"math/rand"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// Config 压测配置
+type Config struct {
+	Ops         int           //总操作数
+	Concurrency int           //并发数，小于等于0时为1
+	Keys        int           //键空间大小
+	KeyPrefix   string        //缓存键前缀
+	ReadRatio   float64       //读操作比例，取值 [0,1]，其余为写操作（删除缓存，模拟数据更新后缓存失效）
+	Skew        float64       //键分布的 Zipf 参数 s，大于1时使用 Zipf 分布，越大越集中；否则均匀分布
+	ValueSize   int           //查询数据的字节数
+	Expire      time.Duration //缓存保留时长，小于等于0时为1分钟
+	Seed        int64         //随机数种子
+}
+
+// Result 压测结果
+type Result struct {
+	Ops         int           //总操作数
+	Reads       int           //读操作数
+	Writes      int           //写操作数
+	Hits        int           //读操作命中缓存数
+	Misses      int           //读操作未命中缓存数，即调用查询方法（回源）的次数
+	HitRatio    float64       //命中率
+	Errors      int           //出错的操作数
+	Elapsed     time.Duration //总耗时
+	P50         time.Duration //延迟分位数
+	P90         time.Duration
+	P99         time.Duration
+	Max         time.Duration
+	AllocsPerOp float64 //每次操作的内存分配次数，包括并发的其他 goroutine
+	BytesPerOp  float64 //每次操作分配的字节数
+}
+
+func (r Result) String() string {
+	return fmt.Sprintf("ops=%d reads=%d writes=%d hit=%.2f%% errors=%d elapsed=%s p50=%s p90=%s p99=%s max=%s allocs/op=%.1f B/op=%.1f",
+		r.Ops, r.Reads, r.Writes, r.HitRatio*100, r.Errors, r.Elapsed, r.P50, r.P90, r.P99, r.Max, r.AllocsPerOp, r.BytesPerOp)
+}
+
+// workerResult 单个并发的统计数据
+type workerResult struct {
+	reads, writes, hits, errors int
+	latencies                   []time.Duration
+}
+
+// Run 使用 repo 创建 Cacher 并按配置压测，ctx 结束时提前返回已完成操作的统计
+func Run(ctx context.Context, repo cacher.Repo, cfg Config) (Result, error) {
+	if cfg.Ops <= 0 {
+		return Result{}, errors.New("总操作数 Ops 必须大于0")
+	}
+	if cfg.Keys <= 0 {
+		return Result{}, errors.New("键空间大小 Keys 必须大于0")
+	}
+	if cfg.ReadRatio < 0 || cfg.ReadRatio > 1 {
+		return Result{}, errors.New("读操作比例 ReadRatio 取值范围为 [0,1]")
+	}
+	if cfg.Concurrency <= 0 {
+		cfg.Concurrency = 1
+	}
+	if cfg.Expire <= 0 {
+		cfg.Expire = time.Minute
+	}
+	c := cacher.New(repo, cfg.Expire)
+	value := make([]byte, cfg.ValueSize)
+	query := func() (interface{}, error) {
+		return value, nil
+	}
+
+	results := make([]workerResult, cfg.Concurrency)
+	var wg sync.WaitGroup
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for w := 0; w < cfg.Concurrency; w++ {
+		ops := cfg.Ops / cfg.Concurrency
+		if w < cfg.Ops%cfg.Concurrency {
+			ops++
+		}
+		wg.Add(1)
+		go func(w, ops int) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(cfg.Seed + int64(w)))
+			next := keyGen(r, cfg)
+			res := workerResult{latencies: make([]time.Duration, 0, ops)}
+			var v []byte
+			for i := 0; i < ops && ctx.Err() == nil; i++ {
+				key := cfg.KeyPrefix + strconv.Itoa(next())
+				if r.Float64() < cfg.ReadRatio {
+					res.get(ctx, c, key, query, &v)
+				} else {
+					res.del(ctx, c, key)
+				}
+			}
+			results[w] = res
+		}(w, ops)
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+
+	return summarize(results, elapsed, &before, &after), ctx.Err()
+}
+
+// get 读取缓存并记录
+func (w *workerResult) get(ctx context.Context, c *cacher.Cacher, key string, query func() (interface{}, error), v interface{}) {
+	start := time.Now()
+	useCache, err := c.Get(ctx, key, query, v)
+	w.latencies = append(w.latencies, time.Since(start))
+	w.reads++
+	if useCache {
+		w.hits++
+	}
+	if err != nil {
+		w.errors++
+	}
+}
+
+// del 删除缓存并记录
+func (w *workerResult) del(ctx context.Context, c *cacher.Cacher, key string) {
+	start := time.Now()
+	err := c.Del(ctx, key)
+	w.latencies = append(w.latencies, time.Since(start))
+	w.writes++
+	if err != nil {
+		w.errors++
+	}
+}
+
+// summarize 汇总各并发的统计数据
+func summarize(results []workerResult, elapsed time.Duration, before, after *runtime.MemStats) Result {
+	result := Result{Elapsed: elapsed}
+	var latencies []time.Duration
+	for _, res := range results {
+		result.Reads += res.reads
+		result.Writes += res.writes
+		result.Hits += res.hits
+		result.Errors += res.errors
+		latencies = append(latencies, res.latencies...)
+	}
+	result.Ops = result.Reads + result.Writes
+	result.Misses = result.Reads - result.Hits
+	if result.Reads > 0 {
+		result.HitRatio = float64(result.Hits) / float64(result.Reads)
+	}
+	if result.Ops > 0 {
+		result.AllocsPerOp = float64(after.Mallocs-before.Mallocs) / float64(result.Ops)
+		result.BytesPerOp = float64(after.TotalAlloc-before.TotalAlloc) / float64(result.Ops)
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	result.P50 = percentile(latencies, 0.5)
+	result.P90 = percentile(latencies, 0.9)
+	result.P99 = percentile(latencies, 0.99)
+	result.Max = percentile(latencies, 1)
+	return result
+}
+
+// keyGen 按配置的键分布生成键序号
+func keyGen(r *rand.Rand, cfg Config) func() int {
+	if cfg.Skew > 1 && cfg.Keys > 1 {
+		zipf := rand.NewZipf(r, cfg.Skew, 1, uint64(cfg.Keys-1))
+		return func() int {
+			return int(zipf.Uint64())
+		}
+	}
+	return func() int {
+		return r.Intn(cfg.Keys)
+	}
+}
+
+// percentile 获取已排序延迟的分位数
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(float64(len(sorted))*p+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i]
+}
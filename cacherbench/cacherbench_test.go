@@ -0,0 +1,87 @@
+package cacherbench_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher/cacherbench"
+	"sync"
+	"testing"
+	"time"
+)
+
+type repoMap struct {
+	mu   sync.Mutex
+	data map[string]interface{}
+}
+
+func (r *repoMap) Get(ctx context.Context, key string) (interface{}, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.data[key], nil
+}
+
+func (r *repoMap) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.data[key] = value
+	return nil
+}
+
+func (r *repoMap) Del(ctx context.Context, keys ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		delete(r.data, key)
+	}
+	return nil
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  cacherbench.Config
+		want func(r cacherbench.Result) bool
+	}{
+		{
+			name: "只读，均匀分布",
+			cfg:  cacherbench.Config{Ops: 1000, Concurrency: 4, Keys: 10, ReadRatio: 1, ValueSize: 16},
+			want: func(r cacherbench.Result) bool {
+				return r.Reads == 1000 && r.Writes == 0 && r.Misses >= 10 && r.HitRatio > 0.9
+			},
+		}, {
+			name: "只写",
+			cfg:  cacherbench.Config{Ops: 100, Keys: 10, ReadRatio: 0},
+			want: func(r cacherbench.Result) bool {
+				return r.Writes == 100 && r.Reads == 0 && r.HitRatio == 0
+			},
+		}, {
+			name: "读写混合，Zipf 分布",
+			cfg:  cacherbench.Config{Ops: 1000, Concurrency: 3, Keys: 1000, ReadRatio: 0.9, Skew: 1.2, Seed: 1},
+			want: func(r cacherbench.Result) bool {
+				return r.Ops == 1000 && r.Reads > r.Writes && r.P50 <= r.P99 && r.P99 <= r.Max
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := cacherbench.Run(context.Background(), &repoMap{data: map[string]interface{}{}}, tt.cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if r.Errors != 0 || !tt.want(r) {
+				t.Errorf("Run() = %s", r)
+			}
+		})
+	}
+}
+
+func TestRun_InvalidConfig(t *testing.T) {
+	for _, cfg := range []cacherbench.Config{
+		{Keys: 1},
+		{Ops: 1},
+		{Ops: 1, Keys: 1, ReadRatio: 2},
+	} {
+		if _, err := cacherbench.Run(context.Background(), &repoMap{data: map[string]interface{}{}}, cfg); err == nil {
+			t.Errorf("Run(%+v) error = nil", cfg)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package cacherbench
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/carteruu/cacher"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// 访问记录的操作类型
+const (
+	OpGet = "get" //读取缓存
+	OpDel = "del" //删除缓存
+)
+
+// TraceEntry 缓存访问记录
+type TraceEntry struct {
+	Time time.Time `json:"time"` //访问时间
+	Op   string    `json:"op"`   //操作类型，OpGet 或 OpDel
+	Key  string    `json:"key"`  //缓存键
+}
+
+// Recorder 缓存访问记录器，通过 Cacher 的事件记录访问，每条记录以一行 JSON 写入
+type Recorder struct {
+	mu     sync.Mutex
+	enc    *json.Encoder
+	err    error
+	cancel func()
+}
+
+// Record 开始记录 c 的缓存访问，写入 w。调用 Recorder.Stop 停止记录
+func Record(c *cacher.Cacher, w io.Writer) *Recorder {
+	r := &Recorder{enc: json.NewEncoder(w)}
+	r.cancel = c.OnEvent(r.record)
+	return r
+}
+
+// record 记录读取和删除事件，其他事件都是这两种操作的结果，不需要记录
+func (r *Recorder) record(ev cacher.Event) {
+	entry := TraceEntry{Time: ev.Time, Key: ev.Key}
+	switch ev.Type {
+	case cacher.EventHit, cacher.EventMiss:
+		entry.Op = OpGet
+	case cacher.EventDel:
+		entry.Op = OpDel
+	default:
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.err == nil {
+		r.err = r.enc.Encode(entry)
+	}
+}
+
+// Stop 停止记录，返回写入时的第一个错误
+func (r *Recorder) Stop() error {
+	r.cancel()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// ReadTrace 读取 Recorder 写入的访问记录
+func ReadTrace(r io.Reader) ([]TraceEntry, error) {
+	var trace []TraceEntry
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry TraceEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, err
+		}
+		trace = append(trace, entry)
+	}
+	return trace, scanner.Err()
+}
+
+// ReplayConfig 回放配置
+type ReplayConfig struct {
+	Expire    time.Duration              //缓存保留时长，小于等于0时为1分钟
+	ValueSize int                        //查询数据的字节数
+	Speed     float64                    //回放速度倍数，按记录的时间间隔除以 Speed 等待；小于等于0时不等待，尽快回放
+	Options   []func(opt *cacher.Option) //读取缓存时的选项，用于比较不同的配置
+}
+
+// Replay 使用 repo 创建 Cacher，按顺序回放访问记录，预测新的存储库或配置下的命中率和回源次数（Result.Misses）
+func Replay(ctx context.Context, repo cacher.Repo, trace []TraceEntry, cfg ReplayConfig) (Result, error) {
+	if len(trace) == 0 {
+		return Result{}, errors.New("访问记录不能为空")
+	}
+	if cfg.Expire <= 0 {
+		cfg.Expire = time.Minute
+	}
+	c := cacher.New(repo, cfg.Expire, cfg.Options...)
+	value := make([]byte, cfg.ValueSize)
+	query := func() (interface{}, error) {
+		return value, nil
+	}
+
+	res := workerResult{latencies: make([]time.Duration, 0, len(trace))}
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	var v []byte
+	for i, entry := range trace {
+		if ctx.Err() != nil {
+			break
+		}
+		if cfg.Speed > 0 && i > 0 {
+			//按记录的时间间隔等待
+			wait := time.Duration(float64(entry.Time.Sub(trace[0].Time))/cfg.Speed) - time.Since(start)
+			if wait > 0 {
+				select {
+				case <-ctx.Done():
+				case <-time.After(wait):
+				}
+			}
+		}
+		switch entry.Op {
+		case OpGet:
+			res.get(ctx, c, entry.Key, query, &v)
+		case OpDel:
+			res.del(ctx, c, entry.Key)
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return summarize([]workerResult{res}, elapsed, &before, &after), ctx.Err()
+}
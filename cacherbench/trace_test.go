@@ -0,0 +1,55 @@
+package cacherbench_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cacherbench"
+	"testing"
+	"time"
+)
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(&repoMap{data: map[string]interface{}{}}, time.Minute)
+	var buf bytes.Buffer
+	rec := cacherbench.Record(c, &buf)
+	query := func() (interface{}, error) { return "v", nil }
+	var v string
+	for _, key := range []string{"a", "b", "a", "a"} {
+		if _, err := c.Get(ctx, key, query, &v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Del(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "a", query, &v); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	trace, err := cacherbench.ReadTrace(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace) != 6 || trace[4].Op != cacherbench.OpDel || trace[4].Key != "a" {
+		t.Fatalf("trace = %+v", trace)
+	}
+
+	r, err := cacherbench.Replay(ctx, &repoMap{data: map[string]interface{}{}}, trace, cacherbench.ReplayConfig{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Reads != 5 || r.Writes != 1 || r.Hits != 2 || r.Misses != 3 {
+		t.Errorf("Replay() = %s", r)
+	}
+}
+
+func TestReplay_Empty(t *testing.T) {
+	if _, err := cacherbench.Replay(context.Background(), &repoMap{data: map[string]interface{}{}}, nil, cacherbench.ReplayConfig{}); err == nil {
+		t.Errorf("Replay() error = nil, want error for empty trace")
+	}
+}
@@ -0,0 +1,255 @@
+// Package cachertest 存储库的契约测试，第三方存储库可以用 RunRepoSuite 验证是否满足 cacher.Repo 的约定：
+// 缓存不存在时返回 nil,nil，保留时长到期后不可读取，保留时长为 0 时永不过期，Del 支持多个键且键不存在时不报错，
+// 并发读写安全，以及各种类型的缓存数据都能读回
+package cachertest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/carteruu/cacher"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// SuiteOption 契约测试选项
+type SuiteOption struct {
+	// Advance 让存储库的时间前进 d，用于测试保留时长。默认为 time.Sleep，
+	// 使用模拟时钟的存储库（例如 miniredis 的 FastForward）应当设置该选项
+	Advance func(d time.Duration)
+	// TTL 测试保留时长时使用的保留时长，默认为100毫秒。存储库的过期精度较低时应当调大
+	TTL time.Duration
+}
+
+// WithAdvance 设置让存储库的时间前进的方法
+func WithAdvance(advance func(d time.Duration)) func(opt *SuiteOption) {
+	return func(opt *SuiteOption) {
+		opt.Advance = advance
+	}
+}
+
+// WithTTL 设置测试保留时长时使用的保留时长
+func WithTTL(ttl time.Duration) func(opt *SuiteOption) {
+	return func(opt *SuiteOption) {
+		opt.TTL = ttl
+	}
+}
+
+// RunRepoSuite 对 newRepo 创建的存储库运行契约测试，每个子测试调用一次 newRepo 创建新的存储库。
+// 存储库同时实现了 cacher.NXRepo 时，一并测试 SetNX
+func RunRepoSuite(t *testing.T, newRepo func(t *testing.T) cacher.Repo, optFns ...func(opt *SuiteOption)) {
+	opt := SuiteOption{Advance: time.Sleep, TTL: 100 * time.Millisecond}
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo cacher.Repo, opt SuiteOption)
+	}{
+		{name: "NilOnMiss", fn: testNilOnMiss},
+		{name: "ValueKinds", fn: testValueKinds},
+		{name: "Overwrite", fn: testOverwrite},
+		{name: "TTL", fn: testTTL},
+		{name: "NoExpire", fn: testNoExpire},
+		{name: "Del", fn: testDel},
+		{name: "Concurrency", fn: testConcurrency},
+		{name: "SetNX", fn: testSetNX},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t), opt)
+		})
+	}
+}
+
+// testNilOnMiss 缓存不存在时返回 nil,nil，Cacher 据此判断是否调用查询方法
+func testNilOnMiss(t *testing.T, repo cacher.Repo, _ SuiteOption) {
+	got, err := repo.Get(context.Background(), "cachertest:missing")
+	if err != nil || got != nil {
+		t.Errorf("Get() of missing key = %#v, %v, want nil, nil", got, err)
+	}
+}
+
+// testValueKinds 各种类型的缓存数据都能读回。存储库可以按原类型返回，也可以返回字符串或字节切片，切片和映射可以返回 JSON 编码
+func testValueKinds(t *testing.T, repo cacher.Repo, _ SuiteOption) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		value interface{}
+	}{
+		{name: "字符串", value: "value"},
+		{name: "空字符串", value: ""},
+		{name: "字节切片", value: []byte("bytes")},
+		{name: "非 UTF-8 字节", value: []byte{0xff, 0x00, 0xfe}},
+		{name: "整数", value: 42},
+		{name: "负整数", value: int64(-7)},
+		{name: "浮点数", value: 1.5},
+		{name: "布尔", value: true},
+		{name: "字符串切片", value: []string{"a", "b"}},
+		{name: "字符串映射", value: map[string]string{"a": "1"}},
+	}
+	for i, tt := range tests {
+		key := "cachertest:kind:" + strconv.Itoa(i)
+		if err := repo.Set(ctx, key, tt.value, time.Minute); err != nil {
+			t.Errorf("%s: Set() = %v", tt.name, err)
+			continue
+		}
+		got, err := repo.Get(ctx, key)
+		if err != nil {
+			t.Errorf("%s: Get() = %v", tt.name, err)
+			continue
+		}
+		if got == nil {
+			t.Errorf("%s: Get() = nil, saved value must not be reported as missing", tt.name)
+			continue
+		}
+		if text(got) != text(tt.value) {
+			t.Errorf("%s: Get() = %#v, want %#v", tt.name, got, tt.value)
+		}
+	}
+}
+
+// testOverwrite 再次保存时覆盖原来的缓存
+func testOverwrite(t *testing.T, repo cacher.Repo, _ SuiteOption) {
+	ctx := context.Background()
+	for _, v := range []string{"v1", "v2"} {
+		if err := repo.Set(ctx, "cachertest:overwrite", v, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got, err := repo.Get(ctx, "cachertest:overwrite"); err != nil || text(got) != "v2" {
+		t.Errorf("Get() = %#v, %v, want v2", got, err)
+	}
+}
+
+// testTTL 保留时长到期后，缓存不可读取
+func testTTL(t *testing.T, repo cacher.Repo, opt SuiteOption) {
+	ctx := context.Background()
+	if err := repo.Set(ctx, "cachertest:ttl", "v", opt.TTL); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Set(ctx, "cachertest:ttl-long", "v", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := repo.Get(ctx, "cachertest:ttl"); err != nil || got == nil {
+		t.Fatalf("Get() before expire = %#v, %v", got, err)
+	}
+	opt.Advance(2 * opt.TTL)
+	if got, err := repo.Get(ctx, "cachertest:ttl"); err != nil || got != nil {
+		t.Errorf("Get() after expire = %#v, %v, want nil, nil", got, err)
+	}
+	if got, err := repo.Get(ctx, "cachertest:ttl-long"); err != nil || got == nil {
+		t.Errorf("Get() of unexpired key = %#v, %v", got, err)
+	}
+}
+
+// testNoExpire 保留时长为 0 时永不过期，命名空间的代数、依赖索引依赖这一约定
+func testNoExpire(t *testing.T, repo cacher.Repo, opt SuiteOption) {
+	ctx := context.Background()
+	if err := repo.Set(ctx, "cachertest:no-expire", "v", 0); err != nil {
+		t.Fatal(err)
+	}
+	opt.Advance(2 * opt.TTL)
+	if got, err := repo.Get(ctx, "cachertest:no-expire"); err != nil || text(got) != "v" {
+		t.Errorf("Get() of key saved with expire 0 = %#v, %v, want v", got, err)
+	}
+}
+
+// testDel 删除多个缓存，键不存在时不报错
+func testDel(t *testing.T, repo cacher.Repo, _ SuiteOption) {
+	ctx := context.Background()
+	keys := []string{"cachertest:del:1", "cachertest:del:2", "cachertest:del:3"}
+	for _, key := range keys {
+		if err := repo.Set(ctx, key, "v", time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := repo.Del(ctx, keys[0], keys[1]); err != nil {
+		t.Fatalf("Del() = %v", err)
+	}
+	for i, key := range keys {
+		got, err := repo.Get(ctx, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if deleted := got == nil; deleted != (i < 2) {
+			t.Errorf("Get(%s) after Del = %#v", key, got)
+		}
+	}
+	if err := repo.Del(ctx, "cachertest:del:missing"); err != nil {
+		t.Errorf("Del() of missing key = %v, want nil", err)
+	}
+	if err := repo.Del(ctx); err != nil {
+		t.Errorf("Del() without keys = %v, want nil", err)
+	}
+}
+
+// testConcurrency 并发读写、删除同一批缓存键，不应出错
+func testConcurrency(t *testing.T, repo cacher.Repo, _ SuiteOption) {
+	ctx := context.Background()
+	const workers, ops = 8, 100
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; i < ops; i++ {
+				key := "cachertest:concurrency:" + strconv.Itoa(i%10)
+				var err error
+				switch (w + i) % 3 {
+				case 0:
+					err = repo.Set(ctx, key, strconv.Itoa(w), time.Minute)
+				case 1:
+					_, err = repo.Get(ctx, key)
+				case 2:
+					err = repo.Del(ctx, key)
+				}
+				if err != nil {
+					errs <- err
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent operation = %v", err)
+	}
+}
+
+// testSetNX 缓存不存在时才保存
+func testSetNX(t *testing.T, repo cacher.Repo, _ SuiteOption) {
+	nx, ok := repo.(cacher.NXRepo)
+	if !ok {
+		t.Skip("存储库没有实现 cacher.NXRepo")
+	}
+	ctx := context.Background()
+	for i, want := range []bool{true, false} {
+		ok, err := nx.SetNX(ctx, "cachertest:nx", "v"+strconv.Itoa(i), time.Minute)
+		if err != nil || ok != want {
+			t.Errorf("SetNX() #%d = %v, %v, want %v", i, ok, err, want)
+		}
+	}
+	if got, err := repo.Get(ctx, "cachertest:nx"); err != nil || text(got) != "v0" {
+		t.Errorf("Get() after SetNX = %#v, %v, want v0", got, err)
+	}
+}
+
+// text 缓存数据的文本形式，用于比较按原类型、字符串、字节切片或 JSON 编码返回的数据
+func text(v interface{}) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	case []string, map[string]string:
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+	return fmt.Sprint(v)
+}
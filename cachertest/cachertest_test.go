@@ -0,0 +1,20 @@
+package cachertest_test
+
+import (
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cachertest"
+	"testing"
+	"time"
+)
+
+func TestRunRepoSuite_MemoryRepo(t *testing.T) {
+	cachertest.RunRepoSuite(t, func(t *testing.T) cacher.Repo {
+		return cacher.NewMemoryRepo()
+	}, cachertest.WithTTL(20*time.Millisecond))
+}
+
+func TestRunRepoSuite_RouteRepo(t *testing.T) {
+	cachertest.RunRepoSuite(t, func(t *testing.T) cacher.Repo {
+		return cacher.NewRouteRepo(cacher.NewMemoryRepo())
+	}, cachertest.WithTTL(20*time.Millisecond))
+}
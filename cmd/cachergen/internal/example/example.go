@@ -0,0 +1,11 @@
+// Package example 演示 cachergen 生成的缓存访问方法
+package example
+
+//go:generate go run github.com/carteruu/cacher/cmd/cachergen -name User -key "user:%d" -args "id int64" -type User
+//go:generate go run github.com/carteruu/cacher/cmd/cachergen -name TenantUsers -key "tenant:%s:users:%d" -args "tenant string, page int" -type []User -o tenant_users_cache_gen.go
+
+// User 用户
+type User struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+}
@@ -0,0 +1,53 @@
+package example_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cmd/cachergen/internal/example"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestUserCache(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(cacher.NewMemoryRepo(), time.Minute)
+	calls := 0
+	users := example.NewUserCache(c, func(ctx context.Context, id int64) (example.User, error) {
+		calls++
+		return example.User{ID: id, Name: "name"}, nil
+	})
+	if key := users.UserKey(1); key != "user:1" {
+		t.Errorf("UserKey() = %v, want user:1", key)
+	}
+	for i := 0; i < 2; i++ {
+		u, err := users.GetUser(ctx, 1)
+		if err != nil || u != (example.User{ID: 1, Name: "name"}) {
+			t.Fatalf("GetUser() = %+v, %v", u, err)
+		}
+	}
+	if err := users.DelUser(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := users.GetUser(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if calls != 2 {
+		t.Errorf("query calls = %d, want 2", calls)
+	}
+}
+
+func TestTenantUsersCache(t *testing.T) {
+	c := cacher.New(cacher.NewMemoryRepo(), time.Minute)
+	want := []example.User{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	cache := example.NewTenantUsersCache(c, func(ctx context.Context, tenant string, page int) ([]example.User, error) {
+		return want, nil
+	})
+	if key := cache.TenantUsersKey("t1", 2); key != "tenant:t1:users:2" {
+		t.Errorf("TenantUsersKey() = %v", key)
+	}
+	got, err := cache.GetTenantUsers(context.Background(), "t1", 2)
+	if err != nil || !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTenantUsers() = %+v, %v", got, err)
+	}
+}
@@ -0,0 +1,38 @@
+// Code generated by cachergen. DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+)
+
+// TenantUsersCache 缓存键 "tenant:%s:users:%d" 的类型安全访问方法
+type TenantUsersCache struct {
+	key   *cacher.KeyTemplate
+	query func(ctx context.Context, tenant string, page int) ([]User, error)
+}
+
+// NewTenantUsersCache 创建访问方法，query 是缓存不存在时的查询方法，optFns 是默认选项
+func NewTenantUsersCache(c *cacher.Cacher, query func(ctx context.Context, tenant string, page int) ([]User, error), optFns ...func(opt *cacher.Option)) *TenantUsersCache {
+	return &TenantUsersCache{key: c.Key("tenant:%s:users:%d", optFns...), query: query}
+}
+
+// TenantUsersKey 生成缓存键
+func (c *TenantUsersCache) TenantUsersKey(tenant string, page int) string {
+	return c.key.String(tenant, page)
+}
+
+// GetTenantUsers 获取缓存数据，缓存不存在时调用查询方法并保存
+func (c *TenantUsersCache) GetTenantUsers(ctx context.Context, tenant string, page int, optFns ...func(opt *cacher.Option)) ([]User, error) {
+	var v []User
+	_, err := c.key.GetArgs(ctx, []interface{}{tenant, page}, func() (interface{}, error) {
+		return c.query(ctx, tenant, page)
+	}, &v, optFns...)
+	return v, err
+}
+
+// DelTenantUsers 删除缓存
+func (c *TenantUsersCache) DelTenantUsers(ctx context.Context, tenant string, page int) error {
+	return c.key.Del(ctx, tenant, page)
+}
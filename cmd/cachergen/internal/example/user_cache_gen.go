@@ -0,0 +1,38 @@
+// Code generated by cachergen. DO NOT EDIT.
+
+package example
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+)
+
+// UserCache 缓存键 "user:%d" 的类型安全访问方法
+type UserCache struct {
+	key   *cacher.KeyTemplate
+	query func(ctx context.Context, id int64) (User, error)
+}
+
+// NewUserCache 创建访问方法，query 是缓存不存在时的查询方法，optFns 是默认选项
+func NewUserCache(c *cacher.Cacher, query func(ctx context.Context, id int64) (User, error), optFns ...func(opt *cacher.Option)) *UserCache {
+	return &UserCache{key: c.Key("user:%d", optFns...), query: query}
+}
+
+// UserKey 生成缓存键
+func (c *UserCache) UserKey(id int64) string {
+	return c.key.String(id)
+}
+
+// GetUser 获取缓存数据，缓存不存在时调用查询方法并保存
+func (c *UserCache) GetUser(ctx context.Context, id int64, optFns ...func(opt *cacher.Option)) (User, error) {
+	var v User
+	_, err := c.key.GetArgs(ctx, []interface{}{id}, func() (interface{}, error) {
+		return c.query(ctx, id)
+	}, &v, optFns...)
+	return v, err
+}
+
+// DelUser 删除缓存
+func (c *UserCache) DelUser(ctx context.Context, id int64) error {
+	return c.key.Del(ctx, id)
+}
@@ -0,0 +1,211 @@
+// Command cachergen 根据缓存键模板和数据类型生成类型安全的缓存访问方法，
+// 避免在各处手写缓存键字符串和类型断言。通常通过 go:generate 调用：
+//
+//	//go:generate cachergen -name User -key "user:%d" -args "id int64" -type User
+//
+// 生成的 UserCache 包含 GetUser、DelUser 和 UserKey 方法，缓存键和参数类型在编译期检查
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"strings"
+	"text/template"
+)
+
+// spec 一个缓存访问器的生成配置
+type spec struct {
+	Package string   //生成代码的包名
+	Name    string   //访问器名称，生成 <Name>Cache 类型
+	Key     string   //缓存键模板，同 fmt.Sprintf
+	Type    string   //缓存数据类型
+	Args    []arg    //缓存键参数，依次对应 Key 中的占位符
+	Imports []string //数据类型、参数类型引用的其他包
+}
+
+// arg 缓存键参数
+type arg struct {
+	Name string
+	Type string
+}
+
+func main() {
+	var (
+		s       spec
+		args    string
+		imports string
+		output  string
+	)
+	flag.StringVar(&s.Package, "pkg", os.Getenv("GOPACKAGE"), "生成代码的包名，默认使用 go generate 设置的 $GOPACKAGE")
+	flag.StringVar(&s.Name, "name", "", "访问器名称，例如 User")
+	flag.StringVar(&s.Key, "key", "", `缓存键模板，例如 "user:%d"`)
+	flag.StringVar(&s.Type, "type", "", "缓存数据类型，例如 User、*model.User")
+	flag.StringVar(&args, "args", "", `缓存键参数，逗号分隔，例如 "tenant string, id int64"`)
+	flag.StringVar(&imports, "import", "", "数据类型、参数类型引用的包路径，逗号分隔")
+	flag.StringVar(&output, "o", "", "输出文件，默认为 <name>_cache_gen.go")
+	flag.Parse()
+
+	if err := run(s, args, imports, output); err != nil {
+		fmt.Fprintln(os.Stderr, "cachergen:", err)
+		os.Exit(1)
+	}
+}
+
+func run(s spec, args, imports, output string) error {
+	var err error
+	if s.Args, err = parseArgs(args); err != nil {
+		return err
+	}
+	for _, path := range strings.Split(imports, ",") {
+		if path = strings.TrimSpace(path); path != "" {
+			s.Imports = append(s.Imports, path)
+		}
+	}
+	src, err := generate(s)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		output = strings.ToLower(s.Name) + "_cache_gen.go"
+	}
+	return os.WriteFile(output, src, 0o644)
+}
+
+// parseArgs 解析缓存键参数，格式为 "name type, name type"
+func parseArgs(s string) ([]arg, error) {
+	var args []arg
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		fields := strings.Fields(item)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("参数格式错误：%q，应为 \"name type\"", item)
+		}
+		if !token.IsIdentifier(fields[0]) {
+			return nil, fmt.Errorf("参数名不是合法的标识符：%q", fields[0])
+		}
+		args = append(args, arg{Name: fields[0], Type: fields[1]})
+	}
+	return args, nil
+}
+
+// verbs 统计格式中的占位符数量，不包括 %%
+func verbs(format string) int {
+	n := 0
+	for i := 0; i < len(format); i++ {
+		if format[i] != '%' {
+			continue
+		}
+		if i+1 < len(format) && format[i+1] == '%' {
+			i++
+			continue
+		}
+		n++
+	}
+	return n
+}
+
+// generate 生成访问器代码
+func generate(s spec) ([]byte, error) {
+	switch {
+	case s.Package == "":
+		return nil, errors.New("缺少包名，请通过 go generate 调用或设置 -pkg")
+	case !token.IsIdentifier(s.Name) || !token.IsExported(s.Name):
+		return nil, fmt.Errorf("访问器名称必须是导出的标识符：%q", s.Name)
+	case s.Key == "":
+		return nil, errors.New("缺少缓存键模板 -key")
+	case s.Type == "":
+		return nil, errors.New("缺少缓存数据类型 -type")
+	}
+	if n := verbs(s.Key); n != len(s.Args) {
+		return nil, fmt.Errorf("缓存键模板 %q 有 %d 个占位符，但有 %d 个参数", s.Key, n, len(s.Args))
+	}
+	for _, typ := range append([]string{s.Type}, argTypes(s.Args)...) {
+		if _, err := parser.ParseExpr(typ); err != nil {
+			return nil, fmt.Errorf("类型格式错误：%q", typ)
+		}
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, s); err != nil {
+		return nil, err
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("格式化生成的代码失败：%w", err)
+	}
+	return src, nil
+}
+
+func argTypes(args []arg) []string {
+	types := make([]string, 0, len(args))
+	for _, a := range args {
+		types = append(types, a.Type)
+	}
+	return types
+}
+
+var tmpl = template.Must(template.New("cachergen").Funcs(template.FuncMap{
+	"params": func(args []arg) string {
+		params := make([]string, 0, len(args))
+		for _, a := range args {
+			params = append(params, a.Name+" "+a.Type)
+		}
+		return strings.Join(params, ", ")
+	},
+	"names": func(args []arg) string {
+		names := make([]string, 0, len(args))
+		for _, a := range args {
+			names = append(names, a.Name)
+		}
+		return strings.Join(names, ", ")
+	},
+}).Parse(`// Code generated by cachergen. DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+{{- range .Imports}}
+	"{{.}}"
+{{- end}}
+)
+
+// {{.Name}}Cache 缓存键 {{printf "%q" .Key}} 的类型安全访问方法
+type {{.Name}}Cache struct {
+	key   *cacher.KeyTemplate
+	query func(ctx context.Context{{if .Args}}, {{params .Args}}{{end}}) ({{.Type}}, error)
+}
+
+// New{{.Name}}Cache 创建访问方法，query 是缓存不存在时的查询方法，optFns 是默认选项
+func New{{.Name}}Cache(c *cacher.Cacher, query func(ctx context.Context{{if .Args}}, {{params .Args}}{{end}}) ({{.Type}}, error), optFns ...func(opt *cacher.Option)) *{{.Name}}Cache {
+	return &{{.Name}}Cache{key: c.Key({{printf "%q" .Key}}, optFns...), query: query}
+}
+
+// {{.Name}}Key 生成缓存键
+func (c *{{.Name}}Cache) {{.Name}}Key({{params .Args}}) string {
+	return c.key.String({{names .Args}})
+}
+
+// Get{{.Name}} 获取缓存数据，缓存不存在时调用查询方法并保存
+func (c *{{.Name}}Cache) Get{{.Name}}(ctx context.Context{{if .Args}}, {{params .Args}}{{end}}, optFns ...func(opt *cacher.Option)) ({{.Type}}, error) {
+	var v {{.Type}}
+	_, err := c.key.GetArgs(ctx, []interface{}{ {{- names .Args -}} }, func() (interface{}, error) {
+		return c.query(ctx{{if .Args}}, {{names .Args}}{{end}})
+	}, &v, optFns...)
+	return v, err
+}
+
+// Del{{.Name}} 删除缓存
+func (c *{{.Name}}Cache) Del{{.Name}}(ctx context.Context{{if .Args}}, {{params .Args}}{{end}}) error {
+	return c.key.Del(ctx{{if .Args}}, {{names .Args}}{{end}})
+}
+`))
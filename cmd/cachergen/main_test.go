@@ -0,0 +1,74 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerate_Golden(t *testing.T) {
+	tests := []struct {
+		file string
+		spec spec
+		args string
+	}{
+		{file: "user_cache_gen.go", spec: spec{Package: "example", Name: "User", Key: "user:%d", Type: "User"}, args: "id int64"},
+		{file: "tenant_users_cache_gen.go", spec: spec{Package: "example", Name: "TenantUsers", Key: "tenant:%s:users:%d", Type: "[]User"}, args: "tenant string, page int"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			args, err := parseArgs(tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.spec.Args = args
+			got, err := generate(tt.spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want, err := os.ReadFile(filepath.Join("internal", "example", tt.file))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("generate() differs from %s, run go generate ./cmd/cachergen/...\n%s", tt.file, got)
+			}
+		})
+	}
+}
+
+func TestGenerate_Error(t *testing.T) {
+	valid := spec{Package: "p", Name: "User", Key: "user:%d", Type: "User", Args: []arg{{Name: "id", Type: "int64"}}}
+	tests := []struct {
+		name    string
+		modify  func(s *spec)
+		wantErr string
+	}{
+		{name: "缺少包名", modify: func(s *spec) { s.Package = "" }, wantErr: "包名"},
+		{name: "名称未导出", modify: func(s *spec) { s.Name = "user" }, wantErr: "导出"},
+		{name: "占位符与参数数量不一致", modify: func(s *spec) { s.Key = "user:%d:%d" }, wantErr: "占位符"},
+		{name: "%% 不是占位符", modify: func(s *spec) { s.Key = "user:%%:%d" }},
+		{name: "类型格式错误", modify: func(s *spec) { s.Type = "[]" }, wantErr: "类型格式错误"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := valid
+			tt.modify(&s)
+			_, err := generate(s)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("generate() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("generate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+
+	if _, err := parseArgs("id"); err == nil {
+		t.Errorf("parseArgs() want error for missing type")
+	}
+}
@@ -0,0 +1,189 @@
+package cacher
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"reflect"
+)
+
+// Codec 编解码器，负责缓存数据与字节切片之间的转换
+type Codec interface {
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// JSON 基于 encoding/json 的编解码器，是 Option.Codec 为空时使用的编解码器
+var JSON Codec = jsonCodec{}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// CanonicalJSON 输出规范 JSON 的编解码器：所有对象（包括结构体）的键按字典序排列，不转义 HTML 字符，没有多余空白。
+// 相同的数据总是编码为相同的字节，适合对缓存数据做哈希、去重或在多个实例之间比较。
+// 类型标签中的名称为 canonical-json
+var CanonicalJSON Codec = canonicalJSONCodec{}
+
+type canonicalJSONCodec struct{}
+
+func (canonicalJSONCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := marshalJSON(v)
+	if err != nil {
+		return nil, err
+	}
+	//解码为通用类型后重新编码：map 的键按字典序编码，json.Number 保留数值原文
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var generic interface{}
+	if err := dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+	return marshalJSON(generic)
+}
+
+func (canonicalJSONCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// marshalJSON 编码为不转义 HTML 字符、没有末尾换行的 JSON
+func marshalJSON(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// encodeValue 按选项转换保存的数据：BinaryNumbers 时数值编码为定长二进制，设置了 Codec 或类型标签声明了编解码器时，
+// 结构体、切片、map 等编码为字节切片，与 setDecoded 使用同一个编解码器
+func encodeValue(value interface{}, opt Option) (interface{}, error) {
+	if opt.BinaryNumbers {
+		value = encodeNumber(value)
+	}
+	if value == nil {
+		return value, nil
+	}
+	t := reflect.TypeOf(value)
+	if !isCodecType(t) {
+		return value, nil
+	}
+	if elem, _ := indirectType(t); opt.Codec == nil && typeTagOf(elem).codec == nil {
+		return value, nil
+	}
+	return opt.codecFor(t).Marshal(value)
+}
+
+// setDecoded 使用编解码器把字符串、字节切片 from 解码为 toType 类型后赋值给 to
+func setDecoded(to reflect.Value, toType reflect.Type, from reflect.Value, opt Option) error {
+	from = indirect(from)
+	var data []byte
+	if from.Kind() == reflect.String {
+		data = []byte(from.String())
+	} else {
+		data = from.Bytes()
+	}
+	ptr := reflect.New(toType)
+	if err := opt.codecFor(toType).Unmarshal(data, ptr.Interface()); err != nil {
+		return err
+	}
+	to.Set(ptr.Elem())
+	return nil
+}
+
+// isEncoded 是否为编码后的数据类型：字符串或字节切片
+func isEncoded(t reflect.Type) bool {
+	return t.Kind() == reflect.String || isBytes(t)
+}
+
+// isCodecType 是否由编解码器编码和解码：结构体、切片（字节切片除外）、数组、map 以及它们的指针
+func isCodecType(t reflect.Type) bool {
+	t, _ = indirectType(t)
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array:
+		return true
+	case reflect.Slice:
+		return !isBytes(t)
+	}
+	return false
+}
+
+// decodeFallback 字节数据没有对应的转换器时，是否用编解码器解码为 t 类型：开启了 CodecFallback，或设置了 Codec、类型标签声明了编解码器
+func (o Option) decodeFallback(t reflect.Type) bool {
+	elem, _ := indirectType(t)
+	return o.CodecFallback || o.Codec != nil || typeTagOf(elem).codec != nil
+}
+
+// codec 选项中的编解码器，没有设置时使用 JSON
+func (o Option) codec() Codec {
+	if o.Codec != nil {
+		return o.Codec
+	}
+	return JSON
+}
+
+// RegisterType 为类型 T 注册 string、[]byte 与 T 之间的双向转换器，代替为每个类型手写多个相似的 TypeConverter。
+// 优先使用 T 的类型标签 cache:"codec=<name>" 声明的编解码器，其次使用 c 的默认编解码器。T 不能是指针或接口类型
+func RegisterType[T any](c *Cacher) error {
+	typ := reflect.TypeOf((*T)(nil)).Elem()
+	if typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Interface {
+		return errors.New("RegisterType 不支持指针和接口类型")
+	}
+	if err := typeTagOf(typ).err; err != nil {
+		return err
+	}
+	codec := c.typeCodec(typ)
+	var zero T
+	decode := func(data []byte) (interface{}, error) {
+		var v T
+		if err := codec.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+		return v, nil
+	}
+	converters := []TypeConverter{
+		{
+			SrcType: "",
+			DstType: zero,
+			Fn: func(src interface{}) (interface{}, error) {
+				return decode([]byte(src.(string)))
+			},
+		}, {
+			SrcType: []byte{},
+			DstType: zero,
+			Fn: func(src interface{}) (interface{}, error) {
+				return decode(src.([]byte))
+			},
+		}, {
+			SrcType: zero,
+			DstType: "",
+			Fn: func(src interface{}) (interface{}, error) {
+				data, err := codec.Marshal(src)
+				if err != nil {
+					return nil, err
+				}
+				return string(data), nil
+			},
+		}, {
+			SrcType: zero,
+			DstType: []byte{},
+			Fn: func(src interface{}) (interface{}, error) {
+				return codec.Marshal(src)
+			},
+		},
+	}
+	for _, conv := range converters {
+		if err := c.RegisterConverter(conv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,33 @@
+// Package gobcodec 基于 encoding/gob 的编解码器。
+// 与 JSON 相比，整数不会经过 float64 丢失精度，time.Time 保留纳秒和时区偏移，编码结果更紧凑。
+// 只能在 Go 程序之间共享缓存数据。导入本包时以名称 gob 注册，可以在类型标签中通过 cache:"codec=gob" 使用
+package gobcodec
+
+import (
+	"bytes"
+	"encoding/gob"
+	"github.com/carteruu/cacher"
+)
+
+// Codec 基于 encoding/gob 的编解码器，可以设置为 Option.Codec
+var Codec cacher.Codec = codec{}
+
+var _ cacher.Codec = codec{}
+
+func init() {
+	cacher.RegisterCodec("gob", Codec)
+}
+
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
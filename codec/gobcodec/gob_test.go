@@ -0,0 +1,73 @@
+package gobcodec_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/codec/gobcodec"
+	"testing"
+	"time"
+)
+
+type order struct {
+	ID      int64
+	Amount  uint64
+	Created time.Time
+	Tags    []string
+}
+
+func TestCodec(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.Codec = gobcodec.Codec
+	})
+	//JSON 解码到 interface{} 时大整数会丢失精度
+	want := order{ID: 1<<62 + 1, Amount: 1<<64 - 1, Created: time.Date(2022, 1, 2, 3, 4, 5, 6, time.FixedZone("CST", 8*3600)), Tags: []string{"a"}}
+	for i := 0; i < 2; i++ {
+		var got order
+		useCache, err := c.Get(ctx, "order", func() (interface{}, error) { return want, nil }, &got)
+		if err != nil || useCache != (i == 1) {
+			t.Fatalf("Get() = %v, %v", useCache, err)
+		}
+		if got.ID != want.ID || got.Amount != want.Amount || !got.Created.Equal(want.Created) || got.Created.Format(time.RFC3339Nano) != want.Created.Format(time.RFC3339Nano) || len(got.Tags) != 1 {
+			t.Errorf("Get() v = %+v, want %+v", got, want)
+		}
+	}
+	if data, _ := repo.Get(ctx, "order"); data == nil {
+		t.Fatal("order not cached")
+	} else if _, ok := data.([]byte); !ok {
+		t.Errorf("cached %T, want []byte", data)
+	}
+}
+
+func TestCodec_TypeTag(t *testing.T) {
+	type tagged struct {
+		_       struct{} `cache:"codec=gob"`
+		ID      int64
+		Created time.Time
+	}
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	//没有设置 Option.Codec，只通过类型标签声明编解码器
+	c := cacher.New(repo, time.Minute)
+	want := tagged{ID: 1<<62 + 1, Created: time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC)}
+	for i := 0; i < 2; i++ {
+		var got tagged
+		useCache, err := c.Get(ctx, "t", func() (interface{}, error) { return want, nil }, &got)
+		if err != nil || useCache != (i == 1) {
+			t.Fatalf("Get() #%d = %v, %v", i, useCache, err)
+		}
+		if got.ID != want.ID || !got.Created.Equal(want.Created) {
+			t.Errorf("Get() #%d v = %+v, want %+v", i, got, want)
+		}
+	}
+	data, _ := repo.Get(ctx, "t")
+	bs, ok := data.([]byte)
+	if !ok {
+		t.Fatalf("cached %T, want []byte", data)
+	}
+	var got tagged
+	if err := gobcodec.Codec.Unmarshal(bs, &got); err != nil || got.ID != want.ID {
+		t.Errorf("Unmarshal() = %+v, %v", got, err)
+	}
+}
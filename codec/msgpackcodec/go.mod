@@ -0,0 +1,15 @@
+module github.com/carteruu/cacher/codec/msgpackcodec
+
+go 1.18
+
+replace github.com/carteruu/cacher => ../..
+
+require (
+	github.com/carteruu/cacher v0.0.0-00010101000000-000000000000
+	github.com/vmihailenco/msgpack/v5 v5.3.5
+)
+
+require (
+	github.com/vmihailenco/tagparser/v2 v2.0.0 // indirect
+	golang.org/x/sync v0.0.0-20220923202941-7f9b1623fab7 // indirect
+)
@@ -0,0 +1,37 @@
+// Package msgpackcodec 基于 MessagePack 的编解码器。
+// 与 JSON 相比，编码结果更紧凑、编解码更快，整数不会经过 float64 丢失精度，time.Time 以扩展类型保存，可以与其他语言共享缓存数据。
+// 导入本包时以名称 msgpack 注册，可以在类型标签中通过 cache:"codec=msgpack" 使用
+package msgpackcodec
+
+import (
+	"bytes"
+	"github.com/carteruu/cacher"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// Codec 基于 MessagePack 的编解码器，可以设置为 Option.Codec。结构体字段名使用 msgpack 标签，没有标签时使用 json 标签
+var Codec cacher.Codec = codec{}
+
+var _ cacher.Codec = codec{}
+
+func init() {
+	cacher.RegisterCodec("msgpack", Codec)
+}
+
+type codec struct{}
+
+func (codec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetCustomStructTag("json")
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (codec) Unmarshal(data []byte, v interface{}) error {
+	dec := msgpack.NewDecoder(bytes.NewReader(data))
+	dec.SetCustomStructTag("json")
+	return dec.Decode(v)
+}
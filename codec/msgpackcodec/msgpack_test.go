@@ -0,0 +1,102 @@
+package msgpackcodec_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/codec/msgpackcodec"
+	"github.com/vmihailenco/msgpack/v5"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type order struct {
+	ID      int64     `json:"id"`
+	Amount  uint64    `json:"amount"`
+	Created time.Time `json:"created"`
+	Tags    []string  `json:"tags"`
+}
+
+func TestCodec(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.Codec = msgpackcodec.Codec
+	})
+	want := order{ID: 1<<62 + 1, Amount: 1<<64 - 1, Created: time.Date(2022, 1, 2, 3, 4, 5, 6, time.UTC), Tags: []string{"a"}}
+	for i := 0; i < 2; i++ {
+		var got order
+		useCache, err := c.Get(ctx, "order", func() (interface{}, error) { return want, nil }, &got)
+		if err != nil || useCache != (i == 1) {
+			t.Fatalf("Get() = %v, %v", useCache, err)
+		}
+		if got.ID != want.ID || got.Amount != want.Amount || !got.Created.Equal(want.Created) || len(got.Tags) != 1 {
+			t.Errorf("Get() v = %+v, want %+v", got, want)
+		}
+	}
+	data, _ := repo.Get(ctx, "order")
+	bs, ok := data.([]byte)
+	if !ok {
+		t.Fatalf("cached %T, want []byte", data)
+	}
+	js, _ := json.Marshal(want)
+	if len(bs) >= len(js) {
+		t.Errorf("msgpack size %d, want smaller than JSON %d", len(bs), len(js))
+	}
+}
+
+func TestCodec_TypeTag(t *testing.T) {
+	type tagged struct {
+		_    struct{} `cache:"codec=msgpack"`
+		Name string   `json:"name"`
+	}
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	//类型标签声明的编解码器优先于 Option.Codec
+	c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.Codec = cacher.JSON
+	})
+	if err := cacher.RegisterType[tagged](c); err != nil {
+		t.Fatal(err)
+	}
+	var v tagged
+	if _, err := c.Get(ctx, "t", func() (interface{}, error) { return tagged{Name: "n"}, nil }, &v); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := repo.Get(ctx, "t")
+	bs, ok := data.([]byte)
+	if !ok {
+		t.Fatalf("cached %T, want []byte", data)
+	}
+	var got tagged
+	if err := msgpackcodec.Codec.Unmarshal(bs, &got); err != nil || got.Name != "n" {
+		t.Errorf("Unmarshal() = %+v, %v", got, err)
+	}
+}
+
+func TestCodec_JSONTag(t *testing.T) {
+	type user struct {
+		ID    int    `json:"id"`
+		Name  string `msgpack:"n" json:"name"`
+		Email string
+		Skip  string `json:"-"`
+	}
+	data, err := msgpackcodec.Codec.Marshal(user{ID: 1, Name: "a", Email: "e", Skip: "s"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//按字段名解码，检查编码结果中的字段名：msgpack 标签优先，没有时使用 json 标签，都没有时使用字段名
+	var fields map[string]interface{}
+	if err := msgpack.Unmarshal(data, &fields); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"id": int8(1), "n": "a", "Email": "e"}
+	if !reflect.DeepEqual(fields, want) {
+		t.Errorf("encoded fields = %v, want %v", fields, want)
+	}
+	var got user
+	if err := msgpackcodec.Codec.Unmarshal(data, &got); err != nil || got != (user{ID: 1, Name: "a", Email: "e"}) {
+		t.Errorf("Unmarshal() = %+v, %v", got, err)
+	}
+}
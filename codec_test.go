@@ -0,0 +1,193 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRegisterType(t *testing.T) {
+	tests := []struct {
+		name     string
+		repo     cacher.Repo
+		key      string
+		v        interface{}
+		wantData interface{}
+	}{
+		{name: "字节切片：结构体", repo: &repoBytes{}, key: "person-1", v: &person{}, wantData: personObj},
+		{name: "字节切片：结构体数组", repo: &repoBytes{}, key: "personArr", v: &[2]person{}, wantData: personArr},
+		{name: "字节切片：结构体切片", repo: &repoBytes{}, key: "personSlice", v: &[]person{}, wantData: personSlice},
+		{name: "字节切片：map", repo: &repoBytes{}, key: "personMap", v: &map[string]person{}, wantData: personMap},
+		{name: "字符串：结构体", repo: &repoString{}, key: "person-1", v: &person{}, wantData: personObj},
+		{name: "字符串：结构体切片", repo: &repoString{}, key: "personSlice", v: &[]person{}, wantData: personSlice},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cacher.New(tt.repo, 10*time.Second)
+			for _, err := range []error{
+				cacher.RegisterType[person](c),
+				cacher.RegisterType[[2]person](c),
+				cacher.RegisterType[[]person](c),
+				cacher.RegisterType[map[string]person](c),
+			} {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+			useCache, err := c.Get(context.Background(), tt.key, func() (interface{}, error) {
+				return nil, notNeedCall
+			}, tt.v)
+			if err != nil || !useCache {
+				t.Fatalf("Get() = %v, %v", useCache, err)
+			}
+			if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.wantData) {
+				t.Errorf("v = %v, want %v", got, tt.wantData)
+			}
+		})
+	}
+}
+
+func TestRegisterType_Encode(t *testing.T) {
+	c := cacher.New(newRepoMap(map[string]interface{}{"person": personObj}), 10*time.Second)
+	if err := cacher.RegisterType[person](c); err != nil {
+		t.Fatal(err)
+	}
+	var bs []byte
+	if ok, err := c.Peek(context.Background(), "person", &bs); !ok || err != nil {
+		t.Fatalf("Peek() = %v, %v", ok, err)
+	}
+	if string(bs) != string(personObjBs) {
+		t.Errorf("v = %s, want %s", bs, personObjBs)
+	}
+	if err := cacher.RegisterType[*person](c); err == nil {
+		t.Errorf("RegisterType[*person]() want error")
+	}
+}
+
+func TestOption_Codec(t *testing.T) {
+	tests := []struct {
+		name  string
+		repo  cacher.Repo
+		key   string
+		codec cacher.Codec
+		v     interface{}
+		want  interface{}
+	}{
+		{name: "字节切片：结构体", repo: &repoBytes{}, key: "person-1", v: &person{}, want: personObj},
+		{name: "字节切片：结构体数组", repo: &repoBytes{}, key: "personArr", v: &[2]person{}, want: personArr},
+		{name: "字节切片：map", repo: &repoBytes{}, key: "personMap", v: &map[string]person{}, want: personMap},
+		{name: "字符串：结构体切片", repo: &repoString{}, key: "personSlice", v: &[]person{}, want: personSlice},
+		{name: "字符串：结构体指针", repo: &repoString{}, key: "person-1", v: new(*person), want: &personObj},
+		{name: "自定义编解码器", repo: newRepoMap(map[string]interface{}{"p": `tag:{"name":"n"}`}), key: "p", codec: prefixCodec{}, v: &person{}, want: person{Name: "n"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//不注册转换器，由编解码器解码
+			c := cacher.New(tt.repo, 10*time.Second, func(opt *cacher.Option) {
+				opt.Codec = tt.codec
+				opt.CodecFallback = true
+			})
+			useCache, err := c.Get(context.Background(), tt.key, func() (interface{}, error) {
+				return nil, notNeedCall
+			}, tt.v)
+			if err != nil || !useCache {
+				t.Fatalf("Get() = %v, %v", useCache, err)
+			}
+			if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("v = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOption_CodecFallback(t *testing.T) {
+	tests := []struct {
+		name     string
+		fallback bool
+		codec    cacher.Codec
+		wantErr  error
+	}{
+		{name: "默认不解码", wantErr: cacher.ErrUnsupportedConversion},
+		{name: "开启后用 JSON 解码", fallback: true},
+		{name: "设置了编解码器", codec: cacher.JSON},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cacher.New(&repoBytes{}, 10*time.Second, func(opt *cacher.Option) {
+				opt.CodecFallback = tt.fallback
+				opt.Codec = tt.codec
+			})
+			var p person
+			_, err := c.Get(context.Background(), "person-1", func() (interface{}, error) {
+				return nil, notNeedCall
+			}, &p)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && p != personObj {
+				t.Errorf("v = %v, want %v", p, personObj)
+			}
+		})
+	}
+}
+
+func TestOption_Codec_Encode(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, 10*time.Second, func(opt *cacher.Option) {
+		opt.Codec = prefixCodec{}
+	})
+	want := person{Name: "n", Age: 1}
+	for i := 0; i < 2; i++ {
+		var p person
+		useCache, err := c.Get(ctx, "p", func() (interface{}, error) { return want, nil }, &p)
+		if err != nil || p != want || useCache != (i == 1) {
+			t.Fatalf("Get() = %v, %v, p = %+v", useCache, err, p)
+		}
+	}
+	data, ok := repo.data["p"].([]byte)
+	if !ok || !strings.HasPrefix(string(data), "tag:") {
+		t.Errorf("saved = %#v, want bytes encoded by codec", repo.data["p"])
+	}
+	//字符串和数值不编码
+	var s string
+	if _, err := c.Get(ctx, "s", func() (interface{}, error) { return "v", nil }, &s); err != nil || repo.data["s"] != "v" {
+		t.Errorf("saved string = %#v, %v", repo.data["s"], err)
+	}
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	type item struct {
+		Zeta  string            `json:"zeta"`
+		Alpha int               `json:"alpha"`
+		Tags  map[string]string `json:"tags"`
+		Price float64           `json:"price"`
+	}
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{name: "结构体字段排序", v: item{Zeta: "<a&b>", Alpha: 1, Tags: map[string]string{"b": "2", "a": "1"}, Price: 1.50}, want: `{"alpha":1,"price":1.5,"tags":{"a":"1","b":"2"},"zeta":"<a&b>"}`},
+		{name: "嵌套", v: []interface{}{map[string]interface{}{"y": []int{2, 1}, "x": nil}}, want: `[{"x":null,"y":[2,1]}]`},
+		{name: "大整数不丢失精度", v: map[string]uint64{"n": 1<<63 + 1}, want: `{"n":9223372036854775809}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := cacher.CanonicalJSON.Marshal(tt.v)
+			if err != nil || string(got) != tt.want {
+				t.Errorf("Marshal() = %s, %v, want %s", got, err, tt.want)
+			}
+		})
+	}
+
+	var back item
+	data, _ := cacher.CanonicalJSON.Marshal(item{Zeta: "z", Alpha: 2})
+	if err := cacher.CanonicalJSON.Unmarshal(data, &back); err != nil || back.Zeta != "z" || back.Alpha != 2 {
+		t.Errorf("Unmarshal() = %+v, %v", back, err)
+	}
+}
@@ -0,0 +1,90 @@
+package cacher
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Config 创建 Cacher 的配置，便于从配置文件加载，或者由依赖注入框架提供。
+// 除 Options 外的字段都可以序列化，转换为对应的默认选项后，再应用 Options
+type Config struct {
+	Name           string              `json:"name"`             //名称，依赖注入时用于区分多个 Cacher
+	Expire         time.Duration       `json:"expire"`           //缓存保留时长，必须大于0
+	NilCacheExpire time.Duration       `json:"nil_cache_expire"` //空缓存保留时长，见 Option.NilCacheExpire
+	Namespace      string              `json:"namespace"`        //命名空间，见 Option.Namespace
+	Codec          string              `json:"codec"`            //编解码器的名称，见 RegisterCodec 和 Option.Codec，为空时使用 JSON
+	Options        []func(opt *Option) `json:"-"`                //其他默认选项，同 New 的 optFns，不能序列化
+}
+
+// NewFromConfig 根据配置创建 Cacher，与 New 不同，配置错误时返回错误而不是 panic
+func NewFromConfig(repo Repo, cfg Config) (*Cacher, error) {
+	if repo == nil {
+		return nil, errors.New("存储库 repo 不能为空")
+	}
+	if _, err := cfg.apply(Option{}); err != nil {
+		return nil, err
+	}
+	optFns, err := cfg.optionFns()
+	if err != nil {
+		return nil, err
+	}
+	return New(repo, cfg.Expire, optFns...), nil
+}
+
+// optionFns 把可以序列化的字段转换为选项方法，放在 Options 之前。零值的字段不设置，保留原来的选项
+func (cfg Config) optionFns() ([]func(opt *Option), error) {
+	var codec Codec
+	if cfg.Codec != "" {
+		var ok bool
+		if codec, ok = codecByName(cfg.Codec); !ok {
+			return nil, fmt.Errorf("编解码器 %s 未注册", cfg.Codec)
+		}
+	}
+	optFns := make([]func(opt *Option), 0, len(cfg.Options)+1)
+	optFns = append(optFns, func(opt *Option) {
+		if cfg.NilCacheExpire > 0 {
+			opt.NilCacheExpire = cfg.NilCacheExpire
+		}
+		if cfg.Namespace != "" {
+			opt.Namespace = cfg.Namespace
+		}
+		if codec != nil {
+			opt.Codec = codec
+		}
+	})
+	return append(optFns, cfg.Options...), nil
+}
+
+// apply 校验配置，在 base 的基础上应用配置中的选项，返回新的默认选项，不修改 base
+func (cfg Config) apply(base Option) (Option, error) {
+	if cfg.Expire <= 0 {
+		return Option{}, errors.New("缓存保存时长 Expire 必须大于0")
+	}
+	optFns, err := cfg.optionFns()
+	if err != nil {
+		return Option{}, err
+	}
+	opt := base.clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if err := opt.Valid(); err != nil {
+		return Option{}, err
+	}
+	return opt, nil
+}
+
+// Close 停止固定的缓存键和 AutoRefresh 的自动刷新，并关闭存储库，存储库没有实现 io.Closer 时不关闭。
+// 多个 Cacher 共用同一个存储库时，只应由其中一个关闭
+func (c *Cacher) Close() error {
+	c.pins.stopAll()
+	c.refreshes.stopAll()
+	if closer, ok := c.repo.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package cacher_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+// repoClose 记录是否关闭的测试存储库
+type repoClose struct {
+	*repoMap
+	closed bool
+}
+
+func (r *repoClose) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestNewFromConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		repo    cacher.Repo
+		cfg     cacher.Config
+		wantErr bool
+	}{
+		{name: "正确", repo: newRepoMap(nil), cfg: cacher.Config{Expire: time.Second}},
+		{name: "缺少存储库", cfg: cacher.Config{Expire: time.Second}, wantErr: true},
+		{name: "Expire 为0", repo: newRepoMap(nil), cfg: cacher.Config{}, wantErr: true},
+		{name: "选项错误", repo: newRepoMap(nil), cfg: cacher.Config{Expire: time.Second, Options: []func(opt *cacher.Option){
+			func(opt *cacher.Option) { opt.Jitter = 2 },
+		}}, wantErr: true},
+		{name: "编解码器未注册", repo: newRepoMap(nil), cfg: cacher.Config{Expire: time.Second, Codec: "missing"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := cacher.NewFromConfig(tt.repo, tt.cfg)
+			if (err != nil) != tt.wantErr || (err == nil && c == nil) {
+				t.Errorf("NewFromConfig() = %v, %v, wantErr %v", c, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNewFromConfig_JSON(t *testing.T) {
+	ctx := context.Background()
+	var cfg cacher.Config
+	data := `{"name":"users","expire":60000000000,"nil_cache_expire":1000000000,"namespace":"ns","codec":"canonical-json"}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	repo := newRepoMap(nil)
+	c, err := cacher.NewFromConfig(repo, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m map[string]int
+	if _, err := c.Get(ctx, "m", func() (interface{}, error) { return map[string]int{"b": 2, "a": 1}, nil }, &m); err != nil {
+		t.Fatal(err)
+	}
+	var p *person
+	if _, err := c.Get(ctx, "nil", func() (interface{}, error) { return nil, nil }, &p); err != nil {
+		t.Fatal(err)
+	}
+	mKey, _ := c.NamespaceKey(ctx, "ns", "m")
+	if v, _ := repo.Get(ctx, mKey); v == nil || string(v.([]byte)) != `{"a":1,"b":2}` {
+		t.Errorf("cached m = %v, want canonical JSON in namespace", v)
+	}
+	nilKey, _ := c.NamespaceKey(ctx, "ns", "nil")
+	if v, _ := repo.Get(ctx, nilKey); v == nil {
+		t.Errorf("nil cache not saved")
+	}
+}
+
+func TestCacher_Close(t *testing.T) {
+	repo := &repoClose{repoMap: newRepoMap(nil)}
+	if err := cacher.New(repo, time.Second).Close(); err != nil || !repo.closed {
+		t.Errorf("Close() = %v, closed %v", err, repo.closed)
+	}
+	if err := cacher.New(newRepoMap(nil), time.Second).Close(); err != nil {
+		t.Errorf("Close() without io.Closer = %v", err)
+	}
+}
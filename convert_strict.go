@@ -0,0 +1,93 @@
+package cacher
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+)
+
+// ErrConverterShadowed 转换器与内置的类型转换冲突，见 Option.StrictConvert
+var ErrConverterShadowed = errors.New("转换器与内置的类型转换冲突")
+
+// checkConverter 检查转换器，注册转换器和校验单次调用的 Converters 都通过它检查：
+// SrcType、DstType、Fn 都不能为空；strict 为 true 时（见 Option.StrictConvert），不能与内置的类型转换冲突。
+// 注册的转换器不会代替直接的类型转换，单次调用的 Converters 会代替内置的类型转换，两种情况都会悄悄改变转换结果
+func checkConverter(conv TypeConverter, strict bool) error {
+	pair := pairOf(conv)
+	switch {
+	case conv.SrcType == nil || conv.DstType == nil || conv.Fn == nil:
+		return &ConversionError{From: pair.SrcType, To: pair.DstType, Err: ErrInvalidConverter}
+	case !strict:
+		return nil
+	case builtinConv[pair].Fn != nil:
+		return fmt.Errorf("%w: %v -> %v 已有内置转换器", ErrConverterShadowed, pair.SrcType, pair.DstType)
+	case pair.SrcType.ConvertibleTo(pair.DstType):
+		return fmt.Errorf("%w: %v -> %v 可以直接进行类型转换", ErrConverterShadowed, pair.SrcType, pair.DstType)
+	}
+	return nil
+}
+
+// pairOf 转换器的类型对
+func pairOf(conv TypeConverter) typePair {
+	return typePair{SrcType: reflect.TypeOf(conv.SrcType), DstType: reflect.TypeOf(conv.DstType)}
+}
+
+// checkLossless 检查 from 转换为 toType 时是否有损：整数溢出、负数转无符号整数、小数截断、整数转字符串。
+// 非数值类型之间的转换不检查
+func checkLossless(from reflect.Value, toType reflect.Type) error {
+	from = indirect(from)
+	lossy := false
+	switch {
+	case isInt(from.Kind()):
+		v := from.Int()
+		switch {
+		case isInt(toType.Kind()):
+			lossy = reflect.Zero(toType).OverflowInt(v)
+		case isUint(toType.Kind()):
+			lossy = v < 0 || reflect.Zero(toType).OverflowUint(uint64(v))
+		case isFloat(toType.Kind()):
+			lossy = int64(float64(v)) != v || reflect.Zero(toType).OverflowFloat(float64(v))
+		case toType.Kind() == reflect.String:
+			lossy = true
+		}
+	case isUint(from.Kind()):
+		v := from.Uint()
+		switch {
+		case isInt(toType.Kind()):
+			lossy = v > math.MaxInt64 || reflect.Zero(toType).OverflowInt(int64(v))
+		case isUint(toType.Kind()):
+			lossy = reflect.Zero(toType).OverflowUint(v)
+		case isFloat(toType.Kind()):
+			lossy = uint64(float64(v)) != v || reflect.Zero(toType).OverflowFloat(float64(v))
+		case toType.Kind() == reflect.String:
+			lossy = true
+		}
+	case isFloat(from.Kind()):
+		v := from.Float()
+		switch {
+		case isInt(toType.Kind()):
+			lossy = v != math.Trunc(v) || v < math.MinInt64 || v >= math.MaxInt64 || reflect.Zero(toType).OverflowInt(int64(v))
+		case isUint(toType.Kind()):
+			lossy = v != math.Trunc(v) || v < 0 || v >= math.MaxUint64 || reflect.Zero(toType).OverflowUint(uint64(v))
+		case isFloat(toType.Kind()):
+			lossy = !math.IsInf(v, 0) && reflect.Zero(toType).OverflowFloat(v)
+		}
+	}
+	if lossy {
+		return &ConversionError{From: from.Type(), To: toType, Err: fmt.Errorf("%w: %v", ErrLossyConversion, from.Interface())}
+	}
+	return nil
+}
+
+func isInt(k reflect.Kind) bool {
+	return k >= reflect.Int && k <= reflect.Int64
+}
+
+func isUint(k reflect.Kind) bool {
+	return k >= reflect.Uint && k <= reflect.Uintptr
+}
+
+func isFloat(k reflect.Kind) bool {
+	return k == reflect.Float32 || k == reflect.Float64
+}
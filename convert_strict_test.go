@@ -0,0 +1,107 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"math"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCacher_LossyConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		cached   interface{}
+		v        interface{}
+		wantData interface{}
+		wantErr  bool
+	}{
+		{name: "int -> int8", cached: 12, v: new(int8), wantData: int8(12)},
+		{name: "int -> int8 溢出", cached: 300, v: new(int8), wantErr: true},
+		{name: "负数 int -> uint", cached: -1, v: new(uint), wantErr: true},
+		{name: "uint64 -> int64 溢出", cached: uint64(math.MaxUint64), v: new(int64), wantErr: true},
+		{name: "float64 -> int 整数", cached: 3.0, v: new(int), wantData: 3},
+		{name: "float64 -> int 截断", cached: 3.5, v: new(int), wantErr: true},
+		{name: "float64 -> float32 溢出", cached: math.MaxFloat64, v: new(float32), wantErr: true},
+		{name: "int -> float64", cached: 7, v: new(float64), wantData: 7.0},
+		{name: "int -> string", cached: 65, v: new(string), wantErr: true},
+		{name: "[]byte -> string", cached: []byte("a"), v: new(string), wantData: "a"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cacher.New(newRepoMap(map[string]interface{}{"k": tt.cached}), 10*time.Second)
+			_, err := c.Get(context.Background(), "k", func() (interface{}, error) {
+				return nil, notNeedCall
+			}, tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(reflect.ValueOf(tt.v).Elem().Interface(), tt.wantData) {
+				t.Errorf("v = %v, want %v", reflect.ValueOf(tt.v).Elem().Interface(), tt.wantData)
+			}
+		})
+	}
+}
+
+func TestCacher_LegacyConvert(t *testing.T) {
+	c := cacher.New(newRepoMap(map[string]interface{}{"k": 3.5}), 10*time.Second)
+	var v int
+	_, err := c.GetWithOption(context.Background(), "k", func() (interface{}, error) {
+		return nil, notNeedCall
+	}, &v, func(opt *cacher.Option) {
+		opt.LegacyConvert = true
+	})
+	if err != nil || v != 3 {
+		t.Errorf("Get() = %v, %v, want 3", v, err)
+	}
+}
+
+func TestOption_StrictConvert(t *testing.T) {
+	atoi := func(src interface{}) (interface{}, error) {
+		n, err := strconv.Atoi(src.(string))
+		return n * 100, err
+	}
+	tests := []struct {
+		name    string
+		conv    cacher.TypeConverter
+		wantErr bool
+	}{
+		{name: "覆盖内置转换器", conv: cacher.TypeConverter{SrcType: "", DstType: 0, Fn: atoi}, wantErr: true},
+		{name: "可以直接进行类型转换", conv: cacher.TypeConverter{SrcType: int64(0), DstType: 0, Fn: func(src interface{}) (interface{}, error) { return int(src.(int64)), nil }}, wantErr: true},
+		{name: "没有冲突", conv: cacher.TypeConverter{SrcType: "", DstType: person{}, Fn: func(src interface{}) (interface{}, error) { return person{Name: src.(string)}, nil }}},
+	}
+	for _, tt := range tests {
+		strict := cacher.New(newRepoMap(nil), time.Minute, func(opt *cacher.Option) {
+			opt.StrictConvert = true
+		})
+		if err := strict.RegisterConverter(tt.conv); errors.Is(err, cacher.ErrConverterShadowed) != tt.wantErr {
+			t.Errorf("%s: RegisterConverter() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		if err := strict.TryRegisterConverter(tt.conv); errors.Is(err, cacher.ErrConverterShadowed) != tt.wantErr {
+			t.Errorf("%s: TryRegisterConverter() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		opt := cacher.Option{StrictConvert: true, Converters: []cacher.TypeConverter{tt.conv}}
+		if err := opt.Valid(); errors.Is(err, cacher.ErrInvalidOption) != tt.wantErr {
+			t.Errorf("%s: Valid() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+		//默认不检查
+		if err := cacher.New(newRepoMap(nil), time.Minute).RegisterConverter(tt.conv); err != nil {
+			t.Errorf("%s: RegisterConverter() without StrictConvert = %v", tt.name, err)
+		}
+	}
+
+	//单次调用的转换器被拒绝，不会改变转换结果
+	c := cacher.New(newRepoMap(map[string]interface{}{"k": "12"}), time.Minute, func(opt *cacher.Option) {
+		opt.StrictConvert = true
+	})
+	var n int
+	_, err := c.GetWithOption(context.Background(), "k", func() (interface{}, error) { return 0, nil }, &n, func(opt *cacher.Option) {
+		opt.Converters = []cacher.TypeConverter{{SrcType: "", DstType: 0, Fn: atoi}}
+	})
+	if !errors.Is(err, cacher.ErrInvalidOption) || n != 0 {
+		t.Errorf("GetWithOption() = %v, n = %d, want invalid option", err, n)
+	}
+}
@@ -0,0 +1,109 @@
+package cacher
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// ErrConverterExists 转换器已存在，TryRegisterConverter 不会覆盖已注册的转换器
+var ErrConverterExists = errors.New("转换器已存在")
+
+// TryRegisterConverter 注册类型转换器，同一对类型已注册转换器时不覆盖，返回 ErrConverterExists
+func (c *Cacher) TryRegisterConverter(converter TypeConverter) error {
+	if err := checkConverter(converter, c.options().StrictConvert); err != nil {
+		return err
+	}
+	pair := pairOf(converter)
+	if !c.addConverter(pair, converter, false) {
+		return fmt.Errorf("%w: %v -> %v", ErrConverterExists, pair.SrcType, pair.DstType)
+	}
+	return nil
+}
+
+// builtinConv 内置转换器，包级别的不可变表，所有 Cacher 共享，New 不需要逐个注册
+var builtinConv = func() map[typePair]TypeConverter {
+	convs := make(map[typePair]TypeConverter, len(typeConverters))
+	for _, conv := range typeConverters {
+		convs[pairOf(conv)] = conv
+	}
+	return convs
+}()
+
+// converter 查找类型对的转换器，注册的转换器优先于内置转换器
+func (c *Cacher) converter(pair typePair) (TypeConverter, bool) {
+	c.convMu.RLock()
+	conv, ok := c.typeConv[pair]
+	c.convMu.RUnlock()
+	if ok {
+		return conv, true
+	}
+	conv, ok = builtinConv[pair]
+	return conv, ok
+}
+
+// addConverter 保存注册的转换器。overwrite 为 false 时，类型对已有注册的或内置的转换器则不保存并返回 false，检查和保存在同一次加锁内完成
+func (c *Cacher) addConverter(pair typePair, conv TypeConverter, overwrite bool) bool {
+	c.convMu.Lock()
+	defer c.convMu.Unlock()
+	if !overwrite {
+		if _, ok := c.typeConv[pair]; ok {
+			return false
+		}
+		if _, ok := builtinConv[pair]; ok {
+			return false
+		}
+	}
+	if c.typeConv == nil {
+		c.typeConv = make(map[typePair]TypeConverter)
+	}
+	c.typeConv[pair] = conv
+	return true
+}
+
+// Converters 返回所有已注册的类型转换器，按源类型、目标类型排序
+func (c *Cacher) Converters() []TypeConverter {
+	c.convMu.RLock()
+	converters := make([]TypeConverter, 0, len(builtinConv)+len(c.typeConv))
+	for pair, conv := range builtinConv {
+		if _, ok := c.typeConv[pair]; !ok {
+			converters = append(converters, conv)
+		}
+	}
+	for _, conv := range c.typeConv {
+		converters = append(converters, conv)
+	}
+	c.convMu.RUnlock()
+	sort.Slice(converters, func(i, j int) bool {
+		si, sj := reflect.TypeOf(converters[i].SrcType).String(), reflect.TypeOf(converters[j].SrcType).String()
+		if si != sj {
+			return si < sj
+		}
+		return reflect.TypeOf(converters[i].DstType).String() < reflect.TypeOf(converters[j].DstType).String()
+	})
+	return converters
+}
+
+// ResolveConverter 返回把 src 类型的缓存数据转换为 dst 类型时使用的转换器，用于排查"不支持的类型转换"错误。
+// 可以直接进行类型转换时，返回执行类型转换的转换器；不支持转换时，返回包含两个类型的错误
+func (c *Cacher) ResolveConverter(src, dst interface{}) (TypeConverter, error) {
+	if src == nil || dst == nil {
+		return TypeConverter{}, errors.New("src、dst 都不能为空")
+	}
+	srcType, _ := indirectType(reflect.TypeOf(src))
+	dstType, _ := indirectType(reflect.TypeOf(dst))
+	if srcType.ConvertibleTo(dstType) {
+		return TypeConverter{
+			SrcType: src,
+			DstType: dst,
+			Fn: func(src interface{}) (interface{}, error) {
+				return reflect.ValueOf(src).Convert(dstType).Interface(), nil
+			},
+		}, nil
+	}
+	if conv, ok := c.converter(typePair{SrcType: srcType, DstType: dstType}); ok {
+		return conv, nil
+	}
+	return TypeConverter{}, &ConversionError{From: srcType, To: dstType}
+}
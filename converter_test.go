@@ -0,0 +1,172 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacher_TryRegisterConverter(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	conv := cacher.TypeConverter{
+		SrcType: "",
+		DstType: 0,
+		Fn: func(src interface{}) (interface{}, error) {
+			return 1, nil
+		},
+	}
+	if err := c.TryRegisterConverter(conv); !errors.Is(err, cacher.ErrConverterExists) {
+		t.Errorf("TryRegisterConverter() = %v, want ErrConverterExists", err)
+	}
+	//未覆盖默认转换器
+	got, err := c.ResolveConverter("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := got.Fn("5"); val != 5 {
+		t.Errorf("default converter overwritten, got %v", val)
+	}
+
+	conv.DstType = person{}
+	if err := c.TryRegisterConverter(conv); err != nil {
+		t.Errorf("TryRegisterConverter() = %v, want nil", err)
+	}
+}
+
+func TestCacher_Converters(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	before := len(c.Converters())
+	if before == 0 {
+		t.Fatal("default converters not listed")
+	}
+	if err := cacher.RegisterType[person](c); err != nil {
+		t.Fatal(err)
+	}
+	converters := c.Converters()
+	if len(converters) != before+4 {
+		t.Errorf("len(Converters()) = %d, want %d", len(converters), before+4)
+	}
+	for i := 1; i < len(converters); i++ {
+		prev, cur := reflect.TypeOf(converters[i-1].SrcType).String(), reflect.TypeOf(converters[i].SrcType).String()
+		if prev > cur {
+			t.Errorf("Converters() not sorted: %v > %v", prev, cur)
+		}
+	}
+}
+
+func TestCacher_RegisterConverter_Isolated(t *testing.T) {
+	//覆盖内置转换器只影响当前的 Cacher，内置转换器由所有 Cacher 共享
+	c1 := cacher.New(newRepoMap(nil), 10*time.Second)
+	c2 := cacher.New(newRepoMap(nil), 10*time.Second)
+	if err := c1.RegisterConverter(cacher.TypeConverter{
+		SrcType: "",
+		DstType: 0,
+		Fn: func(src interface{}) (interface{}, error) {
+			return -1, nil
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i, tt := range []struct {
+		c    *cacher.Cacher
+		want int
+	}{{c: c1, want: -1}, {c: c2, want: 5}} {
+		conv, err := tt.c.ResolveConverter("", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got, _ := conv.Fn("5"); got != tt.want {
+			t.Errorf("Cacher #%d converter returned %v, want %d", i, got, tt.want)
+		}
+	}
+	if len(c1.Converters()) != len(c2.Converters()) {
+		t.Errorf("overriding a default converter changed the count: %d != %d", len(c1.Converters()), len(c2.Converters()))
+	}
+}
+
+func TestCacher_RegisterConverter_Concurrent(t *testing.T) {
+	//注册转换器和读取缓存并发执行，用 -race 检查
+	type score int
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	ctx := context.Background()
+	var wg sync.WaitGroup
+	var registered int32
+	for i := 0; i < 8; i++ {
+		wg.Add(3)
+		go func() {
+			defer wg.Done()
+			if err := c.RegisterConverter(cacher.TypeConverter{
+				SrcType: "",
+				DstType: 0,
+				Fn: func(src interface{}) (interface{}, error) {
+					return 5, nil
+				},
+			}); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			err := c.TryRegisterConverter(cacher.TypeConverter{
+				SrcType: "",
+				DstType: score(0),
+				Fn: func(src interface{}) (interface{}, error) {
+					return score(5), nil
+				},
+			})
+			if err == nil {
+				atomic.AddInt32(&registered, 1)
+			} else if !errors.Is(err, cacher.ErrConverterExists) {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			var v int
+			if _, err := c.Get(ctx, "k", func() (interface{}, error) { return "5", nil }, &v); err != nil || v != 5 {
+				t.Errorf("Get() = %v, %v, want 5", v, err)
+			}
+			c.Converters()
+		}()
+	}
+	wg.Wait()
+	if registered != 1 {
+		t.Errorf("TryRegisterConverter succeeded %d times, want 1", registered)
+	}
+}
+
+func TestCacher_ResolveConverter(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	tests := []struct {
+		name    string
+		src     interface{}
+		dst     interface{}
+		input   interface{}
+		want    interface{}
+		wantErr bool
+	}{
+		{name: "类型转换", src: []byte{}, dst: "", input: []byte("a"), want: "a"},
+		{name: "注册的转换器", src: "", dst: 0, input: "12", want: 12},
+		{name: "不支持", src: "", dst: person{}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv, err := c.ResolveConverter(tt.src, tt.dst)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveConverter() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			got, err := conv.Fn(tt.input)
+			if err != nil || !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Fn() = %v, %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package cacher
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// stats 运行状态计数，字段都通过 atomic 读写
+type stats struct {
+	hits       uint64
+	misses     uint64
+	loads      uint64
+	loadErrors uint64
+	herds      uint64
+	inFlight   int64
+	background int64
+}
+
+func (s *stats) hit() {
+	atomic.AddUint64(&s.hits, 1)
+}
+
+func (s *stats) miss() {
+	atomic.AddUint64(&s.misses, 1)
+}
+
+func (s *stats) herd() {
+	atomic.AddUint64(&s.herds, 1)
+}
+
+// load 调用查询方法，并记录查询次数、查询错误次数和正在进行的查询数
+func (s *stats) load(queryFunc func() (interface{}, error)) (interface{}, error) {
+	atomic.AddUint64(&s.loads, 1)
+	atomic.AddInt64(&s.inFlight, 1)
+	defer atomic.AddInt64(&s.inFlight, -1)
+	data, err := queryFunc()
+	if err != nil {
+		atomic.AddUint64(&s.loadErrors, 1)
+	}
+	return data, err
+}
+
+// DebugState 运行状态快照，用于在线排查问题
+type DebugState struct {
+	Hits           uint64  `json:"hits"`            //命中缓存次数
+	Misses         uint64  `json:"misses"`          //未命中缓存次数
+	HitRatio       float64 `json:"hit_ratio"`       //命中率，没有请求时为0
+	Loads          uint64  `json:"loads"`           //调用查询方法的次数，平滑查询合并的请求只计一次
+	LoadErrors     uint64  `json:"load_errors"`     //查询方法返回错误的次数
+	InFlight       int64   `json:"in_flight"`       //正在进行的查询数
+	Background     int64   `json:"background"`      //正在执行的后台任务数，包括 Option.Revalidate 的重新查询和 WriteBehind 的写入
+	Herds          uint64  `json:"herds"`           //短时间内重复调用查询方法的次数，见 Option.HerdWindow
+	Converters     int     `json:"converters"`      //已注册的转换器数量
+	FlightEntries  int     `json:"flight_entries"`  //进程内短时缓存的查询结果数量，包括已过期未清理的
+	EventListeners int     `json:"event_listeners"` //事件监听器数量
+	Disabled       bool    `json:"disabled"`        //缓存是否已被 Disable 关闭
+
+	WorkingSet []WorkingSetEstimate `json:"working_set,omitempty"` //时间窗口内访问过的不同缓存键数量，见 TrackWorkingSet
+	Waiting    []KeyWaiting         `json:"waiting,omitempty"`     //等待查询结果的 goroutine 最多的缓存键，最多10个，持续增长说明查询方法变慢
+	Errors     []RecentError        `json:"errors,omitempty"`      //最近的内部错误，见 RecentErrors
+}
+
+// DebugState 获取运行状态快照
+func (c *Cacher) DebugState() DebugState {
+	state := DebugState{
+		Hits:       atomic.LoadUint64(&c.stats.hits),
+		Misses:     atomic.LoadUint64(&c.stats.misses),
+		Loads:      atomic.LoadUint64(&c.stats.loads),
+		LoadErrors: atomic.LoadUint64(&c.stats.loadErrors),
+		InFlight:   atomic.LoadInt64(&c.stats.inFlight),
+		Background: atomic.LoadInt64(&c.stats.background),
+		Herds:      atomic.LoadUint64(&c.stats.herds),
+		Converters: len(c.Converters()),
+		Waiting:    c.waiters.top(debugTopWaiting),
+		Disabled:   c.Disabled(),
+		Errors:     c.RecentErrors(""),
+	}
+	if total := state.Hits + state.Misses; total > 0 {
+		state.HitRatio = float64(state.Hits) / float64(total)
+	}
+	c.flights.mu.Lock()
+	state.FlightEntries = len(c.flights.entries)
+	c.flights.mu.Unlock()
+	c.events.mu.RLock()
+	state.EventListeners = len(c.events.listeners)
+	c.events.mu.RUnlock()
+	if ws, _ := c.workingSet.Load().(*workingSet); ws != nil {
+		state.WorkingSet = ws.estimates(time.Now())
+	}
+	return state
+}
+
+// PublishExpvar 以 name 发布到 expvar，可以通过 /debug/vars 查看运行状态。
+// 同名变量只能发布一次，重复发布时 expvar 会 panic
+func (c *Cacher) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return c.DebugState()
+	}))
+}
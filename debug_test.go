@@ -0,0 +1,96 @@
+package cacher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacher_DebugState(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	queryErr := errors.New("query error")
+	var v int
+	_, _ = c.Get(ctx, "a", func() (interface{}, error) { return 1, nil }, &v)
+	_, _ = c.Get(ctx, "a", func() (interface{}, error) { return nil, notNeedCall }, &v)
+	_, _ = c.Get(ctx, "b", func() (interface{}, error) { return nil, queryErr }, &v)
+	cancel := c.OnEvent(func(cacher.Event) {})
+	defer cancel()
+
+	state := c.DebugState()
+	want := cacher.DebugState{Hits: 1, Misses: 2, Loads: 2, LoadErrors: 1, EventListeners: 1}
+	want.HitRatio = 1.0 / 3
+	want.Converters = state.Converters
+	if !reflect.DeepEqual(state, want) {
+		t.Errorf("DebugState() = %+v, want %+v", state, want)
+	}
+	if state.Converters == 0 {
+		t.Errorf("DebugState().Converters = 0, want default converters")
+	}
+}
+
+func TestCacher_PublishExpvar(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	//expvar 不能重复发布同名变量，使用唯一的名称以支持 -count
+	name := fmt.Sprintf("cacher_test_%d", time.Now().UnixNano())
+	c.PublishExpvar(name)
+	var state cacher.DebugState
+	if err := json.Unmarshal([]byte(expvar.Get(name).String()), &state); err != nil {
+		t.Fatal(err)
+	}
+	if state.Converters == 0 {
+		t.Errorf("expvar state = %+v", state)
+	}
+}
+
+func TestCacher_DebugState_Pressure(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	release := make(chan struct{})
+	slow := func() (interface{}, error) {
+		<-release
+		return 1, nil
+	}
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		go func() {
+			var v int
+			_, _ = c.Get(ctx, "slow", slow, &v)
+			done <- struct{}{}
+		}()
+	}
+	//命中缓存时在后台重新查询
+	var v int
+	_, _ = c.Get(ctx, "hot", func() (interface{}, error) { return 1, nil }, &v)
+	_, _ = c.GetWithOption(ctx, "hot", slow, &v, func(opt *cacher.Option) { opt.Revalidate = 1 })
+
+	deadline := time.Now().Add(time.Second)
+	var state cacher.DebugState
+	for time.Now().Before(deadline) {
+		state = c.DebugState()
+		if len(state.Waiting) == 1 && state.Waiting[0].Waiters == 3 && state.Background == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if want := []cacher.KeyWaiting{{Key: "slow", Waiters: 3}}; !reflect.DeepEqual(state.Waiting, want) || state.InFlight != 1 || state.Background != 1 {
+		t.Errorf("DebugState() Waiting = %v, InFlight = %d, Background = %d, want %v, 1, 1", state.Waiting, state.InFlight, state.Background, want)
+	}
+
+	close(release)
+	for i := 0; i < 4; i++ {
+		<-done
+	}
+	for time.Now().Before(deadline.Add(time.Second)) && c.DebugState().Background != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	if state = c.DebugState(); len(state.Waiting) != 0 || state.InFlight != 0 || state.Background != 0 {
+		t.Errorf("DebugState() after release = %+v", state)
+	}
+}
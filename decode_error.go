@@ -0,0 +1,48 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// DecodeAction 缓存数据无法转换为目标类型时的处理方式，见 Option.OnDecodeError
+type DecodeAction int
+
+const (
+	// DecodeReturnError 返回转换错误，是默认处理方式
+	DecodeReturnError DecodeAction = iota
+	// DecodeReload 当作没有缓存，调用查询方法并覆盖缓存
+	DecodeReload
+	// DecodeMigrate 调用 Option.Migrate 升级缓存数据，转换成功后写回缓存；升级或转换失败时返回错误
+	DecodeMigrate
+)
+
+// recoverDecode 按 Option.OnDecodeError 处理无法转换的缓存数据 from。
+// 返回值：转换成功时为升级后的缓存数据，当作没有缓存时为无效值
+func (c *Cacher) recoverDecode(ctx context.Context, key string, from, to reflect.Value, toType reflect.Type, decodeErr error, opt Option) (reflect.Value, error) {
+	switch opt.OnDecodeError {
+	case DecodeReload:
+		to.Set(reflect.Zero(to.Type()))
+		c.emitError("decode", key, decodeErr)
+		return reflect.Value{}, nil
+	case DecodeMigrate:
+		migrated, err := opt.Migrate(from.Interface())
+		if err != nil {
+			return from, fmt.Errorf("升级缓存数据失败：%w", err)
+		}
+		if migrated == nil {
+			return from, decodeErr
+		}
+		migratedFrom := reflect.ValueOf(migrated)
+		if err := c.assign(migratedFrom, to, toType, opt); err != nil {
+			return from, fmt.Errorf("升级后的缓存数据转换失败：%w", err)
+		}
+		if err := c.store(ctx, key, migrated, opt.withJitter(opt.Expire), opt); err != nil {
+			//写回失败不影响本次读取
+			c.emitError("decode", key, err)
+		}
+		return migratedFrom, nil
+	}
+	return from, decodeErr
+}
@@ -0,0 +1,75 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOption_OnDecodeError(t *testing.T) {
+	//旧格式 "v1:<n>"
+	migrate := func(v interface{}) (interface{}, error) {
+		s, ok := v.(string)
+		if !ok || !strings.HasPrefix(s, "v1:") {
+			return nil, errors.New("unknown format")
+		}
+		return strconv.Atoi(strings.TrimPrefix(s, "v1:"))
+	}
+	tests := []struct {
+		name      string
+		cached    interface{}
+		action    cacher.DecodeAction
+		wantErr   bool
+		want      int
+		wantCache bool
+		wantStore interface{}
+	}{
+		{name: "默认返回错误", cached: "v1:7", action: cacher.DecodeReturnError, wantErr: true, wantStore: "v1:7"},
+		{name: "重新查询并覆盖缓存", cached: "v1:7", action: cacher.DecodeReload, want: 42, wantStore: 42},
+		{name: "升级并写回缓存", cached: "v1:7", action: cacher.DecodeMigrate, want: 7, wantCache: true, wantStore: 7},
+		{name: "升级失败返回错误", cached: "v0", action: cacher.DecodeMigrate, wantErr: true, wantStore: "v0"},
+		{name: "可以转换时不处理", cached: 3, action: cacher.DecodeMigrate, want: 3, wantCache: true, wantStore: 3},
+	}
+	for _, tt := range tests {
+		repo := newRepoMap(map[string]interface{}{"k": tt.cached})
+		c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+			opt.OnDecodeError = tt.action
+			opt.Migrate = migrate
+		})
+		var v int
+		useCache, err := c.Get(context.Background(), "k", func() (interface{}, error) {
+			return 42, nil
+		}, &v)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Get() error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && (v != tt.want || useCache != tt.wantCache) {
+			t.Errorf("%s: Get() = %v, %d, want %v, %d", tt.name, useCache, v, tt.wantCache, tt.want)
+		}
+		if got, _ := repo.Get(context.Background(), "k"); got != tt.wantStore {
+			t.Errorf("%s: cached = %#v, want %#v", tt.name, got, tt.wantStore)
+		}
+	}
+}
+
+func TestOption_OnDecodeError_Valid(t *testing.T) {
+	tests := []struct {
+		name    string
+		opt     cacher.Option
+		wantErr bool
+	}{
+		{name: "默认", opt: cacher.Option{}},
+		{name: "不支持的处理方式", opt: cacher.Option{OnDecodeError: 9}, wantErr: true},
+		{name: "升级缺少 Migrate", opt: cacher.Option{OnDecodeError: cacher.DecodeMigrate}, wantErr: true},
+	}
+	for _, tt := range tests {
+		if err := tt.opt.Valid(); (err != nil) != tt.wantErr {
+			t.Errorf("%s: Valid() = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package cacher
+
+import (
+	"hash/maphash"
+	"reflect"
+	"sync"
+)
+
+// decodedCacheSize 进程内缓存的解码结果的最大数量，超过时随机淘汰
+const decodedCacheSize = 4096
+
+type (
+	// decodedCache 进程内缓存的解码结果，以缓存键和目标类型区分，以缓存数据的哈希值判断是否可以复用
+	decodedCache struct {
+		once    sync.Once
+		mu      sync.RWMutex
+		seed    maphash.Seed
+		entries map[decodedKey]decodedEntry
+	}
+	decodedKey struct {
+		key    string
+		toType reflect.Type
+	}
+	decodedEntry struct {
+		hash  uint64
+		size  int
+		value reflect.Value
+	}
+)
+
+// assignDecoded 缓存数据的哈希值与上次解码时相同时，直接使用上次的解码结果，否则解码并保存解码结果
+func (c *Cacher) assignDecoded(key string, from, to reflect.Value, toType reflect.Type, opt Option) error {
+	if !decodable(toType) {
+		return c.assign(from, to, toType, opt)
+	}
+	hash, size, ok := c.decoded.hash(from)
+	if !ok {
+		return c.assign(from, to, toType, opt)
+	}
+	dk := decodedKey{key: key, toType: toType}
+	if entry, ok := c.decoded.get(dk); ok && entry.hash == hash && entry.size == size {
+		to.Set(entry.value)
+		return nil
+	}
+	if err := c.assign(from, to, toType, opt); err != nil {
+		return err
+	}
+	c.decoded.set(dk, decodedEntry{hash: hash, size: size, value: reflect.ValueOf(to.Interface())})
+	return nil
+}
+
+// decodable 解码代价较高、值得缓存的目标类型
+func decodable(t reflect.Type) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Array:
+		return true
+	case reflect.Slice:
+		return !isBytes(t)
+	}
+	return false
+}
+
+// hash 计算字符串、字节切片类型的缓存数据的哈希值和长度，其他类型的缓存数据 ok 为 false
+func (d *decodedCache) hash(from reflect.Value) (hash uint64, size int, ok bool) {
+	d.once.Do(func() {
+		d.seed = maphash.MakeSeed()
+		d.entries = make(map[decodedKey]decodedEntry)
+	})
+	var h maphash.Hash
+	h.SetSeed(d.seed)
+	switch {
+	case from.Kind() == reflect.String:
+		_, _ = h.WriteString(from.String())
+	case isBytes(from.Type()):
+		_, _ = h.Write(from.Bytes())
+	default:
+		return 0, 0, false
+	}
+	return h.Sum64(), from.Len(), true
+}
+
+func (d *decodedCache) get(key decodedKey) (decodedEntry, bool) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	entry, ok := d.entries[key]
+	return entry, ok
+}
+
+func (d *decodedCache) set(key decodedKey, entry decodedEntry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.entries[key]; !ok && len(d.entries) >= decodedCacheSize {
+		//随机淘汰一个
+		for k := range d.entries {
+			delete(d.entries, k)
+			break
+		}
+	}
+	d.entries[key] = entry
+}
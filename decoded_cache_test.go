@@ -0,0 +1,80 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_DecodeCache(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(map[string]interface{}{"p": personObjBs})
+	c := cacher.New(repo, 10*time.Second, func(opt *cacher.Option) {
+		opt.DecodeCache = true
+	})
+	decodes := 0
+	if err := c.RegisterConverter(cacher.TypeConverter{
+		SrcType: []byte{},
+		DstType: person{},
+		Fn: func(src interface{}) (interface{}, error) {
+			decodes++
+			var p person
+			return p, cacher.JSON.Unmarshal(src.([]byte), &p)
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	get := func() person {
+		var p person
+		if _, err := c.Get(ctx, "p", func() (interface{}, error) { return nil, notNeedCall }, &p); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	for i := 0; i < 3; i++ {
+		if p := get(); p != personObj {
+			t.Fatalf("Get() = %+v", p)
+		}
+	}
+	if decodes != 1 {
+		t.Errorf("decodes = %d, want 1", decodes)
+	}
+
+	//缓存数据变化后重新解码
+	repo.mu.Lock()
+	repo.data["p"] = []byte(`{"name":"name-2","age":22,"address":{"province":"广东","city":"广州"}}`)
+	repo.mu.Unlock()
+	if p := get(); p != personObj1 {
+		t.Errorf("Get() after change = %+v, want %+v", p, personObj1)
+	}
+	if decodes != 2 {
+		t.Errorf("decodes = %d, want 2", decodes)
+	}
+}
+
+func BenchmarkOption_DecodeCache(b *testing.B) {
+	for _, enabled := range []bool{false, true} {
+		name := "off"
+		if enabled {
+			name = "on"
+		}
+		b.Run(name, func(b *testing.B) {
+			ctx := context.Background()
+			c := cacher.New(newRepoMap(map[string]interface{}{"p": personObjBs}), 10*time.Second, func(opt *cacher.Option) {
+				opt.DecodeCache = enabled
+			})
+			if err := cacher.RegisterType[person](c); err != nil {
+				b.Fatal(err)
+			}
+			query := func() (interface{}, error) { return nil, notNeedCall }
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				var p person
+				if _, err := c.Get(ctx, "p", query, &p); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,103 @@
+package cacher
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// decompressor 读取时自动识别的压缩格式
+type decompressor struct {
+	name  string
+	magic []byte
+	fn    func(data []byte) ([]byte, error) //为 nil 时表示识别到该格式但不支持解压
+}
+
+// decompressors 读取时自动识别的压缩格式。内置 gzip；zstd 需要通过 RegisterDecompressor 注册解压方法
+var decompressors = struct {
+	sync.RWMutex
+	list []decompressor
+}{list: []decompressor{
+	{name: "gzip", magic: []byte{0x1f, 0x8b}, fn: gunzip},
+	{name: "zstd", magic: []byte{0x28, 0xb5, 0x2f, 0xfd}},
+}}
+
+// RegisterDecompressor 注册读取时自动识别的压缩格式，magic 是压缩数据开头的魔数，已注册相同魔数时替换。
+// 例如使用 github.com/klauspost/compress/zstd 支持 zstd：
+//
+//	decoder, _ := zstd.NewReader(nil)
+//	cacher.RegisterDecompressor("zstd", []byte{0x28, 0xb5, 0x2f, 0xfd}, func(data []byte) ([]byte, error) {
+//		return decoder.DecodeAll(data, nil)
+//	})
+func RegisterDecompressor(name string, magic []byte, fn func(data []byte) ([]byte, error)) {
+	decompressors.Lock()
+	defer decompressors.Unlock()
+	d := decompressor{name: name, magic: append([]byte(nil), magic...), fn: fn}
+	for i, exist := range decompressors.list {
+		if bytes.Equal(exist.magic, magic) {
+			decompressors.list[i] = d
+			return
+		}
+	}
+	decompressors.list = append(decompressors.list, d)
+}
+
+// decompress 缓存数据是字符串或字节切片，且以已注册的压缩格式的魔数开头时，解压后按原类型返回。
+// 用于读取由其他系统压缩保存的旧数据，见 Option.KeepCompressed
+func decompress(from reflect.Value) (reflect.Value, error) {
+	data, matched := matchDecompressor(from)
+	if matched == nil {
+		return from, nil
+	}
+	if matched.fn == nil {
+		return from, fmt.Errorf("缓存数据是 %s 压缩格式，需要通过 RegisterDecompressor 注册解压方法", matched.name)
+	}
+	out, err := matched.fn(data)
+	if err != nil {
+		return from, fmt.Errorf("解压 %s 格式的缓存数据失败：%w", matched.name, err)
+	}
+	if from.Kind() == reflect.String {
+		return reflect.ValueOf(string(out)).Convert(from.Type()), nil
+	}
+	return reflect.ValueOf(out).Convert(from.Type()), nil
+}
+
+// matchDecompressor 识别缓存数据的压缩格式，不是字符串、字节切片或没有识别到时返回 nil
+func matchDecompressor(from reflect.Value) ([]byte, *decompressor) {
+	var data []byte
+	switch {
+	case !from.IsValid():
+		return nil, nil
+	case from.Kind() == reflect.String:
+		data = []byte(from.String())
+	case isBytes(from.Type()):
+		data = from.Bytes()
+	default:
+		return nil, nil
+	}
+	if len(data) < 2 {
+		return nil, nil
+	}
+	decompressors.RLock()
+	defer decompressors.RUnlock()
+	for i := range decompressors.list {
+		if bytes.HasPrefix(data, decompressors.list[i].magic) {
+			d := decompressors.list[i]
+			return data, &d
+		}
+	}
+	return nil, nil
+}
+
+// gunzip 解压 gzip 数据
+func gunzip(data []byte) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return io.ReadAll(r)
+}
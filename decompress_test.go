@@ -0,0 +1,68 @@
+package cacher_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func gzipData(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	gz := gzipData(t, personObjBs)
+	cacher.RegisterDecompressor("test", []byte("TZ:"), func(data []byte) ([]byte, error) {
+		return bytes.TrimPrefix(data, []byte("TZ:")), nil
+	})
+	tests := []struct {
+		name    string
+		data    interface{}
+		v       interface{}
+		optFn   func(opt *cacher.Option)
+		want    interface{}
+		wantErr string
+	}{
+		{name: "gzip 字节切片：结构体", data: gz, v: &person{}, want: personObj},
+		{name: "gzip 字符串：结构体", data: string(gz), v: &person{}, want: personObj},
+		{name: "gzip 字节切片：字节切片", data: gz, v: new([]byte), want: personObjBs},
+		{name: "注册的压缩格式", data: "TZ:hello", v: new(string), want: "hello"},
+		{name: "未压缩", data: "hello", v: new(string), want: "hello"},
+		{name: "不自动解压", data: gz, v: new([]byte), optFn: func(opt *cacher.Option) { opt.KeepCompressed = true }, want: gz},
+		{name: "zstd 未注册解压方法", data: []byte{0x28, 0xb5, 0x2f, 0xfd, 0}, v: new([]byte), wantErr: "zstd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cacher.New(newRepoMap(map[string]interface{}{"k": tt.data}), time.Minute)
+			if err := cacher.RegisterType[person](c); err != nil {
+				t.Fatal(err)
+			}
+			_, err := c.GetWithOption(context.Background(), "k", func() (interface{}, error) { return nil, notNeedCall }, tt.v, tt.optFn)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("Get() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("v = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// Failure 读取流程中的故障类型，见 Option.Degrade
+type Failure int
+
+const (
+	// FailRepoRead 读取存储库出错
+	FailRepoRead Failure = iota
+	// FailRepoWrite 查询后写入存储库出错
+	FailRepoWrite
+	// FailDecode 缓存数据无法转换为目标类型，在 Option.OnDecodeError 处理之后仍然失败
+	FailDecode
+	// FailLoader 查询方法返回错误
+	FailLoader
+	// FailLoaderTimeout 查询方法超时：超过 Option.Budget 时间预算，或查询方法返回 context.DeadlineExceeded
+	FailLoaderTimeout
+)
+
+func (f Failure) String() string {
+	switch f {
+	case FailRepoRead:
+		return "repo-read"
+	case FailRepoWrite:
+		return "repo-write"
+	case FailDecode:
+		return "decode"
+	case FailLoader:
+		return "loader"
+	case FailLoaderTimeout:
+		return "loader-timeout"
+	}
+	return "unknown"
+}
+
+// DegradeAction 故障的处理方式
+type DegradeAction int
+
+const (
+	// DegradeDefault 保持该故障原有的处理方式：超过时间预算时有旧数据返回旧数据，否则返回错误；其他故障都返回错误
+	DegradeDefault DegradeAction = iota
+	// DegradeFail 返回错误。超过时间预算时也不返回旧数据
+	DegradeFail
+	// DegradeFailOpen 忽略故障：读取出错或无法转换时当作没有缓存，调用查询方法；写入出错时返回查询数据；
+	// 查询出错或超时时当作没有数据，返回 false, nil，不修改缓存。忽略的错误通过 EventError 事件发布
+	DegradeFailOpen
+	// DegradeServeStale 返回旧数据和 ErrStale，需要设置 Option.StaleExpire，没有旧数据时返回原错误
+	DegradeServeStale
+	// DegradeNegativeCache 查询出错时当作查询数据为空，按空缓存策略保存空缓存，不返回错误。只用于查询出错，需要保存空缓存
+	DegradeNegativeCache
+)
+
+func (a DegradeAction) String() string {
+	switch a {
+	case DegradeDefault:
+		return "default"
+	case DegradeFail:
+		return "fail"
+	case DegradeFailOpen:
+		return "fail-open"
+	case DegradeServeStale:
+		return "serve-stale"
+	case DegradeNegativeCache:
+		return "negative-cache"
+	}
+	return "unknown"
+}
+
+// Degradation 降级策略，声明每种故障的处理方式，在读取流程中统一由 degrade 处理。零值保持原有行为
+type Degradation struct {
+	RepoRead      DegradeAction //读取存储库出错
+	RepoWrite     DegradeAction //写入存储库出错，只支持 DegradeFail、DegradeFailOpen
+	Decode        DegradeAction //缓存数据无法转换为目标类型
+	Loader        DegradeAction //查询方法返回错误。Option.ErrCacheExpire 缓存的查询错误命中时不降级
+	LoaderTimeout DegradeAction //查询方法超时，不支持 DegradeNegativeCache
+}
+
+// action 故障的处理方式，DegradeDefault 转换为原有的处理方式
+func (d Degradation) action(f Failure, opt Option) DegradeAction {
+	var a DegradeAction
+	switch f {
+	case FailRepoRead:
+		a = d.RepoRead
+	case FailRepoWrite:
+		a = d.RepoWrite
+	case FailDecode:
+		a = d.Decode
+	case FailLoader:
+		a = d.Loader
+	case FailLoaderTimeout:
+		a = d.LoaderTimeout
+		if a == DegradeDefault && opt.StaleExpire > 0 {
+			a = DegradeServeStale
+		}
+	}
+	if a == DegradeDefault {
+		return DegradeFail
+	}
+	return a
+}
+
+// valid 校验降级策略
+func (d Degradation) valid(opt Option) error {
+	actions := []struct {
+		field  string
+		action DegradeAction
+	}{
+		{"Degrade.RepoRead", d.RepoRead},
+		{"Degrade.RepoWrite", d.RepoWrite},
+		{"Degrade.Decode", d.Decode},
+		{"Degrade.Loader", d.Loader},
+		{"Degrade.LoaderTimeout", d.LoaderTimeout},
+	}
+	for _, a := range actions {
+		if a.action < DegradeDefault || a.action > DegradeNegativeCache {
+			return &OptionError{Field: a.field, Reason: "不支持的处理方式"}
+		}
+		if a.action == DegradeServeStale && opt.StaleExpire <= 0 {
+			return &OptionError{Field: a.field, Reason: "DegradeServeStale 需要同时设置 StaleExpire，否则没有旧数据可以返回"}
+		}
+	}
+	if d.RepoWrite == DegradeServeStale || d.RepoWrite == DegradeNegativeCache {
+		return &OptionError{Field: "Degrade.RepoWrite", Reason: "只支持 DegradeFail、DegradeFailOpen"}
+	}
+	for _, a := range actions[:3] {
+		if a.action == DegradeNegativeCache {
+			return &OptionError{Field: a.field, Reason: "DegradeNegativeCache 只用于查询出错"}
+		}
+	}
+	if d.LoaderTimeout == DegradeNegativeCache {
+		return &OptionError{Field: "Degrade.LoaderTimeout", Reason: "超时的查询可能在后台完成并写入缓存，不支持 DegradeNegativeCache"}
+	}
+	if d.Loader == DegradeNegativeCache && (!opt.isCacheNil() || opt.OnNil == NilNotFound) {
+		return &OptionError{Field: "Degrade.Loader", Reason: "DegradeNegativeCache 需要保存空缓存，应当设置 NilCacheExpire 或 OnNil 为 NilCache"}
+	}
+	if d.Loader == DegradeNegativeCache && opt.ErrCacheExpire > 0 {
+		return &OptionError{Field: "Degrade.Loader", Reason: "DegradeNegativeCache 与 ErrCacheExpire 冲突，查询错误不能同时缓存为空数据和错误"}
+	}
+	return nil
+}
+
+// loadFailure 查询方法的错误，与写入缓存等其他错误区分，由 degrade 按 Degradation.Loader 处理
+type loadFailure struct {
+	err error
+}
+
+func (e *loadFailure) Error() string { return e.err.Error() }
+
+func (e *loadFailure) Unwrap() error { return e.err }
+
+// loaderFailure 查询方法的错误对应的故障类型
+func loaderFailure(err error) Failure {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return FailLoaderTimeout
+	}
+	return FailLoader
+}
+
+// degrade 按 Option.Degrade 处理读取流程中的故障 cause，所有故障都在这里决定处理方式。
+// 返回值：resume 为 true 时忽略故障，读取流程继续（读取出错、无法转换时当作没有缓存，写入出错时返回查询数据）；
+// 为 false 时读取流程结束，返回 useCache, err
+func (c *Cacher) degrade(ctx context.Context, key string, f Failure, cause error, to reflect.Value, toType reflect.Type, opt Option) (resume, useCache bool, err error) {
+	switch opt.Degrade.action(f, opt) {
+	case DegradeFailOpen:
+		c.emitError(f.String(), key, cause)
+		if f == FailLoader || f == FailLoaderTimeout {
+			to.Set(reflect.Zero(to.Type()))
+			return false, false, nil
+		}
+		return true, false, nil
+	case DegradeServeStale:
+		useCache, err = c.stale(ctx, key, cause, to, toType, opt)
+		return false, useCache, err
+	case DegradeNegativeCache:
+		data, err := c.save(detach(ctx), key, nil, toType, opt)
+		if err != nil {
+			return false, false, err
+		}
+		c.emitError(f.String(), key, cause)
+		if data == nil {
+			return false, false, nil
+		}
+		return false, false, c.assign(reflect.ValueOf(data), to, toType, opt)
+	}
+	//缓存存在但无法转换时，仍然算作命中缓存
+	return false, f == FailDecode, cause
+}
@@ -0,0 +1,152 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+// repoFaulty 读取指定的缓存键或写入时返回错误
+type repoFaulty struct {
+	*repoMap
+	getErrs map[string]error
+	setErr  error
+}
+
+func (r *repoFaulty) Get(ctx context.Context, key string) (interface{}, error) {
+	if err := r.getErrs[key]; err != nil {
+		return nil, err
+	}
+	return r.repoMap.Get(ctx, key)
+}
+
+func (r *repoFaulty) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	if r.setErr != nil {
+		return r.setErr
+	}
+	return r.repoMap.Set(ctx, key, value, expire)
+}
+
+func TestOption_Degrade(t *testing.T) {
+	errRead := errors.New("read failed")
+	errWrite := errors.New("write failed")
+	errQuery := errors.New("query failed")
+	const staleKey = "cacher:stale:k"
+	tests := []struct {
+		name      string
+		data      map[string]interface{}
+		getErrs   map[string]error
+		setErr    error
+		queryErr  error
+		degrade   cacher.Degradation
+		wantCache bool
+		wantErr   error
+		want      int
+		wantSaved interface{} //k 保存的缓存数据，为 nil 时不检查
+	}{
+		{name: "read fail", getErrs: map[string]error{"k": errRead}, wantErr: errRead},
+		{name: "read fail-open", getErrs: map[string]error{"k": errRead}, degrade: cacher.Degradation{RepoRead: cacher.DegradeFailOpen}, want: 1, wantSaved: 1},
+		{name: "read serve-stale", data: map[string]interface{}{staleKey: 7}, getErrs: map[string]error{"k": errRead},
+			degrade: cacher.Degradation{RepoRead: cacher.DegradeServeStale}, wantCache: true, wantErr: cacher.ErrStale, want: 7},
+		{name: "read serve-stale without stale data", getErrs: map[string]error{"k": errRead},
+			degrade: cacher.Degradation{RepoRead: cacher.DegradeServeStale}, wantErr: errRead},
+		{name: "write fail", setErr: errWrite, wantErr: errWrite},
+		{name: "write fail-open", setErr: errWrite, degrade: cacher.Degradation{RepoWrite: cacher.DegradeFailOpen}, want: 1},
+		{name: "decode fail", data: map[string]interface{}{"k": "bad"}, wantErr: errors.New("")},
+		{name: "decode fail-open", data: map[string]interface{}{"k": "bad"}, degrade: cacher.Degradation{Decode: cacher.DegradeFailOpen}, want: 1, wantSaved: 1},
+		{name: "decode serve-stale", data: map[string]interface{}{"k": "bad", staleKey: "7"},
+			degrade: cacher.Degradation{Decode: cacher.DegradeServeStale}, wantCache: true, wantErr: cacher.ErrStale, want: 7},
+		{name: "loader fail", queryErr: errQuery, wantErr: errQuery},
+		{name: "loader fail-open", queryErr: errQuery, degrade: cacher.Degradation{Loader: cacher.DegradeFailOpen}},
+		{name: "loader serve-stale", data: map[string]interface{}{staleKey: 7}, queryErr: errQuery,
+			degrade: cacher.Degradation{Loader: cacher.DegradeServeStale}, wantCache: true, wantErr: cacher.ErrStale, want: 7},
+		{name: "loader negative-cache", queryErr: errQuery, degrade: cacher.Degradation{Loader: cacher.DegradeNegativeCache}, wantSaved: "\x00cacher:nil"},
+		{name: "loader timeout is not loader", queryErr: context.DeadlineExceeded,
+			degrade: cacher.Degradation{Loader: cacher.DegradeFailOpen}, wantErr: context.DeadlineExceeded},
+		{name: "loader timeout fail-open", queryErr: context.DeadlineExceeded, degrade: cacher.Degradation{LoaderTimeout: cacher.DegradeFailOpen}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := &repoFaulty{repoMap: newRepoMap(tt.data), getErrs: tt.getErrs, setErr: tt.setErr}
+			c := cacher.New(repo, time.Minute)
+			v := -1
+			useCache, err := c.GetWithOption(ctx, "k", func() (interface{}, error) {
+				if tt.queryErr != nil {
+					return nil, tt.queryErr
+				}
+				return 1, nil
+			}, &v, func(opt *cacher.Option) {
+				opt.StaleExpire = time.Minute
+				opt.NilCacheExpire = time.Minute
+				opt.Degrade = tt.degrade
+			})
+			switch {
+			case tt.wantErr == nil && err != nil:
+				t.Fatalf("GetWithOption() error = %v", err)
+			case tt.wantErr != nil && err == nil:
+				t.Fatalf("GetWithOption() error = nil, want %v", tt.wantErr)
+			case tt.wantErr != nil && tt.wantErr.Error() != "" && !errors.Is(err, tt.wantErr):
+				t.Fatalf("GetWithOption() error = %v, want %v", err, tt.wantErr)
+			}
+			if useCache != tt.wantCache {
+				t.Errorf("GetWithOption() = %v, want %v", useCache, tt.wantCache)
+			}
+			if (tt.wantErr == nil || errors.Is(tt.wantErr, cacher.ErrStale)) && v != tt.want {
+				t.Errorf("v = %d, want %d", v, tt.want)
+			}
+			if tt.wantSaved != nil {
+				if got := repo.data["k"]; got != tt.wantSaved {
+					t.Errorf("saved = %v, want %v", got, tt.wantSaved)
+				}
+			}
+		})
+	}
+}
+
+func TestOption_Degrade_Valid(t *testing.T) {
+	tests := []struct {
+		name  string
+		optFn func(opt *cacher.Option)
+		field string
+	}{
+		{name: "serve-stale without stale", field: "Degrade.RepoRead", optFn: func(opt *cacher.Option) {
+			opt.Degrade.RepoRead = cacher.DegradeServeStale
+		}},
+		{name: "write serve-stale", field: "Degrade.RepoWrite", optFn: func(opt *cacher.Option) {
+			opt.StaleExpire = time.Minute
+			opt.Degrade.RepoWrite = cacher.DegradeServeStale
+		}},
+		{name: "decode negative-cache", field: "Degrade.Decode", optFn: func(opt *cacher.Option) {
+			opt.Degrade.Decode = cacher.DegradeNegativeCache
+		}},
+		{name: "timeout negative-cache", field: "Degrade.LoaderTimeout", optFn: func(opt *cacher.Option) {
+			opt.NilCacheExpire = time.Minute
+			opt.Degrade.LoaderTimeout = cacher.DegradeNegativeCache
+		}},
+		{name: "negative-cache without nil cache", field: "Degrade.Loader", optFn: func(opt *cacher.Option) {
+			opt.Degrade.Loader = cacher.DegradeNegativeCache
+		}},
+		{name: "negative-cache with error cache", field: "Degrade.Loader", optFn: func(opt *cacher.Option) {
+			opt.NilCacheExpire = time.Minute
+			opt.ErrCacheExpire = time.Minute
+			opt.Degrade.Loader = cacher.DegradeNegativeCache
+		}},
+		{name: "unknown action", field: "Degrade.Loader", optFn: func(opt *cacher.Option) {
+			opt.Degrade.Loader = cacher.DegradeAction(99)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var opt cacher.Option
+			tt.optFn(&opt)
+			err := opt.Valid()
+			var optErr *cacher.OptionError
+			if !errors.As(err, &optErr) || optErr.Field != tt.field {
+				t.Errorf("Valid() = %v, want error of field %s", err, tt.field)
+			}
+		})
+	}
+}
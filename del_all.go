@@ -0,0 +1,80 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// delAllAttempts DelAll 删除每个缓存键的最多尝试次数
+	delAllAttempts = 3
+	// delAllBackoff DelAll 第一次重试前的等待时长，之后每次翻倍
+	delAllBackoff = 10 * time.Millisecond
+)
+
+type (
+	// DelAllError DelAll 部分缓存键删除失败，Failed 按参数顺序列出删除失败的缓存键和最后一次的错误
+	DelAllError struct {
+		Failed []DelFailure
+	}
+	// DelFailure 删除失败的缓存键
+	DelFailure struct {
+		Key string
+		Err error
+	}
+)
+
+func (e *DelAllError) Error() string {
+	keys := make([]string, len(e.Failed))
+	for i, f := range e.Failed {
+		keys[i] = f.Key
+	}
+	return fmt.Sprintf("%d 个缓存删除失败：%s：%v", len(e.Failed), strings.Join(keys, ", "), e.Failed[0].Err)
+}
+
+// Unwrap 返回第一个删除失败的错误
+func (e *DelAllError) Unwrap() error {
+	return e.Failed[0].Err
+}
+
+// DelAll 删除一组相关的缓存（例如实体和它的派生视图），每个缓存键同 Del 级联删除依赖的缓存，出错时重试。
+// 部分缓存键重试后仍然删除失败时，返回 *DelAllError 列出删除失败的缓存键，调用方可以只重试这些缓存键。
+// 已删除的缓存不会恢复：恢复旧数据比缺少缓存更糟
+func (c *Cacher) DelAll(ctx context.Context, keys ...string) error {
+	opt := c.options()
+	var failed []DelFailure
+	for _, key := range keys {
+		k, err := repoKey(key, opt)
+		if err == nil {
+			err = c.delRetry(ctx, k)
+		}
+		if err != nil {
+			failed = append(failed, DelFailure{Key: key, Err: err})
+		}
+	}
+	if len(failed) > 0 {
+		return &DelAllError{Failed: failed}
+	}
+	return nil
+}
+
+// delRetry 删除缓存，出错时按指数退避重试，ctx 结束时不再重试
+func (c *Cacher) delRetry(ctx context.Context, key string) error {
+	backoff := delAllBackoff
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = c.del(ctx, key); err == nil || attempt == delAllAttempts {
+			return err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
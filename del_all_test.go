@@ -0,0 +1,82 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// flakyDelRepo 指定的缓存键前几次删除失败
+type flakyDelRepo struct {
+	*repoMap
+	mu    sync.Mutex
+	fails map[string]int
+}
+
+func (r *flakyDelRepo) Del(ctx context.Context, keys ...string) error {
+	r.mu.Lock()
+	for _, key := range keys {
+		if r.fails[key] > 0 {
+			r.fails[key]--
+			r.mu.Unlock()
+			return errors.New("del " + key + " failed")
+		}
+	}
+	r.mu.Unlock()
+	return r.repoMap.Del(ctx, keys...)
+}
+
+func TestCacher_DelAll(t *testing.T) {
+	tests := []struct {
+		name       string
+		fails      map[string]int
+		wantFailed []string
+	}{
+		{name: "全部删除", wantFailed: nil},
+		{name: "重试后删除", fails: map[string]int{"b": 2}, wantFailed: nil},
+		{name: "重试后仍然失败", fails: map[string]int{"b": 5, "c": 3}, wantFailed: []string{"b", "c"}},
+	}
+	for _, tt := range tests {
+		repo := &flakyDelRepo{repoMap: newRepoMap(map[string]interface{}{"a": "1", "b": "2", "c": "3"}), fails: tt.fails}
+		c := cacher.New(repo, time.Minute)
+		err := c.DelAll(context.Background(), "a", "b", "c")
+		var delErr *cacher.DelAllError
+		if len(tt.wantFailed) == 0 {
+			if err != nil {
+				t.Errorf("%s: DelAll() = %v", tt.name, err)
+			}
+		} else if !errors.As(err, &delErr) {
+			t.Errorf("%s: DelAll() = %v, want *DelAllError", tt.name, err)
+		} else {
+			var failed []string
+			for _, f := range delErr.Failed {
+				failed = append(failed, f.Key)
+			}
+			if !reflect.DeepEqual(failed, tt.wantFailed) {
+				t.Errorf("%s: failed = %v, want %v", tt.name, failed, tt.wantFailed)
+			}
+		}
+		for _, key := range []string{"a", "b", "c"} {
+			_, cached := repo.data[key]
+			wantCached := false
+			for _, f := range tt.wantFailed {
+				wantCached = wantCached || f == key
+			}
+			if cached != wantCached {
+				t.Errorf("%s: %s cached = %v, want %v", tt.name, key, cached, wantCached)
+			}
+		}
+	}
+
+	//ctx 结束后不再重试
+	repo := &flakyDelRepo{repoMap: newRepoMap(map[string]interface{}{"a": "1"}), fails: map[string]int{"a": 2}}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := cacher.New(repo, time.Minute).DelAll(ctx, "a"); err == nil || repo.fails["a"] != 1 {
+		t.Errorf("DelAll() with canceled ctx = %v, remaining fails %d, want no retry", err, repo.fails["a"])
+	}
+}
@@ -0,0 +1,138 @@
+package cacher
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// dependIndexKeyPrefix 依赖索引的缓存键前缀，索引中保存依赖该缓存的缓存键
+	dependIndexKeyPrefix = "cacher:dep:"
+	// dependLockStripes 依赖索引更新锁的分段数
+	dependLockStripes = 64
+)
+
+// keyLocks 按缓存键分段的锁
+type keyLocks [dependLockStripes]sync.Mutex
+
+// lock 锁定缓存键 key 所在的分段，返回解锁的方法
+func (l *keyLocks) lock(key string) func() {
+	mu := &l[fnv32a(key)%dependLockStripes]
+	mu.Lock()
+	return mu.Unlock
+}
+
+// WithDependsOn 声明本次缓存依赖的缓存键。
+// 通过 Cacher.Del 删除被依赖的缓存时，级联删除本缓存
+func WithDependsOn(keys ...string) func(opt *Option) {
+	return func(opt *Option) {
+		opt.DependsOn = append(opt.DependsOn, keys...)
+	}
+}
+
+// dependIndexKey 依赖索引的缓存键
+func dependIndexKey(key string) string {
+	return dependIndexKeyPrefix + key
+}
+
+// addDependent 把 key 登记到所有被依赖缓存的依赖索引中，expire 为 key 的保留时长
+func (c *Cacher) addDependent(ctx context.Context, key string, dependsOn []string, expire time.Duration) error {
+	for _, dep := range dependsOn {
+		if dep == "" || dep == key {
+			continue
+		}
+		if err := c.addDependentTo(ctx, dep, key, expire); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addDependentTo 把 key 登记到 dep 的依赖索引中。同一进程内对同一个索引的读取、修改、写入串行执行，避免并发登记时丢失缓存键。
+// 索引的保留时长取剩余保留时长和 expire 中较长的，不会早于已登记的依赖缓存过期；存储库没有实现 TTLRepo 时无法得知剩余保留时长，索引不过期
+func (c *Cacher) addDependentTo(ctx context.Context, dep, key string, expire time.Duration) error {
+	defer c.dependLocks.lock(dep)()
+	dependents, err := c.readDependents(ctx, dep)
+	if err != nil {
+		return err
+	}
+	exist := false
+	for _, k := range dependents {
+		if k == key {
+			exist = true
+			break
+		}
+	}
+	indexExpire, extend, err := c.dependIndexExpire(ctx, dep, expire)
+	if err != nil {
+		return err
+	}
+	if exist && !extend {
+		return nil
+	}
+	if !exist {
+		dependents = append(dependents, key)
+	}
+	return c.repo.Set(ctx, dependIndexKey(dep), strings.Join(dependents, "\n"), indexExpire)
+}
+
+// dependIndexExpire 登记保留时长为 expire 的依赖缓存后，dep 的依赖索引的保留时长，0表示不过期；extend 为 true 时需要延长索引的保留时长
+func (c *Cacher) dependIndexExpire(ctx context.Context, dep string, expire time.Duration) (_ time.Duration, extend bool, _ error) {
+	ttlRepo, ok := c.repo.(TTLRepo)
+	if !ok {
+		return 0, false, nil
+	}
+	ttl, err := ttlRepo.TTL(ctx, dependIndexKey(dep))
+	if err != nil {
+		return 0, false, err
+	}
+	switch {
+	case ttl == -1:
+		//索引不过期
+		return 0, false, nil
+	case expire <= 0:
+		return 0, true, nil
+	case ttl >= expire:
+		return ttl, false, nil
+	}
+	return expire, true, nil
+}
+
+// dependents 返回 key 及所有直接、间接依赖 key 的缓存键
+func (c *Cacher) dependents(ctx context.Context, key string) ([]string, error) {
+	keys := []string{key}
+	visited := map[string]bool{key: true}
+	for i := 0; i < len(keys); i++ {
+		dependents, err := c.readDependents(ctx, keys[i])
+		if err != nil {
+			return nil, err
+		}
+		for _, k := range dependents {
+			if !visited[k] {
+				visited[k] = true
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys, nil
+}
+
+// readDependents 读取直接依赖 key 的缓存键
+func (c *Cacher) readDependents(ctx context.Context, key string) ([]string, error) {
+	data, err := c.repo.Get(ctx, dependIndexKey(key))
+	if err != nil || data == nil {
+		return nil, err
+	}
+	var index string
+	to := reflect.ValueOf(&index).Elem()
+	if err := c.assign(reflect.ValueOf(data), to, to.Type(), c.options()); err != nil {
+		return nil, err
+	}
+	if index == "" {
+		return nil, nil
+	}
+	return strings.Split(index, "\n"), nil
+}
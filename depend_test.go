@@ -0,0 +1,86 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacher_WithDependsOn(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, 10*time.Second)
+	load := func(key string, optFns ...func(opt *cacher.Option)) {
+		var v string
+		if _, err := c.GetWithOption(ctx, key, func() (interface{}, error) {
+			return key + "-value", nil
+		}, &v, optFns...); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	load("user:42")
+	load("user:42:summary", cacher.WithDependsOn("user:42"))
+	load("team:7:report", cacher.WithDependsOn("user:42:summary"))
+	load("user:43")
+
+	if err := c.Del(ctx, "user:42"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"user:42", "user:42:summary", "team:7:report"} {
+		if val, _ := repo.Get(ctx, key); val != nil {
+			t.Errorf("%s not deleted: %v", key, val)
+		}
+	}
+	if val, _ := repo.Get(ctx, "user:43"); val == nil {
+		t.Errorf("user:43 should not be deleted")
+	}
+}
+
+func TestCacher_WithDependsOn_Index(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, 10*time.Second)
+	load := func(key string, expire time.Duration) {
+		var v string
+		if _, err := c.GetWithOption(ctx, key, func() (interface{}, error) {
+			return key + "-value", nil
+		}, &v, cacher.WithDependsOn("user:42"), func(opt *cacher.Option) {
+			opt.Expire = expire
+		}); err != nil {
+			t.Error(err)
+		}
+	}
+
+	//后登记的依赖缓存保留时长较短时，索引不会提前过期
+	load("long", time.Hour)
+	load("short", time.Second)
+	if ttl, err := repo.TTL(ctx, "cacher:dep:user:42"); err != nil || ttl < 59*time.Minute {
+		t.Errorf("index TTL = %v, %v, want about 1h", ttl, err)
+	}
+
+	//并发登记不丢失缓存键
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			load("dependent:"+strconv.Itoa(i), time.Minute)
+		}(i)
+	}
+	wg.Wait()
+	if err := c.Del(ctx, "user:42"); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if val, _ := repo.Get(ctx, "dependent:"+strconv.Itoa(i)); val != nil {
+			t.Errorf("dependent:%d not deleted", i)
+		}
+	}
+	if val, _ := repo.Get(ctx, "long"); val != nil {
+		t.Errorf("long not deleted")
+	}
+}
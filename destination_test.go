@@ -0,0 +1,94 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacher_Destination(t *testing.T) {
+	var (
+		nilPtr    *person
+		iface     interface{} = person{}
+		nilIface  interface{}
+		m         = map[string]person{"k": {}}
+		personPtr *person
+	)
+	tests := []struct {
+		name     string
+		v        interface{}
+		wantErr  bool
+		wantData interface{}
+	}{
+		{name: "nil", v: nil, wantErr: true},
+		{name: "非指针", v: person{}, wantErr: true},
+		{name: "空指针", v: nilPtr, wantErr: true},
+		{name: "map 元素", v: m["k"], wantErr: true},
+		{name: "接口：nil，赋值原始数据", v: &nilIface, wantData: personObj},
+		{name: "接口：有具体类型", v: &iface, wantData: personObj},
+		{name: "指针的指针：自动分配", v: &personPtr, wantData: &personObj},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cacher.New(newRepoMap(map[string]interface{}{"k": personObj}), 10*time.Second)
+			_, err := c.Get(context.Background(), "k", func() (interface{}, error) {
+				return nil, notNeedCall
+			}, tt.v)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && !reflect.DeepEqual(reflect.ValueOf(tt.v).Elem().Interface(), tt.wantData) {
+				t.Errorf("v = %v, want %v", reflect.ValueOf(tt.v).Elem().Interface(), tt.wantData)
+			}
+		})
+	}
+}
+
+func TestCacher_ConverterWrongType(t *testing.T) {
+	c := cacher.New(newRepoMap(map[string]interface{}{"k": "x"}), 10*time.Second)
+	c.RegisterConverter(cacher.TypeConverter{
+		SrcType: "",
+		DstType: person{},
+		Fn: func(src interface{}) (interface{}, error) {
+			return 1, nil
+		},
+	})
+	var p person
+	if _, err := c.Get(context.Background(), "k", func() (interface{}, error) {
+		return nil, notNeedCall
+	}, &p); err == nil {
+		t.Errorf("Get() want error when converter returns wrong type")
+	}
+}
+
+func TestWithTargetType(t *testing.T) {
+	tests := []struct {
+		name       string
+		targetType interface{}
+		wantData   interface{}
+	}{
+		{name: "结构体", targetType: person{}, wantData: personObj},
+		{name: "结构体指针", targetType: &person{}, wantData: &personObj},
+		{name: "未指定，赋值原始数据", targetType: nil, wantData: personObjBs},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cacher.New(&repoBytes{}, 10*time.Second)
+			if err := cacher.RegisterType[person](c); err != nil {
+				t.Fatal(err)
+			}
+			var v interface{}
+			_, err := c.GetWithOption(context.Background(), "person-1", func() (interface{}, error) {
+				return nil, notNeedCall
+			}, &v, cacher.WithTargetType(tt.targetType))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(v, tt.wantData) {
+				t.Errorf("v = %v, want %v", v, tt.wantData)
+			}
+		})
+	}
+}
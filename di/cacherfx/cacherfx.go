@@ -0,0 +1,58 @@
+// Package cacherfx 为 uber/fx 提供 Cacher 的构造函数和模块：
+// 根据 cacher.Config 创建 Cacher，并在应用停止时关闭存储库。
+// New 的参数使用 fx.In，同样可以直接用于 uber/dig
+package cacherfx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/carteruu/cacher"
+	"go.uber.org/fx"
+)
+
+// Module 提供默认的 *cacher.Cacher，需要由应用提供 cacher.Repo 和 cacher.Config
+var Module = fx.Module("cacher", fx.Provide(New))
+
+// Params New 的依赖
+type Params struct {
+	fx.In
+
+	Repo      cacher.Repo
+	Config    cacher.Config
+	Lifecycle fx.Lifecycle
+}
+
+// New 根据配置创建 Cacher，并注册应用停止时关闭存储库的钩子
+func New(p Params) (*cacher.Cacher, error) {
+	return newCacher(p.Repo, p.Config, p.Lifecycle)
+}
+
+// Named 提供名称为 cfg.Name 的 *cacher.Cacher，使用 `name:"<cfg.Name>"` 标签注入。
+// 存储库同样按名称注入，需要由应用提供名称相同的 cacher.Repo
+func Named(cfg cacher.Config) fx.Option {
+	if cfg.Name == "" {
+		return fx.Error(errors.New("cacherfx: Named 需要设置 Config.Name"))
+	}
+	tag := fmt.Sprintf(`name:"%s"`, cfg.Name)
+	return fx.Provide(fx.Annotate(
+		func(repo cacher.Repo, lc fx.Lifecycle) (*cacher.Cacher, error) {
+			return newCacher(repo, cfg, lc)
+		},
+		fx.ParamTags(tag),
+		fx.ResultTags(tag),
+	))
+}
+
+func newCacher(repo cacher.Repo, cfg cacher.Config, lc fx.Lifecycle) (*cacher.Cacher, error) {
+	c, err := cacher.NewFromConfig(repo, cfg)
+	if err != nil {
+		return nil, err
+	}
+	lc.Append(fx.Hook{
+		OnStop: func(context.Context) error {
+			return c.Close()
+		},
+	})
+	return c, nil
+}
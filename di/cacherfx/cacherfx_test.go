@@ -0,0 +1,72 @@
+package cacherfx_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/di/cacherfx"
+	"go.uber.org/fx"
+	"go.uber.org/fx/fxtest"
+	"testing"
+	"time"
+)
+
+// closeRepo 记录是否关闭的测试存储库
+type closeRepo struct {
+	*cacher.MemoryRepo
+	closed bool
+}
+
+func (r *closeRepo) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestModule(t *testing.T) {
+	repo := &closeRepo{MemoryRepo: cacher.NewMemoryRepo()}
+	var c *cacher.Cacher
+	app := fxtest.New(t,
+		fx.Supply(fx.Annotate(repo, fx.As(new(cacher.Repo)))),
+		fx.Supply(cacher.Config{Expire: time.Minute}),
+		cacherfx.Module,
+		fx.Populate(&c),
+	)
+	app.RequireStart()
+	var v string
+	if _, err := c.Get(context.Background(), "k", func() (interface{}, error) { return "v", nil }, &v); err != nil || v != "v" {
+		t.Fatalf("Get() = %v, %v", v, err)
+	}
+	app.RequireStop()
+	if !repo.closed {
+		t.Errorf("repo not closed on stop")
+	}
+}
+
+func TestNamed(t *testing.T) {
+	users, orders := &closeRepo{MemoryRepo: cacher.NewMemoryRepo()}, &closeRepo{MemoryRepo: cacher.NewMemoryRepo()}
+	var got struct {
+		fx.In
+		Users  *cacher.Cacher `name:"users"`
+		Orders *cacher.Cacher `name:"orders"`
+	}
+	app := fxtest.New(t,
+		fx.Provide(
+			fx.Annotate(func() cacher.Repo { return users }, fx.ResultTags(`name:"users"`)),
+			fx.Annotate(func() cacher.Repo { return orders }, fx.ResultTags(`name:"orders"`)),
+		),
+		cacherfx.Named(cacher.Config{Name: "users", Expire: time.Minute}),
+		cacherfx.Named(cacher.Config{Name: "orders", Expire: time.Minute}),
+		fx.Populate(&got),
+	)
+	app.RequireStart()
+	if got.Users == nil || got.Orders == nil || got.Users == got.Orders {
+		t.Fatalf("named cachers = %p, %p", got.Users, got.Orders)
+	}
+	app.RequireStop()
+	if !users.closed || !orders.closed {
+		t.Errorf("repos closed = %v, %v", users.closed, orders.closed)
+	}
+
+	if err := fx.New(cacherfx.Named(cacher.Config{Expire: time.Minute}), fx.NopLogger).Err(); err == nil {
+		t.Errorf("Named() without name want error")
+	}
+}
@@ -0,0 +1,19 @@
+module github.com/carteruu/cacher/di/cacherfx
+
+go 1.18
+
+replace github.com/carteruu/cacher => ../..
+
+require (
+	github.com/carteruu/cacher v0.0.0-00010101000000-000000000000
+	go.uber.org/fx v1.18.2
+)
+
+require (
+	go.uber.org/atomic v1.6.0 // indirect
+	go.uber.org/dig v1.15.0 // indirect
+	go.uber.org/multierr v1.5.0 // indirect
+	go.uber.org/zap v1.16.0 // indirect
+	golang.org/x/sync v0.0.0-20220923202941-7f9b1623fab7 // indirect
+	golang.org/x/sys v0.0.0-20210903071746-97244b99971b // indirect
+)
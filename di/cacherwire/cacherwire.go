@@ -0,0 +1,21 @@
+// Package cacherwire 为 google/wire 提供 Cacher 的提供者集合：
+// 根据 cacher.Config 创建 Cacher，并返回关闭存储库的清理函数
+package cacherwire
+
+import (
+	"github.com/carteruu/cacher"
+	"github.com/google/wire"
+)
+
+// ProviderSet 提供 *cacher.Cacher，需要由注入器提供 cacher.Repo 和 cacher.Config。
+// wire 按类型注入，需要多个 Cacher 时，请为每个 Cacher 定义不同的类型并编写各自的提供者
+var ProviderSet = wire.NewSet(Provide)
+
+// Provide 根据配置创建 Cacher，返回的清理函数关闭存储库
+func Provide(repo cacher.Repo, cfg cacher.Config) (*cacher.Cacher, func(), error) {
+	c, err := cacher.NewFromConfig(repo, cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, func() { _ = c.Close() }, nil
+}
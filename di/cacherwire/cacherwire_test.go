@@ -0,0 +1,19 @@
+package cacherwire_test
+
+import (
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/di/cacherwire"
+	"testing"
+	"time"
+)
+
+func TestProvide(t *testing.T) {
+	c, cleanup, err := cacherwire.Provide(cacher.NewMemoryRepo(), cacher.Config{Expire: time.Minute})
+	if err != nil || c == nil || cleanup == nil {
+		t.Fatalf("Provide() = %v, %v", c, err)
+	}
+	cleanup()
+	if _, _, err := cacherwire.Provide(cacher.NewMemoryRepo(), cacher.Config{}); err == nil {
+		t.Errorf("Provide() want error for zero Expire")
+	}
+}
@@ -0,0 +1,12 @@
+module github.com/carteruu/cacher/di/cacherwire
+
+go 1.18
+
+replace github.com/carteruu/cacher => ../..
+
+require (
+	github.com/carteruu/cacher v0.0.0-00010101000000-000000000000
+	github.com/google/wire v0.5.0
+)
+
+require golang.org/x/sync v0.0.0-20220923202941-7f9b1623fab7 // indirect
@@ -0,0 +1,140 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Key 结构化的缓存键，代替手工拼接的字符串缓存键，避免拼接方式不一致导致删除不到缓存。
+// 规范格式为 命名空间/实体:ID1:ID2@版本，例如 shop/user:42@2，各部分中的 % / : @ 被转义
+type Key struct {
+	Namespace string   //命名空间，不为空时同 Option.Namespace，可以通过 DelNamespace 使命名空间下的所有缓存失效
+	Entity    string   //实体名，例如 user，不能为空
+	ID        []string //实体 ID，可以由多个部分组成
+	Version   int      //缓存数据格式的版本，数据格式变化时递增，旧版本的缓存不再被读取。0 表示不区分版本
+}
+
+// keyEscaper 转义缓存键各部分中的分隔符
+var keyEscaper = strings.NewReplacer("%", "%25", "/", "%2F", ":", "%3A", "@", "%40")
+
+// String 规范格式的缓存键，可以作为日志字段，通过 ParseKey 解析
+func (k Key) String() string {
+	if k.Namespace == "" {
+		return k.path()
+	}
+	return keyEscaper.Replace(k.Namespace) + "/" + k.path()
+}
+
+// Label 命名空间和实体名，不包含 ID，用作监控指标的标签时不会产生过多的标签值
+func (k Key) Label() string {
+	if k.Namespace == "" {
+		return keyEscaper.Replace(k.Entity)
+	}
+	return keyEscaper.Replace(k.Namespace) + "/" + keyEscaper.Replace(k.Entity)
+}
+
+// path 不包含命名空间的部分，命名空间通过 Option.Namespace 处理
+func (k Key) path() string {
+	var b strings.Builder
+	b.WriteString(keyEscaper.Replace(k.Entity))
+	for _, id := range k.ID {
+		b.WriteByte(':')
+		b.WriteString(keyEscaper.Replace(id))
+	}
+	if k.Version != 0 {
+		b.WriteByte('@')
+		b.WriteString(strconv.Itoa(k.Version))
+	}
+	return b.String()
+}
+
+// valid 检查缓存键
+func (k Key) valid() error {
+	if k.Entity == "" {
+		return errors.New("缓存键的实体名 Entity 不能为空")
+	}
+	if k.Version < 0 {
+		return errors.New("缓存键的版本 Version 不能小于0")
+	}
+	return nil
+}
+
+// ParseKey 解析规范格式的缓存键，是 Key.String 的逆操作
+func ParseKey(s string) (Key, error) {
+	var k Key
+	rest := s
+	if i := strings.IndexByte(rest, '/'); i >= 0 {
+		k.Namespace, rest = rest[:i], rest[i+1:]
+	}
+	if i := strings.IndexByte(rest, '@'); i >= 0 {
+		version, err := strconv.Atoi(rest[i+1:])
+		if err != nil || version <= 0 {
+			return Key{}, fmt.Errorf("缓存键 %q 的版本错误", s)
+		}
+		k.Version, rest = version, rest[:i]
+	}
+	parts := strings.Split(rest, ":")
+	k.Entity = parts[0]
+	if len(parts) > 1 {
+		k.ID = parts[1:]
+	}
+	var err error
+	if k.Namespace, err = url.PathUnescape(k.Namespace); err != nil {
+		return Key{}, fmt.Errorf("缓存键 %q 格式错误：%w", s, err)
+	}
+	if k.Entity, err = url.PathUnescape(k.Entity); err != nil {
+		return Key{}, fmt.Errorf("缓存键 %q 格式错误：%w", s, err)
+	}
+	for i := range k.ID {
+		if k.ID[i], err = url.PathUnescape(k.ID[i]); err != nil {
+			return Key{}, fmt.Errorf("缓存键 %q 格式错误：%w", s, err)
+		}
+	}
+	if err := k.valid(); err != nil {
+		return Key{}, err
+	}
+	return k, nil
+}
+
+// GetKey 同 GetWithOption，使用结构化的缓存键，Key.Namespace 代替 Option.Namespace
+func (c *Cacher) GetKey(ctx context.Context, k Key, queryFunc func() (interface{}, error), v interface{}, optFns ...func(opt *Option)) (bool, error) {
+	if err := k.valid(); err != nil {
+		return false, err
+	}
+	optFns = append(optFns[:len(optFns):len(optFns)], func(opt *Option) {
+		opt.Namespace = k.Namespace
+	})
+	return c.GetWithOption(ctx, k.path(), queryFunc, v, optFns...)
+}
+
+// DelEntity 删除结构化缓存键对应的缓存，与 GetKey 一样通过 buildKey 生成存储库中的缓存键
+func (c *Cacher) DelEntity(ctx context.Context, keys ...Key) error {
+	repoKeys := make([]string, len(keys))
+	for i, k := range keys {
+		if err := k.valid(); err != nil {
+			return err
+		}
+		opt := c.options().clone()
+		opt.Namespace = k.Namespace
+		var err error
+		if repoKeys[i], err = c.buildKey(ctx, k.path(), opt); err != nil {
+			return err
+		}
+	}
+	if len(repoKeys) == 0 {
+		return nil
+	}
+	return c.delMulti(ctx, repoKeys)
+}
+
+// DelNamespace 使命名空间下的所有缓存失效，同 BumpNamespace
+func (c *Cacher) DelNamespace(ctx context.Context, ns string) error {
+	if ns == "" {
+		return errors.New("命名空间 ns 不能为空字符串")
+	}
+	return c.BumpNamespace(ctx, ns)
+}
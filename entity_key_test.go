@@ -0,0 +1,85 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestKey_String(t *testing.T) {
+	tests := []struct {
+		name  string
+		key   cacher.Key
+		want  string
+		label string
+	}{
+		{name: "只有实体", key: cacher.Key{Entity: "config"}, want: "config", label: "config"},
+		{name: "完整", key: cacher.Key{Namespace: "shop", Entity: "user", ID: []string{"42"}, Version: 2}, want: "shop/user:42@2", label: "shop/user"},
+		{name: "多个 ID", key: cacher.Key{Entity: "order", ID: []string{"1", "2"}}, want: "order:1:2", label: "order"},
+		{name: "转义", key: cacher.Key{Namespace: "a/b", Entity: "e:1", ID: []string{"x@y", "50%"}}, want: "a%2Fb/e%3A1:x%40y:50%25", label: "a%2Fb/e%3A1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.key.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+			if got := tt.key.Label(); got != tt.label {
+				t.Errorf("Label() = %q, want %q", got, tt.label)
+			}
+			parsed, err := cacher.ParseKey(tt.want)
+			if err != nil || !reflect.DeepEqual(parsed, tt.key) {
+				t.Errorf("ParseKey() = %+v, %v, want %+v", parsed, err, tt.key)
+			}
+		})
+	}
+	for _, s := range []string{"", "user@x", "user@0", "user:%zz"} {
+		if _, err := cacher.ParseKey(s); err == nil {
+			t.Errorf("ParseKey(%q) should fail", s)
+		}
+	}
+}
+
+func TestCacher_GetKey(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(cacher.NewMemoryRepo(), time.Minute)
+	loads := 0
+	query := func() (interface{}, error) {
+		loads++
+		return loads, nil
+	}
+	user := cacher.Key{Namespace: "shop", Entity: "user", ID: []string{"42"}}
+	get := func(k cacher.Key) int {
+		var v int
+		if _, err := c.GetKey(ctx, k, query, &v); err != nil {
+			t.Fatal(err)
+		}
+		return v
+	}
+	if got := get(user); got != 1 {
+		t.Fatalf("GetKey() = %d, want 1", got)
+	}
+	if got := get(user); got != 1 {
+		t.Errorf("GetKey() = %d, want cached 1", got)
+	}
+	//版本不同是不同的缓存
+	if got := get(cacher.Key{Namespace: "shop", Entity: "user", ID: []string{"42"}, Version: 1}); got != 2 {
+		t.Errorf("GetKey() of new version = %d, want 2", got)
+	}
+	if err := c.DelEntity(ctx, user); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(user); got != 3 {
+		t.Errorf("GetKey() after DelEntity = %d, want 3", got)
+	}
+	if err := c.DelNamespace(ctx, "shop"); err != nil {
+		t.Fatal(err)
+	}
+	if got := get(user); got != 4 {
+		t.Errorf("GetKey() after DelNamespace = %d, want 4", got)
+	}
+	if _, err := c.GetKey(ctx, cacher.Key{}, query, new(int)); err == nil {
+		t.Error("GetKey() with empty entity should fail")
+	}
+}
@@ -0,0 +1,67 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// entityIDPlaceholder 缓存键模板中实体 ID 的占位符
+const entityIDPlaceholder = "{id}"
+
+// entityTable 注册的实体和缓存键模板，见 RegisterEntity
+type entityTable struct {
+	mu sync.RWMutex
+	m  map[string][]string
+}
+
+// RegisterEntity 注册实体的缓存键模板，InvalidateEntity 按模板删除实体的所有缓存，避免新增派生缓存后漏删。
+// 模板中的 {id} 替换为实体 ID，例如 "user:{id}"、"user:{id}:orders"；没有 {id} 的模板（例如列表、汇总 "user:list"）
+// 在任意实体变化时都删除。通过 Option.DependsOn 依赖实体缓存的缓存同 Del 级联删除。再次注册时替换原来的模板
+func (c *Cacher) RegisterEntity(entity string, keys ...string) error {
+	if entity == "" {
+		return errors.New("实体名 entity 不能为空字符串")
+	}
+	if len(keys) == 0 {
+		return errors.New("缓存键模板 keys 不能为空")
+	}
+	for _, key := range keys {
+		if key == "" {
+			return errors.New("缓存键模板不能为空字符串")
+		}
+	}
+	c.entities.mu.Lock()
+	defer c.entities.mu.Unlock()
+	if c.entities.m == nil {
+		c.entities.m = make(map[string][]string)
+	}
+	c.entities.m[entity] = append([]string(nil), keys...)
+	return nil
+}
+
+// EntityKeys 实体 ID 为 id 时 InvalidateEntity 删除的缓存键，用于检查注册的模板
+func (c *Cacher) EntityKeys(entity string, id interface{}) ([]string, error) {
+	c.entities.mu.RLock()
+	templates, ok := c.entities.m[entity]
+	c.entities.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("实体 %s 没有注册缓存键模板", entity)
+	}
+	idStr := fmt.Sprint(id)
+	keys := make([]string, len(templates))
+	for i, tpl := range templates {
+		keys[i] = strings.ReplaceAll(tpl, entityIDPlaceholder, idStr)
+	}
+	return keys, nil
+}
+
+// InvalidateEntity 删除实体的所有缓存，同 DelAll 出错时重试，部分缓存键删除失败时返回 *DelAllError
+func (c *Cacher) InvalidateEntity(ctx context.Context, entity string, id interface{}) error {
+	keys, err := c.EntityKeys(entity, id)
+	if err != nil {
+		return err
+	}
+	return c.DelAll(ctx, keys...)
+}
@@ -0,0 +1,50 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacher_InvalidateEntity(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, time.Minute)
+	if err := c.RegisterEntity("user", "user:{id}", "user:{id}:orders", "user:list"); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := c.EntityKeys("user", 42)
+	if want := []string{"user:42", "user:42:orders", "user:list"}; err != nil || !reflect.DeepEqual(keys, want) {
+		t.Errorf("EntityKeys() = %v, %v, want %v", keys, err, want)
+	}
+
+	query := func() (interface{}, error) { return "v", nil }
+	var v string
+	for _, key := range []string{"user:42", "user:42:orders", "user:list", "user:7", "user:42:summary"} {
+		optFns := []func(opt *cacher.Option){}
+		if key == "user:42:summary" {
+			//通过依赖关系级联删除
+			optFns = append(optFns, func(opt *cacher.Option) { opt.DependsOn = []string{"user:42"} })
+		}
+		if _, err := c.GetWithOption(ctx, key, query, &v, optFns...); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.InvalidateEntity(ctx, "user", 42); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]bool{"user:42": false, "user:42:orders": false, "user:list": false, "user:42:summary": false, "user:7": true} {
+		if got, _ := repo.Get(ctx, key); (got != nil) != want {
+			t.Errorf("%s cached = %v, want %v", key, got != nil, want)
+		}
+	}
+
+	if err := c.InvalidateEntity(ctx, "order", 1); err == nil {
+		t.Error("InvalidateEntity() of unregistered entity should fail")
+	}
+	if err := c.RegisterEntity("order"); err == nil {
+		t.Error("RegisterEntity() without keys should fail")
+	}
+}
@@ -0,0 +1,143 @@
+package cacher
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// envelopeMagic 编码后的信封的前缀，用于识别信封
+var envelopeMagic = []byte("\x00cqe1")
+
+// envelopeHeader 前缀之后的固定长度部分：标志位、数据类型、写入时间、过期时间
+const envelopeHeader = 1 + 1 + 8 + 8
+
+// 编码后的信封中缓存数据的类型
+const (
+	envelopeNil    byte = 'n' //没有数据
+	envelopeString byte = 's' //字符串
+	envelopeBytes  byte = 'b' //字节切片
+	envelopeJSON   byte = 'j' //其他类型，以 JSON 保存，读取时为 JSON 字节切片
+)
+
+// Envelope 缓存数据信封，在缓存数据之外记录写入时间和过期时间，
+// 用于在多个存储库之间比较数据的新旧。以 MarshalBinary 编码为带前缀的字节切片保存，存储库是否序列化数据都可以读取
+type Envelope struct {
+	Value     interface{} //缓存数据
+	CreatedAt time.Time   //写入时间
+	ExpireAt  time.Time   //过期时间，零值表示未知
+	Deleted   bool        //墓碑，数据已在 CreatedAt 删除，阻止更早的数据被当作最新的数据
+}
+
+// newEnvelope 创建信封，写入时间为当前时间
+func newEnvelope(value interface{}, expire time.Duration) Envelope {
+	now := time.Now()
+	env := Envelope{Value: value, CreatedAt: now}
+	if expire > 0 {
+		env.ExpireAt = now.Add(expire)
+	}
+	return env
+}
+
+// newTombstone 创建墓碑，写入时间为当前时间
+func newTombstone(expire time.Duration) Envelope {
+	env := newEnvelope(nil, expire)
+	env.Deleted = true
+	return env
+}
+
+// MarshalBinary 把信封编码为以 envelopeMagic 开头的字节切片。字符串和字节切片原样保存，其他类型的数据保存为 JSON
+func (e Envelope) MarshalBinary() ([]byte, error) {
+	kind, payload := envelopeNil, []byte(nil)
+	switch v := e.Value.(type) {
+	case nil:
+	case string:
+		kind, payload = envelopeString, []byte(v)
+	case []byte:
+		kind, payload = envelopeBytes, v
+	default:
+		var err error
+		if payload, err = json.Marshal(v); err != nil {
+			return nil, err
+		}
+		kind = envelopeJSON
+	}
+	buf := make([]byte, len(envelopeMagic)+envelopeHeader, len(envelopeMagic)+envelopeHeader+len(payload))
+	header := buf[copy(buf, envelopeMagic):]
+	if e.Deleted {
+		header[0] = 1
+	}
+	header[1] = kind
+	binary.BigEndian.PutUint64(header[2:], uint64(unixNano(e.CreatedAt)))
+	binary.BigEndian.PutUint64(header[10:], uint64(unixNano(e.ExpireAt)))
+	return append(buf, payload...), nil
+}
+
+// UnmarshalBinary 解码 MarshalBinary 编码的信封。其他类型的数据解码为 JSON 字节切片
+func (e *Envelope) UnmarshalBinary(data []byte) error {
+	if !bytes.HasPrefix(data, envelopeMagic) {
+		return errors.New("不是信封数据")
+	}
+	data = data[len(envelopeMagic):]
+	if len(data) < envelopeHeader {
+		return errors.New("信封数据不完整")
+	}
+	flags, kind := data[0], data[1]
+	created := int64(binary.BigEndian.Uint64(data[2:]))
+	expire := int64(binary.BigEndian.Uint64(data[10:]))
+	payload := data[envelopeHeader:]
+	env := Envelope{Deleted: flags&1 == 1, CreatedAt: fromUnixNano(created), ExpireAt: fromUnixNano(expire)}
+	switch kind {
+	case envelopeNil:
+	case envelopeString:
+		env.Value = string(payload)
+	case envelopeBytes, envelopeJSON:
+		env.Value = append([]byte(nil), payload...)
+	default:
+		return fmt.Errorf("信封数据类型 %q 错误", kind)
+	}
+	*e = env
+	return nil
+}
+
+// openEnvelope 拆开存储库返回的信封，存储库可能原样返回字节切片或者转换为字符串。不是信封的数据返回错误
+func openEnvelope(data interface{}) (Envelope, error) {
+	var env Envelope
+	switch v := data.(type) {
+	case []byte:
+		return env, env.UnmarshalBinary(v)
+	case string:
+		return env, env.UnmarshalBinary([]byte(v))
+	}
+	return env, fmt.Errorf("不是信封数据: %T", data)
+}
+
+// unixNano 时间的纳秒时间戳，零值为0
+func unixNano(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+// fromUnixNano 纳秒时间戳对应的时间，0为零值
+func fromUnixNano(n int64) time.Time {
+	if n == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, n)
+}
+
+// ttl 剩余保留时长，未知或已过期时返回 0
+func (e Envelope) ttl() time.Duration {
+	if e.ExpireAt.IsZero() {
+		return 0
+	}
+	if ttl := time.Until(e.ExpireAt); ttl > 0 {
+		return ttl
+	}
+	return 0
+}
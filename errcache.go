@@ -0,0 +1,46 @@
+package cacher
+
+import (
+	"context"
+	"strings"
+	"time"
+)
+
+// errCacheMarker 错误缓存的标记前缀，缓存数据以该前缀开头时，表示缓存的是查询错误
+const errCacheMarker = "\x00cacher:err:"
+
+// CachedError 从缓存中读取到的查询错误。
+// 设置 Option.ErrCacheExpire 后，查询方法返回的错误会被缓存，保留期间的请求直接返回该错误
+type CachedError struct {
+	Key string //缓存键
+	Msg string //原始错误信息
+}
+
+func (e *CachedError) Error() string {
+	return "缓存的查询错误: " + e.Msg
+}
+
+// storeError 缓存查询错误
+func (c *Cacher) storeError(ctx context.Context, key string, queryErr error, expire time.Duration) error {
+	return c.repo.Set(ctx, key, errCacheMarker+queryErr.Error(), expire)
+}
+
+// cachedError 缓存数据是错误缓存时，返回缓存的错误
+func cachedError(key string, data interface{}) error {
+	var s string
+	switch v := data.(type) {
+	case string:
+		s = v
+	case []byte:
+		if len(v) < len(errCacheMarker) || string(v[:len(errCacheMarker)]) != errCacheMarker {
+			return nil
+		}
+		s = string(v)
+	default:
+		return nil
+	}
+	if !strings.HasPrefix(s, errCacheMarker) {
+		return nil
+	}
+	return &CachedError{Key: key, Msg: s[len(errCacheMarker):]}
+}
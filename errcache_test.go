@@ -0,0 +1,51 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestCacher_ErrCacheExpire(t *testing.T) {
+	ctx := context.Background()
+	for _, repo := range []cacher.Repo{newRepoMap(nil), &repoBytesStore{repoMap: newRepoMap(nil)}} {
+		c := cacher.New(repo, 10*time.Second)
+		upstreamErr := errors.New("upstream down")
+		calls := 0
+		get := func() (bool, error) {
+			var v string
+			return c.GetWithOption(ctx, "k", func() (interface{}, error) {
+				calls++
+				return nil, upstreamErr
+			}, &v, func(opt *cacher.Option) {
+				opt.ErrCacheExpire = time.Second
+			})
+		}
+
+		if _, err := get(); !errors.Is(err, upstreamErr) {
+			t.Fatalf("first Get() = %v, want %v", err, upstreamErr)
+		}
+		useCache, err := get()
+		var cachedErr *cacher.CachedError
+		if !errors.As(err, &cachedErr) || cachedErr.Msg != upstreamErr.Error() || cachedErr.Key != "k" || !useCache {
+			t.Errorf("second Get() = %v, %v, want CachedError", useCache, err)
+		}
+		if calls != 1 {
+			t.Errorf("queryFunc calls = %d, want 1", calls)
+		}
+	}
+}
+
+// repoBytesStore 以字节切片保存字符串的测试存储库
+type repoBytesStore struct {
+	*repoMap
+}
+
+func (r *repoBytesStore) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	if s, ok := value.(string); ok {
+		value = []byte(s)
+	}
+	return r.repoMap.Set(ctx, key, value, expire)
+}
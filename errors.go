@@ -0,0 +1,48 @@
+package cacher
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+var (
+	// ErrEmptyKey 缓存键为空字符串
+	ErrEmptyKey = errors.New("缓存键 key 不能为空字符串")
+	// ErrNilQueryFunc 查询方法为空
+	ErrNilQueryFunc = errors.New("查询方法不能为空")
+	// ErrNilCached 存储库返回的缓存数据是空指针，无法转换为目标类型。目标变量是没有指定类型的接口时直接赋值，不返回该错误
+	ErrNilCached = errors.New("缓存数据为空，无法转换")
+	// ErrUnsupportedConversion 缓存数据的类型与目标类型之间没有可用的转换，具体的类型见 *ConversionError
+	ErrUnsupportedConversion = errors.New("不支持的类型转换")
+	// ErrLossyConversion 数值类型转换有损（溢出、截断、整数转字符串），见 Option.LegacyConvert
+	ErrLossyConversion = errors.New("有损的类型转换")
+	// ErrInvalidTarget 目标变量 v 不是非空指针或无法赋值
+	ErrInvalidTarget = errors.New("目标变量无效")
+	// ErrInvalidConverter 注册的转换器缺少 SrcType、DstType 或 Fn
+	ErrInvalidConverter = errors.New("转换器的 SrcType、DstType、Fn 都不能为空")
+)
+
+// ConversionError 类型转换失败，可以通过 errors.As 获取类型。
+// 类型之间没有可用的转换（Err 为 nil）时，errors.Is(err, ErrUnsupportedConversion) 为 true；
+// 其他情况通过 errors.Is 判断 Err 中的原因：ErrInvalidTarget、ErrInvalidConverter、ErrLossyConversion 或转换器返回的错误
+type ConversionError struct {
+	From reflect.Type //缓存数据的类型，检查目标变量或转换器失败时可能为 nil
+	To   reflect.Type //目标类型
+	Err  error        //转换失败的原因，类型不支持转换时为 nil
+}
+
+func (e *ConversionError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("%v: %v -> %v", ErrUnsupportedConversion, e.From, e.To)
+	}
+	return fmt.Sprintf("类型转换失败: %v -> %v: %v", e.From, e.To, e.Err)
+}
+
+func (e *ConversionError) Is(target error) bool {
+	return e.Err == nil && target == ErrUnsupportedConversion
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
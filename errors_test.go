@@ -0,0 +1,132 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacher_Errors(t *testing.T) {
+	ctx := context.Background()
+	type T struct{ A int }
+	c := cacher.New(newRepoMap(map[string]interface{}{"num": 5, "big": 300, "nil": (*T)(nil), "str": "s"}), time.Minute)
+	errConv := errors.New("conv")
+	if err := c.RegisterConverter(cacher.TypeConverter{SrcType: "", DstType: T{}, Fn: func(src interface{}) (interface{}, error) {
+		return nil, errConv
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	var i8 int8
+	query := func() (interface{}, error) { return 1, nil }
+	var i int
+	var s T
+	tests := []struct {
+		name string
+		call func() error
+		want error
+	}{
+		{name: "empty key", call: func() error {
+			_, err := c.Get(ctx, "", query, &i)
+			return err
+		}, want: cacher.ErrEmptyKey},
+		{name: "nil query func", call: func() error {
+			_, err := c.Get(ctx, "a", nil, &i)
+			return err
+		}, want: cacher.ErrNilQueryFunc},
+		{name: "GetMulti nil query func", call: func() error {
+			return c.GetMulti(ctx, []string{"a"}, nil, &map[string]int{})
+		}, want: cacher.ErrNilQueryFunc},
+		{name: "Peek empty key", call: func() error {
+			_, err := c.Peek(ctx, "", &i)
+			return err
+		}, want: cacher.ErrEmptyKey},
+		{name: "nil cached", call: func() error {
+			_, err := c.Get(ctx, "nil", query, &s)
+			return err
+		}, want: cacher.ErrNilCached},
+		{name: "unsupported conversion", call: func() error {
+			_, err := c.Get(ctx, "num", query, &s)
+			return err
+		}, want: cacher.ErrUnsupportedConversion},
+		{name: "ResolveConverter", call: func() error {
+			_, err := c.ResolveConverter(T{}, 0)
+			return err
+		}, want: cacher.ErrUnsupportedConversion},
+		{name: "lossy conversion", call: func() error {
+			_, err := c.Get(ctx, "big", query, &i8)
+			return err
+		}, want: cacher.ErrLossyConversion},
+		{name: "converter error", call: func() error {
+			_, err := c.Get(ctx, "str", query, &s)
+			return err
+		}, want: errConv},
+		{name: "nil target", call: func() error {
+			_, err := c.Get(ctx, "num", query, nil)
+			return err
+		}, want: cacher.ErrInvalidTarget},
+		{name: "invalid converter", call: func() error {
+			return c.RegisterConverter(cacher.TypeConverter{SrcType: ""})
+		}, want: cacher.ErrInvalidConverter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+
+	asTests := []struct {
+		name     string
+		key      string
+		v        interface{}
+		from, to reflect.Type
+	}{
+		{name: "As unsupported conversion", key: "num", v: &s, from: reflect.TypeOf(0), to: reflect.TypeOf(T{})},
+		{name: "As lossy conversion", key: "big", v: &i8, from: reflect.TypeOf(0), to: reflect.TypeOf(int8(0))},
+		{name: "As converter error", key: "str", v: &s, from: reflect.TypeOf(""), to: reflect.TypeOf(T{})},
+	}
+	for _, tt := range asTests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.Get(ctx, tt.key, query, tt.v)
+			var convErr *cacher.ConversionError
+			if !errors.As(err, &convErr) {
+				t.Fatalf("err = %v, want *ConversionError", err)
+			}
+			if convErr.From != tt.from || convErr.To != tt.to {
+				t.Errorf("ConversionError = %v -> %v, want %v -> %v", convErr.From, convErr.To, tt.from, tt.to)
+			}
+		})
+	}
+}
+
+func TestConversionError_Is(t *testing.T) {
+	errConv := errors.New("conv")
+	sentinels := []error{cacher.ErrUnsupportedConversion, cacher.ErrInvalidTarget, cacher.ErrInvalidConverter, cacher.ErrLossyConversion, errConv}
+	from, to := reflect.TypeOf(""), reflect.TypeOf(0)
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{name: "不支持的类型转换", err: &cacher.ConversionError{From: from, To: to}, want: cacher.ErrUnsupportedConversion},
+		{name: "目标变量无效", err: &cacher.ConversionError{To: to, Err: fmt.Errorf("%w: 必须是非空指针", cacher.ErrInvalidTarget)}, want: cacher.ErrInvalidTarget},
+		{name: "转换器无效", err: &cacher.ConversionError{From: from, To: to, Err: cacher.ErrInvalidConverter}, want: cacher.ErrInvalidConverter},
+		{name: "有损的类型转换", err: &cacher.ConversionError{From: from, To: to, Err: fmt.Errorf("%w: 300", cacher.ErrLossyConversion)}, want: cacher.ErrLossyConversion},
+		{name: "转换器返回的错误", err: &cacher.ConversionError{From: from, To: to, Err: errConv}, want: errConv},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			//只匹配自己的原因，不匹配其他哨兵错误
+			for _, target := range sentinels {
+				if got := errors.Is(tt.err, target); got != (target == tt.want) {
+					t.Errorf("errors.Is(%v, %v) = %v", tt.err, target, got)
+				}
+			}
+		})
+	}
+}
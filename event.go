@@ -0,0 +1,145 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EventType 缓存事件类型
+type EventType int
+
+const (
+	EventSet      EventType = iota + 1 //写入缓存
+	EventDel                           //删除缓存
+	EventExpire                        //缓存在存储端过期
+	EventEvict                         //缓存在存储端被淘汰
+	EventError                         //不影响调用结果的内部错误，错误信息见 Event.Err
+	EventHit                           //读取时命中缓存
+	EventMiss                          //读取时未命中缓存
+	EventHerd                          //同一个缓存键在短时间内重复调用查询方法，见 Option.HerdWindow
+	EventMismatch                      //缓存数据与重新查询的数据不一致，已更正缓存，见 Option.Revalidate
+)
+
+type (
+	// Event 缓存事件
+	Event struct {
+		Type EventType //事件类型
+		Key  string    //缓存键
+		Time time.Time //事件发生时间
+		Err  error     //错误，仅 EventError 事件有值
+		Size int       //缓存数据的字节数，仅 EventHit、EventSet 事件在缓存数据为字符串或字节切片，或开启 Option.MeasureSize 时有值
+	}
+	// KeyEventSource 可选的存储库接口，存储库实现该接口后，可以把存储端的键事件（过期、淘汰等）通知给 Cacher
+	KeyEventSource interface {
+		// SubscribeKeyEvents 订阅存储端的键事件，阻塞直到 ctx 结束或订阅出错
+		SubscribeKeyEvents(ctx context.Context, fn func(Event)) error
+	}
+	// eventBus 事件监听器列表
+	eventBus struct {
+		mu        sync.RWMutex
+		seq       int
+		listeners map[int]func(Event)
+	}
+)
+
+func (t EventType) String() string {
+	switch t {
+	case EventSet:
+		return "set"
+	case EventDel:
+		return "del"
+	case EventExpire:
+		return "expire"
+	case EventEvict:
+		return "evict"
+	case EventError:
+		return "error"
+	case EventHit:
+		return "hit"
+	case EventMiss:
+		return "miss"
+	case EventHerd:
+		return "herd"
+	case EventMismatch:
+		return "mismatch"
+	}
+	return "unknown"
+}
+
+// OnEvent 注册事件监听器，返回取消监听的方法。
+// 监听器在触发事件的 goroutine 中同步调用，不应执行耗时操作
+func (c *Cacher) OnEvent(fn func(Event)) (cancel func()) {
+	return c.events.subscribe(fn)
+}
+
+// WatchKeyEvents 订阅存储库的键事件，并通过 OnEvent 注册的监听器发布。
+// 阻塞直到 ctx 结束或订阅出错；存储库未实现 KeyEventSource 时返回错误
+func (c *Cacher) WatchKeyEvents(ctx context.Context) error {
+	source, ok := c.repo.(KeyEventSource)
+	if !ok {
+		return errors.New("存储库不支持订阅键事件")
+	}
+	return source.SubscribeKeyEvents(ctx, c.emit)
+}
+
+// emit 发布事件
+func (c *Cacher) emit(ev Event) {
+	c.events.publish(ev)
+}
+
+// subscribe 注册监听器，返回取消监听的方法
+func (b *eventBus) subscribe(fn func(Event)) (cancel func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.listeners == nil {
+		b.listeners = make(map[int]func(Event))
+	}
+	b.seq++
+	id := b.seq
+	b.listeners[id] = fn
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		delete(b.listeners, id)
+	}
+}
+
+// publish 调用所有监听器
+func (b *eventBus) publish(ev Event) {
+	//复制监听器后释放锁再调用，监听器内可以取消自身或注册新的监听器
+	b.mu.RLock()
+	if len(b.listeners) == 0 {
+		b.mu.RUnlock()
+		return
+	}
+	listeners := make([]func(Event), 0, len(b.listeners))
+	for _, fn := range b.listeners {
+		listeners = append(listeners, fn)
+	}
+	b.mu.RUnlock()
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	for _, fn := range listeners {
+		fn(ev)
+	}
+}
+
+// OnExpire 注册缓存过期监听器，缓存键以 prefix 开头的缓存在存储端过期时调用 fn，返回取消监听的方法。
+// 过期事件来自存储库的键事件，需要同时调用 WatchKeyEvents 订阅。prefix 和 fn 收到的都是存储库中的缓存键，
+// 即经过 KeyPolicy 处理、加上命名空间后的缓存键，不是调用 Get 时传入的缓存键。
+// 设置了 Option.KeyHMAC 时存储库中的缓存键是 HMAC，无法按前缀匹配，返回 OptionError。
+// MemoryRepo 由后台清理发布过期事件，需要设置 WithJanitor；redis 存储库需要服务端开启键事件通知
+func (c *Cacher) OnExpire(prefix string, fn func(key string)) (cancel func(), err error) {
+	if len(c.options().KeyHMAC) > 0 {
+		return nil, &OptionError{Field: "KeyHMAC", Reason: "存储库中的缓存键是 HMAC，OnExpire 无法按前缀匹配"}
+	}
+	return c.OnEvent(func(ev Event) {
+		if ev.Type == EventExpire && strings.HasPrefix(ev.Key, prefix) {
+			fn(ev.Key)
+		}
+	}), nil
+}
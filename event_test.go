@@ -0,0 +1,180 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"sync"
+	"testing"
+	"time"
+)
+
+// repoEvents 支持订阅键事件的测试存储库
+type repoEvents struct {
+	*repoMap
+	events chan cacher.Event
+}
+
+func (r *repoEvents) SubscribeKeyEvents(ctx context.Context, fn func(cacher.Event)) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev := <-r.events:
+			fn(ev)
+		}
+	}
+}
+
+func TestCacher_OnEvent(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	var (
+		mu     sync.Mutex
+		events []cacher.Event
+	)
+	cancel := c.OnEvent(func(ev cacher.Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	})
+
+	var v string
+	if _, err := c.Get(ctx, "k", func() (interface{}, error) { return "v", nil }, &v); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+	if err := c.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(events) != 3 || events[0].Type != cacher.EventMiss || events[1].Type != cacher.EventSet || events[2].Type != cacher.EventDel || events[2].Key != "k" {
+		t.Errorf("events = %+v, want miss, set and del of k", events)
+	}
+}
+
+func TestCacher_WatchKeyEvents(t *testing.T) {
+	if err := cacher.New(newRepoMap(nil), time.Second).WatchKeyEvents(context.Background()); err == nil {
+		t.Errorf("WatchKeyEvents() want error when repo is not a KeyEventSource")
+	}
+
+	repo := &repoEvents{repoMap: newRepoMap(nil), events: make(chan cacher.Event)}
+	c := cacher.New(repo, 10*time.Second)
+	got := make(chan cacher.Event, 1)
+	c.OnEvent(func(ev cacher.Event) { got <- ev })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.WatchKeyEvents(ctx) }()
+
+	repo.events <- cacher.Event{Type: cacher.EventExpire, Key: "k"}
+	select {
+	case ev := <-got:
+		if ev.Type != cacher.EventExpire || ev.Key != "k" || ev.Time.IsZero() {
+			t.Errorf("event = %+v, want expire of k", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event not delivered")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("WatchKeyEvents() = %v, want context.Canceled", err)
+	}
+}
+
+func TestCacher_OnExpire(t *testing.T) {
+	repo := &repoEvents{repoMap: newRepoMap(nil), events: make(chan cacher.Event)}
+	c := cacher.New(repo, 10*time.Second)
+	got := make(chan string, 4)
+	if _, err := c.OnExpire("user:", func(key string) { got <- key }); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go c.WatchKeyEvents(ctx)
+
+	repo.events <- cacher.Event{Type: cacher.EventExpire, Key: "order:1"}
+	repo.events <- cacher.Event{Type: cacher.EventEvict, Key: "user:1"}
+	repo.events <- cacher.Event{Type: cacher.EventExpire, Key: "user:2"}
+	select {
+	case key := <-got:
+		if key != "user:2" {
+			t.Errorf("expired key = %v, want user:2", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expire not delivered")
+	}
+}
+
+func TestCacher_OnExpireMemoryRepo(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	repo := cacher.NewMemoryRepo(cacher.WithJanitor(5 * time.Millisecond))
+	defer repo.Close()
+	c := cacher.New(repo, 10*time.Millisecond)
+	got := make(chan string, 4)
+	if _, err := c.OnExpire("user:", func(key string) { got <- key }); err != nil {
+		t.Fatal(err)
+	}
+	go c.WatchKeyEvents(ctx)
+
+	//等待订阅生效后再写入，避免过期事件在订阅前发布
+	time.Sleep(10 * time.Millisecond)
+	for _, key := range []string{"order:1", "user:1"} {
+		var v int
+		if _, err := c.Get(ctx, key, func() (interface{}, error) { return 1, nil }, &v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case key := <-got:
+		if key != "user:1" {
+			t.Errorf("expired key = %v, want user:1", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expire not delivered")
+	}
+}
+
+func TestCacher_OnExpire_KeyHMAC(t *testing.T) {
+	//存储库中的缓存键是 HMAC，无法按前缀匹配
+	c := cacher.New(newRepoMap(nil), 10*time.Second, func(opt *cacher.Option) {
+		opt.KeyHMAC = []byte("secret")
+	})
+	if _, err := c.OnExpire("user:", func(key string) {}); !errors.Is(err, cacher.ErrInvalidOption) {
+		t.Errorf("OnExpire() = %v, want ErrInvalidOption", err)
+	}
+}
+
+func TestCacher_OnEventCancelInListener(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	var (
+		cancel func()
+		calls  int
+	)
+	cancel = c.OnEvent(func(ev cacher.Event) {
+		calls++
+		cancel()
+	})
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = c.Del(context.Background(), "k")
+		_ = c.Del(context.Background(), "k")
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("listener cancelling itself deadlocked")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %v, want 1", calls)
+	}
+}
@@ -0,0 +1,168 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+type (
+	// TTLRepo 可选的存储库接口，支持查询缓存的剩余保留时长，用于 Cacher.Explain
+	TTLRepo interface {
+		// TTL 查询剩余保留时长，缓存不存在时返回 -2，不过期时返回 -1，与 Redis 的 PTTL 一致
+		TTL(ctx context.Context, key string) (time.Duration, error)
+	}
+	// Explanation 一次读取缓存的诊断报告，见 Cacher.Explain
+	Explanation struct {
+		Key         string        //存储库中的缓存键，已加上命名空间、经过 KeyHMAC 处理
+		Hit         bool          //存储库中是否有缓存
+		CachedError bool          //缓存的是查询错误
+		ValueType   string        //缓存数据的类型，没有缓存时为空
+		Compression string        //缓存数据的压缩格式，没有压缩时为空
+		TargetType  string        //目标类型，目标是没有指定类型的 nil 接口时为空
+		Conversion  string        //转换方式：assign 直接赋值、convert 类型转换、converter 使用转换器、nil 空缓存的标记，不支持转换时为空
+		Converter   string        //使用的转换器，格式为 "源类型 -> 目标类型"
+		Codec       string        //目标类型使用的编解码器
+		TTL         time.Duration //剩余保留时长，存储库没有实现 TTLRepo 时为0，不过期时为 -1
+		Expire      time.Duration //查询后写入缓存使用的保留时长
+		Options     Option        //生效的选项
+		Err         error         //读取或转换缓存数据的错误，就是 Get 会返回的错误
+	}
+)
+
+// Explain 诊断读取缓存的过程：存储库中是否有缓存、缓存数据的类型、使用的转换方式和编解码器、剩余保留时长和生效的选项，
+// 用于排查"不支持的类型转换"等问题。只读取缓存，不调用查询方法，不写入缓存，也不修改 v，v 只用于确定目标类型。
+// 返回的错误是参数或选项错误，读取和转换缓存数据的错误记录在 Explanation.Err 中
+func (c *Cacher) Explain(ctx context.Context, key string, v interface{}, optFns ...func(opt *Option)) (*Explanation, error) {
+	if key == "" {
+		return nil, ErrEmptyKey
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return nil, fmt.Errorf("目标变量 v 必须是非空指针，实际为 %T", v)
+	}
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if err := opt.Valid(); err != nil {
+		return nil, err
+	}
+	key, err := c.buildKey(ctx, key, opt)
+	if err != nil {
+		return nil, err
+	}
+	//转换到 v 的副本，不修改 v
+	clone := reflect.New(rv.Type().Elem())
+	clone.Elem().Set(rv.Elem())
+	to, toType, finish, err := target(clone.Interface(), opt.TargetType)
+	if err != nil {
+		return nil, err
+	}
+	defer finish()
+	if opt.Expire == 0 {
+		opt.Expire = c.typeExpire(toType)
+	}
+
+	e := &Explanation{Key: key, Expire: opt.Expire, Options: opt}
+	if toType != nil {
+		e.TargetType = toType.String()
+	}
+	e.Codec = fmt.Sprintf("%T", opt.codecFor(toType))
+	if ttlRepo, ok := c.repo.(TTLRepo); ok {
+		if e.TTL, err = ttlRepo.TTL(ctx, key); err != nil {
+			e.Err = err
+			return e, nil
+		}
+	}
+
+	cacheData, err := c.repo.Get(ctx, key)
+	if err != nil {
+		e.Err = err
+		return e, nil
+	}
+	if cacheData == nil {
+		return e, nil
+	}
+	e.Hit = true
+	e.ValueType = fmt.Sprintf("%T", cacheData)
+	if err := cachedError(key, cacheData); err != nil {
+		e.CachedError = true
+		e.Err = err
+		return e, nil
+	}
+	from := reflect.ValueOf(cacheData)
+	if _, d := matchDecompressor(from); d != nil {
+		e.Compression = d.name
+	}
+	e.Conversion, e.Converter = c.explainPlan(from, toType, opt)
+	e.Err = c.assign(from, to, toType, opt)
+	return e, nil
+}
+
+// explainPlan 确定缓存数据 from 转换为目标类型的方式，与 assign 的顺序一致
+func (c *Cacher) explainPlan(from reflect.Value, toType reflect.Type, opt Option) (conversion, converter string) {
+	if isNilMarker(from) {
+		return "nil", ""
+	}
+	if !opt.KeepCompressed {
+		if decompressed, err := decompress(from); err == nil {
+			from = decompressed
+		}
+	}
+	if number, ok := decodeNumber(from, toType); ok {
+		from = number
+	}
+	if toType == nil {
+		return "assign", ""
+	}
+	fromType, _ := indirectType(from.Type())
+	plan := c.resolvePlan(from, typePair{SrcType: fromType, DstType: toType}, opt)
+	switch plan.kind {
+	case planConverter:
+		return "converter", fmt.Sprintf("%T -> %T", plan.conv.SrcType, plan.conv.DstType)
+	case planConvert:
+		return "convert", ""
+	case planCodec:
+		return "codec", ""
+	}
+	return "", ""
+}
+
+// String 以多行文本输出诊断报告，便于打印到日志
+func (e *Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "key: %s\n", e.Key)
+	fmt.Fprintf(&b, "hit: %v\n", e.Hit)
+	if e.Hit {
+		fmt.Fprintf(&b, "value type: %s\n", e.ValueType)
+	}
+	if e.CachedError {
+		b.WriteString("cached error: true\n")
+	}
+	if e.Compression != "" {
+		fmt.Fprintf(&b, "compression: %s\n", e.Compression)
+	}
+	fmt.Fprintf(&b, "target type: %s\n", e.TargetType)
+	if e.Hit && !e.CachedError {
+		conversion := e.Conversion
+		if conversion == "" {
+			conversion = "none"
+		}
+		fmt.Fprintf(&b, "conversion: %s\n", conversion)
+	}
+	if e.Converter != "" {
+		fmt.Fprintf(&b, "converter: %s\n", e.Converter)
+	}
+	fmt.Fprintf(&b, "codec: %s\n", e.Codec)
+	fmt.Fprintf(&b, "ttl: %v\n", e.TTL)
+	fmt.Fprintf(&b, "expire: %v\n", e.Expire)
+	if e.Err != nil {
+		fmt.Fprintf(&b, "error: %v\n", e.Err)
+	}
+	return b.String()
+}
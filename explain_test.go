@@ -0,0 +1,84 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacher_Explain(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, time.Minute)
+	if err := cacher.RegisterType[person](c); err != nil {
+		t.Fatal(err)
+	}
+	_ = repo.Set(ctx, "int", "12", time.Minute)
+	_ = repo.Set(ctx, "person", `{"Name":"tom","Age":18}`, 0)
+	_ = repo.Set(ctx, "bad", []int{1}, time.Minute)
+	_, _ = c.GetWithOption(ctx, "err", func() (interface{}, error) {
+		return nil, errors.New("down")
+	}, new(string), func(opt *cacher.Option) {
+		opt.ErrCacheExpire = time.Minute
+	})
+	_, _ = c.GetWithOption(ctx, "nil", func() (interface{}, error) {
+		return nil, nil
+	}, new(int), cacher.WithNilCache(time.Minute, nil))
+
+	var i int
+	var p person
+	tests := []struct {
+		name           string
+		key            string
+		v              interface{}
+		wantHit        bool
+		wantConversion string
+		wantTTL        func(ttl time.Duration) bool
+		wantErr        bool
+		wantCachedErr  bool
+	}{
+		{name: "没有缓存", key: "missing", v: &i, wantTTL: func(ttl time.Duration) bool { return ttl == -2 }},
+		{name: "类型转换", key: "int", v: &i, wantHit: true, wantConversion: "converter", wantTTL: func(ttl time.Duration) bool { return ttl > 0 && ttl <= time.Minute }},
+		{name: "注册的转换器", key: "person", v: &p, wantHit: true, wantConversion: "converter", wantTTL: func(ttl time.Duration) bool { return ttl == -1 }},
+		{name: "不支持的类型转换", key: "bad", v: &i, wantHit: true, wantErr: true},
+		{name: "缓存的查询错误", key: "err", v: &i, wantHit: true, wantErr: true, wantCachedErr: true},
+		{name: "空缓存", key: "nil", v: &i, wantHit: true, wantConversion: "nil", wantTTL: func(ttl time.Duration) bool { return ttl > 0 }},
+	}
+	for _, tt := range tests {
+		e, err := c.Explain(ctx, tt.key, tt.v)
+		if err != nil {
+			t.Errorf("%s: Explain() = %v", tt.name, err)
+			continue
+		}
+		if e.Hit != tt.wantHit || e.Conversion != tt.wantConversion || e.CachedError != tt.wantCachedErr || (e.Err != nil) != tt.wantErr {
+			t.Errorf("%s: Explain() = %+v", tt.name, e)
+		}
+		if tt.wantTTL != nil && !tt.wantTTL(e.TTL) {
+			t.Errorf("%s: TTL = %v", tt.name, e.TTL)
+		}
+		if !strings.Contains(e.String(), "key: "+tt.key) {
+			t.Errorf("%s: String() = %q", tt.name, e.String())
+		}
+	}
+	if i != 0 || p != (person{}) {
+		t.Errorf("Explain() must not modify v: %v, %+v", i, p)
+	}
+
+	//生效的选项和保留时长
+	e, err := c.Explain(ctx, "int", &i, func(opt *cacher.Option) {
+		opt.Expire = time.Hour
+		opt.Namespace = "ns"
+	})
+	if err != nil || e.Expire != time.Hour || e.Options.Namespace != "ns" || e.Key == "int" || e.Hit {
+		t.Errorf("Explain() with options = %+v, %v", e, err)
+	}
+	if _, err := c.Explain(ctx, "", &i); err == nil {
+		t.Errorf("Explain() with empty key want error")
+	}
+	if _, err := c.Explain(ctx, "int", i); err == nil {
+		t.Errorf("Explain() with non-pointer want error")
+	}
+}
@@ -0,0 +1,47 @@
+package cacher
+
+import "context"
+
+// FlagCache 配置类缓存门面，适合热点路径上的开关、阈值等配置查询。
+// 缓存不存在、查询缓存错误或类型转换失败时，均返回调用方传入的默认值，不向调用方返回错误
+type FlagCache struct {
+	c *Cacher
+}
+
+// NewFlagCache 基于 Cacher 创建配置类缓存门面
+func NewFlagCache(c *Cacher) *FlagCache {
+	return &FlagCache{c: c}
+}
+
+// Bool 获取布尔类型配置，失败时返回 def
+func (f *FlagCache) Bool(ctx context.Context, key string, def bool) bool {
+	v := def
+	if !f.lookup(ctx, key, &v) {
+		return def
+	}
+	return v
+}
+
+// Int 获取整数类型配置，失败时返回 def
+func (f *FlagCache) Int(ctx context.Context, key string, def int) int {
+	v := def
+	if !f.lookup(ctx, key, &v) {
+		return def
+	}
+	return v
+}
+
+// String 获取字符串类型配置，失败时返回 def
+func (f *FlagCache) String(ctx context.Context, key string, def string) string {
+	v := def
+	if !f.lookup(ctx, key, &v) {
+		return def
+	}
+	return v
+}
+
+// lookup 只读取缓存，不调用查询方法。返回值：是否成功读取并转换
+func (f *FlagCache) lookup(ctx context.Context, key string, v interface{}) bool {
+	ok, err := f.c.Peek(ctx, key, v)
+	return ok && err == nil
+}
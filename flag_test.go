@@ -0,0 +1,47 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestFlagCache(t *testing.T) {
+	repo := newRepoMap(map[string]interface{}{
+		"bool":       "true",
+		"int":        []byte("12"),
+		"string":     "on",
+		"bad-int":    "abc",
+		"native-int": 7,
+	})
+	flags := cacher.NewFlagCache(cacher.New(repo, 10*time.Second))
+	ctx := context.Background()
+
+	if got := flags.Bool(ctx, "bool", false); got != true {
+		t.Errorf("Bool() = %v, want true", got)
+	}
+	if got := flags.Int(ctx, "int", 0); got != 12 {
+		t.Errorf("Int() = %v, want 12", got)
+	}
+	if got := flags.Int(ctx, "native-int", 0); got != 7 {
+		t.Errorf("Int() = %v, want 7", got)
+	}
+	if got := flags.String(ctx, "string", "off"); got != "on" {
+		t.Errorf("String() = %v, want on", got)
+	}
+	//缓存不存在
+	if got := flags.Bool(ctx, "missing", true); got != true {
+		t.Errorf("Bool() = %v, want default true", got)
+	}
+	//类型转换失败
+	if got := flags.Int(ctx, "bad-int", 3); got != 3 {
+		t.Errorf("Int() = %v, want default 3", got)
+	}
+	//查询缓存错误
+	repo.getErr = errors.New("repo down")
+	if got := flags.String(ctx, "string", "off"); got != "off" {
+		t.Errorf("String() = %v, want default off", got)
+	}
+}
@@ -0,0 +1,61 @@
+package cacher
+
+import (
+	"sync"
+	"time"
+)
+
+// flightCache 进程内短时缓存的查询结果（微缓存）。
+// 查询完成后、写入存储库的数据可见之前到达的相同请求，直接使用查询结果，不再查询
+type flightCache struct {
+	mu      sync.Mutex
+	entries map[string]flightEntry
+}
+
+type flightEntry struct {
+	val      interface{}
+	expireAt time.Time
+}
+
+// get 获取未过期的查询结果
+func (f *flightCache) get(key string) (interface{}, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	entry, ok := f.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(entry.expireAt) {
+		delete(f.entries, key)
+		return nil, false
+	}
+	return entry.val, true
+}
+
+// set 保存查询结果，保留 expire 时长
+func (f *flightCache) set(key string, val interface{}, expire time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.entries == nil {
+		f.entries = make(map[string]flightEntry)
+	}
+	now := time.Now()
+	//条目较多时顺带清理过期条目，避免无限增长
+	if len(f.entries) >= 1024 {
+		for k, entry := range f.entries {
+			if now.After(entry.expireAt) {
+				delete(f.entries, k)
+			}
+		}
+	}
+	f.entries[key] = flightEntry{val: val, expireAt: now.Add(expire)}
+}
+
+// del 删除查询结果
+func (f *flightCache) del(keys ...string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, key := range keys {
+		delete(f.entries, key)
+	}
+}
@@ -0,0 +1,55 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+// repoNoWrite 写入不可见的测试存储库，模拟异步写入
+type repoNoWrite struct {
+	*repoMap
+}
+
+func (r *repoNoWrite) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	return nil
+}
+
+func TestOption_FlightCache(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(&repoNoWrite{repoMap: newRepoMap(nil)}, 10*time.Second)
+	calls := 0
+	get := func() {
+		var v string
+		_, err := c.GetWithOption(ctx, "k", func() (interface{}, error) {
+			calls++
+			return "v", nil
+		}, &v, func(opt *cacher.Option) {
+			opt.FlightCache = 50 * time.Millisecond
+		})
+		if err != nil || v != "v" {
+			t.Fatalf("Get() = %v, %v", v, err)
+		}
+	}
+
+	get()
+	get()
+	if calls != 1 {
+		t.Errorf("queryFunc calls = %d, want 1", calls)
+	}
+	//删除缓存时，同时删除进程内的查询结果
+	if err := c.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	get()
+	if calls != 2 {
+		t.Errorf("queryFunc calls after Del = %d, want 2", calls)
+	}
+	//过期后重新查询
+	time.Sleep(60 * time.Millisecond)
+	get()
+	if calls != 3 {
+		t.Errorf("queryFunc calls after expire = %d, want 3", calls)
+	}
+}
@@ -0,0 +1,177 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrHashUnsupported 存储库未实现 HashRepo
+var ErrHashUnsupported = errors.New("存储库不支持哈希表，需要实现 HashRepo 接口")
+
+// HashRepo 可选的存储库接口，以哈希表保存结构体，可以单独读写字段
+type HashRepo interface {
+	// HSet 设置哈希表字段，expire 大于0时同时设置哈希表的保留时长
+	HSet(ctx context.Context, key string, fields map[string]string, expire time.Duration) error
+	// HGet 获取哈希表字段，不存在的字段不包含在返回值中
+	HGet(ctx context.Context, key string, fields ...string) (map[string]string, error)
+	// HGetAll 获取哈希表的所有字段，哈希表不存在时返回空 map
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+}
+
+// Hash 哈希表门面，结构体字段对应哈希表字段，字段名使用 `cache:"name"` 标签指定，
+// 没有标签时使用字段名，标签为 "-" 时忽略该字段。
+// 字符串、字节切片、布尔、数值类型的字段保存为文本，其他类型使用默认编解码器
+type Hash struct {
+	c    *Cacher
+	key  string
+	repo HashRepo
+	err  error //缓存键不符合 Option.KeyPolicy 时的错误
+}
+
+// Hash 获取缓存键 key 对应的哈希表，存储库未实现 HashRepo 时，各方法返回 ErrHashUnsupported
+func (c *Cacher) Hash(key string) *Hash {
+	repo, _ := c.repo.(HashRepo)
+	key, err := repoKey(key, c.options())
+	return &Hash{c: c, key: key, repo: repo, err: err}
+}
+
+// valid 缓存键不符合 Option.KeyPolicy 时返回 *KeyError，存储库未实现 HashRepo 时返回 ErrHashUnsupported
+func (h *Hash) valid() error {
+	if h.err != nil {
+		return h.err
+	}
+	if h.repo == nil {
+		return ErrHashUnsupported
+	}
+	return nil
+}
+
+// Set 以结构体 v 的所有字段设置哈希表，expire 等于0时使用 Cacher 的默认保留时长
+func (h *Hash) Set(ctx context.Context, v interface{}, expire time.Duration) error {
+	if err := h.valid(); err != nil {
+		return err
+	}
+	rv := indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("哈希表数据必须是结构体，实际为 %T", v)
+	}
+	fields := make(map[string]string)
+	for _, f := range hashFieldsOf(rv.Type()) {
+		val, err := h.c.encodeText(rv.Field(f.index))
+		if err != nil {
+			return fmt.Errorf("字段 %s: %w", f.name, err)
+		}
+		fields[f.name] = val
+	}
+	if expire == 0 {
+		expire = h.c.defaultExpire()
+	}
+	return h.repo.HSet(ctx, h.key, fields, expire)
+}
+
+// SetField 设置单个字段，不改变哈希表的保留时长
+func (h *Hash) SetField(ctx context.Context, field string, value interface{}) error {
+	if err := h.valid(); err != nil {
+		return err
+	}
+	val, err := h.c.encodeText(reflect.ValueOf(value))
+	if err != nil {
+		return err
+	}
+	return h.repo.HSet(ctx, h.key, map[string]string{field: val}, 0)
+}
+
+// Get 读取哈希表的所有字段到结构体指针 v。返回值：哈希表是否存在
+func (h *Hash) Get(ctx context.Context, v interface{}) (bool, error) {
+	if err := h.valid(); err != nil {
+		return false, err
+	}
+	to, _, finish, err := target(v, nil)
+	if err != nil {
+		return false, err
+	}
+	defer finish()
+	if to.Kind() != reflect.Struct {
+		return false, fmt.Errorf("目标变量 v 必须指向结构体，实际为 %T", v)
+	}
+	fields, err := h.repo.HGetAll(ctx, h.key)
+	if err != nil || len(fields) == 0 {
+		return false, err
+	}
+	for _, f := range hashFieldsOf(to.Type()) {
+		val, ok := fields[f.name]
+		if !ok {
+			continue
+		}
+		if err := h.c.decodeText(val, to.Field(f.index)); err != nil {
+			return true, fmt.Errorf("字段 %s: %w", f.name, err)
+		}
+	}
+	return true, nil
+}
+
+// GetField 读取单个字段到指针 v。返回值：字段是否存在
+func (h *Hash) GetField(ctx context.Context, field string, v interface{}) (bool, error) {
+	if err := h.valid(); err != nil {
+		return false, err
+	}
+	to, _, finish, err := target(v, nil)
+	if err != nil {
+		return false, err
+	}
+	defer finish()
+	fields, err := h.repo.HGet(ctx, h.key, field)
+	if err != nil {
+		return false, err
+	}
+	val, ok := fields[field]
+	if !ok {
+		return false, nil
+	}
+	return true, h.c.decodeText(val, to)
+}
+
+// Del 删除哈希表
+func (h *Hash) Del(ctx context.Context) error {
+	if h.err != nil {
+		return h.err
+	}
+	return h.c.del(ctx, h.key)
+}
+
+// hashField 结构体字段与哈希表字段的对应关系
+type hashField struct {
+	index int
+	name  string
+}
+
+// hashFields 结构体类型的字段对应关系缓存
+var hashFields sync.Map
+
+// hashFieldsOf 解析结构体类型的字段对应关系，忽略未导出的字段
+func hashFieldsOf(t reflect.Type) []hashField {
+	if fields, ok := hashFields.Load(t); ok {
+		return fields.([]hashField)
+	}
+	var fields []hashField
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name := sf.Tag.Get("cache")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		fields = append(fields, hashField{index: i, name: name})
+	}
+	hashFields.Store(t, fields)
+	return fields
+}
@@ -0,0 +1,129 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// repoHash 支持哈希表的测试存储库
+type repoHash struct {
+	*repoMap
+	hashes  map[string]map[string]string
+	expires map[string]time.Duration
+}
+
+func newRepoHash() *repoHash {
+	return &repoHash{repoMap: newRepoMap(nil), hashes: make(map[string]map[string]string), expires: make(map[string]time.Duration)}
+}
+
+func (r *repoHash) HSet(ctx context.Context, key string, fields map[string]string, expire time.Duration) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.hashes[key] == nil {
+		r.hashes[key] = make(map[string]string)
+	}
+	for f, v := range fields {
+		r.hashes[key][f] = v
+	}
+	if expire > 0 {
+		r.expires[key] = expire
+	}
+	return nil
+}
+
+func (r *repoHash) HGet(ctx context.Context, key string, fields ...string) (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := make(map[string]string)
+	for _, f := range fields {
+		if v, ok := r.hashes[key][f]; ok {
+			m[f] = v
+		}
+	}
+	return m, nil
+}
+
+func (r *repoHash) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	m := make(map[string]string)
+	for f, v := range r.hashes[key] {
+		m[f] = v
+	}
+	return m, nil
+}
+
+func (r *repoHash) Del(ctx context.Context, keys ...string) error {
+	r.mu.Lock()
+	for _, key := range keys {
+		delete(r.hashes, key)
+	}
+	r.mu.Unlock()
+	return r.repoMap.Del(ctx, keys...)
+}
+
+type profile struct {
+	Name    string   `cache:"name"`
+	Age     int      `cache:"age"`
+	Score   float64  `cache:"score"`
+	VIP     bool     `cache:"vip"`
+	Tags    []string `cache:"tags"`
+	Avatar  []byte
+	Nick    *string `cache:"nick"`
+	Ignored string  `cache:"-"`
+	private string
+}
+
+func TestHash(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoHash()
+	c := cacher.New(repo, 10*time.Second)
+	h := c.Hash("profile:1")
+	nick := "tommy"
+	p := profile{Name: "tom", Age: 18, Score: 9.5, VIP: true, Tags: []string{"a", "b"}, Avatar: []byte("png"), Nick: &nick, Ignored: "x", private: "y"}
+	if err := h.Set(ctx, &p, 0); err != nil {
+		t.Fatal(err)
+	}
+	wantFields := map[string]string{"name": "tom", "age": "18", "score": "9.5", "vip": "true", "tags": `["a","b"]`, "Avatar": "png", "nick": "tommy"}
+	if !reflect.DeepEqual(repo.hashes["profile:1"], wantFields) || repo.expires["profile:1"] != 10*time.Second {
+		t.Fatalf("hash = %v, expire = %v", repo.hashes["profile:1"], repo.expires["profile:1"])
+	}
+
+	if err := h.SetField(ctx, "age", 19); err != nil {
+		t.Fatal(err)
+	}
+	var age int
+	if ok, err := h.GetField(ctx, "age", &age); !ok || err != nil || age != 19 {
+		t.Errorf("GetField(age) = %v, %v, age = %v", ok, err, age)
+	}
+	if ok, err := h.GetField(ctx, "missing", &age); ok || err != nil {
+		t.Errorf("GetField(missing) = %v, %v", ok, err)
+	}
+
+	var got profile
+	if ok, err := h.Get(ctx, &got); !ok || err != nil {
+		t.Fatalf("Get() = %v, %v", ok, err)
+	}
+	p.Age, p.Ignored, p.private = 19, "", ""
+	if !reflect.DeepEqual(got, p) {
+		t.Errorf("Get() = %+v, want %+v", got, p)
+	}
+
+	if err := h.Del(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := h.Get(ctx, &got); ok || err != nil {
+		t.Errorf("Get() after Del = %v, %v", ok, err)
+	}
+}
+
+func TestHash_Unsupported(t *testing.T) {
+	h := cacher.New(newRepoMap(nil), 10*time.Second).Hash("k")
+	if err := h.SetField(context.Background(), "f", 1); !errors.Is(err, cacher.ErrHashUnsupported) {
+		t.Errorf("SetField() error = %v, want ErrHashUnsupported", err)
+	}
+}
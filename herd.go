@@ -0,0 +1,35 @@
+package cacher
+
+import (
+	"sync"
+	"time"
+)
+
+// herdDetector 检测同一个缓存键在短时间内重复调用查询方法。
+// 平滑查询正常工作时，缓存写入后的保留时长内不应该再次查询；重复查询说明缓存没有写入成功、
+// 保留时长过短、缓存被频繁删除，或多个实例同时查询
+type herdDetector struct {
+	mu   sync.Mutex
+	last map[string]time.Time
+}
+
+// observe 记录一次查询，返回距离上一次查询是否不足 window
+func (h *herdDetector) observe(key string, window time.Duration) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	now := time.Now()
+	if h.last == nil {
+		h.last = make(map[string]time.Time)
+	}
+	last, ok := h.last[key]
+	//条目较多时顺带清理过期条目，避免无限增长
+	if len(h.last) >= 1024 {
+		for k, t := range h.last {
+			if now.Sub(t) >= window {
+				delete(h.last, k)
+			}
+		}
+	}
+	h.last[key] = now
+	return ok && now.Sub(last) < window
+}
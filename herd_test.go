@@ -0,0 +1,44 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_HerdWindow(t *testing.T) {
+	tests := []struct {
+		name      string
+		window    time.Duration
+		wantHerds uint64
+	}{
+		{name: "不检测", window: 0, wantHerds: 0},
+		{name: "缓存未写入，重复查询", window: time.Minute, wantHerds: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := cacher.New(newRepoMap(nil), 10*time.Second, func(opt *cacher.Option) {
+				opt.HerdWindow = tt.window
+				//不写入缓存，模拟缓存写入失败
+				opt.ShouldCache = func(v interface{}) bool { return false }
+			})
+			var herds []string
+			c.OnEvent(func(ev cacher.Event) {
+				if ev.Type == cacher.EventHerd {
+					herds = append(herds, ev.Key)
+				}
+			})
+			var v string
+			for i := 0; i < 3; i++ {
+				if _, err := c.Get(ctx, "k", func() (interface{}, error) { return "v", nil }, &v); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := c.DebugState().Herds; got != tt.wantHerds || uint64(len(herds)) != tt.wantHerds {
+				t.Errorf("herds = %d, events = %v, want %d", got, herds, tt.wantHerds)
+			}
+		})
+	}
+}
@@ -0,0 +1,56 @@
+package cacher
+
+import (
+	"math"
+	"math/bits"
+)
+
+// hllPrecision HyperLogLog 的精度，寄存器数量为 2^hllPrecision，标准误差约为 1.04/sqrt(2^hllPrecision)，即 3.25%
+const hllPrecision = 10
+
+// hyperLogLog 基数估计
+type hyperLogLog struct {
+	reg [1 << hllPrecision]uint8
+}
+
+// add 添加一个哈希值
+func (h *hyperLogLog) add(hash uint64) {
+	idx := hash >> (64 - hllPrecision)
+	rank := uint8(bits.LeadingZeros64(hash<<hllPrecision|1<<(hllPrecision-1))) + 1
+	if rank > h.reg[idx] {
+		h.reg[idx] = rank
+	}
+}
+
+// merge 合并另一个估计，结果为两个集合并集的估计
+func (h *hyperLogLog) merge(other *hyperLogLog) {
+	for i, r := range other.reg {
+		if r > h.reg[i] {
+			h.reg[i] = r
+		}
+	}
+}
+
+// reset 清空
+func (h *hyperLogLog) reset() {
+	h.reg = [1 << hllPrecision]uint8{}
+}
+
+// estimate 估计不同元素的数量
+func (h *hyperLogLog) estimate() uint64 {
+	const m = float64(1 << hllPrecision)
+	sum, zeros := 0.0, 0
+	for _, r := range h.reg {
+		sum += math.Ldexp(1, -int(r))
+		if r == 0 {
+			zeros++
+		}
+	}
+	alpha := 0.7213 / (1 + 1.079/m)
+	e := alpha * m * m / sum
+	if e <= 2.5*m && zeros > 0 {
+		//基数较小时使用线性计数
+		e = m * math.Log(m/float64(zeros))
+	}
+	return uint64(e + 0.5)
+}
@@ -0,0 +1,71 @@
+package cacher
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// debugTopWaiting DebugState.Waiting 列出的缓存键数量
+const debugTopWaiting = 10
+
+type (
+	// KeyWaiting 等待同一个缓存键查询结果的 goroutine 数量
+	KeyWaiting struct {
+		Key     string `json:"key"`     //存储库中的缓存键
+		Waiters int    `json:"waiters"` //等待查询结果的 goroutine 数量，不包括执行查询的 goroutine
+	}
+	// keyWaiters 每个缓存键正在平滑查询中的 goroutine 数量，包括执行查询的 goroutine
+	keyWaiters struct {
+		mu     sync.Mutex
+		counts map[string]int
+	}
+)
+
+// enter 进入缓存键 key 的平滑查询，返回离开的方法
+func (w *keyWaiters) enter(key string) func() {
+	w.mu.Lock()
+	if w.counts == nil {
+		w.counts = make(map[string]int)
+	}
+	w.counts[key]++
+	w.mu.Unlock()
+	return func() {
+		w.mu.Lock()
+		if w.counts[key]--; w.counts[key] <= 0 {
+			delete(w.counts, key)
+		}
+		w.mu.Unlock()
+	}
+}
+
+// top 等待的 goroutine 最多的 n 个缓存键，按等待数量降序，没有等待的缓存键不列出
+func (w *keyWaiters) top(n int) []KeyWaiting {
+	w.mu.Lock()
+	var waiting []KeyWaiting
+	for key, count := range w.counts {
+		if count > 1 {
+			waiting = append(waiting, KeyWaiting{Key: key, Waiters: count - 1})
+		}
+	}
+	w.mu.Unlock()
+	sort.Slice(waiting, func(i, j int) bool {
+		if waiting[i].Waiters != waiting[j].Waiters {
+			return waiting[i].Waiters > waiting[j].Waiters
+		}
+		return waiting[i].Key < waiting[j].Key
+	})
+	if len(waiting) > n {
+		waiting = waiting[:n]
+	}
+	return waiting
+}
+
+// goBackground 在后台执行 fn，执行期间计入 DebugState.Background
+func (c *Cacher) goBackground(fn func()) {
+	atomic.AddInt64(&c.stats.background, 1)
+	go func() {
+		defer atomic.AddInt64(&c.stats.background, -1)
+		fn()
+	}()
+}
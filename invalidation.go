@@ -0,0 +1,123 @@
+package cacher
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+type (
+	// InvalidationBus 跨实例的缓存失效消息总线。
+	// 一个实例删除缓存后，通过总线通知其他实例删除各自的本地缓存
+	InvalidationBus interface {
+		// Publish 发布失效消息
+		Publish(ctx context.Context, keys ...string) error
+		// Subscribe 订阅失效消息，阻塞直到 ctx 结束或订阅出错
+		Subscribe(ctx context.Context, fn func(keys []string)) error
+	}
+	// LocalBus 进程内的失效消息总线，用于单进程多 Cacher 或测试
+	LocalBus struct {
+		mu   sync.RWMutex
+		seq  int
+		subs map[int]*localSub
+	}
+	// localSub LocalBus 的订阅者
+	localSub struct {
+		ch   chan []string
+		done chan struct{}
+	}
+	// invalidationMsg 失效消息的传输格式
+	invalidationMsg struct {
+		Keys []string `json:"keys"`
+	}
+)
+
+var _ InvalidationBus = (*LocalBus)(nil)
+
+// SetInvalidationBus 设置失效消息总线，需要在使用 Cacher 之前设置。
+// 设置后，Del 删除缓存时会发布失效消息
+func (c *Cacher) SetInvalidationBus(bus InvalidationBus) {
+	c.bus = bus
+}
+
+// ListenInvalidation 订阅失效消息，收到消息后删除存储库中对应的缓存。
+// 阻塞直到 ctx 结束或订阅出错
+func (c *Cacher) ListenInvalidation(ctx context.Context) error {
+	if c.bus == nil {
+		return errors.New("没有设置失效消息总线")
+	}
+	return c.bus.Subscribe(ctx, func(keys []string) {
+		if len(keys) == 0 {
+			return
+		}
+		if err := c.repo.Del(ctx, keys...); err != nil {
+			return
+		}
+		for _, key := range keys {
+			c.emit(Event{Type: EventDel, Key: key})
+		}
+	})
+}
+
+// MarshalInvalidation 编码失效消息，各总线实现使用相同的格式传输
+func MarshalInvalidation(keys []string) ([]byte, error) {
+	return json.Marshal(invalidationMsg{Keys: keys})
+}
+
+// UnmarshalInvalidation 解码失效消息
+func UnmarshalInvalidation(data []byte) ([]string, error) {
+	var msg invalidationMsg
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return nil, err
+	}
+	return msg.Keys, nil
+}
+
+// NewLocalBus 创建进程内的失效消息总线
+func NewLocalBus() *LocalBus {
+	return &LocalBus{subs: make(map[int]*localSub)}
+}
+
+// Publish 把失效消息发送给所有订阅者
+func (b *LocalBus) Publish(ctx context.Context, keys ...string) error {
+	b.mu.RLock()
+	subs := make([]*localSub, 0, len(b.subs))
+	for _, sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.RUnlock()
+	for _, sub := range subs {
+		select {
+		case sub.ch <- keys:
+		case <-sub.done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribe 订阅失效消息，阻塞直到 ctx 结束
+func (b *LocalBus) Subscribe(ctx context.Context, fn func(keys []string)) error {
+	sub := &localSub{ch: make(chan []string, 16), done: make(chan struct{})}
+	b.mu.Lock()
+	b.seq++
+	id := b.seq
+	b.subs[id] = sub
+	b.mu.Unlock()
+	defer func() {
+		b.mu.Lock()
+		delete(b.subs, id)
+		b.mu.Unlock()
+		close(sub.done)
+	}()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case keys := <-sub.ch:
+			fn(keys)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacher_ListenInvalidation(t *testing.T) {
+	bus := cacher.NewLocalBus()
+	repoA, repoB := newRepoMap(nil), newRepoMap(map[string]interface{}{"k": "v"})
+	a, b := cacher.New(repoA, 10*time.Second), cacher.New(repoB, 10*time.Second)
+	a.SetInvalidationBus(bus)
+	b.SetInvalidationBus(bus)
+
+	deleted := make(chan string, 1)
+	b.OnEvent(func(ev cacher.Event) {
+		if ev.Type == cacher.EventDel {
+			deleted <- ev.Key
+		}
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.ListenInvalidation(ctx)
+	//等待订阅生效
+	time.Sleep(10 * time.Millisecond)
+
+	if err := a.Del(context.Background(), "k"); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case key := <-deleted:
+		if key != "k" {
+			t.Errorf("deleted key = %v, want k", key)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("invalidation not delivered")
+	}
+	if val, _ := repoB.Get(context.Background(), "k"); val != nil {
+		t.Errorf("repo b still has k = %v", val)
+	}
+}
+
+func TestInvalidationCodec(t *testing.T) {
+	data, err := cacher.MarshalInvalidation([]string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys, err := cacher.UnmarshalInvalidation(data)
+	if err != nil || !reflect.DeepEqual(keys, []string{"a", "b"}) {
+		t.Errorf("UnmarshalInvalidation() = %v, %v", keys, err)
+	}
+}
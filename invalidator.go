@@ -0,0 +1,135 @@
+package cacher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+type (
+	// Invalidator 外部缓存失效钩子，显式写入或删除缓存后调用，用于同步失效 CDN 等边缘缓存
+	Invalidator interface {
+		// Invalidate 失效外部缓存。tags 为写入缓存时 Option.Tags 的值，删除缓存时为空
+		Invalidate(ctx context.Context, keys []string, tags []string) error
+	}
+	// InvalidatorFunc 函数形式的 Invalidator
+	InvalidatorFunc func(ctx context.Context, keys []string, tags []string) error
+	// PurgeInvalidator 通过 HTTP PURGE 请求失效 Varnish、Fastly 等 CDN 中缓存键对应的 URL
+	PurgeInvalidator struct {
+		Client *http.Client            //为空时使用 http.DefaultClient
+		URL    func(key string) string //缓存键对应的 URL，返回空字符串时跳过
+	}
+	// CloudflareInvalidator 通过 Cloudflare 清除缓存 API 失效缓存键对应的 URL 和缓存标签
+	CloudflareInvalidator struct {
+		Client   *http.Client            //为空时使用 http.DefaultClient
+		Endpoint string                  //API 地址，为空时根据 ZoneID 生成
+		ZoneID   string                  //区域 ID
+		Token    string                  //API 令牌
+		URL      func(key string) string //缓存键对应的 URL，为空或返回空字符串时只按标签清除
+	}
+)
+
+var (
+	_ Invalidator = InvalidatorFunc(nil)
+	_ Invalidator = (*PurgeInvalidator)(nil)
+	_ Invalidator = (*CloudflareInvalidator)(nil)
+)
+
+// AddInvalidator 添加外部缓存失效钩子，需要在使用 Cacher 之前添加。
+// 钩子在 Write、PrepareSet 提交、Option.Revalidate 更正缓存等显式写入和删除缓存后调用，读取时未命中后回填缓存不调用。
+// 写入缓存时钩子的错误通过 EventError 事件发布，不影响写入结果；删除缓存时钩子的错误由 Del 返回
+func (c *Cacher) AddInvalidator(inv Invalidator) {
+	c.invalidators = append(c.invalidators, inv)
+}
+
+// invalidate 调用所有外部缓存失效钩子
+func (c *Cacher) invalidate(ctx context.Context, keys []string, tags []string) error {
+	for _, inv := range c.invalidators {
+		if err := inv.Invalidate(ctx, keys, tags); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// invalidateWritten 显式写入缓存后调用所有外部缓存失效钩子，错误通过 EventError 事件发布。
+// 读取时未命中后回填的缓存不调用，避免读取路径同步等待外部请求
+func (c *Cacher) invalidateWritten(ctx context.Context, key string, opt Option) {
+	if err := c.invalidate(ctx, []string{key}, opt.Tags); err != nil {
+		c.emitError("invalidate", key, err)
+	}
+}
+
+// Invalidate 实现 Invalidator
+func (f InvalidatorFunc) Invalidate(ctx context.Context, keys []string, tags []string) error {
+	return f(ctx, keys, tags)
+}
+
+// Invalidate 为每个缓存键发送 PURGE 请求
+func (p *PurgeInvalidator) Invalidate(ctx context.Context, keys []string, _ []string) error {
+	for _, key := range keys {
+		url := p.URL(key)
+		if url == "" {
+			continue
+		}
+		req, err := http.NewRequestWithContext(ctx, "PURGE", url, nil)
+		if err != nil {
+			return err
+		}
+		if err := doPurge(p.Client, req); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Invalidate 按 URL 和缓存标签清除 Cloudflare 缓存
+func (p *CloudflareInvalidator) Invalidate(ctx context.Context, keys []string, tags []string) error {
+	body := struct {
+		Files []string `json:"files,omitempty"`
+		Tags  []string `json:"tags,omitempty"`
+	}{Tags: tags}
+	if p.URL != nil {
+		for _, key := range keys {
+			if url := p.URL(key); url != "" {
+				body.Files = append(body.Files, url)
+			}
+		}
+	}
+	if len(body.Files) == 0 && len(body.Tags) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	endpoint := p.Endpoint
+	if endpoint == "" {
+		endpoint = "https://api.cloudflare.com/client/v4/zones/" + p.ZoneID + "/purge_cache"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.Token)
+	return doPurge(p.Client, req)
+}
+
+// doPurge 发送清除请求，非 2xx 响应视为失败
+func doPurge(client *http.Client, req *http.Request) error {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("清除 %s 失败，状态码 %d", req.URL, resp.StatusCode)
+	}
+	return nil
+}
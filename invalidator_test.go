@@ -0,0 +1,136 @@
+package cacher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/carteruu/cacher"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacher_AddInvalidator(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	type call struct {
+		keys []string
+		tags []string
+	}
+	var calls []call
+	c.AddInvalidator(cacher.InvalidatorFunc(func(ctx context.Context, keys []string, tags []string) error {
+		calls = append(calls, call{keys: keys, tags: tags})
+		return nil
+	}))
+
+	//读取时未命中后回填缓存，不调用钩子
+	var v string
+	if _, err := c.GetWithOption(ctx, "k", func() (interface{}, error) { return "v", nil }, &v, func(opt *cacher.Option) {
+		opt.Tags = []string{"t1"}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if len(calls) != 0 {
+		t.Fatalf("calls = %+v, want none after read-path fill", calls)
+	}
+	if err := c.Write(ctx, "k", "v2", func(ctx context.Context) error { return nil }, func(opt *cacher.Option) {
+		opt.WriteMode = cacher.WriteThrough
+		opt.Tags = []string{"t1"}
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+	want := []call{{keys: []string{"k"}, tags: []string{"t1"}}, {keys: []string{"k"}}}
+	if !reflect.DeepEqual(calls, want) {
+		t.Errorf("calls = %+v, want %+v", calls, want)
+	}
+}
+
+func TestCacher_AddInvalidator_Error(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	purgeErr := errors.New("purge failed")
+	c.AddInvalidator(cacher.InvalidatorFunc(func(ctx context.Context, keys []string, tags []string) error {
+		return purgeErr
+	}))
+	var gotErr error
+	c.OnEvent(func(ev cacher.Event) {
+		if ev.Type == cacher.EventError {
+			gotErr = ev.Err
+		}
+	})
+
+	//写入缓存时，钩子错误不影响写入结果
+	if err := c.Write(ctx, "k", "v", func(ctx context.Context) error { return nil }, func(opt *cacher.Option) {
+		opt.WriteMode = cacher.WriteThrough
+	}); err != nil {
+		t.Fatalf("Write() = %v", err)
+	}
+	if !errors.Is(gotErr, purgeErr) {
+		t.Errorf("error event = %v, want %v", gotErr, purgeErr)
+	}
+	//删除缓存时，返回钩子错误
+	if err := c.Del(ctx, "k"); !errors.Is(err, purgeErr) {
+		t.Errorf("Del() = %v, want %v", err, purgeErr)
+	}
+}
+
+func TestPurgeInvalidator(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		purged []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		if r.Method != "PURGE" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		purged = append(purged, r.URL.Path)
+	}))
+	defer srv.Close()
+
+	inv := &cacher.PurgeInvalidator{URL: func(key string) string {
+		if key == "skip" {
+			return ""
+		}
+		return srv.URL + "/" + key
+	}}
+	if err := inv.Invalidate(context.Background(), []string{"a", "skip", "b"}, nil); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(purged, []string{"/a", "/b"}) {
+		t.Errorf("purged = %v, want [/a /b]", purged)
+	}
+}
+
+func TestCloudflareInvalidator(t *testing.T) {
+	var body struct {
+		Files []string `json:"files"`
+		Tags  []string `json:"tags"`
+	}
+	var auth string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}))
+	defer srv.Close()
+
+	inv := &cacher.CloudflareInvalidator{
+		Endpoint: srv.URL,
+		Token:    "token",
+		URL:      func(key string) string { return "https://example.com/" + key },
+	}
+	if err := inv.Invalidate(context.Background(), []string{"a"}, []string{"t"}); err != nil {
+		t.Fatal(err)
+	}
+	if auth != "Bearer token" || !reflect.DeepEqual(body.Files, []string{"https://example.com/a"}) || !reflect.DeepEqual(body.Tags, []string{"t"}) {
+		t.Errorf("auth = %v, body = %+v", auth, body)
+	}
+}
@@ -0,0 +1,78 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+// repoExpire 记录保留时长的测试存储库
+type repoExpire struct {
+	*repoMap
+	expires map[string]time.Duration
+}
+
+func (r *repoExpire) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	r.mu.Lock()
+	r.expires[key] = expire
+	r.mu.Unlock()
+	return r.repoMap.Set(ctx, key, value, expire)
+}
+
+func TestOption_Jitter(t *testing.T) {
+	tests := []struct {
+		name     string
+		nilData  bool
+		jitter   float64
+		min, max time.Duration
+	}{
+		{name: "缓存：默认随机比例", jitter: 0, min: 10 * time.Second, max: 11 * time.Second},
+		{name: "空缓存：默认随机比例", nilData: true, jitter: 0, min: 5 * time.Second, max: 5500 * time.Millisecond},
+		{name: "空缓存：指定随机比例", nilData: true, jitter: 0.5, min: 5 * time.Second, max: 7500 * time.Millisecond},
+		{name: "缓存：不加随机", jitter: -1, min: 10 * time.Second, max: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &repoExpire{repoMap: newRepoMap(nil), expires: make(map[string]time.Duration)}
+			c := cacher.New(repo, 10*time.Second)
+			for i := 0; i < 20; i++ {
+				key := tt.name + string(rune('a'+i))
+				var v string
+				_, err := c.GetWithOption(context.Background(), key, func() (interface{}, error) {
+					if tt.nilData {
+						return nil, nil
+					}
+					return "v", nil
+				}, &v, func(opt *cacher.Option) {
+					opt.NilCacheExpire = 5 * time.Second
+					opt.Jitter = tt.jitter
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if got := repo.expires[key]; got < tt.min || got > tt.max {
+					t.Errorf("expire = %v, want in [%v, %v]", got, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestOption_ZeroExpire(t *testing.T) {
+	repo := &repoExpire{repoMap: newRepoMap(nil), expires: make(map[string]time.Duration)}
+	c := cacher.New(repo, 10*time.Second)
+	var v string
+	_, err := c.GetWithOption(context.Background(), "k", func() (interface{}, error) {
+		return "v", nil
+	}, &v, func(opt *cacher.Option) {
+		opt.Expire = 0
+		opt.Jitter = -1
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.expires["k"]; got != 10*time.Second {
+		t.Errorf("expire = %v, want default 10s", got)
+	}
+}
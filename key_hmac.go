@@ -0,0 +1,20 @@
+package cacher
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+)
+
+// hmacKeyPrefix 经过 HMAC 处理的缓存键的前缀
+const hmacKeyPrefix = "h:"
+
+// hideKey 设置了 Option.KeyHMAC 时，返回缓存键的 HMAC-SHA256，使邮箱、令牌等敏感标识不出现在存储库的键列表中
+func hideKey(key string, opt Option) string {
+	if len(opt.KeyHMAC) == 0 {
+		return key
+	}
+	mac := hmac.New(sha256.New, opt.KeyHMAC)
+	_, _ = mac.Write([]byte(key))
+	return hmacKeyPrefix + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,97 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_KeyHMAC(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.KeyHMAC = []byte("secret")
+	})
+	const email = "user:alice@example.com"
+	calls := 0
+	get := func(key string, optFns ...func(opt *cacher.Option)) bool {
+		var v string
+		useCache, err := c.GetWithOption(ctx, key, func() (interface{}, error) {
+			calls++
+			return "v", nil
+		}, &v, optFns...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return useCache
+	}
+
+	get(email)
+	if raw, _ := repo.Get(ctx, email); raw != nil {
+		t.Errorf("raw key stored in repo")
+	}
+	if !get(email) {
+		t.Errorf("second Get() missed cache")
+	}
+	if err := c.Del(ctx, email); err != nil {
+		t.Fatal(err)
+	}
+	if get(email) {
+		t.Errorf("Get() after Del hit cache")
+	}
+
+	//命名空间
+	ns := func(opt *cacher.Option) { opt.Namespace = "team" }
+	get(email, ns)
+	key, err := c.NamespaceKey(ctx, "team", email)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Del(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if get(email, ns) {
+		t.Errorf("Get() after Del of namespace key hit cache")
+	}
+
+	//依赖的缓存删除时级联删除
+	get("order:1", func(opt *cacher.Option) { opt.DependsOn = []string{email} })
+	if err := c.Del(ctx, email); err != nil {
+		t.Fatal(err)
+	}
+	if get("order:1") {
+		t.Errorf("dependent not deleted")
+	}
+
+	//不同密钥生成不同的缓存键
+	other := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.KeyHMAC = []byte("other")
+	})
+	var v string
+	if ok, err := other.Peek(ctx, "order:1", &v); ok || err != nil {
+		t.Errorf("Peek() with other secret = %v, %v, want miss", ok, err)
+	}
+}
+
+func TestOption_KeyHMAC_Queue(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.KeyHMAC = []byte("secret")
+	})
+	q := c.Queue("queue:alice@example.com")
+	if err := q.Push(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if items, err := repo.LRange(ctx, "queue:alice@example.com", 0, -1); err != nil || len(items) != 0 {
+		t.Errorf("raw key items = %v, %v, want none", items, err)
+	}
+	if err := q.Del(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var items []string
+	if err := q.Range(ctx, 0, -1, &items); err != nil || len(items) != 0 {
+		t.Errorf("Range() after Del = %v, %v", items, err)
+	}
+}
@@ -0,0 +1,76 @@
+package cacher
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrInvalidKey 缓存键不符合 Option.KeyPolicy，可以通过 errors.As 获取 *KeyError
+var ErrInvalidKey = errors.New("缓存键不符合规则")
+
+type (
+	// KeyPolicy 缓存键的规范化和校验规则，见 Option.KeyPolicy。
+	// 在读取、写入、删除以及 Hash、Set、Queue、SortedSet 等数据结构门面中，先规范化再校验，均在加上命名空间和 KeyHMAC 处理之前进行，
+	// 不符合规则的缓存键不会发送到存储库。数据结构门面的缓存键不符合规则时，各方法返回 *KeyError
+	KeyPolicy struct {
+		Normalize func(key string) string //自定义的规范化方法，在 Lowercase 之前调用
+		Lowercase bool                    //转换为小写
+		MaxLength int                     //规范化后的最大字节数，小于等于0时不限制
+		Charset   string                  //允许的字符，例如 "abcdefghijklmnopqrstuvwxyz0123456789:_-"，为空时不限制
+	}
+	// KeyError 缓存键不符合 KeyPolicy 的错误
+	KeyError struct {
+		Key    string //规范化后的缓存键
+		Reason string //不符合的原因
+	}
+)
+
+func (e *KeyError) Error() string {
+	return "缓存键 " + strconv.Quote(e.Key) + " 不符合规则: " + e.Reason
+}
+
+// Is 使 errors.Is(err, ErrInvalidKey) 成立
+func (e *KeyError) Is(target error) bool {
+	return target == ErrInvalidKey
+}
+
+// apply 规范化并校验缓存键，p 为 nil 时原样返回
+func (p *KeyPolicy) apply(key string) (string, error) {
+	if p == nil {
+		return key, nil
+	}
+	if p.Normalize != nil {
+		key = p.Normalize(key)
+	}
+	if p.Lowercase {
+		key = strings.ToLower(key)
+	}
+	if key == "" {
+		return "", &KeyError{Key: key, Reason: "规范化后为空字符串"}
+	}
+	if p.MaxLength > 0 && len(key) > p.MaxLength {
+		return "", &KeyError{Key: key, Reason: "长度 " + strconv.Itoa(len(key)) + " 超过 " + strconv.Itoa(p.MaxLength)}
+	}
+	if !utf8.ValidString(key) {
+		return "", &KeyError{Key: key, Reason: "不是有效的 UTF-8 字符串"}
+	}
+	if p.Charset != "" {
+		for _, r := range key {
+			if !strings.ContainsRune(p.Charset, r) {
+				return "", &KeyError{Key: key, Reason: "包含不允许的字符 " + strconv.QuoteRune(r)}
+			}
+		}
+	}
+	return key, nil
+}
+
+// repoKey 按 KeyPolicy 规范化并校验缓存键后，经过 KeyHMAC 处理，得到不带命名空间的存储库缓存键
+func repoKey(key string, opt Option) (string, error) {
+	key, err := opt.KeyPolicy.apply(key)
+	if err != nil {
+		return "", err
+	}
+	return hideKey(key, opt), nil
+}
@@ -0,0 +1,112 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOption_KeyPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := &cacher.KeyPolicy{
+		Normalize: strings.TrimSpace,
+		Lowercase: true,
+		MaxLength: 10,
+		Charset:   "abcdefghijklmnopqrstuvwxyz0123456789:",
+	}
+	tests := []struct {
+		name    string
+		key     string
+		wantKey string
+		wantErr bool
+	}{
+		{name: "规范化", key: " User:1 ", wantKey: "user:1"},
+		{name: "超过长度", key: "user:1234567", wantErr: true},
+		{name: "不允许的字符", key: "user 1", wantErr: true},
+		{name: "规范化后为空", key: "  ", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepoMap(nil)
+			c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+				opt.KeyPolicy = policy
+			})
+			var s string
+			_, err := c.Get(ctx, tt.key, func() (interface{}, error) { return "v", nil }, &s)
+			var keyErr *cacher.KeyError
+			if tt.wantErr {
+				if !errors.Is(err, cacher.ErrInvalidKey) || !errors.As(err, &keyErr) {
+					t.Fatalf("Get() error = %v, want KeyError", err)
+				}
+				if err := c.Del(ctx, tt.key); !errors.Is(err, cacher.ErrInvalidKey) {
+					t.Errorf("Del() error = %v, want ErrInvalidKey", err)
+				}
+				if len(repo.data) != 0 {
+					t.Errorf("repo = %v, want nothing saved", repo.data)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repo.data[tt.wantKey] != "v" {
+				t.Fatalf("repo = %v, want key %s", repo.data, tt.wantKey)
+			}
+			//删除时使用相同的规范化
+			if err := c.Del(ctx, strings.ToUpper(tt.key)); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := repo.data[tt.wantKey]; ok {
+				t.Errorf("key %s not deleted", tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestOption_KeyPolicy_Paths(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.KeyPolicy = &cacher.KeyPolicy{Lowercase: true, MaxLength: 40}
+	})
+
+	//DelEntity 与 GetKey 使用相同的缓存键
+	k := cacher.Key{Namespace: "Shop", Entity: "Product", ID: []string{"A1"}}
+	var s string
+	if _, err := c.GetKey(ctx, k, func() (interface{}, error) { return "v", nil }, &s); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DelEntity(ctx, k); err != nil {
+		t.Fatal(err)
+	}
+	if useCache, err := c.GetKey(ctx, k, func() (interface{}, error) { return "v2", nil }, &s); err != nil || useCache || s != "v2" {
+		t.Errorf("GetKey() after DelEntity = %v, %v, %v, want v2 from query", s, useCache, err)
+	}
+
+	//数据结构门面同样规范化和校验缓存键
+	if _, err := c.Set("Tags").Add(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := c.Set("TAGS").Contains(ctx, "a"); err != nil || !ok {
+		t.Errorf("Set(TAGS).Contains() = %v, %v, want true", ok, err)
+	}
+	long := strings.Repeat("k", 41)
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "Hash", call: func() error { return c.Hash(long).SetField(ctx, "f", "v") }},
+		{name: "Queue", call: func() error { return c.Queue(long).Push(ctx, "v") }},
+		{name: "Set", call: func() error { _, err := c.Set(long).Add(ctx, "v"); return err }},
+		{name: "SortedSet", call: func() error { return c.SortedSet(long).Add(ctx, cacher.ZMember{Member: "v"}) }},
+		{name: "SortedSet.Del", call: func() error { return c.SortedSet(long).Del(ctx) }},
+	}
+	for _, tt := range tests {
+		if err := tt.call(); !errors.Is(err, cacher.ErrInvalidKey) {
+			t.Errorf("%s error = %v, want ErrInvalidKey", tt.name, err)
+		}
+	}
+}
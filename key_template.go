@@ -0,0 +1,99 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// KeyTemplate 预先解析的缓存键模板，适合非常热的接口：
+// 只有末尾一个占位符的模板不再调用 fmt.Sprintf 生成缓存键，类型转换方式也只解析一次
+type KeyTemplate struct {
+	c      *Cacher
+	format string
+	prefix string //末尾占位符之前的部分
+	verb   byte   //末尾占位符，0 表示不能快速生成缓存键
+	optFns []func(opt *Option)
+	plans  planCache
+}
+
+// Key 创建缓存键模板，format 同 fmt.Sprintf，例如 "user:%d"。
+// optFns 是使用该模板时的默认选项，转换器应当在这里设置，以便缓存类型转换方式
+func (c *Cacher) Key(format string, optFns ...func(opt *Option)) *KeyTemplate {
+	k := &KeyTemplate{c: c, format: format, optFns: optFns}
+	if n := len(format); n >= 2 && format[n-2] == '%' && strings.IndexByte(format[:n-2], '%') < 0 {
+		switch format[n-1] {
+		case 'd', 's', 'v':
+			k.prefix = format[:n-2]
+			k.verb = format[n-1]
+		}
+	}
+	k.optFns = append(k.optFns, func(opt *Option) {
+		opt.plans = &k.plans
+	})
+	return k
+}
+
+// String 生成缓存键
+func (k *KeyTemplate) String(args ...interface{}) string {
+	if k.verb != 0 && len(args) == 1 {
+		if key, ok := k.fast(args[0]); ok {
+			return key
+		}
+	}
+	return fmt.Sprintf(k.format, args...)
+}
+
+// fast 快速生成缓存键，参数类型与占位符不匹配时返回 false
+func (k *KeyTemplate) fast(arg interface{}) (string, bool) {
+	switch v := arg.(type) {
+	case string:
+		if k.verb != 'd' {
+			return k.prefix + v, true
+		}
+	case int:
+		if k.verb != 's' {
+			return k.prefix + strconv.Itoa(v), true
+		}
+	case int64:
+		if k.verb != 's' {
+			return k.prefix + strconv.FormatInt(v, 10), true
+		}
+	case uint:
+		if k.verb != 's' {
+			return k.prefix + strconv.FormatUint(uint64(v), 10), true
+		}
+	case uint64:
+		if k.verb != 's' {
+			return k.prefix + strconv.FormatUint(v, 10), true
+		}
+	}
+	return "", false
+}
+
+// Get 使用只有一个参数的模板生成缓存键，然后调用 Cacher.GetWithOption
+func (k *KeyTemplate) Get(ctx context.Context, arg interface{}, queryFn func() (interface{}, error), v interface{}, optFns ...func(opt *Option)) (bool, error) {
+	return k.GetArgs(ctx, []interface{}{arg}, queryFn, v, optFns...)
+}
+
+// GetArgs 使用 args 生成缓存键，然后调用 Cacher.GetWithOption。
+// 传入 optFns 时，本次调用不使用缓存的类型转换方式
+func (k *KeyTemplate) GetArgs(ctx context.Context, args []interface{}, queryFn func() (interface{}, error), v interface{}, optFns ...func(opt *Option)) (bool, error) {
+	key := k.String(args...)
+	if len(optFns) == 0 {
+		return k.c.GetWithOption(ctx, key, queryFn, v, k.optFns...)
+	}
+	fns := make([]func(opt *Option), 0, len(k.optFns)+len(optFns)+1)
+	fns = append(fns, k.optFns...)
+	fns = append(fns, optFns...)
+	fns = append(fns, func(opt *Option) {
+		opt.plans = nil
+	})
+	return k.c.GetWithOption(ctx, key, queryFn, v, fns...)
+}
+
+// Del 删除 args 生成的缓存键对应的缓存
+func (k *KeyTemplate) Del(ctx context.Context, args ...interface{}) error {
+	return k.c.Del(ctx, k.String(args...))
+}
@@ -0,0 +1,99 @@
+package cacher_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestKeyTemplate_String(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	tests := []struct {
+		format string
+		args   []interface{}
+	}{
+		{format: "user:%d", args: []interface{}{42}},
+		{format: "user:%d", args: []interface{}{int64(-42)}},
+		{format: "user:%d", args: []interface{}{uint64(42)}},
+		{format: "user:%d", args: []interface{}{"42"}},
+		{format: "user:%s", args: []interface{}{"tom"}},
+		{format: "user:%s", args: []interface{}{42}},
+		{format: "user:%v", args: []interface{}{uint(42)}},
+		{format: "user:%d:%d", args: []interface{}{1, 2}},
+		{format: "100%%:%d", args: []interface{}{1}},
+	}
+	for _, tt := range tests {
+		want := fmt.Sprintf(tt.format, tt.args...)
+		if got := c.Key(tt.format).String(tt.args...); got != want {
+			t.Errorf("Key(%q).String(%v) = %q, want %q", tt.format, tt.args, got, want)
+		}
+	}
+}
+
+func TestKeyTemplate_Get(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, 10*time.Second)
+	users := c.Key("user:%d")
+
+	for i := 0; i < 2; i++ {
+		var v int
+		useCache, err := users.Get(ctx, 42, func() (interface{}, error) {
+			return "42", nil
+		}, &v)
+		if err != nil || v != 42 || useCache != (i == 1) {
+			t.Fatalf("Get() = %v, %v, v = %v", useCache, err, v)
+		}
+	}
+	if _, ok := repo.data["user:42"]; !ok {
+		t.Errorf("cache key user:42 not written")
+	}
+	if err := users.Del(ctx, 42); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := repo.data["user:42"]; ok {
+		t.Errorf("cache key user:42 not deleted")
+	}
+}
+
+func BenchmarkKeyTemplate_String(b *testing.B) {
+	k := cacher.New(newRepoMap(nil), 10*time.Second).Key("user:%d")
+	for i := 0; i < b.N; i++ {
+		_ = k.String(i)
+	}
+}
+
+func BenchmarkSprintf(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		_ = fmt.Sprintf("user:%d", i)
+	}
+}
+
+func BenchmarkKeyTemplate_Get(b *testing.B) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(map[string]interface{}{"user:1": []byte("1")}), 10*time.Second)
+	k := c.Key("user:%d")
+	query := func() (interface{}, error) { return nil, notNeedCall }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var v int
+		if _, err := k.Get(ctx, 1, query, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCacher_Get(b *testing.B) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(map[string]interface{}{"user:1": []byte("1")}), 10*time.Second)
+	query := func() (interface{}, error) { return nil, notNeedCall }
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		var v int
+		if _, err := c.Get(ctx, fmt.Sprintf("user:%d", 1), query, &v); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package cacher
+
+// HashFunc 缓存键的哈希函数，决定缓存键在 MemoryRepo 分片和 PeerRepo 一致性哈希环上的分布。
+// 需要与已有的缓存键分布兼容时（例如其他客户端使用 ketama），通过 WithHash、WithPeerHash 设置相同的哈希函数
+type HashFunc func(key string) uint32
+
+// fnv32a FNV-1a 哈希，MemoryRepo 默认的哈希函数，不分配内存
+func fnv32a(key string) uint32 {
+	h := uint32(2166136261)
+	for i := 0; i < len(key); i++ {
+		h ^= uint32(key[i])
+		h *= 16777619
+	}
+	return h
+}
@@ -0,0 +1,46 @@
+package cacher
+
+import (
+	"context"
+	"reflect"
+	"sync/atomic"
+)
+
+// Disable 关闭缓存，用于发现缓存数据错误时的紧急止损：之后的 Get、GetWithOption 直接调用查询方法，
+// 不读取也不写入存储库，不合并并发查询；GetMulti 不读取存储库，全部按未命中查询；
+// Write 等写入缓存的操作改为删除缓存，删除缓存不受影响。
+// 可以在运行时随时调用，也可以通过 AdminHandler 设置，通过 Enable 恢复。已写入的缓存不会删除，恢复前应当删除错误的缓存
+func (c *Cacher) Disable() {
+	atomic.StoreInt32(&c.disabled, 1)
+}
+
+// Enable 恢复被 Disable 关闭的缓存
+func (c *Cacher) Enable() {
+	atomic.StoreInt32(&c.disabled, 0)
+}
+
+// Disabled 缓存是否已被 Disable 关闭
+func (c *Cacher) Disabled() bool {
+	return atomic.LoadInt32(&c.disabled) == 1
+}
+
+// bypass 缓存关闭时直接调用查询方法，查询数据按 Option 处理后赋值给 v，不访问存储库
+func (c *Cacher) bypass(ctx context.Context, key string, queryFunc func() (interface{}, error), v interface{}, opt Option) error {
+	if _, err := opt.KeyPolicy.apply(key); err != nil {
+		return err
+	}
+	to, toType, finish, err := target(v, opt.TargetType)
+	if err != nil {
+		return err
+	}
+	defer finish()
+	queryData, err := c.load(ctx, queryFunc, key)
+	if err != nil {
+		return err
+	}
+	data, _, _, err := c.prepareSave(queryData, toType, opt)
+	if err != nil || data == nil {
+		return err
+	}
+	return c.assign(reflect.ValueOf(data), to, toType, opt)
+}
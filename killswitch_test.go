@@ -0,0 +1,117 @@
+package cacher_test
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/carteruu/cacher"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCacher_Disable(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, time.Minute)
+	var v int
+	if _, err := c.Get(ctx, "a", func() (interface{}, error) { return 1, nil }, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Disable()
+	calls := 0
+	query := func() (interface{}, error) {
+		calls++
+		return 2, nil
+	}
+	for _, key := range []string{"a", "b"} {
+		useCache, err := c.Get(ctx, key, query, &v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if useCache || v != 2 {
+			t.Errorf("Get(%q) when disabled = %d, %v, want 2, false", key, v, useCache)
+		}
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+	if _, ok := repo.data["b"]; ok {
+		t.Error("Get() when disabled should not write cache")
+	}
+	//写入缓存改为删除缓存
+	write := func(ctx context.Context) error { return nil }
+	if err := c.Write(ctx, "a", 3, write, func(opt *cacher.Option) { opt.WriteMode = cacher.WriteThrough }); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := repo.data["a"]; ok {
+		t.Error("Write() when disabled should delete cache")
+	}
+
+	c.Enable()
+	if useCache, err := c.Get(ctx, "b", query, &v); err != nil || useCache || calls != 3 {
+		t.Errorf("Get() after Enable = %d, %v, %v, want 2, false", v, useCache, err)
+	}
+	if useCache, err := c.Get(ctx, "b", func() (interface{}, error) { return nil, notNeedCall }, &v); err != nil || !useCache || v != 2 {
+		t.Errorf("Get() after Enable = %d, %v, %v, want 2, true", v, useCache, err)
+	}
+}
+
+func TestCacher_Disable_PrepareSet(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(map[string]interface{}{"a": 1})
+	c := cacher.New(repo, time.Minute)
+	staged := c.PrepareSet()
+	for _, key := range []string{"a", "b"} {
+		if err := staged.Set(ctx, key, 2); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Disable()
+	if err := staged.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	//提交改为删除缓存，旧的缓存不会保留到 Enable 之后
+	if len(repo.data) != 0 {
+		t.Errorf("repo = %v, want staged keys deleted when disabled", repo.data)
+	}
+}
+
+func TestCacher_AdminHandler(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	h := c.AdminHandler()
+	tests := []struct {
+		method   string
+		path     string
+		code     int
+		disabled bool
+	}{
+		{http.MethodGet, "/", http.StatusOK, false},
+		{http.MethodPost, "/disable", http.StatusOK, true},
+		{http.MethodGet, "/", http.StatusOK, true},
+		{http.MethodPost, "/unknown", http.StatusNotFound, true},
+		{http.MethodDelete, "/", http.StatusMethodNotAllowed, true},
+		{http.MethodPost, "/enable", http.StatusOK, false},
+	}
+	for _, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(tt.method, tt.path, nil))
+		if rec.Code != tt.code {
+			t.Errorf("%s %s code = %d, want %d", tt.method, tt.path, rec.Code, tt.code)
+		}
+		if c.Disabled() != tt.disabled {
+			t.Errorf("%s %s Disabled() = %v, want %v", tt.method, tt.path, c.Disabled(), tt.disabled)
+		}
+		if rec.Code != http.StatusOK {
+			continue
+		}
+		var state cacher.DebugState
+		if err := json.Unmarshal(rec.Body.Bytes(), &state); err != nil {
+			t.Fatal(err)
+		}
+		if state.Disabled != tt.disabled {
+			t.Errorf("%s %s state.Disabled = %v, want %v", tt.method, tt.path, state.Disabled, tt.disabled)
+		}
+	}
+}
@@ -0,0 +1,180 @@
+package cacher
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrLeaseHeld 缓存不存在，且租约已被其他调用方持有
+	ErrLeaseHeld = errors.New("租约已被其他调用方持有")
+	// ErrLeaseExpired 租约已过期或已被释放
+	ErrLeaseExpired = errors.New("租约已过期或已被释放")
+
+	// errLeased 获取到租约，由 GetOrLease 处理
+	errLeased = errors.New("获取到租约")
+)
+
+// leasePrefix 租约的缓存键前缀
+const leasePrefix = "cacher:lease:"
+
+type (
+	// NXRepo 可选的存储库接口，支持缓存键不存在时才保存。
+	// 存储库实现该接口后，租约在多个进程之间互斥，否则只在当前进程内互斥
+	NXRepo interface {
+		// SetNX 缓存键不存在时保存，返回是否保存成功
+		SetNX(ctx context.Context, key string, value interface{}, expire time.Duration) (bool, error)
+	}
+	// Lease 缓存租约，持有者负责计算数据并通过 Cacher.Fill 写入缓存
+	Lease struct {
+		key      string       //缓存键，已加上命名空间
+		token    string       //租约令牌
+		toType   reflect.Type //目标类型，用于生成空缓存数据
+		opt      Option
+		expireAt time.Time
+	}
+	// localLeases 进程内的租约，存储库未实现 NXRepo 时使用
+	localLeases struct {
+		mu sync.Mutex
+		m  map[string]localLease
+	}
+	localLease struct {
+		token    string
+		expireAt time.Time
+	}
+)
+
+// Key 租约对应的缓存键
+func (l *Lease) Key() string {
+	return l.key
+}
+
+// Token 租约令牌
+func (l *Lease) Token() string {
+	return l.token
+}
+
+// GetOrLease 获取缓存，缓存不存在时尝试获取 leaseExpire 时长的租约，适合在查询方法之外计算数据的场景。
+// 返回值：命中缓存时 hit 为 true；获取到租约时 lease 不为空，调用方计算数据后调用 Fill 写入缓存，放弃时调用 Release；
+// 租约已被其他调用方持有时返回 ErrLeaseHeld，调用方可以稍后重试
+func (c *Cacher) GetOrLease(ctx context.Context, key string, leaseExpire time.Duration, v interface{}, optFns ...func(opt *Option)) (hit bool, lease *Lease, err error) {
+	if leaseExpire <= 0 {
+		return false, nil, errors.New("租约时长 leaseExpire 必须大于0")
+	}
+	fns := append(optFns[:len(optFns):len(optFns)], func(opt *Option) {
+		opt.lease = func(key string, toType reflect.Type, opt Option) error {
+			l := &Lease{key: key, toType: toType, opt: opt, expireAt: time.Now().Add(leaseExpire)}
+			var err error
+			if l.token, err = newLeaseToken(); err != nil {
+				return err
+			}
+			ok, err := c.acquireLease(ctx, l, leaseExpire)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				return ErrLeaseHeld
+			}
+			lease = l
+			return errLeased
+		}
+	})
+	hit, err = c.GetWithOption(ctx, key, func() (interface{}, error) {
+		return nil, nil
+	}, v, fns...)
+	if errors.Is(err, errLeased) {
+		if lease != nil {
+			return false, lease, nil
+		}
+		//与租约持有者同时请求，共享了获取租约的结果
+		return false, nil, ErrLeaseHeld
+	}
+	return hit, nil, err
+}
+
+// Fill 租约持有者写入缓存数据并释放租约，value 为空时按选项处理空缓存。租约已过期时返回 ErrLeaseExpired
+func (c *Cacher) Fill(ctx context.Context, lease *Lease, value interface{}) error {
+	ok, err := c.holdsLease(ctx, lease)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrLeaseExpired
+	}
+	if _, err := c.save(ctx, lease.key, value, lease.toType, lease.opt); err != nil {
+		return err
+	}
+	return c.Release(ctx, lease)
+}
+
+// Release 释放租约，其他调用方可以重新获取
+func (c *Cacher) Release(ctx context.Context, lease *Lease) error {
+	if _, ok := c.repo.(NXRepo); ok {
+		held, err := c.holdsLease(ctx, lease)
+		if err != nil || !held {
+			return err
+		}
+		return c.repo.Del(ctx, leasePrefix+lease.key)
+	}
+	c.leases.mu.Lock()
+	defer c.leases.mu.Unlock()
+	if l, ok := c.leases.m[lease.key]; ok && l.token == lease.token {
+		delete(c.leases.m, lease.key)
+	}
+	return nil
+}
+
+// acquireLease 获取租约
+func (c *Cacher) acquireLease(ctx context.Context, lease *Lease, expire time.Duration) (bool, error) {
+	if repo, ok := c.repo.(NXRepo); ok {
+		return repo.SetNX(ctx, leasePrefix+lease.key, lease.token, expire)
+	}
+	c.leases.mu.Lock()
+	defer c.leases.mu.Unlock()
+	if l, ok := c.leases.m[lease.key]; ok && time.Now().Before(l.expireAt) {
+		return false, nil
+	}
+	if c.leases.m == nil {
+		c.leases.m = make(map[string]localLease)
+	}
+	c.leases.m[lease.key] = localLease{token: lease.token, expireAt: lease.expireAt}
+	return true, nil
+}
+
+// holdsLease 租约是否仍然有效
+func (c *Cacher) holdsLease(ctx context.Context, lease *Lease) (bool, error) {
+	if lease == nil || time.Now().After(lease.expireAt) {
+		return false, nil
+	}
+	if _, ok := c.repo.(NXRepo); ok {
+		token, err := c.repo.Get(ctx, leasePrefix+lease.key)
+		if err != nil {
+			return false, err
+		}
+		switch t := token.(type) {
+		case string:
+			return t == lease.token, nil
+		case []byte:
+			return string(t) == lease.token, nil
+		}
+		return false, nil
+	}
+	c.leases.mu.Lock()
+	defer c.leases.mu.Unlock()
+	l, ok := c.leases.m[lease.key]
+	return ok && l.token == lease.token, nil
+}
+
+// newLeaseToken 生成随机的租约令牌
+func newLeaseToken() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
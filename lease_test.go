@@ -0,0 +1,76 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestCacher_GetOrLease(t *testing.T) {
+	tests := []struct {
+		name string
+		repo cacher.Repo
+	}{
+		{name: "进程内租约", repo: newRepoMap(nil)},
+		{name: "存储库租约", repo: cacher.NewMemoryRepo()},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			c := cacher.New(tt.repo, 10*time.Second)
+			var v string
+			hit, lease, err := c.GetOrLease(ctx, "k", time.Second, &v)
+			if hit || lease == nil || err != nil {
+				t.Fatalf("GetOrLease() = %v, %v, %v, want lease", hit, lease, err)
+			}
+			if _, _, err := c.GetOrLease(ctx, "k", time.Second, &v); !errors.Is(err, cacher.ErrLeaseHeld) {
+				t.Fatalf("GetOrLease() error = %v, want ErrLeaseHeld", err)
+			}
+			if err := c.Fill(ctx, lease, "v"); err != nil {
+				t.Fatal(err)
+			}
+			if err := c.Fill(ctx, lease, "v2"); !errors.Is(err, cacher.ErrLeaseExpired) {
+				t.Errorf("Fill() twice error = %v, want ErrLeaseExpired", err)
+			}
+			hit, lease, err = c.GetOrLease(ctx, "k", time.Second, &v)
+			if !hit || lease != nil || err != nil || v != "v" {
+				t.Errorf("GetOrLease() after Fill = %v, %v, %v, v = %v", hit, lease, err, v)
+			}
+		})
+	}
+}
+
+func TestCacher_LeaseRelease(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	var v string
+	_, lease, err := c.GetOrLease(ctx, "k", time.Second, &v)
+	if err != nil || lease == nil {
+		t.Fatalf("GetOrLease() = %v, %v", lease, err)
+	}
+	if err := c.Release(ctx, lease); err != nil {
+		t.Fatal(err)
+	}
+	if _, lease, err = c.GetOrLease(ctx, "k", time.Second, &v); err != nil || lease == nil {
+		t.Errorf("GetOrLease() after Release = %v, %v, want lease", lease, err)
+	}
+}
+
+func TestCacher_LeaseExpire(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(cacher.NewMemoryRepo(), 10*time.Second)
+	var v string
+	_, lease, err := c.GetOrLease(ctx, "k", 10*time.Millisecond, &v)
+	if err != nil || lease == nil {
+		t.Fatalf("GetOrLease() = %v, %v", lease, err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if err := c.Fill(ctx, lease, "v"); !errors.Is(err, cacher.ErrLeaseExpired) {
+		t.Errorf("Fill() error = %v, want ErrLeaseExpired", err)
+	}
+	if _, lease, err = c.GetOrLease(ctx, "k", time.Second, &v); err != nil || lease == nil {
+		t.Errorf("GetOrLease() after expire = %v, %v, want lease", lease, err)
+	}
+}
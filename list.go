@@ -0,0 +1,144 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// ErrListUnsupported 存储库未实现 ListRepo
+var ErrListUnsupported = errors.New("存储库不支持列表，需要实现 ListRepo 接口")
+
+// ListRepo 可选的存储库接口，提供列表操作，用于轻量的任务队列和最近访问列表
+type ListRepo interface {
+	// LPush 从列表头部插入元素，expire 大于0时同时设置列表的保留时长
+	LPush(ctx context.Context, key string, expire time.Duration, values ...string) error
+	// RPop 从列表尾部弹出元素，列表为空时 ok 为 false
+	RPop(ctx context.Context, key string) (value string, ok bool, err error)
+	// LRange 获取 [start, stop] 的元素，下标从0开始，负数表示倒数，同 Redis 的 LRANGE
+	LRange(ctx context.Context, key string, start, stop int64) ([]string, error)
+	// LTrim 只保留 [start, stop] 的元素，同 Redis 的 LTRIM
+	LTrim(ctx context.Context, key string, start, stop int64) error
+}
+
+// Queue 列表门面：Push 从头部插入，Pop 从尾部弹出，先进先出；Range 从头部开始获取，即最近插入的在前。
+// 元素的编码方式同 Hash 的字段
+type Queue struct {
+	c      *Cacher
+	key    string
+	expire time.Duration
+	repo   ListRepo
+	err    error //缓存键不符合 Option.KeyPolicy 时的错误
+}
+
+// Queue 获取缓存键 key 对应的列表，optFns 中的 Expire 为列表的保留时长，等于0时使用 Cacher 的默认保留时长。
+// 存储库未实现 ListRepo 时，各方法返回 ErrListUnsupported
+func (c *Cacher) Queue(key string, optFns ...func(opt *Option)) *Queue {
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if opt.Expire == 0 {
+		opt.Expire = c.defaultExpire()
+	}
+	repo, _ := c.repo.(ListRepo)
+	key, err := repoKey(key, opt)
+	return &Queue{c: c, key: key, expire: opt.Expire, repo: repo, err: err}
+}
+
+// valid 缓存键不符合 Option.KeyPolicy 时返回 *KeyError，存储库未实现 ListRepo 时返回 ErrListUnsupported
+func (q *Queue) valid() error {
+	if q.err != nil {
+		return q.err
+	}
+	if q.repo == nil {
+		return ErrListUnsupported
+	}
+	return nil
+}
+
+// Push 插入元素，并刷新列表的保留时长
+func (q *Queue) Push(ctx context.Context, values ...interface{}) error {
+	if err := q.valid(); err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+	texts := make([]string, len(values))
+	for i, v := range values {
+		text, err := q.c.encodeText(reflect.ValueOf(v))
+		if err != nil {
+			return err
+		}
+		texts[i] = text
+	}
+	return q.repo.LPush(ctx, q.key, q.expire, texts...)
+}
+
+// Pop 弹出最早插入的元素到指针 v。返回值：是否弹出了元素
+func (q *Queue) Pop(ctx context.Context, v interface{}) (bool, error) {
+	if err := q.valid(); err != nil {
+		return false, err
+	}
+	to, _, finish, err := target(v, nil)
+	if err != nil {
+		return false, err
+	}
+	defer finish()
+	text, ok, err := q.repo.RPop(ctx, q.key)
+	if err != nil || !ok {
+		return false, err
+	}
+	return true, q.c.decodeText(text, to)
+}
+
+// Range 获取 [start, stop] 的元素到切片指针 v，下标0为最近插入的元素
+func (q *Queue) Range(ctx context.Context, start, stop int64, v interface{}) error {
+	if err := q.valid(); err != nil {
+		return err
+	}
+	to, _, finish, err := target(v, nil)
+	if err != nil {
+		return err
+	}
+	defer finish()
+	if to.Kind() != reflect.Slice {
+		return fmt.Errorf("目标变量 v 必须指向切片，实际为 %T", v)
+	}
+	texts, err := q.repo.LRange(ctx, q.key, start, stop)
+	if err != nil {
+		return err
+	}
+	items := reflect.MakeSlice(to.Type(), len(texts), len(texts))
+	for i, text := range texts {
+		if err := q.c.decodeText(text, items.Index(i)); err != nil {
+			return err
+		}
+	}
+	to.Set(items)
+	return nil
+}
+
+// Trim 只保留最近插入的 n 个元素，用于最近访问列表
+func (q *Queue) Trim(ctx context.Context, n int64) error {
+	if err := q.valid(); err != nil {
+		return err
+	}
+	if n <= 0 {
+		return q.Del(ctx)
+	}
+	return q.repo.LTrim(ctx, q.key, 0, n-1)
+}
+
+// Del 删除列表
+func (q *Queue) Del(ctx context.Context) error {
+	if q.err != nil {
+		return q.err
+	}
+	return q.c.del(ctx, q.key)
+}
@@ -0,0 +1,136 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// repoList 支持列表的测试存储库，列表下标0为头部
+type repoList struct {
+	*repoMap
+	lists   map[string][]string
+	expires map[string]time.Duration
+}
+
+func newRepoList() *repoList {
+	return &repoList{repoMap: newRepoMap(nil), lists: make(map[string][]string), expires: make(map[string]time.Duration)}
+}
+
+func (r *repoList) LPush(ctx context.Context, key string, expire time.Duration, values ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range values {
+		r.lists[key] = append([]string{v}, r.lists[key]...)
+	}
+	if expire > 0 {
+		r.expires[key] = expire
+	}
+	return nil
+}
+
+func (r *repoList) RPop(ctx context.Context, key string) (string, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.lists[key]
+	if len(list) == 0 {
+		return "", false, nil
+	}
+	r.lists[key] = list[:len(list)-1]
+	return list[len(list)-1], true, nil
+}
+
+func (r *repoList) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.lists[key]
+	if stop < 0 || stop >= int64(len(list)) {
+		stop = int64(len(list)) - 1
+	}
+	if start > stop {
+		return nil, nil
+	}
+	return append([]string(nil), list[start:stop+1]...), nil
+}
+
+func (r *repoList) LTrim(ctx context.Context, key string, start, stop int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	list := r.lists[key]
+	if stop >= int64(len(list)) {
+		stop = int64(len(list)) - 1
+	}
+	r.lists[key] = list[start : stop+1]
+	return nil
+}
+
+func (r *repoList) Del(ctx context.Context, keys ...string) error {
+	r.mu.Lock()
+	for _, key := range keys {
+		delete(r.lists, key)
+	}
+	r.mu.Unlock()
+	return r.repoMap.Del(ctx, keys...)
+}
+
+func TestQueue(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoList()
+	c := cacher.New(repo, 10*time.Second)
+	q := c.Queue("jobs", func(opt *cacher.Option) {
+		opt.Expire = time.Minute
+	})
+	if err := q.Push(ctx, personObj, personObj1); err != nil {
+		t.Fatal(err)
+	}
+	if repo.expires["jobs"] != time.Minute {
+		t.Errorf("expire = %v, want 1m", repo.expires["jobs"])
+	}
+	var recent []person
+	if err := q.Range(ctx, 0, -1, &recent); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(recent, []person{personObj1, personObj}) {
+		t.Errorf("Range() = %v", recent)
+	}
+	var p person
+	if ok, err := q.Pop(ctx, &p); !ok || err != nil || p != personObj {
+		t.Errorf("Pop() = %v, %v, p = %+v", ok, err, p)
+	}
+	if ok, err := q.Pop(ctx, &p); !ok || err != nil || p != personObj1 {
+		t.Errorf("Pop() = %v, %v, p = %+v", ok, err, p)
+	}
+	if ok, err := q.Pop(ctx, &p); ok || err != nil {
+		t.Errorf("Pop() on empty = %v, %v", ok, err)
+	}
+}
+
+func TestQueue_Trim(t *testing.T) {
+	ctx := context.Background()
+	q := cacher.New(newRepoList(), 10*time.Second).Queue("recent")
+	for i := 1; i <= 5; i++ {
+		if err := q.Push(ctx, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := q.Trim(ctx, 3); err != nil {
+		t.Fatal(err)
+	}
+	var ids []int
+	if err := q.Range(ctx, 0, -1, &ids); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []int{5, 4, 3}) {
+		t.Errorf("Range() = %v, want [5 4 3]", ids)
+	}
+}
+
+func TestQueue_Unsupported(t *testing.T) {
+	q := cacher.New(newRepoMap(nil), 10*time.Second).Queue("k")
+	if err := q.Push(context.Background(), 1); !errors.Is(err, cacher.ErrListUnsupported) {
+		t.Errorf("Push() error = %v, want ErrListUnsupported", err)
+	}
+}
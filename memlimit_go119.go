@@ -0,0 +1,16 @@
+//go:build go1.19
+
+package cacher
+
+import (
+	"math"
+	"runtime/debug"
+)
+
+// runtimeMemoryLimit 运行时的软内存限制，没有设置时返回0
+func runtimeMemoryLimit() int64 {
+	if limit := debug.SetMemoryLimit(-1); limit != math.MaxInt64 {
+		return limit
+	}
+	return 0
+}
@@ -0,0 +1,8 @@
+//go:build !go1.19
+
+package cacher
+
+// runtimeMemoryLimit Go 1.19 之前的运行时没有软内存限制，返回0
+func runtimeMemoryLimit() int64 {
+	return 0
+}
@@ -0,0 +1,70 @@
+package cacher
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Metrics 缓存指标的钩子，通过 SetMetrics 设置，用于导出命中率、查询耗时等监控指标，Prometheus 适配见 metrics/prommetrics。
+// 方法在调用方的 goroutine 中同步调用，实现需要并发安全且不应阻塞。key 为存储库中的缓存键，已加上命名空间
+type Metrics interface {
+	// Hit 命中缓存，nilHit 为 true 时命中的是空缓存
+	Hit(key string, nilHit bool)
+	// Miss 未命中缓存
+	Miss(key string)
+	// Load 调用查询方法完成，d 为查询耗时，err 为查询方法返回的错误。GetMulti 一次查询多个缓存键时 key 为空字符串
+	Load(key string, d time.Duration, err error)
+}
+
+// SetMetrics 设置缓存指标的钩子，需要在使用 Cacher 之前设置，为 nil 时不记录
+func (c *Cacher) SetMetrics(m Metrics) {
+	c.metrics = m
+}
+
+// observeHit 记录命中缓存，from 为缓存数据，to 为已赋值的目标变量，见 isNilHit
+func (c *Cacher) observeHit(key string, from, to reflect.Value, toType reflect.Type, opt Option) {
+	if c.metrics == nil {
+		return
+	}
+	c.metrics.Hit(key, isNilHit(from, to, toType, opt))
+}
+
+// observeMiss 记录未命中缓存
+func (c *Cacher) observeMiss(key string) {
+	if c.metrics != nil {
+		c.metrics.Miss(key)
+	}
+}
+
+// load 调用查询方法，记录查询次数和耗时。批量查询时 keys 为多个缓存键，Metrics.Load 的缓存键为空字符串
+func (c *Cacher) load(ctx context.Context, queryFunc func() (interface{}, error), keys ...string) (interface{}, error) {
+	_, span := c.startSpan(ctx, SpanLoad, keys...)
+	start := time.Now()
+	data, err := c.stats.load(queryFunc)
+	span.end(false, err)
+	if c.metrics != nil {
+		key := ""
+		if len(keys) == 1 {
+			key = keys[0]
+		}
+		c.metrics.Load(key, time.Since(start), err)
+	}
+	return data, err
+}
+
+// isNilHit 是否命中空缓存：缓存数据是空缓存的标记，或者设置了 Option.NilData 时目标变量的值与 NilData 相等。
+// 没有 NilData 时只有标记算作空缓存，查询到并保存的零值不算
+func isNilHit(from, to reflect.Value, toType reflect.Type, opt Option) bool {
+	if isNilMarker(from) {
+		return true
+	}
+	if opt.NilData == nil || !opt.isCacheNil() || !to.IsValid() {
+		return false
+	}
+	nilData := reflect.ValueOf(opt.NilData)
+	if toType == nil || !nilData.Type().ConvertibleTo(toType) {
+		return false
+	}
+	return reflect.DeepEqual(to.Interface(), nilData.Convert(toType).Interface())
+}
@@ -0,0 +1,134 @@
+// Package prommetrics 把缓存指标导出为 Prometheus 指标，通过 Cacher.SetMetrics 设置：
+//
+//	m := prommetrics.New(prommetrics.WithGroup(func(key string) string { return strings.SplitN(key, ":", 2)[0] }))
+//	prometheus.MustRegister(m)
+//	c.SetMetrics(m)
+//
+// 所有指标都带有 group 标签，值由 WithGroup 设置的分组方法根据缓存键计算。分组方法应当返回有限的几个值（例如实体名），
+// 直接使用缓存键会产生过多的标签值
+package prommetrics
+
+import (
+	"github.com/carteruu/cacher"
+	"github.com/prometheus/client_golang/prometheus"
+	"time"
+)
+
+// DefaultGroup 没有设置分组方法时所有缓存键的分组
+const DefaultGroup = "default"
+
+type (
+	// Metrics 实现 cacher.Metrics 和 prometheus.Collector
+	Metrics struct {
+		group      func(key string) string
+		hits       *prometheus.CounterVec
+		nilHits    *prometheus.CounterVec
+		misses     *prometheus.CounterVec
+		loads      *prometheus.CounterVec
+		loadErrors *prometheus.CounterVec
+		duration   *prometheus.HistogramVec
+	}
+	// Option 指标的选项
+	Option struct {
+		Namespace string                  //指标名的前缀，默认为 cacher
+		Group     func(key string) string //缓存键的分组方法，为空时所有缓存键属于 DefaultGroup。批量查询的缓存键为空字符串
+		Buckets   []float64               //查询耗时直方图的桶（秒），默认为 prometheus.DefBuckets
+	}
+)
+
+var (
+	_ cacher.Metrics       = (*Metrics)(nil)
+	_ prometheus.Collector = (*Metrics)(nil)
+)
+
+// WithNamespace 设置指标名的前缀
+func WithNamespace(ns string) func(opt *Option) {
+	return func(opt *Option) {
+		opt.Namespace = ns
+	}
+}
+
+// WithGroup 设置缓存键的分组方法
+func WithGroup(group func(key string) string) func(opt *Option) {
+	return func(opt *Option) {
+		opt.Group = group
+	}
+}
+
+// WithBuckets 设置查询耗时直方图的桶
+func WithBuckets(buckets []float64) func(opt *Option) {
+	return func(opt *Option) {
+		opt.Buckets = buckets
+	}
+}
+
+// New 创建指标，需要注册到 prometheus.Registerer 后才会导出
+func New(optFns ...func(opt *Option)) *Metrics {
+	opt := Option{Namespace: "cacher", Buckets: prometheus.DefBuckets}
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	if opt.Group == nil {
+		opt.Group = func(string) string { return DefaultGroup }
+	}
+	labels := []string{"group"}
+	counter := func(name, help string) *prometheus.CounterVec {
+		return prometheus.NewCounterVec(prometheus.CounterOpts{Namespace: opt.Namespace, Name: name, Help: help}, labels)
+	}
+	return &Metrics{
+		group:      opt.Group,
+		hits:       counter("hits_total", "命中缓存次数，包括命中空缓存"),
+		nilHits:    counter("nil_hits_total", "命中空缓存次数"),
+		misses:     counter("misses_total", "未命中缓存次数"),
+		loads:      counter("loads_total", "调用查询方法的次数"),
+		loadErrors: counter("load_errors_total", "查询方法返回错误的次数"),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: opt.Namespace,
+			Name:      "load_duration_seconds",
+			Help:      "查询方法的耗时",
+			Buckets:   opt.Buckets,
+		}, labels),
+	}
+}
+
+// Hit 实现 cacher.Metrics
+func (m *Metrics) Hit(key string, nilHit bool) {
+	group := m.group(key)
+	m.hits.WithLabelValues(group).Inc()
+	if nilHit {
+		m.nilHits.WithLabelValues(group).Inc()
+	}
+}
+
+// Miss 实现 cacher.Metrics
+func (m *Metrics) Miss(key string) {
+	m.misses.WithLabelValues(m.group(key)).Inc()
+}
+
+// Load 实现 cacher.Metrics
+func (m *Metrics) Load(key string, d time.Duration, err error) {
+	group := m.group(key)
+	m.loads.WithLabelValues(group).Inc()
+	if err != nil {
+		m.loadErrors.WithLabelValues(group).Inc()
+	}
+	m.duration.WithLabelValues(group).Observe(d.Seconds())
+}
+
+// Describe 实现 prometheus.Collector
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect 实现 prometheus.Collector
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.hits, m.nilHits, m.misses, m.loads, m.loadErrors, m.duration}
+}
@@ -0,0 +1,67 @@
+package prommetrics_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/metrics/prommetrics"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMetrics(t *testing.T) {
+	ctx := context.Background()
+	m := prommetrics.New(prommetrics.WithGroup(func(key string) string {
+		return strings.SplitN(key, ":", 2)[0]
+	}))
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(m); err != nil {
+		t.Fatal(err)
+	}
+	c := cacher.New(cacher.NewMemoryRepo(), time.Minute, func(opt *cacher.Option) {
+		opt.NilCacheExpire = time.Minute
+	})
+	c.SetMetrics(m)
+
+	var s string
+	for _, key := range []string{"user:1", "user:1", "user:2", "user:2", "order:1"} {
+		key := key
+		_, _ = c.Get(ctx, key, func() (interface{}, error) {
+			if key == "user:2" {
+				return nil, nil
+			}
+			return key, nil
+		}, &s)
+	}
+	_, _ = c.Get(ctx, "order:2", func() (interface{}, error) { return nil, errors.New("query failed") }, &s)
+
+	want := `
+# HELP cacher_hits_total 命中缓存次数，包括命中空缓存
+# TYPE cacher_hits_total counter
+cacher_hits_total{group="user"} 2
+# HELP cacher_load_errors_total 查询方法返回错误的次数
+# TYPE cacher_load_errors_total counter
+cacher_load_errors_total{group="order"} 1
+# HELP cacher_loads_total 调用查询方法的次数
+# TYPE cacher_loads_total counter
+cacher_loads_total{group="order"} 2
+cacher_loads_total{group="user"} 2
+# HELP cacher_misses_total 未命中缓存次数
+# TYPE cacher_misses_total counter
+cacher_misses_total{group="order"} 2
+cacher_misses_total{group="user"} 2
+# HELP cacher_nil_hits_total 命中空缓存次数
+# TYPE cacher_nil_hits_total counter
+cacher_nil_hits_total{group="user"} 1
+`
+	names := []string{"cacher_hits_total", "cacher_load_errors_total", "cacher_loads_total", "cacher_misses_total", "cacher_nil_hits_total"}
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(want), names...); err != nil {
+		t.Error(err)
+	}
+	if n := testutil.CollectAndCount(m, "cacher_load_duration_seconds"); n != 2 {
+		t.Errorf("load duration series = %d, want 2", n)
+	}
+}
@@ -0,0 +1,115 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordMetrics 记录 Metrics 的调用
+type recordMetrics struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (m *recordMetrics) record(call string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+}
+
+func (m *recordMetrics) Hit(key string, nilHit bool) {
+	if nilHit {
+		m.record("nil-hit " + key)
+		return
+	}
+	m.record("hit " + key)
+}
+
+func (m *recordMetrics) Miss(key string) { m.record("miss " + key) }
+
+func (m *recordMetrics) Load(key string, d time.Duration, err error) {
+	if err != nil {
+		m.record("load error " + key)
+		return
+	}
+	m.record("load " + key)
+}
+
+func TestCacher_SetMetrics(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute, func(opt *cacher.Option) {
+		opt.NilCacheExpire = time.Minute
+	})
+	m := &recordMetrics{}
+	c.SetMetrics(m)
+	query := func(data interface{}, err error) func() (interface{}, error) {
+		return func() (interface{}, error) { return data, err }
+	}
+
+	var s string
+	_, _ = c.Get(ctx, "a", query("v", nil), &s)
+	_, _ = c.Get(ctx, "a", query("v", nil), &s)
+	_, _ = c.Get(ctx, "nil", query(nil, nil), &s)
+	_, _ = c.Get(ctx, "nil", query(nil, nil), &s)
+	_, _ = c.Get(ctx, "err", query(nil, errors.New("query failed")), &s)
+	want := []string{
+		"miss a", "load a",
+		"hit a",
+		"miss nil", "load nil",
+		"nil-hit nil",
+		"miss err", "load error err",
+	}
+	if !reflect.DeepEqual(m.calls, want) {
+		t.Errorf("calls = %q, want %q", m.calls, want)
+	}
+
+	m.calls = nil
+	var dst map[string]string
+	err := c.GetMulti(ctx, []string{"a", "b", "c"}, func(missing []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"b": "v", "c": "v"}, nil
+	}, &dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//批量查询多个缓存键时不区分缓存键
+	want = []string{"miss b", "miss c", "hit a", "load "}
+	if !reflect.DeepEqual(m.calls, want) {
+		t.Errorf("GetMulti calls = %q, want %q", m.calls, want)
+	}
+}
+
+func TestCacher_SetMetrics_NilHit(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		data    interface{}
+		nilData interface{}
+		want    string
+	}{
+		{name: "空缓存", data: nil, want: "nil-hit k"},
+		{name: "查询到零值", data: "", want: "hit k"},
+		{name: "与 NilData 相等", data: nil, nilData: "<nil>", want: "nil-hit k"},
+		{name: "查询到零值，设置了 NilData", data: "", nilData: "<nil>", want: "hit k"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cacher.New(newRepoMap(nil), time.Minute, cacher.WithNilCache(time.Minute, tt.nilData))
+			m := &recordMetrics{}
+			c.SetMetrics(m)
+			for i := 0; i < 2; i++ {
+				var s string
+				if _, err := c.Get(ctx, "k", func() (interface{}, error) { return tt.data, nil }, &s); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if got := m.calls[len(m.calls)-1]; got != tt.want {
+				t.Errorf("Hit = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,427 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+	"sync/atomic"
+)
+
+// multiDecodeParallel GetMulti 并发解码命中缓存的最小数量，数量较少时并发的开销大于收益
+const multiDecodeParallel = 64
+
+// MultiGetRepo 可选的存储库接口，支持一次读取多个缓存，Cacher.GetMulti 使用该接口减少往返次数
+type MultiGetRepo interface {
+	// GetMulti 读取多个缓存，结果与 keys 一一对应，缓存不存在时对应的结果为 nil
+	GetMulti(ctx context.Context, keys []string) ([]interface{}, error)
+}
+
+// GetMulti 批量读取缓存：一次读取所有缓存键，只为未命中的缓存键调用一次 queryFn，查询结果写入缓存后填充到 dst。
+// queryFn 的 missing 按 keys 中首次出现的顺序排列，返回未命中的缓存键到查询数据的映射，没有返回的缓存键视为查询数据为空，按 Option 的空缓存策略处理。
+// dst 是 map[string]T 的指针时，填充命中或查询到的缓存键，查询数据为空且不保存空缓存的缓存键不填充；
+// dst 是 []T 的指针时，结果与 keys 一一对应，没有数据的位置为 T 的零值。缓存的查询错误视为未命中。
+// 并发的 GetMulti 查询同一个缓存键时只查询一次：正在被其他调用查询的缓存键等待其结果，不传给 queryFn，由执行查询的调用写入缓存。
+// 存储库实现了 BatchRepo 时，查询到的数据一次保存。命中的缓存较多时，由有限数量的 goroutine 并发解码
+func (c *Cacher) GetMulti(
+	ctx context.Context,
+	keys []string,
+	queryFn func(missing []string) (map[string]interface{}, error),
+	dst interface{},
+	optFns ...func(opt *Option)) error {
+	if queryFn == nil {
+		return ErrNilQueryFunc
+	}
+	for _, key := range keys {
+		if key == "" {
+			return ErrEmptyKey
+		}
+	}
+	out := reflect.ValueOf(dst)
+	if out.Kind() != reflect.Ptr || out.IsNil() || (out.Elem().Kind() != reflect.Map && out.Elem().Kind() != reflect.Slice) ||
+		(out.Elem().Kind() == reflect.Map && out.Elem().Type().Key().Kind() != reflect.String) {
+		return fmt.Errorf("目标变量 dst 必须是 map[string]T 或 []T 的指针，实际为 %T", dst)
+	}
+	out = out.Elem()
+	elemType := out.Type().Elem()
+
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if err := opt.Valid(); err != nil {
+		return err
+	}
+	if err := checkTypeTag(elemType); err != nil {
+		return err
+	}
+
+	//去重并生成存储库中的缓存键
+	var (
+		uniq     []string
+		repoKeys []string
+		seen     = make(map[string]bool, len(keys))
+	)
+	for _, key := range keys {
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		repoKey, err := c.buildKey(ctx, key, opt)
+		if err != nil {
+			return err
+		}
+		uniq = append(uniq, key)
+		repoKeys = append(repoKeys, repoKey)
+	}
+	//缓存关闭时不读取存储库，全部按未命中处理
+	datas := make([]interface{}, len(repoKeys))
+	if !c.Disabled() {
+		var err error
+		if datas, err = c.getMulti(ctx, repoKeys); err != nil {
+			return err
+		}
+	}
+
+	results := make(map[string]reflect.Value, len(uniq))
+	var (
+		missing []string
+		hits    []string
+		hitKeys []string
+		froms   []reflect.Value
+	)
+	missingIdx := make(map[string]int)
+	for i, key := range uniq {
+		repoKey := repoKeys[i]
+		c.trackKey(repoKey)
+		if datas[i] == nil || cachedError(repoKey, datas[i]) != nil {
+			c.stats.miss()
+			c.emit(Event{Type: EventMiss, Key: repoKey})
+			c.observeMiss(repoKey)
+			missing = append(missing, key)
+			missingIdx[key] = i
+			continue
+		}
+		from := reflect.ValueOf(datas[i])
+		size := c.measure(from, opt)
+		c.stats.hit()
+		c.emit(Event{Type: EventHit, Key: repoKey, Size: size})
+		opt.report(repoKey, true, size)
+		hits = append(hits, key)
+		hitKeys = append(hitKeys, repoKey)
+		froms = append(froms, from)
+	}
+	elems, err := c.assignElems(froms, elemType, opt)
+	if err != nil {
+		e := err.(*elemError)
+		return fmt.Errorf("缓存 %s：%w", hits[e.index], e.err)
+	}
+	for i, key := range hits {
+		results[key] = elems[i]
+		c.observeHit(hitKeys[i], froms[i], elems[i], elemType, opt)
+	}
+
+	if len(missing) > 0 {
+		missingKeys := make([]string, len(missing))
+		for i, key := range missing {
+			missingKeys[i] = repoKeys[missingIdx[key]]
+		}
+		queryData, owned, err := c.loadMulti(ctx, missing, missingKeys, queryFn)
+		if err != nil {
+			return err
+		}
+		_, toType, finish, err := target(reflect.New(elemType).Interface(), opt.TargetType)
+		if err != nil {
+			return err
+		}
+		finish()
+		if opt.Expire == 0 {
+			opt.Expire = c.typeExpire(toType)
+		}
+		var entries []RepoEntry
+		for _, key := range missing {
+			repoKey := repoKeys[missingIdx[key]]
+			data, expire, store, err := c.prepareSave(queryData[key], toType, opt)
+			if errors.Is(err, ErrNotFound) {
+				continue
+			}
+			if err != nil {
+				return fmt.Errorf("保存缓存 %s 失败：%w", key, err)
+			}
+			if store && owned[key] {
+				entries = append(entries, RepoEntry{Key: repoKey, Value: data, Expire: expire})
+			}
+			opt.report(repoKey, false, 0)
+			if data == nil {
+				continue
+			}
+			elem, err := c.assignElem(reflect.ValueOf(data), elemType, opt)
+			if err != nil {
+				return fmt.Errorf("缓存 %s：%w", key, err)
+			}
+			results[key] = elem
+		}
+		//一次保存所有查询到的数据，失败时按 Degrade.RepoWrite 处理
+		if err := c.storeMulti(ctx, entries, opt); err != nil {
+			for _, entry := range entries {
+				if ok, _, err := c.degrade(ctx, entry.Key, FailRepoWrite, err, reflect.Value{}, toType, opt); !ok {
+					return err
+				}
+			}
+		}
+	}
+
+	if out.Kind() == reflect.Map {
+		if out.IsNil() {
+			out.Set(reflect.MakeMapWithSize(out.Type(), len(results)))
+		}
+		for key, elem := range results {
+			out.SetMapIndex(reflect.ValueOf(key).Convert(out.Type().Key()), elem)
+		}
+		return nil
+	}
+	slice := reflect.MakeSlice(out.Type(), len(keys), len(keys))
+	for i, key := range keys {
+		if elem, ok := results[key]; ok {
+			slice.Index(i).Set(elem)
+		}
+	}
+	out.Set(slice)
+	return nil
+}
+
+// getMulti 读取多个缓存，存储库没有实现 BatchRepo 或 MultiGetRepo 时逐个读取
+func (c *Cacher) getMulti(ctx context.Context, keys []string) ([]interface{}, error) {
+	var multi func(ctx context.Context, keys []string) ([]interface{}, error)
+	switch r := c.repo.(type) {
+	case BatchRepo:
+		multi = func(ctx context.Context, keys []string) ([]interface{}, error) { return r.MGet(ctx, keys...) }
+	case MultiGetRepo:
+		multi = r.GetMulti
+	}
+	if multi != nil {
+		datas, err := multi(ctx, keys)
+		if err != nil {
+			return nil, err
+		}
+		if len(datas) != len(keys) {
+			return nil, fmt.Errorf("存储库批量读取返回 %d 个结果，需要 %d 个", len(datas), len(keys))
+		}
+		return datas, nil
+	}
+	datas := make([]interface{}, len(keys))
+	for i, key := range keys {
+		data, err := c.repo.Get(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		datas[i] = data
+	}
+	return datas, nil
+}
+
+// elemError 批量解码中第 index 个缓存数据的错误
+type elemError struct {
+	index int
+	err   error
+}
+
+func (e *elemError) Error() string { return e.err.Error() }
+
+func (e *elemError) Unwrap() error { return e.err }
+
+// assignElems 把多个缓存数据转换为 elemType 类型的值，结果与 froms 一一对应。
+// 数量达到 multiDecodeParallel 时由最多 GOMAXPROCS 个 goroutine 并发解码，出错时返回下标最小的 *elemError
+func (c *Cacher) assignElems(froms []reflect.Value, elemType reflect.Type, opt Option) ([]reflect.Value, error) {
+	elems := make([]reflect.Value, len(froms))
+	errs := make([]error, len(froms))
+	workers := runtime.GOMAXPROCS(0)
+	if len(froms) < multiDecodeParallel || workers <= 1 {
+		for i, from := range froms {
+			elem, err := c.assignElem(from, elemType, opt)
+			if err != nil {
+				return nil, &elemError{index: i, err: err}
+			}
+			elems[i] = elem
+		}
+		return elems, nil
+	}
+	if workers > len(froms) {
+		workers = len(froms)
+	}
+	var (
+		wg   sync.WaitGroup
+		next int64 = -1
+	)
+	wg.Add(workers)
+	for w := 0; w < workers; w++ {
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(atomic.AddInt64(&next, 1))
+				if i >= len(froms) {
+					return
+				}
+				elems[i], errs[i] = c.assignElem(froms[i], elemType, opt)
+			}
+		}()
+	}
+	wg.Wait()
+	for i, err := range errs {
+		if err != nil {
+			return nil, &elemError{index: i, err: err}
+		}
+	}
+	return elems, nil
+}
+
+// assignElem 把缓存数据转换为 elemType 类型的值
+func (c *Cacher) assignElem(from reflect.Value, elemType reflect.Type, opt Option) (reflect.Value, error) {
+	ptr := reflect.New(elemType)
+	to, toType, finish, err := target(ptr.Interface(), opt.TargetType)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	err = c.assign(from, to, toType, opt)
+	finish()
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return ptr.Elem(), nil
+}
+
+type (
+	// multiFlight 合并并发的 Get、GetMulti 对同一个缓存键的查询
+	multiFlight struct {
+		mu    sync.Mutex
+		calls map[string]*multiCall
+	}
+	// multiCall 一个缓存键正在进行的查询，done 关闭后 val、err 有效
+	multiCall struct {
+		done chan struct{}
+		val  interface{}
+		err  error
+	}
+)
+
+// join 登记存储库中的缓存键 keys 的查询，返回每个缓存键的查询；owned[i] 为 true 时由本次调用查询，需要调用 finish 发布结果
+func (f *multiFlight) join(keys []string) (calls []*multiCall, owned []bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.calls == nil {
+		f.calls = make(map[string]*multiCall)
+	}
+	calls = make([]*multiCall, len(keys))
+	owned = make([]bool, len(keys))
+	for i, key := range keys {
+		if call, ok := f.calls[key]; ok {
+			calls[i] = call
+			continue
+		}
+		calls[i] = &multiCall{done: make(chan struct{})}
+		owned[i] = true
+		f.calls[key] = calls[i]
+	}
+	return calls, owned
+}
+
+// finish 发布本次调用负责的查询结果，val 返回第 i 个缓存键的查询数据
+func (f *multiFlight) finish(keys []string, calls []*multiCall, owned []bool, val func(i int) interface{}, err error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i, key := range keys {
+		if !owned[i] {
+			continue
+		}
+		if err == nil {
+			calls[i].val = val(i)
+		}
+		calls[i].err = err
+		delete(f.calls, key)
+		close(calls[i].done)
+	}
+}
+
+// loadShared Get 查询存储库中的缓存键 key。正在被 GetMulti 查询时等待其结果，owned 为 false，查询数据已由 GetMulti 保存；
+// 否则调用 queryFunc，查询期间 GetMulti 对该缓存键的查询等待本次结果
+func (c *Cacher) loadShared(ctx context.Context, key string, queryFunc func() (interface{}, error)) (data interface{}, owned bool, err error) {
+	keys := []string{key}
+	calls, isOwned := c.multiFlights.join(keys)
+	if !isOwned[0] {
+		select {
+		case <-calls[0].done:
+			return calls[0].val, false, calls[0].err
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		}
+	}
+	err = errors.New("查询方法 queryFunc 异常退出")
+	//queryFunc panic 时也要发布结果，避免等待的调用永远阻塞
+	defer func() {
+		c.multiFlights.finish(keys, calls, isOwned, func(int) interface{} { return data }, err)
+	}()
+	data, err = c.load(ctx, queryFunc, key)
+	return data, true, err
+}
+
+// loadMulti 查询未命中的缓存键 missing，repoKeys 为对应的存储库中的缓存键。
+// 正在被 Get 或其他 GetMulti 查询的缓存键等待其结果，其余的缓存键调用一次 queryFn 查询；owned 为本次调用查询的缓存键
+func (c *Cacher) loadMulti(
+	ctx context.Context,
+	missing, repoKeys []string,
+	queryFn func(missing []string) (map[string]interface{}, error),
+) (queryData map[string]interface{}, owned map[string]bool, err error) {
+	calls, isOwned := c.multiFlights.join(repoKeys)
+	var own, ownKeys []string
+	owned = make(map[string]bool, len(missing))
+	for i, key := range missing {
+		if isOwned[i] {
+			own = append(own, key)
+			ownKeys = append(ownKeys, repoKeys[i])
+			owned[key] = true
+		}
+	}
+	var queried map[string]interface{}
+	if len(own) > 0 {
+		err = errors.New("查询方法 queryFn 异常退出")
+		func() {
+			//queryFn panic 时也要发布结果，避免等待的调用永远阻塞
+			defer func() {
+				c.multiFlights.finish(repoKeys, calls, isOwned, func(i int) interface{} { return queried[missing[i]] }, err)
+			}()
+			var data interface{}
+			data, err = c.load(ctx, func() (interface{}, error) {
+				return queryFn(own)
+			}, ownKeys...)
+			queried, _ = data.(map[string]interface{})
+		}()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	queryData = make(map[string]interface{}, len(missing))
+	for i, key := range missing {
+		if isOwned[i] {
+			if data, ok := queried[key]; ok {
+				queryData[key] = data
+			}
+			continue
+		}
+		select {
+		case <-calls[i].done:
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+		if calls[i].err != nil {
+			return nil, nil, calls[i].err
+		}
+		if calls[i].val != nil {
+			queryData[key] = calls[i].val
+		}
+	}
+	return queryData, owned, nil
+}
@@ -0,0 +1,233 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacher_GetMulti(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, time.Minute)
+	if err := cacher.RegisterType[person](c); err != nil {
+		t.Fatal(err)
+	}
+
+	var calls [][]string
+	query := func(missing []string) (map[string]interface{}, error) {
+		calls = append(calls, append([]string(nil), missing...))
+		data := make(map[string]interface{})
+		for _, key := range missing {
+			if key == "p:none" {
+				continue
+			}
+			data[key] = person{Name: key, Age: len(key)}
+		}
+		return data, nil
+	}
+
+	var m map[string]person
+	if err := c.GetMulti(ctx, []string{"p:a", "p:bb", "p:a", "p:none"}, query, &m); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]person{"p:a": {Name: "p:a", Age: 3}, "p:bb": {Name: "p:bb", Age: 4}}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("GetMulti() map = %v, want %v", m, want)
+	}
+	if len(calls) != 1 || !reflect.DeepEqual(calls[0], []string{"p:a", "p:bb", "p:none"}) {
+		t.Errorf("queryFn calls = %v, want one call with deduplicated keys", calls)
+	}
+
+	//命中的缓存不再查询，只查询新的缓存键和没有保存空缓存的缓存键
+	calls = nil
+	var s []person
+	if err := c.GetMulti(ctx, []string{"p:bb", "p:ccc", "p:none", "p:a"}, query, &s); err != nil {
+		t.Fatal(err)
+	}
+	wantSlice := []person{{Name: "p:bb", Age: 4}, {Name: "p:ccc", Age: 5}, {}, {Name: "p:a", Age: 3}}
+	if !reflect.DeepEqual(s, wantSlice) {
+		t.Errorf("GetMulti() slice = %v, want %v", s, wantSlice)
+	}
+	if len(calls) != 1 {
+		t.Fatalf("queryFn calls = %v, want 1", calls)
+	}
+	sort.Strings(calls[0])
+	if !reflect.DeepEqual(calls[0], []string{"p:ccc", "p:none"}) {
+		t.Errorf("queryFn missing = %v, want [p:ccc p:none]", calls[0])
+	}
+
+	//全部命中时不调用 queryFn
+	calls = nil
+	if err := c.GetMulti(ctx, []string{"p:a", "p:ccc"}, query, &s); err != nil || len(calls) != 0 {
+		t.Errorf("GetMulti() = %v, queryFn calls = %v, want no calls", err, calls)
+	}
+}
+
+func TestCacher_GetMulti_Error(t *testing.T) {
+	ctx := context.Background()
+	errQuery := errors.New("query failed")
+	query := func(missing []string) (map[string]interface{}, error) { return nil, errQuery }
+	tests := []struct {
+		name    string
+		keys    []string
+		query   func(missing []string) (map[string]interface{}, error)
+		dst     interface{}
+		wantErr error
+	}{
+		{name: "nil query", keys: []string{"a"}, dst: &map[string]int{}},
+		{name: "empty key", keys: []string{""}, query: query, dst: &map[string]int{}},
+		{name: "not pointer", keys: []string{"a"}, query: query, dst: map[string]int{}},
+		{name: "not map or slice", keys: []string{"a"}, query: query, dst: new(int)},
+		{name: "map key not string", keys: []string{"a"}, query: query, dst: &map[int]int{}},
+		{name: "query error", keys: []string{"a"}, query: query, dst: &map[string]int{}, wantErr: errQuery},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := cacher.New(newRepoMap(nil), time.Minute)
+			err := c.GetMulti(ctx, tt.keys, tt.query, tt.dst)
+			if err == nil || (tt.wantErr != nil && !errors.Is(err, tt.wantErr)) {
+				t.Errorf("GetMulti() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCacher_GetMulti_Parallel(t *testing.T) {
+	ctx := context.Background()
+	data := make(map[string]interface{})
+	var keys []string
+	for i := 0; i < 200; i++ {
+		key := "n:" + strconv.Itoa(i)
+		keys = append(keys, key)
+		data[key] = strconv.Itoa(i)
+	}
+	data["n:150"] = "x"
+	c := cacher.New(newRepoMap(data), time.Minute)
+	query := func(missing []string) (map[string]interface{}, error) { return nil, nil }
+
+	//并发解码的结果仍然与 keys 一一对应
+	var s []int
+	if err := c.GetMulti(ctx, keys[:150], query, &s); err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range s {
+		if v != i {
+			t.Fatalf("GetMulti()[%d] = %d, want %d", i, v, i)
+		}
+	}
+	if err := c.GetMulti(ctx, keys, query, &s); err == nil || !strings.Contains(err.Error(), "n:150") {
+		t.Errorf("GetMulti() error = %v, want error of n:150", err)
+	}
+}
+
+func TestCacher_GetMulti_Flight(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	var (
+		mu      sync.Mutex
+		calls   [][]string
+		entered = make(chan struct{})
+		release = make(chan struct{})
+	)
+	queryFn := func(missing []string) (map[string]interface{}, error) {
+		mu.Lock()
+		calls = append(calls, missing)
+		first := len(calls) == 1
+		mu.Unlock()
+		if first {
+			close(entered)
+			<-release
+		}
+		data := make(map[string]interface{}, len(missing))
+		for _, key := range missing {
+			data[key] = strings.ToUpper(key)
+		}
+		return data, nil
+	}
+
+	results := make([]map[string]string, 3)
+	var wg sync.WaitGroup
+	get := func(i int, keys ...string) {
+		defer wg.Done()
+		if err := c.GetMulti(ctx, keys, queryFn, &results[i]); err != nil {
+			t.Error(err)
+		}
+	}
+	wg.Add(1)
+	go get(0, "a", "b")
+	<-entered
+	//a、b 正在查询，只查询 c
+	wg.Add(2)
+	go get(1, "b", "c")
+	go get(2, "a", "b")
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if want := [][]string{{"a", "b"}, {"c"}}; !reflect.DeepEqual(calls, want) {
+		t.Errorf("queryFn calls = %v, want %v", calls, want)
+	}
+	wants := []map[string]string{{"a": "A", "b": "B"}, {"b": "B", "c": "C"}, {"a": "A", "b": "B"}}
+	if !reflect.DeepEqual(results, wants) {
+		t.Errorf("results = %v, want %v", results, wants)
+	}
+}
+
+func TestCacher_GetMulti_FlightWithGet(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	var (
+		loads   int32
+		entered = make(chan struct{})
+		release = make(chan struct{})
+	)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		var v string
+		if _, err := c.Get(ctx, "a", func() (interface{}, error) {
+			atomic.AddInt32(&loads, 1)
+			close(entered)
+			<-release
+			return "A", nil
+		}, &v); err != nil || v != "A" {
+			t.Errorf("Get() = %v, %v", v, err)
+		}
+	}()
+	<-entered
+	//a 正在被 Get 查询，GetMulti 只查询 b
+	var got map[string]string
+	go func() {
+		defer wg.Done()
+		if err := c.GetMulti(ctx, []string{"a", "b"}, func(missing []string) (map[string]interface{}, error) {
+			atomic.AddInt32(&loads, int32(len(missing)))
+			data := make(map[string]interface{}, len(missing))
+			for _, key := range missing {
+				data[key] = strings.ToUpper(key)
+			}
+			return data, nil
+		}, &got); err != nil {
+			t.Error(err)
+		}
+	}()
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := atomic.LoadInt32(&loads); n != 2 {
+		t.Errorf("loads = %d, want 2 (a once, b once)", n)
+	}
+	if want := map[string]string{"a": "A", "b": "B"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("GetMulti() = %v, want %v", got, want)
+	}
+}
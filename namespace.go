@@ -0,0 +1,71 @@
+package cacher
+
+import (
+	"context"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// namespaceGenKeyPrefix 命名空间代数的缓存键前缀
+const namespaceGenKeyPrefix = "cacher:ns:"
+
+// BumpNamespace 递增命名空间的代数，使该命名空间下的所有缓存立即失效。
+// 代数嵌入在缓存键中，失效时不需要扫描缓存键，旧代数的缓存等待自然过期。
+// 代数以 0 作为保留时长保存，存储库应将小于等于 0 的保留时长视为永不过期
+func (c *Cacher) BumpNamespace(ctx context.Context, ns string) error {
+	_, err := c.newNamespaceGen(ctx, ns)
+	return err
+}
+
+// NamespaceKey 返回命名空间 ns 中缓存键 key 在存储库中实际使用的键，
+// 用于在 Get/GetWithOption 之外直接操作该缓存，例如 Del。设置了 Option.KeyHMAC 时，存储库中使用该键的 HMAC。
+// 不应用 Option.KeyPolicy：设置了 KeyPolicy 时，Del 会再次规范化包括命名空间在内的整个键，应当通过 GetKey、DelEntity 读写命名空间中的缓存
+func (c *Cacher) NamespaceKey(ctx context.Context, ns, key string) (string, error) {
+	gen, err := c.namespaceGen(ctx, ns)
+	if err != nil {
+		return "", err
+	}
+	return ns + ":" + gen + ":" + key, nil
+}
+
+// buildKey 根据选项生成存储库中实际使用的键
+func (c *Cacher) buildKey(ctx context.Context, key string, opt Option) (string, error) {
+	key, err := opt.KeyPolicy.apply(key)
+	if err != nil {
+		return "", err
+	}
+	if opt.Namespace != "" {
+		if key, err = c.NamespaceKey(ctx, opt.Namespace, key); err != nil {
+			return "", err
+		}
+	}
+	return hideKey(key, opt), nil
+}
+
+// namespaceGen 查询命名空间的当前代数，不存在时创建
+func (c *Cacher) namespaceGen(ctx context.Context, ns string) (string, error) {
+	data, err := c.repo.Get(ctx, namespaceGenKeyPrefix+ns)
+	if err != nil {
+		return "", err
+	}
+	if data == nil {
+		return c.newNamespaceGen(ctx, ns)
+	}
+	var gen string
+	to := reflect.ValueOf(&gen).Elem()
+	if err := c.assign(reflect.ValueOf(data), to, to.Type(), c.options()); err != nil {
+		return "", err
+	}
+	return gen, nil
+}
+
+// newNamespaceGen 生成并保存新的代数。
+// 代数取当前时间，不需要先读后写，并发递增时后写入的生效
+func (c *Cacher) newNamespaceGen(ctx context.Context, ns string) (string, error) {
+	gen := strconv.FormatInt(time.Now().UnixNano(), 36)
+	if err := c.repo.Set(ctx, namespaceGenKeyPrefix+ns, gen, 0); err != nil {
+		return "", err
+	}
+	return gen, nil
+}
@@ -0,0 +1,55 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestCacher_BumpNamespace(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	calls := 0
+	get := func() (bool, string) {
+		var v string
+		useCache, err := c.GetWithOption(ctx, "k", func() (interface{}, error) {
+			calls++
+			return "v", nil
+		}, &v, func(opt *cacher.Option) {
+			opt.Namespace = "user"
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return useCache, v
+	}
+
+	if useCache, v := get(); useCache || v != "v" {
+		t.Errorf("first get = %v, %v", useCache, v)
+	}
+	if useCache, _ := get(); !useCache {
+		t.Errorf("second get should use cache")
+	}
+	key, err := c.NamespaceKey(ctx, "user", "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.BumpNamespace(ctx, "user"); err != nil {
+		t.Fatal(err)
+	}
+	if useCache, _ := get(); useCache {
+		t.Errorf("get after bump should not use cache")
+	}
+	if calls != 2 {
+		t.Errorf("queryFunc calls = %d, want 2", calls)
+	}
+	newKey, err := c.NamespaceKey(ctx, "user", "k")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key == newKey {
+		t.Errorf("NamespaceKey() not changed after bump: %v", key)
+	}
+}
@@ -0,0 +1,60 @@
+package cacher
+
+import (
+	"errors"
+	"reflect"
+)
+
+// ErrNotFound 查询数据为空。Option.OnNil 为 NilNotFound 时返回
+var ErrNotFound = errors.New("数据不存在")
+
+// NilAction 查询方法返回 nil, nil 时的处理方式
+type NilAction int
+
+const (
+	// NilDefault 默认处理方式：NilCacheExpire 大于0时保存空缓存并返回空缓存数据，否则不修改 v，返回 false, nil
+	NilDefault NilAction = iota
+	// NilCache 总是保存空缓存，NilCacheExpire 为0时使用 Expire 作为空缓存保留时长
+	NilCache
+	// NilNotFound 不保存空缓存，返回 ErrNotFound
+	NilNotFound
+)
+
+// nilCacheMarker 空缓存的标记。没有设置 Option.NilData 时，保存空缓存写入该标记而不是零值，读取时赋值目标类型的零值。
+// 与错误缓存一样使用专门的标记，查询到并保存的零值不会被当作空缓存
+const nilCacheMarker = "\x00cacher:nil"
+
+// isNilMarker 缓存数据是否为空缓存的标记
+func isNilMarker(from reflect.Value) bool {
+	switch {
+	case !from.IsValid():
+		return false
+	case from.Kind() == reflect.String:
+		return from.String() == nilCacheMarker
+	case isBytes(from.Type()):
+		return string(from.Bytes()) == nilCacheMarker
+	}
+	return false
+}
+
+// assignNil 命中空缓存的标记，赋值零值；本次调用设置了 Option.NilData 时，将 NilData 转换为 toType 类型后赋值
+func assignNil(to reflect.Value, toType reflect.Type, opt Option) error {
+	nilData := reflect.ValueOf(opt.NilData)
+	switch {
+	case !nilData.IsValid() && toType == nil:
+		to.Set(reflect.Zero(to.Type()))
+	case !nilData.IsValid():
+		to.Set(reflect.Zero(toType))
+	case toType == nil:
+		//目标是 nil 接口，且没有指定目标类型，直接赋值空缓存数据
+		if !nilData.Type().AssignableTo(to.Type()) {
+			return &ConversionError{From: nilData.Type(), To: to.Type()}
+		}
+		to.Set(nilData)
+	case nilData.Type().ConvertibleTo(toType):
+		to.Set(nilData.Convert(toType))
+	default:
+		return &ConversionError{From: nilData.Type(), To: toType}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_OnNil(t *testing.T) {
+	tests := []struct {
+		name         string
+		onNil        cacher.NilAction
+		nilExpire    time.Duration
+		wantErr      error
+		wantCached   bool
+		wantUseCache bool
+	}{
+		{name: "默认：不保存空缓存", onNil: cacher.NilDefault},
+		{name: "默认：保存空缓存", onNil: cacher.NilDefault, nilExpire: time.Second, wantCached: true, wantUseCache: true},
+		{name: "总是保存空缓存", onNil: cacher.NilCache, wantCached: true, wantUseCache: true},
+		{name: "返回 ErrNotFound", onNil: cacher.NilNotFound, nilExpire: time.Second, wantErr: cacher.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepoMap(nil)
+			c := cacher.New(repo, 10*time.Second)
+			get := func() (bool, error) {
+				var v person
+				return c.GetWithOption(ctx, "k", func() (interface{}, error) {
+					return nil, nil
+				}, &v, func(opt *cacher.Option) {
+					opt.OnNil = tt.onNil
+					opt.NilCacheExpire = tt.nilExpire
+				})
+			}
+			if _, err := get(); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Get() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if val, _ := repo.Get(ctx, "k"); (val != nil) != tt.wantCached {
+				t.Errorf("cached = %v, wantCached %v", val, tt.wantCached)
+			}
+			if useCache, _ := get(); useCache != tt.wantUseCache {
+				t.Errorf("second Get() useCache = %v, want %v", useCache, tt.wantUseCache)
+			}
+		})
+	}
+}
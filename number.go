@@ -0,0 +1,81 @@
+package cacher
+
+import (
+	"encoding/binary"
+	"math"
+	"reflect"
+)
+
+// 定长二进制数值的首字节，标识数值类型。十进制字符串不会以这些字节开头，读取时可以与文本数据区分
+const (
+	numberInt   byte = 0xf8 //int64，后跟8字节大端序
+	numberUint  byte = 0xf9 //uint64，后跟8字节大端序
+	numberFloat byte = 0xfa //float64，后跟8字节大端序的 IEEE 754 表示
+	numberBool  byte = 0xfb //bool，后跟1字节
+)
+
+// encodeNumber 将整数、浮点数、布尔类型的数据编码为定长二进制，其他类型原样返回，见 Option.BinaryNumbers
+func encodeNumber(value interface{}) interface{} {
+	v := reflect.ValueOf(value)
+	var buf []byte
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		buf = make([]byte, 9)
+		buf[0] = numberInt
+		binary.BigEndian.PutUint64(buf[1:], uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		buf = make([]byte, 9)
+		buf[0] = numberUint
+		binary.BigEndian.PutUint64(buf[1:], v.Uint())
+	case reflect.Float32, reflect.Float64:
+		buf = make([]byte, 9)
+		buf[0] = numberFloat
+		binary.BigEndian.PutUint64(buf[1:], math.Float64bits(v.Float()))
+	case reflect.Bool:
+		buf = []byte{numberBool, 0}
+		if v.Bool() {
+			buf[1] = 1
+		}
+	default:
+		return value
+	}
+	return buf
+}
+
+// decodeNumber 目标类型是数值、布尔类型或未知时，解码 encodeNumber 编码的字符串、字节切片，
+// 不是定长二进制数值时返回 false。目标是字符串、字节切片等类型时不解码，以免误读恰好以标识字节开头的数据
+func decodeNumber(from reflect.Value, toType reflect.Type) (reflect.Value, bool) {
+	if toType != nil {
+		switch toType.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+			reflect.Float32, reflect.Float64, reflect.Bool:
+		default:
+			return from, false
+		}
+	}
+	var data []byte
+	switch {
+	case from.Kind() == reflect.String:
+		s := from.String()
+		if len(s) != 9 && len(s) != 2 || s[0] < numberInt || s[0] > numberBool {
+			return from, false
+		}
+		data = []byte(s)
+	case isBytes(from.Type()):
+		data = from.Bytes()
+	default:
+		return from, false
+	}
+	switch {
+	case len(data) == 9 && data[0] == numberInt:
+		return reflect.ValueOf(int64(binary.BigEndian.Uint64(data[1:]))), true
+	case len(data) == 9 && data[0] == numberUint:
+		return reflect.ValueOf(binary.BigEndian.Uint64(data[1:])), true
+	case len(data) == 9 && data[0] == numberFloat:
+		return reflect.ValueOf(math.Float64frombits(binary.BigEndian.Uint64(data[1:]))), true
+	case len(data) == 2 && data[0] == numberBool && data[1] <= 1:
+		return reflect.ValueOf(data[1] == 1), true
+	}
+	return from, false
+}
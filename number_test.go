@@ -0,0 +1,85 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestOption_BinaryNumbers(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     interface{}
+		v        interface{}
+		wantSize int
+	}{
+		{name: "int", data: 42, v: new(int), wantSize: 9},
+		{name: "负数 int8", data: int8(-7), v: new(int8), wantSize: 9},
+		{name: "uint64 最大值", data: uint64(math.MaxUint64), v: new(uint64), wantSize: 9},
+		{name: "float64", data: 3.25, v: new(float64), wantSize: 9},
+		{name: "float32", data: float32(1.5), v: new(float32), wantSize: 9},
+		{name: "bool", data: true, v: new(bool), wantSize: 2},
+		{name: "字符串不编码", data: "42", v: new(string), wantSize: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := cacher.NewMemoryRepo()
+			c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+				opt.BinaryNumbers = true
+			})
+			for i := 0; i < 2; i++ {
+				useCache, err := c.Get(ctx, "n", func() (interface{}, error) { return tt.data, nil }, tt.v)
+				if err != nil || useCache != (i == 1) {
+					t.Fatalf("Get() #%d = %v, %v", i, useCache, err)
+				}
+				if got := reflect.ValueOf(tt.v).Elem().Interface(); got != tt.data {
+					t.Errorf("v #%d = %v, want %v", i, got, tt.data)
+				}
+			}
+			raw, _ := repo.Get(ctx, "n")
+			if size := reflect.ValueOf(raw).Len(); size != tt.wantSize {
+				t.Errorf("stored size = %d, want %d", size, tt.wantSize)
+			}
+		})
+	}
+}
+
+func TestOption_BinaryNumbers_Read(t *testing.T) {
+	ctx := context.Background()
+	encoded := cacher.NewMemoryRepo()
+	c := cacher.New(encoded, time.Minute, func(opt *cacher.Option) {
+		opt.BinaryNumbers = true
+	})
+	var n int64
+	if _, err := c.Get(ctx, "n", func() (interface{}, error) { return int64(12345678), nil }, &n); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := encoded.Get(ctx, "n")
+
+	//存储库按字符串返回、未开启选项时也能读取
+	reader := cacher.New(newRepoMap(map[string]interface{}{"n": string(raw.([]byte)), "text": "12345678", "raw": raw}), time.Minute)
+	tests := []struct {
+		name string
+		key  string
+		v    interface{}
+		want interface{}
+	}{
+		{name: "字符串形式的二进制数值", key: "n", v: new(int64), want: int64(12345678)},
+		{name: "十进制字符串", key: "text", v: new(int), want: 12345678},
+		{name: "目标是字节切片时不解码", key: "raw", v: new([]byte), want: raw},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if ok, err := reader.Peek(ctx, tt.key, tt.v); !ok || err != nil {
+				t.Fatalf("Peek() = %v, %v", ok, err)
+			}
+			if got := reflect.ValueOf(tt.v).Elem().Interface(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("v = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package cacher
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidOption 选项错误，Option.Valid 返回的错误都可以通过 errors.Is(err, ErrInvalidOption) 判断
+var ErrInvalidOption = errors.New("选项错误")
+
+// OptionError 选项错误，记录出错的字段和原因
+type OptionError struct {
+	Field  string //出错的字段
+	Reason string //出错原因
+}
+
+func (e *OptionError) Error() string {
+	return "选项 " + e.Field + " 错误: " + e.Reason
+}
+
+// Is 使 errors.Is(err, ErrInvalidOption) 成立
+func (e *OptionError) Is(target error) bool {
+	return target == ErrInvalidOption
+}
+
+// WithNilCache 设置空缓存策略：查询数据为空时，以 nilData 作为空缓存数据保存 expire 时长。
+// nilData 为 nil 时保存目标类型的零值；expire 小于等于0时不保存空缓存。
+// 可以传给 New 作为默认策略，也可以传给 GetWithOption 覆盖默认策略
+func WithNilCache(expire time.Duration, nilData interface{}) func(opt *Option) {
+	return func(opt *Option) {
+		if expire <= 0 {
+			opt.NilCacheExpire = 0
+			opt.NilData = nil
+			return
+		}
+		opt.NilCacheExpire = expire
+		opt.NilData = nilData
+	}
+}
+
+// WithTargetType 目标变量 v 指向接口时，指定转换的目标类型，例如 WithTargetType(Person{})
+func WithTargetType(t interface{}) func(opt *Option) {
+	return func(opt *Option) {
+		opt.TargetType = t
+	}
+}
@@ -0,0 +1,96 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestOption_Valid(t *testing.T) {
+	tests := []struct {
+		name      string
+		opt       cacher.Option
+		wantField string
+	}{
+		{name: "正确", opt: cacher.Option{Expire: time.Second}},
+		{name: "空缓存", opt: cacher.Option{Expire: time.Second, NilData: "", NilCacheExpire: time.Second}},
+		{name: "Expire 为0，使用默认保留时长", opt: cacher.Option{}},
+		{name: "Expire 小于0", opt: cacher.Option{Expire: -time.Second}, wantField: "Expire"},
+		{name: "NilCacheExpire 小于0", opt: cacher.Option{Expire: time.Second, NilCacheExpire: -1}, wantField: "NilCacheExpire"},
+		{name: "设置 NilData 但不保存空缓存", opt: cacher.Option{Expire: time.Second, NilData: ""}, wantField: "NilData"},
+		{name: "ErrCacheExpire 小于0", opt: cacher.Option{Expire: time.Second, ErrCacheExpire: -1}, wantField: "ErrCacheExpire"},
+		{name: "Jitter 大于1", opt: cacher.Option{Expire: time.Second, Jitter: 2}, wantField: "Jitter"},
+		{name: "Jitter 为 NaN", opt: cacher.Option{Expire: time.Second, Jitter: math.NaN()}, wantField: "Jitter"},
+		{name: "转换器缺少 Fn", opt: cacher.Option{Expire: time.Second, Converters: []cacher.TypeConverter{{SrcType: "", DstType: 0}}}, wantField: "Converters[0]"},
+		{name: "依赖空缓存键", opt: cacher.Option{Expire: time.Second, DependsOn: []string{"a", ""}}, wantField: "DependsOn[1]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.opt.Valid()
+			if tt.wantField == "" {
+				if err != nil {
+					t.Errorf("Valid() = %v, want nil", err)
+				}
+				return
+			}
+			var optErr *cacher.OptionError
+			if !errors.As(err, &optErr) || optErr.Field != tt.wantField {
+				t.Errorf("Valid() = %v, want field %v", err, tt.wantField)
+			}
+			if !errors.Is(err, cacher.ErrInvalidOption) {
+				t.Errorf("Valid() = %v, want ErrInvalidOption", err)
+			}
+		})
+	}
+}
+
+func TestNew_DefaultNilCache(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, 10*time.Second, cacher.WithNilCache(time.Second, "<nil>"))
+	query := func() (interface{}, error) { return nil, nil }
+
+	var v string
+	if _, err := c.Get(ctx, "default", query, &v); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := repo.Get(ctx, "default"); val != "<nil>" {
+		t.Errorf("default nil cache = %v, want <nil>", val)
+	}
+	//单次调用覆盖默认策略
+	if _, err := c.GetWithOption(ctx, "override", query, &v, cacher.WithNilCache(0, nil)); err != nil {
+		t.Fatal(err)
+	}
+	if val, _ := repo.Get(ctx, "override"); val != nil {
+		t.Errorf("override nil cache = %v, want not cached", val)
+	}
+}
+
+func TestOption_CloneSlices(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, 10*time.Second, func(opt *cacher.Option) {
+		opt.Tags = make([]string, 1, 4)
+		opt.Tags[0] = "default"
+	})
+	var seen [][]string
+	c.AddInvalidator(cacher.InvalidatorFunc(func(ctx context.Context, keys []string, tags []string) error {
+		seen = append(seen, tags)
+		return nil
+	}))
+	for _, tag := range []string{"a", "b"} {
+		tag := tag
+		if err := c.Write(ctx, tag, "v", func(ctx context.Context) error { return nil }, func(opt *cacher.Option) {
+			opt.WriteMode = cacher.WriteThrough
+			opt.Tags = append(opt.Tags, tag)
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(seen) != 2 || seen[0][1] != "a" || seen[1][1] != "b" {
+		t.Errorf("tags = %v, want [[default a] [default b]]", seen)
+	}
+}
@@ -0,0 +1,184 @@
+package cacher
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// outboxBatch 每次转发的最大记录数
+const outboxBatch = 100
+
+type (
+	// OutboxEntry 发件箱中待删除的缓存
+	OutboxEntry struct {
+		ID  int64  //记录 ID，按写入顺序递增
+		Key string //缓存键，同 Cacher.Del 的参数
+	}
+	// OutboxStore 发件箱存储，记录与业务数据在同一个事务中写入，由 Cacher.RelayOutbox 转发
+	OutboxStore interface {
+		// Pending 按写入顺序获取最多 limit 条待转发的记录
+		Pending(ctx context.Context, limit int) ([]OutboxEntry, error)
+		// Done 删除已转发的记录
+		Done(ctx context.Context, ids ...int64) error
+	}
+	// SQLOutbox 基于 database/sql 的发件箱，表结构（以 MySQL 为例）：
+	//
+	//	CREATE TABLE cacher_outbox (
+	//		id         BIGINT AUTO_INCREMENT PRIMARY KEY,
+	//		cache_key  VARCHAR(512) NOT NULL,
+	//		created_at TIMESTAMP NOT NULL
+	//	);
+	SQLOutbox struct {
+		db          *sql.DB
+		table       string
+		placeholder func(n int) string
+	}
+	// SQLOutboxOption SQLOutbox 的选项
+	SQLOutboxOption struct {
+		Table       string             //表名，默认为 cacher_outbox
+		Placeholder func(n int) string //第 n 个（从1开始）参数的占位符，默认为 ?，PostgreSQL 使用 DollarPlaceholder
+	}
+)
+
+var _ OutboxStore = (*SQLOutbox)(nil)
+
+// DollarPlaceholder PostgreSQL 风格的参数占位符 $n
+func DollarPlaceholder(n int) string {
+	return "$" + strconv.Itoa(n)
+}
+
+// WithOutboxTable 设置发件箱的表名
+func WithOutboxTable(table string) func(opt *SQLOutboxOption) {
+	return func(opt *SQLOutboxOption) {
+		opt.Table = table
+	}
+}
+
+// WithOutboxPlaceholder 设置参数占位符
+func WithOutboxPlaceholder(placeholder func(n int) string) func(opt *SQLOutboxOption) {
+	return func(opt *SQLOutboxOption) {
+		opt.Placeholder = placeholder
+	}
+}
+
+// NewSQLOutbox 创建基于 database/sql 的发件箱，db 用于转发时读取和删除记录
+func NewSQLOutbox(db *sql.DB, optFns ...func(opt *SQLOutboxOption)) *SQLOutbox {
+	opt := SQLOutboxOption{Table: "cacher_outbox", Placeholder: func(int) string { return "?" }}
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	return &SQLOutbox{db: db, table: opt.Table, placeholder: opt.Placeholder}
+}
+
+// Add 在业务事务 tx 中记录待删除的缓存键，事务提交后由 Cacher.RelayOutbox 删除缓存。
+// 事务回滚时记录一起回滚，进程在提交后崩溃时记录仍然保留，重启后继续删除
+func (o *SQLOutbox) Add(ctx context.Context, tx *sql.Tx, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	values := make([]string, len(keys))
+	args := make([]interface{}, 0, 2*len(keys))
+	now := time.Now()
+	for i, key := range keys {
+		values[i] = "(" + o.placeholder(2*i+1) + ", " + o.placeholder(2*i+2) + ")"
+		args = append(args, key, now)
+	}
+	query := "INSERT INTO " + o.table + " (cache_key, created_at) VALUES " + strings.Join(values, ", ")
+	_, err := tx.ExecContext(ctx, query, args...)
+	return err
+}
+
+// Pending 按写入顺序获取最多 limit 条待转发的记录
+func (o *SQLOutbox) Pending(ctx context.Context, limit int) ([]OutboxEntry, error) {
+	query := "SELECT id, cache_key FROM " + o.table + " ORDER BY id LIMIT " + strconv.Itoa(limit)
+	rows, err := o.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var entries []OutboxEntry
+	for rows.Next() {
+		var entry OutboxEntry
+		if err := rows.Scan(&entry.ID, &entry.Key); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+	return entries, rows.Err()
+}
+
+// Done 删除已转发的记录
+func (o *SQLOutbox) Done(ctx context.Context, ids ...int64) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	placeholders := make([]string, len(ids))
+	args := make([]interface{}, len(ids))
+	for i, id := range ids {
+		placeholders[i] = o.placeholder(i + 1)
+		args[i] = id
+	}
+	query := "DELETE FROM " + o.table + " WHERE id IN (" + strings.Join(placeholders, ", ") + ")"
+	_, err := o.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+// RelayOutboxOnce 转发发件箱中的全部记录：删除记录的缓存后删除记录，返回转发的记录数。
+// 删除缓存出错时保留记录，下次重试
+func (c *Cacher) RelayOutboxOnce(ctx context.Context, store OutboxStore) (int, error) {
+	if store == nil {
+		return 0, errors.New("发件箱 store 不能为空")
+	}
+	relayed := 0
+	for {
+		entries, err := store.Pending(ctx, outboxBatch)
+		if err != nil {
+			return relayed, err
+		}
+		if len(entries) == 0 {
+			return relayed, nil
+		}
+		ids := make([]int64, 0, len(entries))
+		for _, entry := range entries {
+			if err := c.Del(ctx, entry.Key); err != nil {
+				//已删除的缓存先确认，避免重复删除
+				if doneErr := store.Done(ctx, ids...); doneErr != nil {
+					return relayed, doneErr
+				}
+				return relayed + len(ids), fmt.Errorf("删除缓存 %s 失败：%w", entry.Key, err)
+			}
+			ids = append(ids, entry.ID)
+		}
+		if err := store.Done(ctx, ids...); err != nil {
+			return relayed, err
+		}
+		relayed += len(ids)
+		if len(entries) < outboxBatch {
+			return relayed, nil
+		}
+	}
+}
+
+// RelayOutbox 每隔 interval 转发一次发件箱，出错时触发 EventError 事件并在下次重试。阻塞直到 ctx 结束
+func (c *Cacher) RelayOutbox(ctx context.Context, store OutboxStore, interval time.Duration) error {
+	if interval <= 0 {
+		return errors.New("转发间隔 interval 必须大于0")
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := c.RelayOutboxOnce(ctx, store); err != nil && ctx.Err() == nil {
+			c.emitError("outbox", "", err)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,248 @@
+package cacher_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"github.com/carteruu/cacher"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeOutboxDB 只支持 SQLOutbox 语句的内存数据库
+type fakeOutboxDB struct {
+	mu      sync.Mutex
+	seq     int64
+	rows    []cacher.OutboxEntry
+	queries []string
+}
+
+type fakeOutboxConn struct {
+	db      *fakeOutboxDB
+	pending []cacher.OutboxEntry //未提交的事务中写入的记录
+}
+
+type fakeOutboxStmt struct {
+	conn  *fakeOutboxConn
+	query string
+}
+
+type fakeOutboxRows struct {
+	entries []cacher.OutboxEntry
+}
+
+var fakeOutboxDBs sync.Map
+
+func init() {
+	sql.Register("cacher-outbox-fake", fakeOutboxDriver{})
+}
+
+type fakeOutboxDriver struct{}
+
+func (fakeOutboxDriver) Open(name string) (driver.Conn, error) {
+	db, _ := fakeOutboxDBs.Load(name)
+	return &fakeOutboxConn{db: db.(*fakeOutboxDB)}, nil
+}
+
+func (c *fakeOutboxConn) Prepare(query string) (driver.Stmt, error) {
+	c.db.mu.Lock()
+	c.db.queries = append(c.db.queries, query)
+	c.db.mu.Unlock()
+	return &fakeOutboxStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeOutboxConn) Close() error { return nil }
+func (c *fakeOutboxConn) Begin() (driver.Tx, error) {
+	c.pending = []cacher.OutboxEntry{}
+	return c, nil
+}
+
+func (c *fakeOutboxConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	c.db.rows = append(c.db.rows, c.pending...)
+	c.pending = nil
+	return nil
+}
+
+func (c *fakeOutboxConn) Rollback() error {
+	c.pending = nil
+	return nil
+}
+
+func (s *fakeOutboxStmt) Close() error  { return nil }
+func (s *fakeOutboxStmt) NumInput() int { return -1 }
+
+func (s *fakeOutboxStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	switch {
+	case strings.HasPrefix(s.query, "INSERT"):
+		for i := 0; i < len(args); i += 2 {
+			db.seq++
+			entry := cacher.OutboxEntry{ID: db.seq, Key: args[i].(string)}
+			if s.conn.pending != nil {
+				s.conn.pending = append(s.conn.pending, entry)
+			} else {
+				db.rows = append(db.rows, entry)
+			}
+		}
+	case strings.HasPrefix(s.query, "DELETE"):
+		done := make(map[int64]bool, len(args))
+		for _, arg := range args {
+			done[arg.(int64)] = true
+		}
+		rows := db.rows[:0]
+		for _, row := range db.rows {
+			if !done[row.ID] {
+				rows = append(rows, row)
+			}
+		}
+		db.rows = rows
+	}
+	return driver.RowsAffected(len(args)), nil
+}
+
+func (s *fakeOutboxStmt) Query(args []driver.Value) (driver.Rows, error) {
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	limit, _ := strconv.Atoi(s.query[strings.LastIndex(s.query, " ")+1:])
+	entries := append([]cacher.OutboxEntry(nil), db.rows...)
+	if len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return &fakeOutboxRows{entries: entries}, nil
+}
+
+func (r *fakeOutboxRows) Columns() []string { return []string{"id", "cache_key"} }
+func (r *fakeOutboxRows) Close() error      { return nil }
+
+func (r *fakeOutboxRows) Next(dest []driver.Value) error {
+	if len(r.entries) == 0 {
+		return io.EOF
+	}
+	dest[0], dest[1] = r.entries[0].ID, r.entries[0].Key
+	r.entries = r.entries[1:]
+	return nil
+}
+
+func newFakeOutboxDB(t *testing.T) (*sql.DB, *fakeOutboxDB) {
+	fake := &fakeOutboxDB{}
+	fakeOutboxDBs.Store(t.Name(), fake)
+	db, err := sql.Open("cacher-outbox-fake", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+	return db, fake
+}
+
+func TestSQLOutbox(t *testing.T) {
+	ctx := context.Background()
+	db, fake := newFakeOutboxDB(t)
+	outbox := cacher.NewSQLOutbox(db, cacher.WithOutboxPlaceholder(cacher.DollarPlaceholder))
+	repo := newRepoMap(map[string]interface{}{"a": "1", "b": "2", "c": "3"})
+	c := cacher.New(repo, time.Minute)
+
+	tests := []struct {
+		name   string
+		keys   []string
+		commit bool
+	}{
+		{name: "提交的事务", keys: []string{"a", "b"}, commit: true},
+		{name: "回滚的事务", keys: []string{"c"}},
+	}
+	for _, tt := range tests {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := outbox.Add(ctx, tx, tt.keys...); err != nil {
+			t.Fatalf("%s: Add() = %v", tt.name, err)
+		}
+		if tt.commit {
+			err = tx.Commit()
+		} else {
+			err = tx.Rollback()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if want := "INSERT INTO cacher_outbox (cache_key, created_at) VALUES ($1, $2), ($3, $4)"; fake.queries[0] != want {
+		t.Errorf("query = %q, want %q", fake.queries[0], want)
+	}
+
+	n, err := c.RelayOutboxOnce(ctx, outbox)
+	if err != nil || n != 2 {
+		t.Fatalf("RelayOutboxOnce() = %d, %v, want 2", n, err)
+	}
+	for key, wantCached := range map[string]bool{"a": false, "b": false, "c": true} {
+		if _, ok := repo.data[key]; ok != wantCached {
+			t.Errorf("cached %s = %v, want %v", key, ok, wantCached)
+		}
+	}
+	if len(fake.rows) != 0 {
+		t.Errorf("outbox rows after relay = %v, want empty", fake.rows)
+	}
+}
+
+// memoryOutbox 测试用的内存发件箱
+type memoryOutbox struct {
+	mu      sync.Mutex
+	entries []cacher.OutboxEntry
+}
+
+func (o *memoryOutbox) Pending(ctx context.Context, limit int) ([]cacher.OutboxEntry, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.entries) > limit {
+		return append([]cacher.OutboxEntry(nil), o.entries[:limit]...), nil
+	}
+	return append([]cacher.OutboxEntry(nil), o.entries...), nil
+}
+
+func (o *memoryOutbox) Done(ctx context.Context, ids ...int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.entries = o.entries[len(ids):]
+	return nil
+}
+
+func TestCacher_RelayOutbox(t *testing.T) {
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, time.Minute)
+	outbox := &memoryOutbox{}
+	for i := 0; i < 250; i++ {
+		key := strconv.Itoa(i)
+		repo.data[key] = "v"
+		outbox.entries = append(outbox.entries, cacher.OutboxEntry{ID: int64(i + 1), Key: key})
+	}
+
+	//删除缓存出错时保留记录
+	repo.delErr = errors.New("del error")
+	if n, err := c.RelayOutboxOnce(context.Background(), outbox); err == nil || n != 0 || len(outbox.entries) != 250 {
+		t.Errorf("RelayOutboxOnce() with del error = %d, %v, %d entries left", n, err, len(outbox.entries))
+	}
+	repo.delErr = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := c.RelayOutbox(ctx, outbox, 10*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("RelayOutbox() = %v, want deadline exceeded", err)
+	}
+	if len(outbox.entries) != 0 || len(repo.data) != 0 {
+		t.Errorf("after RelayOutbox: %d entries, %d cached", len(outbox.entries), len(repo.data))
+	}
+	if err := c.RelayOutbox(context.Background(), outbox, 0); err == nil {
+		t.Errorf("RelayOutbox() with zero interval want error")
+	}
+}
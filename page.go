@@ -0,0 +1,45 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"strconv"
+)
+
+// pagesNamespacePrefix 分页结果的命名空间前缀，与用户的命名空间区分
+const pagesNamespacePrefix = "cacher:pages:"
+
+// Pages 分页结果的缓存门面，见 Cacher.Pages。
+// 同一个集合的所有分页缓存共享一个代数，集合中的数据变化时调用 Invalidate 递增代数，
+// 所有页立即失效，不需要知道或扫描缓存了哪些页，旧代数的缓存等待自然过期
+type Pages struct {
+	c  *Cacher
+	ns string
+}
+
+// Pages 获取集合 collection（例如 orders、user:42:orders）的分页结果缓存
+func (c *Cacher) Pages(collection string) *Pages {
+	return &Pages{c: c, ns: pagesNamespacePrefix + collection}
+}
+
+// Get 读取一页，不存在时调用 queryFn 查询并缓存，返回值同 GetWithOption。
+// query 标识筛选和排序条件，不同条件的分页分别缓存，没有条件时为空字符串；page 为页码，size 为每页数量。
+// 缓存键的命名空间由 Pages 设置，optFns 中的 Option.Namespace 不生效
+func (p *Pages) Get(ctx context.Context, query string, page, size int, queryFn func() (interface{}, error), v interface{}, optFns ...func(opt *Option)) (bool, error) {
+	if page < 0 {
+		return false, errors.New("页码 page 不能小于0")
+	}
+	if size <= 0 {
+		return false, errors.New("每页数量 size 必须大于0")
+	}
+	key := "page:" + keyEscaper.Replace(query) + ":" + strconv.Itoa(size) + ":" + strconv.Itoa(page)
+	optFns = append(optFns[:len(optFns):len(optFns)], func(opt *Option) {
+		opt.Namespace = p.ns
+	})
+	return p.c.GetWithOption(ctx, key, queryFn, v, optFns...)
+}
+
+// Invalidate 使集合的所有分页缓存失效，在集合中插入、删除或修改数据后调用。只写入一次代数，与缓存的页数无关
+func (p *Pages) Invalidate(ctx context.Context) error {
+	return p.c.BumpNamespace(ctx, p.ns)
+}
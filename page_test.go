@@ -0,0 +1,75 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCacher_Pages(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	records := []int{1, 2, 3, 4, 5}
+	calls := 0
+	get := func(pages *cacher.Pages, query string, page, size int) ([]int, bool) {
+		var v []int
+		useCache, err := pages.Get(ctx, query, page, size, func() (interface{}, error) {
+			calls++
+			start, end := page*size, (page+1)*size
+			if end > len(records) {
+				end = len(records)
+			}
+			return append([]int(nil), records[start:end]...), nil
+		}, &v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return v, useCache
+	}
+
+	orders := c.Pages("orders")
+	if v, useCache := get(orders, "", 0, 2); useCache || !reflect.DeepEqual(v, []int{1, 2}) {
+		t.Errorf("page 0 = %v, %v", v, useCache)
+	}
+	if v, useCache := get(orders, "", 1, 2); useCache || !reflect.DeepEqual(v, []int{3, 4}) {
+		t.Errorf("page 1 = %v, %v", v, useCache)
+	}
+	if _, useCache := get(orders, "", 0, 2); !useCache {
+		t.Errorf("page 0 should use cache")
+	}
+	//不同的条件和每页数量分别缓存
+	if _, useCache := get(orders, "status:paid", 0, 2); useCache {
+		t.Errorf("page with other query should not use cache")
+	}
+	if _, useCache := get(orders, "", 0, 3); useCache {
+		t.Errorf("page with other size should not use cache")
+	}
+	//其他集合不受影响
+	users := c.Pages("users")
+	get(users, "", 0, 2)
+
+	records = append([]int{0}, records...)
+	if err := orders.Invalidate(ctx); err != nil {
+		t.Fatal(err)
+	}
+	calls = 0
+	if v, useCache := get(orders, "", 0, 2); useCache || !reflect.DeepEqual(v, []int{0, 1}) {
+		t.Errorf("page 0 after Invalidate = %v, %v", v, useCache)
+	}
+	if _, useCache := get(orders, "", 1, 2); useCache {
+		t.Errorf("page 1 after Invalidate should not use cache")
+	}
+	if _, useCache := get(users, "", 0, 2); !useCache {
+		t.Errorf("other collection should still use cache")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+
+	var v []int
+	if _, err := orders.Get(ctx, "", 0, 0, func() (interface{}, error) { return nil, nil }, &v); err == nil {
+		t.Error("Get() with size 0 should fail")
+	}
+}
@@ -0,0 +1,37 @@
+package cacher
+
+import (
+	"context"
+	"reflect"
+)
+
+// Peek 只读取并转换缓存数据，不调用查询方法，也不写入缓存，用于健康检查、"是否已缓存"等场景。
+// 返回值：是否存在缓存；缓存的是查询错误时，返回 CachedError
+func (c *Cacher) Peek(ctx context.Context, key string, v interface{}) (bool, error) {
+	if key == "" {
+		return false, ErrEmptyKey
+	}
+	key, err := c.buildKey(ctx, key, c.options())
+	if err != nil {
+		return false, err
+	}
+	cacheData, err := c.repo.Get(ctx, key)
+	if err != nil {
+		return false, err
+	}
+	if cacheData == nil {
+		return false, nil
+	}
+	if err := cachedError(key, cacheData); err != nil {
+		return true, err
+	}
+	to, toType, finish, err := target(v, c.options().TargetType)
+	if err != nil {
+		return false, err
+	}
+	defer finish()
+	if err := c.assign(reflect.ValueOf(cacheData), to, toType, c.options()); err != nil {
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,48 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestCacher_Peek(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(map[string]interface{}{
+		"int":    "12",
+		"person": personObj,
+	})
+	c := cacher.New(repo, 10*time.Second)
+
+	var i int
+	if ok, err := c.Peek(ctx, "int", &i); !ok || err != nil || i != 12 {
+		t.Errorf("Peek() = %v, %v, v = %v", ok, err, i)
+	}
+	var p person
+	if ok, err := c.Peek(ctx, "person", &p); !ok || err != nil || p != personObj {
+		t.Errorf("Peek() = %v, %v, v = %v", ok, err, p)
+	}
+	var s string
+	if ok, err := c.Peek(ctx, "missing", &s); ok || err != nil {
+		t.Errorf("Peek() = %v, %v, want false, nil", ok, err)
+	}
+	if _, ok := repo.data["missing"]; ok {
+		t.Errorf("Peek() must not write the cache")
+	}
+	if _, err := c.Peek(ctx, "", &s); err == nil {
+		t.Errorf("Peek() with empty key want error")
+	}
+
+	//缓存的是查询错误
+	_, _ = c.GetWithOption(ctx, "err", func() (interface{}, error) {
+		return nil, errors.New("down")
+	}, &s, func(opt *cacher.Option) {
+		opt.ErrCacheExpire = time.Second
+	})
+	var cachedErr *cacher.CachedError
+	if ok, err := c.Peek(ctx, "err", &s); !ok || !errors.As(err, &cachedErr) {
+		t.Errorf("Peek() = %v, %v, want CachedError", ok, err)
+	}
+}
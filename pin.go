@@ -0,0 +1,147 @@
+package cacher
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// pinRefreshRatio 固定的缓存在保留时长的该比例时刷新，保证刷新完成前缓存不过期
+const pinRefreshRatio = 0.9
+
+type (
+	// PinRepo 可选的存储库接口，容量有限的进程内存储库实现该接口后，固定的缓存键不会被淘汰
+	PinRepo interface {
+		// Pin 固定缓存键，缓存键不存在时也生效，之后写入的缓存不会被淘汰
+		Pin(ctx context.Context, key string) error
+		// Unpin 取消固定
+		Unpin(ctx context.Context, key string) error
+	}
+	// timerTable 缓存键的刷新定时器，用于 Pin 和 AutoRefresh
+	timerTable struct {
+		mu     sync.Mutex
+		timers map[string]*time.Timer
+	}
+)
+
+// Pin 固定缓存键：立即调用查询方法并写入缓存，之后在缓存过期前自动刷新，存储库实现了 PinRepo 时不会被淘汰。
+// 用于数据量小但必须始终命中的基础数据。刷新出错时通过 EventError 事件发布，并在保留时长的1/10后重试。
+// 已固定的缓存键再次固定时，替换查询方法和选项
+func (c *Cacher) Pin(ctx context.Context, key string, queryFunc func() (interface{}, error), optFns ...func(opt *Option)) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	if queryFunc == nil {
+		return ErrNilQueryFunc
+	}
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if err := opt.Valid(); err != nil {
+		return err
+	}
+	key, err := c.buildKey(ctx, key, opt)
+	if err != nil {
+		return err
+	}
+	if opt.Expire == 0 {
+		opt.Expire = c.defaultExpire()
+	}
+	pr, isPinRepo := c.repo.(PinRepo)
+	if isPinRepo {
+		if err := pr.Pin(ctx, key); err != nil {
+			return err
+		}
+	}
+	if err := c.reload(ctx, key, queryFunc, opt); err != nil {
+		if isPinRepo {
+			_ = pr.Unpin(ctx, key)
+		}
+		return err
+	}
+	c.pins.schedule(key, time.Duration(float64(opt.Expire)*pinRefreshRatio), func(retry func(d time.Duration)) {
+		if err := c.reload(detach(ctx), key, queryFunc, opt); err != nil {
+			c.emitError("pin", key, err)
+			retry(opt.Expire / 10)
+		}
+	})
+	return nil
+}
+
+// Unpin 取消固定缓存键，停止自动刷新。缓存保留到过期，不会删除
+func (c *Cacher) Unpin(ctx context.Context, key string, optFns ...func(opt *Option)) error {
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	key, err := c.buildKey(ctx, key, opt)
+	if err != nil {
+		return err
+	}
+	c.pins.stop(key)
+	if pr, ok := c.repo.(PinRepo); ok {
+		return pr.Unpin(ctx, key)
+	}
+	return nil
+}
+
+// reload 调用查询方法并写入缓存，与其他调用共享平滑查询合并。用于 Pin 和 AutoRefresh
+func (c *Cacher) reload(ctx context.Context, key string, queryFunc func() (interface{}, error), opt Option) error {
+	_, err, _ := c.sf.Do(key, func() (interface{}, error) {
+		data, err := c.load(ctx, queryFunc, key)
+		if err != nil {
+			return nil, err
+		}
+		return c.save(ctx, key, data, nil, opt)
+	})
+	return err
+}
+
+// schedule 每隔 interval 调用 fn，fn 可以通过 retry 让下一次调用提前。替换缓存键原来的定时器
+func (p *timerTable) schedule(key string, interval time.Duration, fn func(retry func(d time.Duration))) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.timers == nil {
+		p.timers = make(map[string]*time.Timer)
+	}
+	if old, ok := p.timers[key]; ok {
+		old.Stop()
+	}
+	var timer *time.Timer
+	timer = time.AfterFunc(interval, func() {
+		next := interval
+		fn(func(d time.Duration) { next = d })
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		//已取消固定或被替换时不再调度
+		if p.timers[key] == timer {
+			timer.Reset(next)
+		}
+	})
+	p.timers[key] = timer
+}
+
+// stop 停止缓存键的定时器
+func (p *timerTable) stop(key string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if timer, ok := p.timers[key]; ok {
+		timer.Stop()
+		delete(p.timers, key)
+	}
+}
+
+// stopAll 停止所有定时器
+func (p *timerTable) stopAll() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for key, timer := range p.timers {
+		timer.Stop()
+		delete(p.timers, key)
+	}
+}
@@ -0,0 +1,85 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacher_Pin(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo(cacher.WithMaxEntries(1))
+	c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.Jitter = -1
+	})
+	defer c.Close()
+	var loads int32
+	query := func() (interface{}, error) {
+		return atomic.AddInt32(&loads, 1), nil
+	}
+	if err := c.Pin(ctx, "table", query, func(opt *cacher.Option) {
+		opt.Expire = 40 * time.Millisecond
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&loads); n != 1 {
+		t.Fatalf("loads after Pin = %d, want 1", n)
+	}
+
+	//固定的缓存不被淘汰
+	var s string
+	for _, key := range []string{"a", "b"} {
+		if _, err := c.Get(ctx, key, func() (interface{}, error) { return key, nil }, &s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if data, _ := repo.Get(ctx, "table"); data == nil {
+		t.Errorf("pinned key evicted")
+	}
+	if data, _ := repo.Get(ctx, "a"); data != nil {
+		t.Errorf("unpinned key a should be evicted")
+	}
+
+	//过期前自动刷新
+	time.Sleep(100 * time.Millisecond)
+	var n int32
+	if ok, err := c.Peek(ctx, "table", &n); !ok || err != nil || n < 2 {
+		t.Errorf("Peek() after refresh = %v, %v, %d, want refreshed value", ok, err, n)
+	}
+
+	//取消固定后不再刷新，并恢复淘汰
+	if err := c.Unpin(ctx, "table"); err != nil {
+		t.Fatal(err)
+	}
+	stopped := atomic.LoadInt32(&loads)
+	time.Sleep(80 * time.Millisecond)
+	if got := atomic.LoadInt32(&loads); got != stopped {
+		t.Errorf("loads after Unpin = %d, want %d", got, stopped)
+	}
+}
+
+func TestCacher_Pin_Error(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	defer c.Close()
+	loadErr := errors.New("load error")
+	tests := []struct {
+		name  string
+		key   string
+		query func() (interface{}, error)
+		want  error
+	}{
+		{name: "空缓存键", key: "", query: func() (interface{}, error) { return 1, nil }},
+		{name: "没有查询方法", key: "k"},
+		{name: "查询出错", key: "k", query: func() (interface{}, error) { return nil, loadErr }, want: loadErr},
+	}
+	for _, tt := range tests {
+		err := c.Pin(ctx, tt.key, tt.query)
+		if err == nil || (tt.want != nil && !errors.Is(err, tt.want)) {
+			t.Errorf("%s: Pin() = %v, want error %v", tt.name, err, tt.want)
+		}
+	}
+}
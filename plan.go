@@ -0,0 +1,41 @@
+package cacher
+
+import "sync"
+
+// 类型转换方式
+const (
+	planNone      = iota //不支持的类型转换
+	planConverter        //使用转换器
+	planConvert          //直接进行类型转换
+	planCodec            //使用编解码器解码字符串、字节切片
+)
+
+type (
+	// convertPlan 类型转换方式
+	convertPlan struct {
+		kind int
+		conv TypeConverter
+	}
+	// planCache 类型转换方式的缓存，nil 时不缓存
+	planCache struct {
+		m sync.Map
+	}
+)
+
+func (p *planCache) load(pair typePair) (convertPlan, bool) {
+	if p == nil {
+		return convertPlan{}, false
+	}
+	plan, ok := p.m.Load(pair)
+	if !ok {
+		return convertPlan{}, false
+	}
+	return plan.(convertPlan), true
+}
+
+func (p *planCache) store(pair typePair, plan convertPlan) {
+	if p == nil {
+		return
+	}
+	p.m.Store(pair, plan)
+}
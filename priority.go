@@ -0,0 +1,57 @@
+package cacher
+
+import (
+	"context"
+	"time"
+)
+
+// Priority 缓存的淘汰优先级，容量有限的进程内存储库优先淘汰低优先级的缓存，见 Option.Priority
+type Priority int
+
+const (
+	PriorityLow    Priority = iota - 1 //低优先级，例如页面片段，最先被淘汰
+	PriorityNormal                     //默认优先级
+	PriorityHigh                       //高优先级，例如基础数据，最后被淘汰
+)
+
+// priorityLevels 优先级数量
+const priorityLevels = int(PriorityHigh-PriorityLow) + 1
+
+// PriorityRepo 可选的存储库接口，支持按优先级保存缓存。
+// Option.Priority 不是 PriorityNormal 时，Cacher 通过该接口保存缓存；存储库没有实现该接口时忽略优先级
+type PriorityRepo interface {
+	// SetPriority 按优先级保存缓存
+	SetPriority(ctx context.Context, key string, value interface{}, expire time.Duration, priority Priority) error
+}
+
+// WithPriority 设置缓存的淘汰优先级
+func WithPriority(priority Priority) func(opt *Option) {
+	return func(opt *Option) {
+		opt.Priority = priority
+	}
+}
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	}
+	return "unknown"
+}
+
+// level 优先级在淘汰顺序中的下标，低优先级在前
+func (p Priority) level() int {
+	return int(p - PriorityLow)
+}
+
+// set 保存缓存，优先级不是 PriorityNormal 且存储库实现了 PriorityRepo 时按优先级保存
+func (c *Cacher) set(ctx context.Context, key string, value interface{}, expire time.Duration, priority Priority) error {
+	if pr, ok := c.repo.(PriorityRepo); ok && priority != PriorityNormal {
+		return pr.SetPriority(ctx, key, value, expire, priority)
+	}
+	return c.repo.Set(ctx, key, value, expire)
+}
@@ -0,0 +1,63 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_Priority(t *testing.T) {
+	ctx := context.Background()
+	type entry struct {
+		key      string
+		priority cacher.Priority
+	}
+	tests := []struct {
+		name    string
+		max     int
+		entries []entry
+		touch   string //写入后读取，标记为最近使用
+		want    []string
+	}{
+		{name: "不限制数量", entries: []entry{{"a", cacher.PriorityLow}, {"b", cacher.PriorityNormal}, {"c", cacher.PriorityHigh}}, want: []string{"a", "b", "c"}},
+		{name: "先淘汰低优先级", max: 2, entries: []entry{{"a", cacher.PriorityHigh}, {"b", cacher.PriorityLow}, {"c", cacher.PriorityNormal}}, want: []string{"a", "c"}},
+		{name: "高优先级最后淘汰", max: 1, entries: []entry{{"a", cacher.PriorityHigh}, {"b", cacher.PriorityNormal}, {"c", cacher.PriorityNormal}}, want: []string{"a"}},
+		{name: "同一优先级淘汰最近最少使用", max: 2, entries: []entry{{"a", cacher.PriorityNormal}, {"b", cacher.PriorityNormal}, {"c", cacher.PriorityNormal}}, touch: "a", want: []string{"a", "c"}},
+	}
+	for _, tt := range tests {
+		repo := cacher.NewMemoryRepo(cacher.WithMaxEntries(tt.max))
+		c := cacher.New(repo, time.Minute)
+		var v string
+		for i, e := range tt.entries {
+			if _, err := c.GetWithOption(ctx, e.key, func() (interface{}, error) { return e.key, nil }, &v, cacher.WithPriority(e.priority)); err != nil {
+				t.Fatal(err)
+			}
+			if i == 1 && tt.touch != "" {
+				if _, err := c.Peek(ctx, tt.touch, &v); err != nil {
+					t.Fatal(err)
+				}
+			}
+		}
+		var got []string
+		for _, key := range []string{"a", "b", "c"} {
+			if data, _ := repo.Get(ctx, key); data != nil {
+				got = append(got, key)
+			}
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%s: cached = %v, want %v", tt.name, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%s: cached = %v, want %v", tt.name, got, tt.want)
+				break
+			}
+		}
+	}
+
+	if err := (cacher.Option{Priority: 5}).Valid(); err == nil {
+		t.Errorf("Valid() with unknown priority want error")
+	}
+}
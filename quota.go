@@ -0,0 +1,159 @@
+package cacher
+
+import (
+	"container/list"
+	"context"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+// QuotaPolicy 命名空间超出配额时的处理方式
+type QuotaPolicy int
+
+const (
+	// QuotaSkip 不保存新的缓存，查询数据仍然返回给调用方
+	QuotaSkip QuotaPolicy = iota
+	// QuotaEvict 按写入顺序删除该命名空间中最早写入的缓存，直到能够保存新的缓存
+	QuotaEvict
+)
+
+type (
+	// NamespaceQuota 命名空间的配额
+	NamespaceQuota struct {
+		MaxEntries int         //最多保存的缓存数量，小于等于0时不限制
+		MaxBytes   int64       //最多保存的字节数，只统计字符串、字节切片类型的缓存数据，小于等于0时不限制
+		Policy     QuotaPolicy //超出配额时的处理方式
+	}
+	// quotaTable 命名空间的配额和用量
+	quotaTable struct {
+		mu sync.Mutex
+		m  map[string]*quotaUsage
+	}
+	// quotaUsage 一个命名空间的用量。只统计本 Cacher 写入的缓存，多个实例共用存储库时，每个实例各自统计
+	quotaUsage struct {
+		quota   NamespaceQuota
+		bytes   int64
+		order   *list.List               //按写入顺序排列的 *quotaEntry
+		entries map[string]*list.Element //缓存键对应的 order 元素
+	}
+	quotaEntry struct {
+		key      string
+		size     int64
+		expireAt time.Time
+	}
+)
+
+// SetNamespaceQuota 设置命名空间的配额，避免共用存储库时一个命名空间占满存储空间。
+// 用量按本 Cacher 写入的缓存统计，缓存过期或通过 Del 删除后释放；quota 的限制都小于等于0时删除配额
+func (c *Cacher) SetNamespaceQuota(ns string, quota NamespaceQuota) {
+	c.quotas.mu.Lock()
+	defer c.quotas.mu.Unlock()
+	if quota.MaxEntries <= 0 && quota.MaxBytes <= 0 {
+		delete(c.quotas.m, ns)
+		return
+	}
+	if c.quotas.m == nil {
+		c.quotas.m = make(map[string]*quotaUsage)
+	}
+	if usage, ok := c.quotas.m[ns]; ok {
+		usage.quota = quota
+		return
+	}
+	c.quotas.m[ns] = &quotaUsage{quota: quota, order: list.New(), entries: make(map[string]*list.Element)}
+}
+
+// reserve 为命名空间 ns 中即将写入的缓存预留配额。
+// 返回值：是否可以写入，为写入新缓存需要删除的缓存键
+func (q *quotaTable) reserve(ns, key string, size int64, expire time.Duration) (bool, []string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	usage, ok := q.m[ns]
+	if !ok {
+		return true, nil
+	}
+	now := time.Now()
+	usage.remove(key)
+	if usage.exceeds(size) {
+		usage.purge(now)
+	}
+	var evicted []string
+	for usage.exceeds(size) {
+		front := usage.order.Front()
+		if usage.quota.Policy != QuotaEvict || front == nil {
+			return false, evicted
+		}
+		entry := front.Value.(*quotaEntry)
+		usage.remove(entry.key)
+		evicted = append(evicted, entry.key)
+	}
+	entry := &quotaEntry{key: key, size: size}
+	if expire > 0 {
+		entry.expireAt = now.Add(expire)
+	}
+	usage.entries[key] = usage.order.PushBack(entry)
+	usage.bytes += size
+	return true, evicted
+}
+
+// release 缓存已删除，释放配额
+func (q *quotaTable) release(keys ...string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, key := range keys {
+		for ns, usage := range q.m {
+			if strings.HasPrefix(key, ns+":") {
+				usage.remove(key)
+			}
+		}
+	}
+}
+
+// exceeds 再写入 size 字节的缓存是否超出配额
+func (u *quotaUsage) exceeds(size int64) bool {
+	if u.quota.MaxEntries > 0 && len(u.entries)+1 > u.quota.MaxEntries {
+		return true
+	}
+	return u.quota.MaxBytes > 0 && u.bytes+size > u.quota.MaxBytes
+}
+
+// purge 释放已过期的缓存占用的配额
+func (u *quotaUsage) purge(now time.Time) {
+	for e := u.order.Front(); e != nil; {
+		next := e.Next()
+		if entry := e.Value.(*quotaEntry); !entry.expireAt.IsZero() && now.After(entry.expireAt) {
+			u.remove(entry.key)
+		}
+		e = next
+	}
+}
+
+// remove 释放缓存键占用的配额
+func (u *quotaUsage) remove(key string) {
+	e, ok := u.entries[key]
+	if !ok {
+		return
+	}
+	u.bytes -= e.Value.(*quotaEntry).size
+	u.order.Remove(e)
+	delete(u.entries, key)
+}
+
+// checkQuota 命名空间设置了配额时，为写入缓存预留配额并删除被淘汰的缓存。返回值：是否可以写入
+func (c *Cacher) checkQuota(ctx context.Context, key string, value interface{}, expire time.Duration, opt Option) (bool, error) {
+	if opt.Namespace == "" {
+		return true, nil
+	}
+	ok, evicted := c.quotas.reserve(opt.Namespace, key, int64(sizeOf(reflect.ValueOf(value))), expire)
+	if len(evicted) > 0 {
+		if err := c.repo.Del(ctx, evicted...); err != nil {
+			return false, err
+		}
+		c.flights.del(evicted...)
+		for _, k := range evicted {
+			c.emit(Event{Type: EventEvict, Key: k})
+		}
+	}
+	return ok, nil
+}
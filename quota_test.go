@@ -0,0 +1,93 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestCacher_SetNamespaceQuota(t *testing.T) {
+	tests := []struct {
+		name       string
+		quota      cacher.NamespaceQuota
+		values     []string
+		wantStored []bool
+	}{
+		{name: "数量超出配额时不保存", quota: cacher.NamespaceQuota{MaxEntries: 2}, values: []string{"a", "b", "c"}, wantStored: []bool{true, true, false}},
+		{name: "数量超出配额时淘汰最早写入的", quota: cacher.NamespaceQuota{MaxEntries: 2, Policy: cacher.QuotaEvict}, values: []string{"a", "b", "c"}, wantStored: []bool{false, true, true}},
+		{name: "字节数超出配额时不保存", quota: cacher.NamespaceQuota{MaxBytes: 5}, values: []string{"aa", "bbb", "c"}, wantStored: []bool{true, true, false}},
+		{name: "字节数超出配额时淘汰", quota: cacher.NamespaceQuota{MaxBytes: 5, Policy: cacher.QuotaEvict}, values: []string{"aa", "bbb", "cccc"}, wantStored: []bool{false, false, true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepoMap(nil)
+			c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+				opt.Namespace = "team-a"
+			})
+			c.SetNamespaceQuota("team-a", tt.quota)
+			for i, value := range tt.values {
+				value := value
+				var v string
+				if _, err := c.Get(ctx, "k"+value, func() (interface{}, error) { return value, nil }, &v); err != nil || v != value {
+					t.Fatalf("Get() #%d = %v, %v", i, v, err)
+				}
+			}
+			for i, value := range tt.values {
+				key, err := c.NamespaceKey(ctx, "team-a", "k"+value)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got, _ := repo.Get(ctx, key)
+				if stored := got != nil; stored != tt.wantStored[i] {
+					t.Errorf("stored #%d = %v, want %v", i, stored, tt.wantStored[i])
+				}
+			}
+		})
+	}
+}
+
+func TestCacher_SetNamespaceQuota_Release(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	c.SetNamespaceQuota("ns", cacher.NamespaceQuota{MaxEntries: 1})
+	get := func(ns, key string) bool {
+		var v string
+		useCache, err := c.GetWithOption(ctx, key, func() (interface{}, error) { return "v", nil }, &v, func(opt *cacher.Option) {
+			opt.Namespace = ns
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return useCache
+	}
+	get("ns", "a")
+	get("ns", "b")
+	if get("ns", "b") {
+		t.Errorf("b cached, want skipped by quota")
+	}
+	//其他命名空间不受影响
+	get("other", "b")
+	if !get("other", "b") {
+		t.Errorf("b in other namespace not cached")
+	}
+	//删除后释放配额
+	key, err := c.NamespaceKey(ctx, "ns", "a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Del(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	get("ns", "b")
+	if !get("ns", "b") {
+		t.Errorf("b not cached after quota released")
+	}
+	//删除配额
+	c.SetNamespaceQuota("ns", cacher.NamespaceQuota{})
+	get("ns", "c")
+	if !get("ns", "c") {
+		t.Errorf("c not cached after quota removed")
+	}
+}
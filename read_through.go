@@ -0,0 +1,51 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type (
+	// Loader 缓存不存在时的数据加载器，适合为每种实体集中编写一个加载器，代替在各个调用处编写查询闭包
+	Loader interface {
+		// Load 加载 key 对应的数据，数据不存在时返回 nil,nil
+		Load(ctx context.Context, key string) (interface{}, error)
+	}
+	// LoaderFunc 函数形式的 Loader
+	LoaderFunc func(ctx context.Context, key string) (interface{}, error)
+	// ReadThrough 读穿透缓存：读取时缓存不存在，由 Loader 加载数据并保存
+	ReadThrough struct {
+		c      *Cacher
+		loader Loader
+	}
+)
+
+func (f LoaderFunc) Load(ctx context.Context, key string) (interface{}, error) {
+	return f(ctx, key)
+}
+
+// NewReadThrough 创建读穿透缓存，expire、optFns 同 New
+func NewReadThrough(repo Repo, loader Loader, expire time.Duration, optFns ...func(opt *Option)) *ReadThrough {
+	if loader == nil {
+		panic(errors.New("加载器 loader 不能为空"))
+	}
+	return &ReadThrough{c: New(repo, expire, optFns...), loader: loader}
+}
+
+// Get 获取缓存数据，缓存不存在时调用 Loader 加载。返回值同 Cacher.GetWithOption
+func (r *ReadThrough) Get(ctx context.Context, key string, v interface{}, optFns ...func(opt *Option)) (bool, error) {
+	return r.c.GetWithOption(ctx, key, func() (interface{}, error) {
+		return r.loader.Load(ctx, key)
+	}, v, optFns...)
+}
+
+// Del 删除一个或多个缓存，下次读取时重新加载
+func (r *ReadThrough) Del(ctx context.Context, keys ...string) error {
+	return r.c.Del(ctx, keys...)
+}
+
+// Cacher 读穿透缓存使用的 Cacher，用于注册转换器、监听事件等
+func (r *ReadThrough) Cacher() *Cacher {
+	return r.c
+}
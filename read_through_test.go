@@ -0,0 +1,78 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+// personLoader 按缓存键加载 person 的测试加载器
+type personLoader struct {
+	calls int
+}
+
+func (l *personLoader) Load(ctx context.Context, key string) (interface{}, error) {
+	l.calls++
+	switch key {
+	case "person-1":
+		return personObj, nil
+	case "error":
+		return nil, errors.New("load error")
+	}
+	return nil, nil
+}
+
+func TestReadThrough(t *testing.T) {
+	ctx := context.Background()
+	loader := &personLoader{}
+	rt := cacher.NewReadThrough(newRepoMap(nil), loader, time.Minute)
+	tests := []struct {
+		name      string
+		key       string
+		wantCache bool
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "加载", key: "person-1", wantCalls: 1},
+		{name: "命中缓存", key: "person-1", wantCache: true, wantCalls: 1},
+		{name: "加载错误", key: "error", wantCalls: 2, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var p person
+			useCache, err := rt.Get(ctx, tt.key, &p)
+			if (err != nil) != tt.wantErr || useCache != tt.wantCache {
+				t.Fatalf("Get() = %v, %v", useCache, err)
+			}
+			if !tt.wantErr && p != personObj {
+				t.Errorf("v = %+v, want %+v", p, personObj)
+			}
+			if loader.calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", loader.calls, tt.wantCalls)
+			}
+		})
+	}
+
+	if err := rt.Del(ctx, "person-1"); err != nil {
+		t.Fatal(err)
+	}
+	var p person
+	if useCache, err := rt.Get(ctx, "person-1", &p); useCache || err != nil {
+		t.Errorf("Get() after Del = %v, %v, want reload", useCache, err)
+	}
+}
+
+func TestLoaderFunc(t *testing.T) {
+	rt := cacher.NewReadThrough(newRepoMap(nil), cacher.LoaderFunc(func(ctx context.Context, key string) (interface{}, error) {
+		return "v:" + key, nil
+	}), time.Minute)
+	var v string
+	if _, err := rt.Get(context.Background(), "k", &v); err != nil || v != "v:k" {
+		t.Errorf("Get() = %v, %v", v, err)
+	}
+	if rt.Cacher() == nil {
+		t.Errorf("Cacher() = nil")
+	}
+}
@@ -0,0 +1,71 @@
+package cacher
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// recentErrorsSize 保留的最近错误数量
+const recentErrorsSize = 32
+
+type (
+	// RecentError 最近发生的不影响调用结果的内部错误，见 Cacher.RecentErrors
+	RecentError struct {
+		Op   string    `json:"op"`            //出错的操作，例如 repo-read、decode、revalidate，降级处理的故障为 Failure 的名称
+		Key  string    `json:"key,omitempty"` //存储库中的缓存键，与缓存键无关的错误为空字符串
+		Err  string    `json:"err"`           //错误信息
+		Time time.Time `json:"time"`          //发生时间
+	}
+	// errorRing 最近错误的环形缓冲区，写满后覆盖最早的错误
+	errorRing struct {
+		mu   sync.Mutex
+		buf  [recentErrorsSize]RecentError
+		next int
+		full bool
+	}
+)
+
+// add 记录错误
+func (r *errorRing) add(e RecentError) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buf[r.next] = e
+	r.next = (r.next + 1) % len(r.buf)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// list 缓存键以 prefix 开头的错误，按时间倒序
+func (r *errorRing) list(prefix string) []RecentError {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	n := r.next
+	if r.full {
+		n = len(r.buf)
+	}
+	var errs []RecentError
+	for i := 1; i <= n; i++ {
+		e := r.buf[(r.next-i+len(r.buf))%len(r.buf)]
+		if strings.HasPrefix(e.Key, prefix) {
+			errs = append(errs, e)
+		}
+	}
+	return errs
+}
+
+// emitError 记录被忽略的内部错误，并发布 EventError 事件。
+// 降级策略、后台刷新等吞掉的错误不会返回给调用方，通过 RecentErrors 仍然可以查到
+func (c *Cacher) emitError(op, key string, err error) {
+	now := time.Now()
+	c.recentErrors.add(RecentError{Op: op, Key: key, Err: err.Error(), Time: now})
+	c.emit(Event{Type: EventError, Key: key, Time: now, Err: err})
+}
+
+// RecentErrors 最近发生的不影响调用结果的内部错误，最多保留32个，按时间倒序。
+// 只返回存储库中的缓存键以 prefix 开头的错误，prefix 为空字符串时返回全部。
+// 与 OnEvent 监听 EventError 不同，不需要事先注册监听器，可以在问题发生后排查
+func (c *Cacher) RecentErrors(prefix string) []RecentError {
+	return c.recentErrors.list(prefix)
+}
@@ -0,0 +1,61 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCacher_RecentErrors(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(map[string]interface{}{"order:2": "bad"})
+	c := cacher.New(repo, time.Minute)
+	failOpen := func(opt *cacher.Option) {
+		opt.Degrade = cacher.Degradation{RepoRead: cacher.DegradeFailOpen, Decode: cacher.DegradeFailOpen}
+	}
+	query := func() (interface{}, error) { return 1, nil }
+	var v int
+	if _, err := c.GetWithOption(ctx, "order:2", query, &v, failOpen); err != nil {
+		t.Fatal(err)
+	}
+	repo.getErr = errors.New("connection refused")
+	if _, err := c.GetWithOption(ctx, "user:1", query, &v, failOpen); err != nil {
+		t.Fatal(err)
+	}
+	repo.getErr = nil
+
+	errs := c.RecentErrors("")
+	if len(errs) != 2 {
+		t.Fatalf("RecentErrors() = %+v, want 2 errors", errs)
+	}
+	if e := errs[0]; e.Op != "repo-read" || e.Key != "user:1" || e.Err != "connection refused" || e.Time.IsZero() {
+		t.Errorf("RecentErrors()[0] = %+v", e)
+	}
+	if e := errs[1]; e.Op != "decode" || e.Key != "order:2" {
+		t.Errorf("RecentErrors()[1] = %+v", e)
+	}
+	if errs := c.RecentErrors("order:"); len(errs) != 1 || errs[0].Key != "order:2" {
+		t.Errorf("RecentErrors(order:) = %+v", errs)
+	}
+	if state := c.DebugState(); len(state.Errors) != 2 {
+		t.Errorf("DebugState().Errors = %+v", state.Errors)
+	}
+
+	//写满后覆盖最早的错误
+	repo.getErr = errors.New("timeout")
+	for i := 0; i < 40; i++ {
+		if _, err := c.GetWithOption(ctx, "user:"+strconv.Itoa(i), query, &v, failOpen); err != nil {
+			t.Fatal(err)
+		}
+	}
+	errs = c.RecentErrors("")
+	if len(errs) != 32 || errs[0].Key != "user:39" || errs[31].Key != "user:8" {
+		t.Errorf("RecentErrors() after overflow = %d errors, first %+v", len(errs), errs[0])
+	}
+	if errs := c.RecentErrors("order:"); len(errs) != 0 {
+		t.Errorf("RecentErrors(order:) after overflow = %+v", errs)
+	}
+}
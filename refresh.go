@@ -0,0 +1,70 @@
+package cacher
+
+import (
+	"context"
+	"time"
+)
+
+// refreshPrefix 重建占位符的缓存键前缀
+const refreshPrefix = "cacher:refreshing:"
+
+// staleValue 其他实例正在重建缓存时，代替查询结果返回的旧数据，见 Option.RefreshLock
+type staleValue struct {
+	data interface{}
+}
+
+// refreshKey 重建占位符的缓存键
+func refreshKey(key string) string {
+	return refreshPrefix + key
+}
+
+// beginRefresh 写入保留时长为 ttl 的重建占位符，通知其他实例正在重建缓存。
+// 写入成功时返回 true 和结束重建的方法：重建期间每隔 ttl/2 延长占位符的保留时长，结束时删除占位符。
+// 存储库实现了 NXRepo 时使用 SetNX 写入，否则先读取再写入，不是原子操作
+func (c *Cacher) beginRefresh(ctx context.Context, key string, ttl time.Duration) (bool, func()) {
+	placeholder := refreshKey(key)
+	var ok bool
+	var err error
+	if repo, isNX := c.repo.(NXRepo); isNX {
+		ok, err = repo.SetNX(ctx, placeholder, "1", ttl)
+	} else {
+		var data interface{}
+		if data, err = c.repo.Get(ctx, placeholder); err == nil && data == nil {
+			ok, err = true, c.repo.Set(ctx, placeholder, "1", ttl)
+		}
+	}
+	if err != nil {
+		//无法写入占位符时，按没有其他实例重建处理
+		c.emitError("refresh-lock", key, err)
+		return false, nil
+	}
+	if !ok {
+		return false, nil
+	}
+	//续期和删除占位符不随 ctx 取消
+	ctx = detach(ctx)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(ttl / 2)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := c.repo.Set(ctx, placeholder, "1", ttl); err != nil {
+					c.emitError("refresh-lock", key, err)
+				}
+			}
+		}
+	}()
+	return true, func() {
+		close(done)
+		<-stopped
+		if err := c.repo.Del(ctx, placeholder); err != nil {
+			c.emitError("refresh-lock", key, err)
+		}
+	}
+}
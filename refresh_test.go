@@ -0,0 +1,70 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_RefreshLock(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	newInstance := func() *cacher.Cacher {
+		return cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+			opt.StaleExpire = time.Minute
+			opt.RefreshLock = 20 * time.Millisecond
+		})
+	}
+	a, b := newInstance(), newInstance()
+
+	//写入缓存和旧数据后，缓存过期
+	var v string
+	if _, err := a.Get(ctx, "k", func() (interface{}, error) { return "old", nil }, &v); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+
+	//实例 a 重建缓存，耗时超过占位符的保留时长
+	started, release := make(chan struct{}), make(chan struct{})
+	done := make(chan error)
+	go func() {
+		var v string
+		_, err := a.Get(ctx, "k", func() (interface{}, error) {
+			close(started)
+			<-release
+			return "new", nil
+		}, &v)
+		done <- err
+	}()
+	<-started
+	time.Sleep(50 * time.Millisecond)
+
+	//实例 b 看到占位符，返回旧数据，不调用查询方法
+	useCache, err := b.Get(ctx, "k", func() (interface{}, error) { return nil, notNeedCall }, &v)
+	if err != nil || !useCache || v != "old" {
+		t.Errorf("Get() during refresh = %v, %v, %v, want old from cache", v, useCache, err)
+	}
+
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if placeholder, _ := repo.Get(ctx, "cacher:refreshing:k"); placeholder != nil {
+		t.Errorf("placeholder = %v, want deleted after refresh", placeholder)
+	}
+	if useCache, err := b.Get(ctx, "k", func() (interface{}, error) { return nil, notNeedCall }, &v); err != nil || !useCache || v != "new" {
+		t.Errorf("Get() after refresh = %v, %v, %v, want new", v, useCache, err)
+	}
+}
+
+func TestOption_RefreshLock_Valid(t *testing.T) {
+	var optErr *cacher.OptionError
+	err := cacher.Option{RefreshLock: time.Second}.Valid()
+	if !errors.As(err, &optErr) || optErr.Field != "RefreshLock" {
+		t.Errorf("Valid() = %v, want RefreshLock OptionError", err)
+	}
+}
@@ -0,0 +1,219 @@
+// Package grpc 基于 gRPC 的远程存储库：Register 把任意 cacher.Repo 注册为 gRPC 服务，Repo 通过 gRPC 访问该服务，
+// 两端都使用本包即可搭建独立的缓存服务层。服务 cacher.Repo 提供 Get、Set、Del、MGet 四个方法，
+// 服务和消息定义在 cache.proto 中，消息使用 gRPC 默认的 protobuf 编码，其他语言的客户端可以用 cache.proto 生成代码访问服务
+package grpc
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative cache.proto
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/carteruu/cacher"
+	gogrpc "google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+// ServiceName gRPC 服务名，见 cache.proto
+const ServiceName = "cacher.Repo"
+
+type (
+	// Repo 通过 gRPC 访问远程存储库，实现 cacher.Repo、cacher.NXRepo 和 cacher.MultiGetRepo
+	Repo struct {
+		conn gogrpc.ClientConnInterface
+	}
+	// server 把 cacher.Repo 包装为 gRPC 服务
+	server struct {
+		repo cacher.Repo
+	}
+)
+
+var (
+	_ cacher.Repo         = (*Repo)(nil)
+	_ cacher.NXRepo       = (*Repo)(nil)
+	_ cacher.MultiGetRepo = (*Repo)(nil)
+)
+
+// New 创建远程存储库，conn 连接到通过 Register 注册了服务的 gRPC 服务器
+func New(conn gogrpc.ClientConnInterface) *Repo {
+	return &Repo{conn: conn}
+}
+
+// Get 获取缓存，缓存不存在时返回 nil, nil，存在时返回字节切片
+func (r *Repo) Get(ctx context.Context, key string) (interface{}, error) {
+	var resp GetResponse
+	if err := r.invoke(ctx, "Get", &GetRequest{Key: key}, &resp); err != nil {
+		return nil, err
+	}
+	if !resp.Found {
+		return nil, nil
+	}
+	return resp.Value, nil
+}
+
+// MGet 获取多个缓存，结果与 keys 一一对应，缓存不存在时对应的结果为 nil
+func (r *Repo) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	var resp MGetResponse
+	if err := r.invoke(ctx, "MGet", &MGetRequest{Keys: keys}, &resp); err != nil {
+		return nil, err
+	}
+	values := make([]interface{}, len(keys))
+	for i := range values {
+		if i < len(resp.Values) && resp.Values[i].Found {
+			values[i] = resp.Values[i].Value
+		}
+	}
+	return values, nil
+}
+
+// GetMulti 同 MGet，实现 cacher.MultiGetRepo
+func (r *Repo) GetMulti(ctx context.Context, keys []string) ([]interface{}, error) {
+	return r.MGet(ctx, keys...)
+}
+
+// Set 保存缓存。字符串和字节切片原样保存，其他类型保存为 JSON
+func (r *Repo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	_, err := r.set(ctx, key, value, expire, false)
+	return err
+}
+
+// SetNX 缓存键不存在时保存，服务端的存储库需要实现 cacher.NXRepo
+func (r *Repo) SetNX(ctx context.Context, key string, value interface{}, expire time.Duration) (bool, error) {
+	return r.set(ctx, key, value, expire, true)
+}
+
+func (r *Repo) set(ctx context.Context, key string, value interface{}, expire time.Duration, nx bool) (bool, error) {
+	val, err := encode(value)
+	if err != nil {
+		return false, err
+	}
+	var resp SetResponse
+	req := &SetRequest{Key: key, Value: val, Expire: expire.Milliseconds(), Nx: nx}
+	if err := r.invoke(ctx, "Set", req, &resp); err != nil {
+		return false, err
+	}
+	return resp.Ok, nil
+}
+
+// Del 删除缓存
+func (r *Repo) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.invoke(ctx, "Del", &DelRequest{Keys: keys}, &DelResponse{})
+}
+
+func (r *Repo) invoke(ctx context.Context, method string, req, resp interface{}) error {
+	return r.conn.Invoke(ctx, "/"+ServiceName+"/"+method, req, resp)
+}
+
+// Register 把 repo 注册为 gRPC 服务，服务端不解析缓存数据，客户端写入的字节原样保存在 repo 中
+func Register(s gogrpc.ServiceRegistrar, repo cacher.Repo) {
+	s.RegisterService(&serviceDesc, &server{repo: repo})
+}
+
+func (s *server) get(ctx context.Context, req *GetRequest) (*GetResponse, error) {
+	value, err := s.repo.Get(ctx, req.Key)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return response(value)
+}
+
+func (s *server) mget(ctx context.Context, req *MGetRequest) (*MGetResponse, error) {
+	resp := &MGetResponse{Values: make([]*GetResponse, len(req.Keys))}
+	for i, key := range req.Keys {
+		value, err := s.repo.Get(ctx, key)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		r, err := response(value)
+		if err != nil {
+			return nil, err
+		}
+		resp.Values[i] = r
+	}
+	return resp, nil
+}
+
+func (s *server) set(ctx context.Context, req *SetRequest) (*SetResponse, error) {
+	expire := time.Duration(req.Expire) * time.Millisecond
+	if !req.Nx {
+		if err := s.repo.Set(ctx, req.Key, req.Value, expire); err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+		return &SetResponse{Ok: true}, nil
+	}
+	nxRepo, ok := s.repo.(cacher.NXRepo)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "存储库 %T 没有实现 NXRepo", s.repo)
+	}
+	ok, err := nxRepo.SetNX(ctx, req.Key, req.Value, expire)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &SetResponse{Ok: ok}, nil
+}
+
+func (s *server) del(ctx context.Context, req *DelRequest) (*DelResponse, error) {
+	if err := s.repo.Del(ctx, req.Keys...); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &DelResponse{}, nil
+}
+
+// response 把存储库中的缓存数据编码为响应
+func response(value interface{}) (*GetResponse, error) {
+	if value == nil {
+		return &GetResponse{}, nil
+	}
+	val, err := encode(value)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &GetResponse{Value: val, Found: true}, nil
+}
+
+// encode 编码缓存数据，字符串和字节切片原样返回，其他类型编码为 JSON
+func encode(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	}
+	return json.Marshal(value)
+}
+
+// unaryHandler 创建 gRPC 方法的处理器，Req、Resp 为请求和响应的类型
+func unaryHandler[Req any, Resp any](fn func(s *server, ctx context.Context, req *Req) (*Resp, error), method string) gogrpc.MethodDesc {
+	return gogrpc.MethodDesc{
+		MethodName: method,
+		Handler: func(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor gogrpc.UnaryServerInterceptor) (interface{}, error) {
+			req := new(Req)
+			if err := dec(req); err != nil {
+				return nil, err
+			}
+			if interceptor == nil {
+				return fn(srv.(*server), ctx, req)
+			}
+			info := &gogrpc.UnaryServerInfo{Server: srv, FullMethod: "/" + ServiceName + "/" + method}
+			return interceptor(ctx, req, info, func(ctx context.Context, req interface{}) (interface{}, error) {
+				return fn(srv.(*server), ctx, req.(*Req))
+			})
+		},
+	}
+}
+
+var serviceDesc = gogrpc.ServiceDesc{
+	ServiceName: ServiceName,
+	HandlerType: (*interface{})(nil),
+	Methods: []gogrpc.MethodDesc{
+		unaryHandler((*server).get, "Get"),
+		unaryHandler((*server).set, "Set"),
+		unaryHandler((*server).del, "Del"),
+		unaryHandler((*server).mget, "MGet"),
+	},
+	Metadata: "cache.proto",
+}
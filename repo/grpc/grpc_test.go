@@ -0,0 +1,130 @@
+package grpc_test
+
+import (
+	"bytes"
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cachertest"
+	grpcrepo "github.com/carteruu/cacher/repo/grpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/encoding/protowire"
+	"net"
+	"testing"
+	"time"
+)
+
+// newRepo 启动包装了 MemoryRepo 的 gRPC 服务器，返回连接到该服务器的远程存储库
+func newRepo(t *testing.T) *grpcrepo.Repo {
+	return grpcrepo.New(newConn(t))
+}
+
+// newConn 启动包装了 MemoryRepo 的 gRPC 服务器，返回连接到该服务器的连接
+func newConn(t *testing.T) *grpc.ClientConn {
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	grpcrepo.Register(srv, cacher.NewMemoryRepo())
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// rawCodec 收发已编码的 protobuf 消息，模拟不使用本包、按 cache.proto 自行编码的客户端
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v interface{}) ([]byte, error) { return *v.(*[]byte), nil }
+
+func (rawCodec) Unmarshal(data []byte, v interface{}) error {
+	*v.(*[]byte) = append([]byte(nil), data...)
+	return nil
+}
+
+func (rawCodec) Name() string { return "proto" }
+
+func TestRepo_ProtoWire(t *testing.T) {
+	ctx := context.Background()
+	conn := newConn(t)
+	invoke := func(method string, req []byte) []byte {
+		t.Helper()
+		var resp []byte
+		if err := conn.Invoke(ctx, "/"+grpcrepo.ServiceName+"/"+method, &req, &resp, grpc.ForceCodec(rawCodec{})); err != nil {
+			t.Fatalf("%s: %v", method, err)
+		}
+		return resp
+	}
+	//SetRequest：1 key，2 value，3 expire
+	var set []byte
+	set = protowire.AppendTag(set, 1, protowire.BytesType)
+	set = protowire.AppendString(set, "k")
+	set = protowire.AppendTag(set, 2, protowire.BytesType)
+	set = protowire.AppendBytes(set, []byte("v"))
+	set = protowire.AppendTag(set, 3, protowire.VarintType)
+	set = protowire.AppendVarint(set, uint64(time.Minute.Milliseconds()))
+	//SetResponse：1 ok
+	if got, want := invoke("Set", set), protowire.AppendVarint(protowire.AppendTag(nil, 1, protowire.VarintType), 1); !bytes.Equal(got, want) {
+		t.Errorf("SetResponse = %x, want %x", got, want)
+	}
+	//GetRequest：1 key；GetResponse：1 value，2 found
+	get := protowire.AppendString(protowire.AppendTag(nil, 1, protowire.BytesType), "k")
+	want := protowire.AppendBytes(protowire.AppendTag(nil, 1, protowire.BytesType), []byte("v"))
+	want = protowire.AppendVarint(protowire.AppendTag(want, 2, protowire.VarintType), 1)
+	if got := invoke("Get", get); !bytes.Equal(got, want) {
+		t.Errorf("GetResponse = %x, want %x", got, want)
+	}
+	//通过本包的 Repo 读取自行编码写入的缓存
+	v, err := grpcrepo.New(conn).Get(ctx, "k")
+	if b, _ := v.([]byte); err != nil || string(b) != "v" {
+		t.Errorf("Get() = %v, %v, want v", v, err)
+	}
+}
+
+func TestRepo_Suite(t *testing.T) {
+	cachertest.RunRepoSuite(t, func(t *testing.T) cacher.Repo {
+		return newRepo(t)
+	})
+}
+
+func TestRepo_MGet(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	if err := repo.Set(ctx, "a", "1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Set(ctx, "c", 3, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	values, err := repo.MGet(ctx, "a", "b", "c")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"1", "", "3"}
+	for i, v := range values {
+		got := ""
+		if v != nil {
+			got = string(v.([]byte))
+		}
+		if got != want[i] || (v == nil) != (want[i] == "") {
+			t.Errorf("MGet()[%d] = %#v, want %q", i, v, want[i])
+		}
+	}
+}
+
+func TestRepo_Cacher(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepo(t), time.Minute)
+	for i, wantUseCache := range []bool{false, true} {
+		var v int
+		useCache, err := c.Get(ctx, "k", func() (interface{}, error) { return 42, nil }, &v)
+		if err != nil || v != 42 || useCache != wantUseCache {
+			t.Errorf("Get() #%d = %v, %v, %d", i, useCache, err, v)
+		}
+	}
+}
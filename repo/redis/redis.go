@@ -0,0 +1,427 @@
+// Package redis 基于 go-redis 的存储库
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/carteruu/cacher"
+	goredis "github.com/redis/go-redis/v9"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Repo 基于 go-redis 的存储库，实现 cacher.Repo 和 cacher.NXRepo、cacher.TTLRepo、cacher.GetWithTTLRepo、cacher.MultiGetRepo、cacher.MultiSetRepo、cacher.BatchRepo、cacher.ZRepo、cacher.HashRepo、cacher.ListRepo、cacher.SetRepo、cacher.KeyEventSource
+type Repo struct {
+	client goredis.UniversalClient
+}
+
+var (
+	_ cacher.Repo           = (*Repo)(nil)
+	_ cacher.NXRepo         = (*Repo)(nil)
+	_ cacher.TTLRepo        = (*Repo)(nil)
+	_ cacher.GetWithTTLRepo = (*Repo)(nil)
+	_ cacher.MultiGetRepo   = (*Repo)(nil)
+	_ cacher.MultiSetRepo   = (*Repo)(nil)
+	_ cacher.BatchRepo      = (*Repo)(nil)
+	_ cacher.ZRepo          = (*Repo)(nil)
+	_ cacher.HashRepo       = (*Repo)(nil)
+	_ cacher.ListRepo       = (*Repo)(nil)
+	_ cacher.SetRepo        = (*Repo)(nil)
+	_ cacher.KeyEventSource = (*Repo)(nil)
+)
+
+// New 创建存储库
+func New(client goredis.UniversalClient) *Repo {
+	return &Repo{client: client}
+}
+
+// SubscribeKeyEvents 订阅 Redis 的键事件通知，把过期、淘汰事件转换为 cacher.EventExpire、cacher.EventEvict 事件。
+// 需要 Redis 开启键事件通知，notify-keyspace-events 至少包含 Exe，本方法不修改服务端配置；
+// 集群模式下订阅每个主节点。阻塞直到 ctx 结束或订阅出错
+func (r *Repo) SubscribeKeyEvents(ctx context.Context, fn func(cacher.Event)) error {
+	clients := []goredis.UniversalClient{r.client}
+	if cluster, ok := r.client.(*goredis.ClusterClient); ok {
+		clients = nil
+		var mu sync.Mutex
+		err := cluster.ForEachMaster(ctx, func(ctx context.Context, client *goredis.Client) error {
+			mu.Lock()
+			defer mu.Unlock()
+			clients = append(clients, client)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	errs := make(chan error, len(clients))
+	for _, client := range clients {
+		go func(client goredis.UniversalClient) {
+			errs <- subscribeKeyEvents(ctx, client, fn)
+		}(client)
+	}
+	return <-errs
+}
+
+// subscribeKeyEvents 订阅单个节点的键事件通知
+func subscribeKeyEvents(ctx context.Context, client goredis.UniversalClient, fn func(cacher.Event)) error {
+	db := 0
+	if c, ok := client.(*goredis.Client); ok {
+		db = c.Options().DB
+	}
+	prefix := fmt.Sprintf("__keyevent@%d__:", db)
+	sub := client.Subscribe(ctx, prefix+"expired", prefix+"evicted")
+	defer sub.Close()
+	//等待订阅确认，连接或订阅失败时直接返回错误
+	if _, err := sub.Receive(ctx); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-ch:
+			if !ok {
+				return errors.New("键事件订阅已关闭")
+			}
+			ev := cacher.Event{Type: cacher.EventExpire, Key: msg.Payload}
+			if strings.HasSuffix(msg.Channel, ":evicted") {
+				ev.Type = cacher.EventEvict
+			}
+			fn(ev)
+		}
+	}
+}
+
+// Get 获取缓存，缓存不存在时返回 nil, nil
+func (r *Repo) Get(ctx context.Context, key string) (interface{}, error) {
+	val, err := r.client.Get(ctx, key).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// GetMulti 在一个管道中读取多个缓存，集群模式下缓存键可以在不同的哈希槽中
+func (r *Repo) GetMulti(ctx context.Context, keys []string) ([]interface{}, error) {
+	cmds := make([]*goredis.StringCmd, len(keys))
+	_, err := r.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, key := range keys {
+			cmds[i] = pipe.Get(ctx, key)
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, err
+	}
+	vals := make([]interface{}, len(keys))
+	for i, cmd := range cmds {
+		val, err := cmd.Bytes()
+		if errors.Is(err, goredis.Nil) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+	return vals, nil
+}
+
+// GetWithTTL 在一个管道中读取缓存和剩余保留时长，缓存不存在时返回 nil, -2, nil
+func (r *Repo) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, error) {
+	var (
+		get *goredis.StringCmd
+		ttl *goredis.DurationCmd
+	)
+	_, err := r.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		get = pipe.Get(ctx, key)
+		ttl = pipe.PTTL(ctx, key)
+		return nil
+	})
+	if errors.Is(err, goredis.Nil) {
+		return nil, -2, nil
+	}
+	if err != nil {
+		return nil, 0, err
+	}
+	val, err := get.Bytes()
+	if err != nil {
+		return nil, 0, err
+	}
+	return val, ttl.Val(), nil
+}
+
+// Set 保存缓存。字符串和字节切片原样保存，其他类型保存为 JSON
+func (r *Repo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	val, err := encode(value)
+	if err != nil {
+		return err
+	}
+	return r.client.Set(ctx, key, val, expire).Err()
+}
+
+// SetMulti 在 MULTI/EXEC 事务中保存多个缓存，其他客户端不会看到只保存了一部分的状态。
+// 集群模式下所有缓存键需要在同一个哈希槽中，例如使用相同的 {hash tag}
+func (r *Repo) SetMulti(ctx context.Context, entries []cacher.RepoEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	vals := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		val, err := encode(entry.Value)
+		if err != nil {
+			return err
+		}
+		vals[i] = val
+	}
+	_, err := r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, entry := range entries {
+			pipe.Set(ctx, entry.Key, vals[i], entry.Expire)
+		}
+		return nil
+	})
+	return err
+}
+
+// MGet 同 GetMulti，实现 cacher.BatchRepo
+func (r *Repo) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return r.GetMulti(ctx, keys)
+}
+
+// MSet 在一个管道中保存多个缓存，不保证原子性，集群模式下缓存键可以在不同的哈希槽中
+func (r *Repo) MSet(ctx context.Context, entries ...cacher.RepoEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	vals := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		val, err := encode(entry.Value)
+		if err != nil {
+			return err
+		}
+		vals[i] = val
+	}
+	_, err := r.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for i, entry := range entries {
+			pipe.Set(ctx, entry.Key, vals[i], entry.Expire)
+		}
+		return nil
+	})
+	return err
+}
+
+// MDel 在一个管道中逐个删除缓存，集群模式下缓存键可以在不同的哈希槽中，不会出现 Del 的 CROSSSLOT 错误
+func (r *Repo) MDel(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := r.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, key := range keys {
+			pipe.Del(ctx, key)
+		}
+		return nil
+	})
+	return err
+}
+
+// SetNX 缓存键不存在时保存
+func (r *Repo) SetNX(ctx context.Context, key string, value interface{}, expire time.Duration) (bool, error) {
+	val, err := encode(value)
+	if err != nil {
+		return false, err
+	}
+	return r.client.SetNX(ctx, key, val, expire).Result()
+}
+
+// TTL 查询剩余保留时长，缓存不存在时返回 -2，不过期时返回 -1
+func (r *Repo) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return r.client.PTTL(ctx, key).Result()
+}
+
+// Del 删除缓存
+func (r *Repo) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	return r.client.Del(ctx, keys...).Err()
+}
+
+// ZAdd 添加有序集合成员
+func (r *Repo) ZAdd(ctx context.Context, key string, members ...cacher.ZMember) error {
+	zs := make([]goredis.Z, len(members))
+	for i, m := range members {
+		zs[i] = goredis.Z{Score: m.Score, Member: m.Member}
+	}
+	return r.client.ZAdd(ctx, key, zs...).Err()
+}
+
+// ZIncrBy 增加有序集合成员的分数
+func (r *Repo) ZIncrBy(ctx context.Context, key string, member string, incr float64) (float64, error) {
+	return r.client.ZIncrBy(ctx, key, incr, member).Result()
+}
+
+// ZRange 按排名获取有序集合成员
+func (r *Repo) ZRange(ctx context.Context, key string, start, stop int64, desc bool) ([]cacher.ZMember, error) {
+	var (
+		zs  []goredis.Z
+		err error
+	)
+	if desc {
+		zs, err = r.client.ZRevRangeWithScores(ctx, key, start, stop).Result()
+	} else {
+		zs, err = r.client.ZRangeWithScores(ctx, key, start, stop).Result()
+	}
+	if err != nil {
+		return nil, err
+	}
+	members := make([]cacher.ZMember, len(zs))
+	for i, z := range zs {
+		member, _ := z.Member.(string)
+		members[i] = cacher.ZMember{Member: member, Score: z.Score}
+	}
+	return members, nil
+}
+
+// HSet 设置哈希表字段，expire 大于0时在同一个事务中设置保留时长
+func (r *Repo) HSet(ctx context.Context, key string, fields map[string]string, expire time.Duration) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	values := make([]interface{}, 0, len(fields)*2)
+	for f, v := range fields {
+		values = append(values, f, v)
+	}
+	if expire <= 0 {
+		return r.client.HSet(ctx, key, values...).Err()
+	}
+	_, err := r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.HSet(ctx, key, values...)
+		pipe.Expire(ctx, key, expire)
+		return nil
+	})
+	return err
+}
+
+// HGet 获取哈希表字段
+func (r *Repo) HGet(ctx context.Context, key string, fields ...string) (map[string]string, error) {
+	vals, err := r.client.HMGet(ctx, key, fields...).Result()
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(fields))
+	for i, v := range vals {
+		if s, ok := v.(string); ok {
+			m[fields[i]] = s
+		}
+	}
+	return m, nil
+}
+
+// HGetAll 获取哈希表的所有字段
+func (r *Repo) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	return r.client.HGetAll(ctx, key).Result()
+}
+
+// LPush 从列表头部插入元素，expire 大于0时在同一个事务中设置保留时长
+func (r *Repo) LPush(ctx context.Context, key string, expire time.Duration, values ...string) error {
+	if len(values) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(values))
+	for i, v := range values {
+		args[i] = v
+	}
+	if expire <= 0 {
+		return r.client.LPush(ctx, key, args...).Err()
+	}
+	_, err := r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.LPush(ctx, key, args...)
+		pipe.Expire(ctx, key, expire)
+		return nil
+	})
+	return err
+}
+
+// RPop 从列表尾部弹出元素
+func (r *Repo) RPop(ctx context.Context, key string) (string, bool, error) {
+	val, err := r.client.RPop(ctx, key).Result()
+	if errors.Is(err, goredis.Nil) {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	return val, true, nil
+}
+
+// LRange 获取列表元素
+func (r *Repo) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	return r.client.LRange(ctx, key, start, stop).Result()
+}
+
+// LTrim 裁剪列表
+func (r *Repo) LTrim(ctx context.Context, key string, start, stop int64) error {
+	return r.client.LTrim(ctx, key, start, stop).Err()
+}
+
+// SAdd 添加集合成员，expire 大于0时在同一个事务中设置保留时长
+func (r *Repo) SAdd(ctx context.Context, key string, expire time.Duration, members ...string) (int64, error) {
+	if len(members) == 0 {
+		return 0, nil
+	}
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	if expire <= 0 {
+		return r.client.SAdd(ctx, key, args...).Result()
+	}
+	var added *goredis.IntCmd
+	_, err := r.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		added = pipe.SAdd(ctx, key, args...)
+		pipe.Expire(ctx, key, expire)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return added.Val(), nil
+}
+
+// SIsMember 判断集合成员是否存在
+func (r *Repo) SIsMember(ctx context.Context, key string, member string) (bool, error) {
+	return r.client.SIsMember(ctx, key, member).Result()
+}
+
+// SRem 删除集合成员
+func (r *Repo) SRem(ctx context.Context, key string, members ...string) error {
+	if len(members) == 0 {
+		return nil
+	}
+	args := make([]interface{}, len(members))
+	for i, m := range members {
+		args[i] = m
+	}
+	return r.client.SRem(ctx, key, args...).Err()
+}
+
+// encode 编码缓存数据
+func encode(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string, []byte:
+		return v, nil
+	}
+	return json.Marshal(value)
+}
@@ -0,0 +1,326 @@
+package redis_test
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cachertest"
+	redisrepo "github.com/carteruu/cacher/repo/redis"
+	"github.com/redis/go-redis/v9"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type person struct {
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+func newRepo(t *testing.T) (*redisrepo.Repo, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	client := redis.NewClient(&redis.Options{Addr: mr.Addr()})
+	t.Cleanup(func() { client.Close() })
+	return redisrepo.New(client), mr
+}
+
+func TestRepo_Suite(t *testing.T) {
+	var mr *miniredis.Miniredis
+	cachertest.RunRepoSuite(t, func(t *testing.T) cacher.Repo {
+		var repo *redisrepo.Repo
+		repo, mr = newRepo(t)
+		return repo
+	}, cachertest.WithAdvance(func(d time.Duration) {
+		mr.FastForward(d)
+	}))
+}
+
+func TestRepo(t *testing.T) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	if v, err := repo.Get(ctx, "missing"); v != nil || err != nil {
+		t.Errorf("Get(missing) = %v, %v, want nil, nil", v, err)
+	}
+	c := cacher.New(repo, time.Minute)
+	if err := cacher.RegisterType[person](c); err != nil {
+		t.Fatal(err)
+	}
+	want := person{Name: "tom", Age: 18}
+	for i := 0; i < 2; i++ {
+		var p person
+		useCache, err := c.Get(ctx, "person", func() (interface{}, error) { return want, nil }, &p)
+		if err != nil || p != want || useCache != (i == 1) {
+			t.Fatalf("Get() = %v, %v, p = %+v", useCache, err, p)
+		}
+	}
+	if ttl := mr.TTL("person"); ttl <= 0 {
+		t.Errorf("TTL = %v, want > 0", ttl)
+	}
+	if ttl, err := repo.TTL(ctx, "person"); err != nil || ttl <= 0 {
+		t.Errorf("repo.TTL() = %v, %v", ttl, err)
+	}
+	if err := c.Del(ctx, "person"); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("person") {
+		t.Errorf("person not deleted")
+	}
+	if ttl, err := repo.TTL(ctx, "person"); err != nil || ttl != -2 {
+		t.Errorf("repo.TTL() of missing key = %v, %v, want -2", ttl, err)
+	}
+}
+
+func TestRepo_SubscribeKeyEvents(t *testing.T) {
+	repo, mr := newRepo(t)
+	c := cacher.New(repo, time.Minute)
+	got := make(chan cacher.Event, 2)
+	c.OnEvent(func(ev cacher.Event) { got <- ev })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- c.WatchKeyEvents(ctx) }()
+
+	//miniredis 不产生键事件通知，手动发布到键事件频道
+	for deadline := time.Now().Add(time.Second); mr.Publish("__keyevent@0__:expired", "user:1") == 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("not subscribed")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mr.Publish("__keyevent@0__:evicted", "user:2")
+	for _, want := range []cacher.Event{{Type: cacher.EventExpire, Key: "user:1"}, {Type: cacher.EventEvict, Key: "user:2"}} {
+		select {
+		case ev := <-got:
+			if ev.Type != want.Type || ev.Key != want.Key {
+				t.Errorf("event = %+v, want %v of %v", ev, want.Type, want.Key)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%v of %v not delivered", want.Type, want.Key)
+		}
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("WatchKeyEvents() = %v, want context.Canceled", err)
+	}
+}
+
+func TestRepo_GetMulti(t *testing.T) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	if err := mr.Set("a", "1"); err != nil {
+		t.Fatal(err)
+	}
+	vals, err := repo.GetMulti(ctx, []string{"a", "missing"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []interface{}{[]byte("1"), nil}; !reflect.DeepEqual(vals, want) {
+		t.Errorf("GetMulti() = %v, want %v", vals, want)
+	}
+
+	c := cacher.New(repo, time.Minute)
+	var m map[string]int
+	err = c.GetMulti(ctx, []string{"a", "b"}, func(missing []string) (map[string]interface{}, error) {
+		return map[string]interface{}{"b": 2}, nil
+	}, &m)
+	if want := map[string]int{"a": 1, "b": 2}; err != nil || !reflect.DeepEqual(m, want) {
+		t.Errorf("Cacher.GetMulti() = %v, %v, want %v", m, err, want)
+	}
+	if v, _ := mr.Get("b"); v != "2" {
+		t.Errorf("b = %q, want 2", v)
+	}
+
+	if err := repo.MSet(ctx, cacher.RepoEntry{Key: "x", Value: "1", Expire: time.Minute}, cacher.RepoEntry{Key: "y", Value: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if v, _ := mr.Get("y"); v != "2" || mr.TTL("x") <= 0 {
+		t.Errorf("MSet() y = %q, x ttl = %v", v, mr.TTL("x"))
+	}
+	if err := repo.MDel(ctx, "x", "y"); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("x") || mr.Exists("y") {
+		t.Errorf("MDel() left keys")
+	}
+}
+
+func TestRepo_GetWithTTL(t *testing.T) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	if err := repo.Set(ctx, "a", "1", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Set(ctx, "b", "2", 0); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		key     string
+		want    interface{}
+		wantTTL time.Duration
+	}{
+		{key: "a", want: []byte("1"), wantTTL: time.Minute},
+		{key: "b", want: []byte("2"), wantTTL: -1},
+		{key: "missing", want: nil, wantTTL: -2},
+	}
+	for _, tt := range tests {
+		v, ttl, err := repo.GetWithTTL(ctx, tt.key)
+		if err != nil || !reflect.DeepEqual(v, tt.want) || ttl != tt.wantTTL {
+			t.Errorf("GetWithTTL(%s) = %v, %v, %v, want %v, %v", tt.key, v, ttl, err, tt.want, tt.wantTTL)
+		}
+	}
+
+	//二级缓存剩余1秒时，一级缓存只保留1秒
+	mr.FastForward(59 * time.Second)
+	l1 := cacher.NewMemoryRepo()
+	tiered := cacher.NewTieredRepo(l1, repo, time.Minute)
+	if v, err := tiered.Get(ctx, "a"); err != nil || string(v.([]byte)) != "1" {
+		t.Fatalf("TieredRepo.Get() = %v, %v", v, err)
+	}
+	if ttl, _ := l1.TTL(ctx, "a"); ttl <= 0 || ttl > time.Second {
+		t.Errorf("l1 TTL = %v, want <= 1s", ttl)
+	}
+}
+
+func TestRepo_SortedSet(t *testing.T) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	board := cacher.New(repo, time.Minute).SortedSet("board")
+	if err := board.Add(ctx, cacher.ZMember{Member: "a", Score: 3}, cacher.ZMember{Member: "b", Score: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if score, err := board.IncrBy(ctx, "b", 5); err != nil || score != 6 {
+		t.Fatalf("IncrBy() = %v, %v", score, err)
+	}
+	top, err := board.Top(ctx, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []cacher.ZMember{{Member: "b", Score: 6}, {Member: "a", Score: 3}}
+	if !reflect.DeepEqual(top, want) {
+		t.Errorf("Top() = %v, want %v", top, want)
+	}
+	if err := board.Del(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if mr.Exists("board") {
+		t.Errorf("board not deleted")
+	}
+}
+
+func TestRepo_Hash(t *testing.T) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	h := cacher.New(repo, time.Minute).Hash("person:1")
+	if err := h.Set(ctx, person{Name: "tom", Age: 18}, 0); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("person:1"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want 1m", ttl)
+	}
+	if err := h.SetField(ctx, "Age", 19); err != nil {
+		t.Fatal(err)
+	}
+	var p person
+	if ok, err := h.Get(ctx, &p); !ok || err != nil || p != (person{Name: "tom", Age: 19}) {
+		t.Errorf("Get() = %v, %v, p = %+v", ok, err, p)
+	}
+	var name string
+	if ok, err := h.GetField(ctx, "Name", &name); !ok || err != nil || name != "tom" {
+		t.Errorf("GetField() = %v, %v, name = %v", ok, err, name)
+	}
+	if ok, err := h.GetField(ctx, "missing", &name); ok || err != nil {
+		t.Errorf("GetField(missing) = %v, %v", ok, err)
+	}
+}
+
+func TestRepo_Queue(t *testing.T) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	q := cacher.New(repo, time.Minute).Queue("jobs")
+	if err := q.Push(ctx, "a", "b", "c"); err != nil {
+		t.Fatal(err)
+	}
+	if ttl := mr.TTL("jobs"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want 1m", ttl)
+	}
+	if err := q.Trim(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	var recent []string
+	if err := q.Range(ctx, 0, -1, &recent); err != nil || !reflect.DeepEqual(recent, []string{"c", "b"}) {
+		t.Errorf("Range() = %v, %v", recent, err)
+	}
+	var v string
+	for _, want := range []string{"b", "c"} {
+		if ok, err := q.Pop(ctx, &v); !ok || err != nil || v != want {
+			t.Errorf("Pop() = %v, %v, v = %v, want %v", ok, err, v, want)
+		}
+	}
+	if ok, err := q.Pop(ctx, &v); ok || err != nil {
+		t.Errorf("Pop() on empty = %v, %v", ok, err)
+	}
+}
+
+func TestRepo_Set(t *testing.T) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	s := cacher.New(repo, time.Minute).Set("seen")
+	for _, want := range []bool{false, true} {
+		if seen, err := s.Seen(ctx, "a"); err != nil || seen != want {
+			t.Errorf("Seen() = %v, %v, want %v", seen, err, want)
+		}
+	}
+	if ttl := mr.TTL("seen"); ttl != time.Minute {
+		t.Errorf("TTL = %v, want 1m", ttl)
+	}
+	if err := s.Remove(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := s.Contains(ctx, "a"); ok || err != nil {
+		t.Errorf("Contains() after Remove = %v, %v", ok, err)
+	}
+}
+
+func TestRepo_Lease(t *testing.T) {
+	ctx := context.Background()
+	repo, _ := newRepo(t)
+	c1, c2 := cacher.New(repo, time.Minute), cacher.New(repo, time.Minute)
+	var v string
+	_, lease, err := c1.GetOrLease(ctx, "k", time.Second, &v)
+	if err != nil || lease == nil {
+		t.Fatalf("GetOrLease() = %v, %v", lease, err)
+	}
+	if _, _, err := c2.GetOrLease(ctx, "k", time.Second, &v); err != cacher.ErrLeaseHeld {
+		t.Fatalf("GetOrLease() on another Cacher error = %v, want ErrLeaseHeld", err)
+	}
+	if err := c1.Fill(ctx, lease, "v"); err != nil {
+		t.Fatal(err)
+	}
+	if hit, _, err := c2.GetOrLease(ctx, "k", time.Second, &v); !hit || err != nil || v != "v" {
+		t.Errorf("GetOrLease() after Fill = %v, %v, v = %v", hit, err, v)
+	}
+}
+
+func TestRepo_SetMulti(t *testing.T) {
+	ctx := context.Background()
+	repo, mr := newRepo(t)
+	c := cacher.New(repo, time.Minute)
+	st := c.PrepareSet()
+	for key, value := range map[string]interface{}{"user:1": person{Name: "tom"}, "user:name:tom": "1"} {
+		if err := st.Set(ctx, key, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := st.Commit(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := mr.Get("user:1"); got != `{"name":"tom","age":0}` {
+		t.Errorf("user:1 = %q", got)
+	}
+	if got, _ := mr.Get("user:name:tom"); got != "1" {
+		t.Errorf("user:name:tom = %q", got)
+	}
+	if ttl := mr.TTL("user:1"); ttl <= 0 {
+		t.Errorf("TTL = %v, want > 0", ttl)
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/carteruu/cacher/repo/rueidis
+
+go 1.18
+
+replace github.com/carteruu/cacher => ../..
+
+require (
+	github.com/alicebob/miniredis/v2 v2.30.0
+	github.com/carteruu/cacher v0.0.0-00010101000000-000000000000
+	github.com/redis/rueidis v1.0.0
+)
+
+require (
+	github.com/alicebob/gopher-json v0.0.0-20200520072559-a9ecdc9d1d3a // indirect
+	github.com/yuin/gopher-lua v0.0.0-20220504180219-658193537a64 // indirect
+	golang.org/x/sync v0.0.0-20220923202941-7f9b1623fab7 // indirect
+)
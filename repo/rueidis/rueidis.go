@@ -0,0 +1,226 @@
+// Package rueidis 基于 rueidis 的存储库，利用 Redis 6 的服务端辅助客户端缓存：
+// 读取的缓存在进程内保留，Redis 通过 RESP3 的 CLIENT TRACKING 在缓存键被修改或删除时通知客户端失效，
+// 热点缓存键的读取不需要访问 Redis，也不需要自行订阅失效消息
+package rueidis
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/carteruu/cacher"
+	rueidiscli "github.com/redis/rueidis"
+	"time"
+)
+
+// defaultLocalTTL 进程内缓存的默认最长保留时长
+const defaultLocalTTL = time.Minute
+
+type (
+	// Repo 基于 rueidis 的存储库，实现 cacher.Repo 和 cacher.NXRepo、cacher.TTLRepo、cacher.MultiGetRepo、cacher.BatchRepo
+	Repo struct {
+		client   rueidiscli.Client
+		localTTL time.Duration
+	}
+	// Option 存储库的选项
+	Option struct {
+		// LocalTTL 进程内缓存的最长保留时长，默认为1分钟，同时受 Redis 中剩余保留时长的限制。
+		// 失效通知正常时进程内缓存总是最新的，该时长只限制连接异常时读到旧数据的时间。小于0时不使用进程内缓存
+		LocalTTL time.Duration
+	}
+)
+
+var (
+	_ cacher.Repo         = (*Repo)(nil)
+	_ cacher.NXRepo       = (*Repo)(nil)
+	_ cacher.TTLRepo      = (*Repo)(nil)
+	_ cacher.MultiGetRepo = (*Repo)(nil)
+	_ cacher.BatchRepo    = (*Repo)(nil)
+)
+
+// WithLocalTTL 设置进程内缓存的最长保留时长
+func WithLocalTTL(ttl time.Duration) func(opt *Option) {
+	return func(opt *Option) {
+		opt.LocalTTL = ttl
+	}
+}
+
+// New 创建存储库。Redis 不支持 RESP3 或客户端缓存时，client 需要设置 ClientOption.DisableCache，此时每次读取都访问 Redis
+func New(client rueidiscli.Client, optFns ...func(opt *Option)) *Repo {
+	opt := Option{LocalTTL: defaultLocalTTL}
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	return &Repo{client: client, localTTL: opt.LocalTTL}
+}
+
+// Get 获取缓存，优先读取进程内缓存，缓存不存在时返回 nil, nil
+func (r *Repo) Get(ctx context.Context, key string) (interface{}, error) {
+	var resp rueidiscli.RedisResult
+	if r.localTTL > 0 {
+		resp = r.client.DoCache(ctx, r.client.B().Get().Key(key).Cache(), r.localTTL)
+	} else {
+		resp = r.client.Do(ctx, r.client.B().Get().Key(key).Build())
+	}
+	return bytesOf(resp)
+}
+
+// GetMulti 一次读取多个缓存，只有进程内没有缓存的缓存键访问 Redis，集群模式下缓存键可以在不同的哈希槽中
+func (r *Repo) GetMulti(ctx context.Context, keys []string) ([]interface{}, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	var resps []rueidiscli.RedisResult
+	if r.localTTL > 0 {
+		cmds := make([]rueidiscli.CacheableTTL, len(keys))
+		for i, key := range keys {
+			cmds[i] = rueidiscli.CT(r.client.B().Get().Key(key).Cache(), r.localTTL)
+		}
+		resps = r.client.DoMultiCache(ctx, cmds...)
+	} else {
+		cmds := make(rueidiscli.Commands, len(keys))
+		for i, key := range keys {
+			cmds[i] = r.client.B().Get().Key(key).Build()
+		}
+		resps = r.client.DoMulti(ctx, cmds...)
+	}
+	vals := make([]interface{}, len(keys))
+	for i, resp := range resps {
+		val, err := bytesOf(resp)
+		if err != nil {
+			return nil, err
+		}
+		vals[i] = val
+	}
+	return vals, nil
+}
+
+// MGet 同 GetMulti
+func (r *Repo) MGet(ctx context.Context, keys ...string) ([]interface{}, error) {
+	return r.GetMulti(ctx, keys)
+}
+
+// Set 保存缓存。字符串和字节切片原样保存，其他类型保存为 JSON。其他客户端的进程内缓存由 Redis 通知失效
+func (r *Repo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	cmd, err := r.set(key, value, expire, false)
+	if err != nil {
+		return err
+	}
+	return r.client.Do(ctx, cmd).Error()
+}
+
+// MSet 一次保存多个缓存，不保证原子性，集群模式下缓存键可以在不同的哈希槽中
+func (r *Repo) MSet(ctx context.Context, entries ...cacher.RepoEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	cmds := make(rueidiscli.Commands, len(entries))
+	for i, entry := range entries {
+		cmd, err := r.set(entry.Key, entry.Value, entry.Expire, false)
+		if err != nil {
+			return err
+		}
+		cmds[i] = cmd
+	}
+	return firstError(r.client.DoMulti(ctx, cmds...))
+}
+
+// SetNX 缓存键不存在时保存
+func (r *Repo) SetNX(ctx context.Context, key string, value interface{}, expire time.Duration) (bool, error) {
+	cmd, err := r.set(key, value, expire, true)
+	if err != nil {
+		return false, err
+	}
+	err = r.client.Do(ctx, cmd).Error()
+	if rueidiscli.IsRedisNil(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// TTL 查询剩余保留时长，缓存不存在时返回 -2，不过期时返回 -1
+func (r *Repo) TTL(ctx context.Context, key string) (time.Duration, error) {
+	ms, err := r.client.Do(ctx, r.client.B().Pttl().Key(key).Build()).AsInt64()
+	if err != nil {
+		return 0, err
+	}
+	if ms < 0 {
+		return time.Duration(ms), nil
+	}
+	return time.Duration(ms) * time.Millisecond, nil
+}
+
+// Del 删除缓存，每个缓存键单独删除，集群模式下缓存键可以在不同的哈希槽中
+func (r *Repo) Del(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	cmds := make(rueidiscli.Commands, len(keys))
+	for i, key := range keys {
+		cmds[i] = r.client.B().Del().Key(key).Build()
+	}
+	return firstError(r.client.DoMulti(ctx, cmds...))
+}
+
+// MDel 同 Del
+func (r *Repo) MDel(ctx context.Context, keys ...string) error {
+	return r.Del(ctx, keys...)
+}
+
+// set 生成 SET 命令，nx 为 true 时只在缓存键不存在时保存
+func (r *Repo) set(key string, value interface{}, expire time.Duration, nx bool) (rueidiscli.Completed, error) {
+	val, err := encode(value)
+	if err != nil {
+		return rueidiscli.Completed{}, err
+	}
+	//不足1毫秒的保留时长按1毫秒保存
+	ms := expire.Milliseconds()
+	if expire > 0 && ms == 0 {
+		ms = 1
+	}
+	cmd := r.client.B().Set().Key(key).Value(val)
+	switch {
+	case nx && expire > 0:
+		return cmd.Nx().PxMilliseconds(ms).Build(), nil
+	case nx:
+		return cmd.Nx().Build(), nil
+	case expire > 0:
+		return cmd.PxMilliseconds(ms).Build(), nil
+	}
+	return cmd.Build(), nil
+}
+
+// bytesOf 读取字符串结果，不存在时返回 nil, nil
+func bytesOf(resp rueidiscli.RedisResult) (interface{}, error) {
+	val, err := resp.AsBytes()
+	if rueidiscli.IsRedisNil(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return val, nil
+}
+
+// firstError 多个命令结果中的第一个错误
+func firstError(resps []rueidiscli.RedisResult) error {
+	for _, resp := range resps {
+		if err := resp.Error(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// encode 字符串和字节切片原样保存，其他类型编码为 JSON
+func encode(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case []byte:
+		return rueidiscli.BinaryString(v), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return rueidiscli.BinaryString(data), nil
+}
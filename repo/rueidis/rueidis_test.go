@@ -0,0 +1,81 @@
+package rueidis_test
+
+import (
+	"context"
+	"github.com/alicebob/miniredis/v2"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cachertest"
+	rueidisrepo "github.com/carteruu/cacher/repo/rueidis"
+	"github.com/redis/rueidis"
+	"testing"
+	"time"
+)
+
+func newRepo(t *testing.T, optFns ...func(opt *rueidisrepo.Option)) (*rueidisrepo.Repo, *miniredis.Miniredis) {
+	mr := miniredis.RunT(t)
+	//miniredis 不支持客户端缓存
+	client, err := rueidis.NewClient(rueidis.ClientOption{InitAddress: []string{mr.Addr()}, DisableCache: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return rueidisrepo.New(client, optFns...), mr
+}
+
+func TestRepo_Suite(t *testing.T) {
+	var mr *miniredis.Miniredis
+	cachertest.RunRepoSuite(t, func(t *testing.T) cacher.Repo {
+		var repo *rueidisrepo.Repo
+		repo, mr = newRepo(t)
+		return repo
+	}, cachertest.WithAdvance(func(d time.Duration) {
+		mr.FastForward(d)
+	}))
+}
+
+func TestRepo(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		optFns []func(opt *rueidisrepo.Option)
+	}{
+		{name: "进程内缓存"},
+		{name: "不使用进程内缓存", optFns: []func(opt *rueidisrepo.Option){rueidisrepo.WithLocalTTL(-1)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, mr := newRepo(t, tt.optFns...)
+			if err := repo.Set(ctx, "a", map[string]int{"n": 1}, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if v, err := mr.Get("a"); err != nil || v != `{"n":1}` {
+				t.Errorf("saved = %q, %v", v, err)
+			}
+			if ok, err := repo.SetNX(ctx, "a", "x", time.Minute); ok || err != nil {
+				t.Errorf("SetNX(existing) = %v, %v", ok, err)
+			}
+			if ok, err := repo.SetNX(ctx, "b", "y", time.Millisecond/2); !ok || err != nil {
+				t.Errorf("SetNX(new) = %v, %v", ok, err)
+			}
+			if ttl, err := repo.TTL(ctx, "b"); err != nil || ttl <= 0 || ttl > time.Millisecond {
+				t.Errorf("TTL(b) = %v, %v", ttl, err)
+			}
+			if ttl, err := repo.TTL(ctx, "none"); err != nil || ttl != -2 {
+				t.Errorf("TTL(none) = %v, %v", ttl, err)
+			}
+			vals, err := repo.GetMulti(ctx, []string{"a", "none", "b"})
+			if err != nil || len(vals) != 3 || string(vals[0].([]byte)) != `{"n":1}` || vals[1] != nil || string(vals[2].([]byte)) != "y" {
+				t.Errorf("GetMulti() = %v, %v", vals, err)
+			}
+			if err := repo.MSet(ctx, cacher.RepoEntry{Key: "c", Value: "1"}, cacher.RepoEntry{Key: "d", Value: []byte("2"), Expire: time.Minute}); err != nil {
+				t.Fatal(err)
+			}
+			if err := repo.MDel(ctx, "a", "c"); err != nil {
+				t.Fatal(err)
+			}
+			if keys := mr.Keys(); len(keys) != 2 || keys[0] != "b" || keys[1] != "d" {
+				t.Errorf("keys = %v, want [b d]", keys)
+			}
+		})
+	}
+}
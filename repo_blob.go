@@ -0,0 +1,202 @@
+package cacher
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrBlobNotFound 对象存储中没有该对象，BlobStore.Get 应当返回该错误（或包装该错误）
+var ErrBlobNotFound = errors.New("对象不存在")
+
+type (
+	// BlobStore 对象存储，例如 S3、GCS。实现只需要按名称读写和删除对象，保留时长由 BlobRepo 的元数据存储库管理
+	BlobStore interface {
+		// Put 写入对象，size 为数据长度，同名对象被覆盖
+		Put(ctx context.Context, name string, r io.Reader, size int64) error
+		// Get 读取对象，对象不存在时返回 ErrBlobNotFound
+		Get(ctx context.Context, name string) (io.ReadCloser, error)
+		// Delete 删除对象，对象不存在时不返回错误
+		Delete(ctx context.Context, name string) error
+	}
+	// BlobRepo 对象存储库，用于几 MB 以上、很少变化的计算结果等放在 Redis 中成本过高的缓存。
+	// 缓存数据保存在对象存储中，对象名和保留时长作为元数据保存在元数据存储库中，元数据过期即视为缓存过期。
+	// 过期的对象不会立即删除，再次保存同一个缓存键时被覆盖，建议在对象存储中为 Prefix 配置生命周期规则清理
+	BlobRepo struct {
+		store  BlobStore
+		meta   Repo
+		prefix string
+	}
+	// BlobOption BlobRepo 的选项
+	BlobOption struct {
+		Meta   Repo   //元数据存储库，默认为进程内的 MemoryRepo，多个实例共享对象存储时应当使用共享的存储库
+		Prefix string //对象名前缀，默认为 cacher/
+	}
+	// DirBlobStore 以本地目录为对象存储，用于开发和测试
+	DirBlobStore struct {
+		dir string
+	}
+)
+
+var (
+	_ Repo      = (*BlobRepo)(nil)
+	_ BlobStore = (*DirBlobStore)(nil)
+)
+
+// WithBlobMeta 设置元数据存储库
+func WithBlobMeta(meta Repo) func(opt *BlobOption) {
+	return func(opt *BlobOption) {
+		opt.Meta = meta
+	}
+}
+
+// WithBlobPrefix 设置对象名前缀
+func WithBlobPrefix(prefix string) func(opt *BlobOption) {
+	return func(opt *BlobOption) {
+		opt.Prefix = prefix
+	}
+}
+
+// NewBlobRepo 创建对象存储库
+func NewBlobRepo(store BlobStore, optFns ...func(opt *BlobOption)) *BlobRepo {
+	opt := BlobOption{Prefix: "cacher/"}
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	if opt.Meta == nil {
+		opt.Meta = NewMemoryRepo()
+	}
+	return &BlobRepo{store: store, meta: opt.Meta, prefix: opt.Prefix}
+}
+
+// Get 获取缓存，缓存不存在或已过期时返回 nil, nil，存在时返回字节切片
+func (r *BlobRepo) Get(ctx context.Context, key string) (interface{}, error) {
+	rc, err := r.Open(ctx, key)
+	if err != nil || rc == nil {
+		return nil, err
+	}
+	defer rc.Close()
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Open 以流的方式读取缓存，不需要把整个对象读入内存，缓存不存在或已过期时返回 nil, nil。调用方负责关闭
+func (r *BlobRepo) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	name, err := r.objectName(ctx, key)
+	if err != nil || name == "" {
+		return nil, err
+	}
+	rc, err := r.store.Get(ctx, name)
+	if errors.Is(err, ErrBlobNotFound) {
+		return nil, nil
+	}
+	return rc, err
+}
+
+// objectName 从元数据读取缓存键对应的对象名，缓存不存在时返回空字符串
+func (r *BlobRepo) objectName(ctx context.Context, key string) (string, error) {
+	meta, err := r.meta.Get(ctx, key)
+	if err != nil || meta == nil {
+		return "", err
+	}
+	switch name := meta.(type) {
+	case string:
+		return name, nil
+	case []byte:
+		return string(name), nil
+	}
+	return "", fmt.Errorf("缓存 %s 的元数据类型错误：%T", key, meta)
+}
+
+// Set 保存缓存。字符串和字节切片原样保存，其他类型保存为 JSON。先写入对象再写入元数据，读取方不会读到不完整的对象
+func (r *BlobRepo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	data, err := peerEncode(value)
+	if err != nil {
+		return err
+	}
+	name := r.name(key)
+	if err := r.store.Put(ctx, name, bytes.NewReader(data), int64(len(data))); err != nil {
+		return err
+	}
+	return r.meta.Set(ctx, key, name, expire)
+}
+
+// Del 删除缓存，先删除元数据再删除对象
+func (r *BlobRepo) Del(ctx context.Context, keys ...string) error {
+	if err := r.meta.Del(ctx, keys...); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := r.store.Delete(ctx, r.name(key)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// name 缓存键对应的对象名，使用哈希值避免缓存键中的字符不能用于对象名
+func (r *BlobRepo) name(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return r.prefix + hex.EncodeToString(sum[:])
+}
+
+// NewDirBlobStore 创建以目录 dir 为对象存储的 BlobStore，目录不存在时自动创建
+func NewDirBlobStore(dir string) (*DirBlobStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &DirBlobStore{dir: dir}, nil
+}
+
+// Put 先写入临时文件再重命名，读取方不会读到写了一半的文件
+func (s *DirBlobStore) Put(_ context.Context, name string, r io.Reader, _ int64) error {
+	path := s.path(name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Get 打开对象对应的文件
+func (s *DirBlobStore) Get(_ context.Context, name string) (io.ReadCloser, error) {
+	f, err := os.Open(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrBlobNotFound
+	}
+	return f, err
+}
+
+// Delete 删除对象对应的文件
+func (s *DirBlobStore) Delete(_ context.Context, name string) error {
+	err := os.Remove(s.path(name))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+func (s *DirBlobStore) path(name string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(name))
+}
@@ -0,0 +1,70 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cachertest"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newBlobRepo(t *testing.T) (*cacher.BlobRepo, string) {
+	dir := t.TempDir()
+	store, err := cacher.NewDirBlobStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cacher.NewBlobRepo(store), dir
+}
+
+func TestBlobRepo_Suite(t *testing.T) {
+	cachertest.RunRepoSuite(t, func(t *testing.T) cacher.Repo {
+		repo, _ := newBlobRepo(t)
+		return repo
+	})
+}
+
+func TestBlobRepo(t *testing.T) {
+	ctx := context.Background()
+	repo, dir := newBlobRepo(t)
+	large := strings.Repeat("x", 4<<20)
+	if err := repo.Set(ctx, "artifact", large, time.Minute); err != nil {
+		t.Fatal(err)
+	}
+
+	//以流的方式读取
+	rc, err := repo.Open(ctx, "artifact")
+	if err != nil || rc == nil {
+		t.Fatalf("Open() = %v, %v", rc, err)
+	}
+	n, err := io.Copy(io.Discard, rc)
+	rc.Close()
+	if err != nil || n != int64(len(large)) {
+		t.Errorf("Open() read %d bytes, %v, want %d", n, err, len(large))
+	}
+
+	//对象只有一个，不留临时文件
+	files, _ := filepath.Glob(filepath.Join(dir, "cacher", "*"))
+	if len(files) != 1 {
+		t.Errorf("objects = %v, want 1", files)
+	}
+
+	//对象被外部删除时视为缓存不存在
+	_ = os.Remove(files[0])
+	if got, err := repo.Get(ctx, "artifact"); got != nil || err != nil {
+		t.Errorf("Get() of deleted object = %v, %v, want nil, nil", got, err)
+	}
+
+	//删除缓存同时删除对象
+	_ = repo.Set(ctx, "artifact", "v", time.Minute)
+	if err := repo.Del(ctx, "artifact", "missing"); err != nil {
+		t.Fatal(err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "cacher", "*")); len(files) != 0 {
+		t.Errorf("objects after Del = %v, want none", files)
+	}
+}
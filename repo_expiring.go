@@ -0,0 +1,109 @@
+package cacher
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"time"
+)
+
+// expiringMagic ExpiringRepo 保存的数据的前缀，之后是8字节的过期时间（Unix 纳秒，0 表示不过期）和缓存数据
+var expiringMagic = []byte("cxp1")
+
+// expiringHeader 前缀和过期时间的长度
+const expiringHeader = 4 + 8
+
+// ExpiringRepo 为没有保留时长功能的存储库（例如普通的 SQL 表、对象存储）模拟保留时长：
+// 写入时把过期时间和缓存数据一起保存，读取时已过期的缓存视为不存在并删除，不需要存储库自己清理过期数据。
+// 保存的数据是字节切片，读取时返回字节切片；没有过期时间前缀的旧数据原样返回
+type ExpiringRepo struct {
+	repo Repo
+}
+
+var (
+	_ Repo    = (*ExpiringRepo)(nil)
+	_ TTLRepo = (*ExpiringRepo)(nil)
+)
+
+// NewExpiringRepo 创建模拟保留时长的存储库
+func NewExpiringRepo(repo Repo) *ExpiringRepo {
+	return &ExpiringRepo{repo: repo}
+}
+
+// Get 获取缓存，已过期时删除缓存并返回 nil, nil
+func (r *ExpiringRepo) Get(ctx context.Context, key string) (interface{}, error) {
+	data, err := r.repo.Get(ctx, key)
+	if err != nil || data == nil {
+		return nil, err
+	}
+	value, expireAt, ok := openExpiring(data)
+	if !ok {
+		return data, nil
+	}
+	if !expireAt.IsZero() && !time.Now().Before(expireAt) {
+		//删除失败不影响本次读取，下次读取时再删除
+		_ = r.repo.Del(ctx, key)
+		return nil, nil
+	}
+	return value, nil
+}
+
+// Set 把过期时间和缓存数据一起保存，expire 小于等于0时不过期。字符串和字节切片原样保存，其他类型保存为 JSON
+func (r *ExpiringRepo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	payload, err := peerEncode(value)
+	if err != nil {
+		return err
+	}
+	data := make([]byte, expiringHeader+len(payload))
+	copy(data, expiringMagic)
+	if expire > 0 {
+		binary.BigEndian.PutUint64(data[len(expiringMagic):], uint64(time.Now().Add(expire).UnixNano()))
+	}
+	copy(data[expiringHeader:], payload)
+	//存储库中的数据不会自动过期，不传递保留时长
+	return r.repo.Set(ctx, key, data, 0)
+}
+
+// Del 删除缓存
+func (r *ExpiringRepo) Del(ctx context.Context, keys ...string) error {
+	return r.repo.Del(ctx, keys...)
+}
+
+// TTL 按保存的过期时间计算剩余保留时长，缓存不存在或已过期时返回 -2，不过期或没有过期时间时返回 -1
+func (r *ExpiringRepo) TTL(ctx context.Context, key string) (time.Duration, error) {
+	data, err := r.repo.Get(ctx, key)
+	if err != nil {
+		return 0, err
+	}
+	if data == nil {
+		return -2, nil
+	}
+	_, expireAt, ok := openExpiring(data)
+	if !ok || expireAt.IsZero() {
+		return -1, nil
+	}
+	if ttl := time.Until(expireAt); ttl > 0 {
+		return ttl, nil
+	}
+	return -2, nil
+}
+
+// openExpiring 拆出过期时间和缓存数据，数据没有过期时间前缀时 ok 为 false
+func openExpiring(data interface{}) (value []byte, expireAt time.Time, ok bool) {
+	var b []byte
+	switch v := data.(type) {
+	case []byte:
+		b = v
+	case string:
+		b = []byte(v)
+	default:
+		return nil, time.Time{}, false
+	}
+	if len(b) < expiringHeader || !bytes.HasPrefix(b, expiringMagic) {
+		return nil, time.Time{}, false
+	}
+	if ns := binary.BigEndian.Uint64(b[len(expiringMagic):]); ns != 0 {
+		expireAt = time.Unix(0, int64(ns))
+	}
+	return b[expiringHeader:], expireAt, true
+}
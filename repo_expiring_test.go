@@ -0,0 +1,58 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cachertest"
+	"testing"
+	"time"
+)
+
+func TestExpiringRepo_Suite(t *testing.T) {
+	//repoMap 没有保留时长功能
+	cachertest.RunRepoSuite(t, func(t *testing.T) cacher.Repo {
+		return cacher.NewExpiringRepo(newRepoMap(nil))
+	})
+}
+
+func TestExpiringRepo(t *testing.T) {
+	ctx := context.Background()
+	backend := newRepoMap(map[string]interface{}{"legacy": "old"})
+	repo := cacher.NewExpiringRepo(backend)
+	c := cacher.New(repo, 20*time.Millisecond)
+
+	loads := 0
+	query := func() (interface{}, error) {
+		loads++
+		return loads, nil
+	}
+	var v int
+	for i, want := range []int{1, 1} {
+		if _, err := c.Get(ctx, "k", query, &v); err != nil || v != want {
+			t.Fatalf("Get() #%d = %d, %v, want %d", i, v, err, want)
+		}
+	}
+	if ttl, err := repo.TTL(ctx, "k"); err != nil || ttl <= 0 {
+		t.Errorf("TTL() = %v, %v, want > 0", ttl, err)
+	}
+
+	//过期后视为不存在，读取时删除
+	time.Sleep(30 * time.Millisecond)
+	if got, err := repo.Get(ctx, "k"); got != nil || err != nil {
+		t.Errorf("Get() after expire = %#v, %v, want nil, nil", got, err)
+	}
+	if got, _ := backend.Get(ctx, "k"); got != nil {
+		t.Errorf("expired entry not deleted: %#v", got)
+	}
+	if _, err := c.Get(ctx, "k", query, &v); err != nil || v != 2 {
+		t.Errorf("Get() after expire = %d, %v, want reload 2", v, err)
+	}
+
+	//没有过期时间的旧数据原样返回
+	if got, err := repo.Get(ctx, "legacy"); got != "old" || err != nil {
+		t.Errorf("Get(legacy) = %#v, %v", got, err)
+	}
+	if ttl, _ := repo.TTL(ctx, "legacy"); ttl != -1 {
+		t.Errorf("TTL(legacy) = %v, want -1", ttl)
+	}
+}
@@ -0,0 +1,167 @@
+package cacher
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPTTLHeader HTTPRepo 保存缓存时通过该请求头传递保留时长，单位为毫秒，0 表示不过期
+const HTTPTTLHeader = "X-Cache-TTL"
+
+type (
+	// HTTPRepo 通过 REST 协议访问 HTTP 缓存服务的存储库，用于只能访问 HTTP 缓存服务的环境。协议：
+	//
+	//	GET    {BaseURL}/cache/{key}  读取缓存，200 返回缓存数据，404 表示缓存不存在
+	//	PUT    {BaseURL}/cache/{key}  保存缓存，请求体为缓存数据，保留时长通过 X-Cache-TTL 请求头传递
+	//	DELETE {BaseURL}/cache/{key}  删除缓存，缓存不存在时返回 404 也视为成功
+	//
+	// 缓存键经过路径转义。连接错误、5xx 和 429 响应按指数退避重试，三种请求都是幂等的
+	HTTPRepo struct {
+		baseURL string
+		client  *http.Client
+		retries int
+		backoff time.Duration
+	}
+	// HTTPOption HTTPRepo 的选项
+	HTTPOption struct {
+		Client       *http.Client  //HTTP 客户端，为空时创建按 MaxIdleConns 复用连接的客户端
+		MaxIdleConns int           //每个主机保持的最大空闲连接数，默认为64，设置了 Client 时无效
+		Retries      int           //出错时的最多重试次数，默认为2
+		Backoff      time.Duration //第一次重试前的等待时长，之后每次翻倍，默认为50毫秒
+	}
+)
+
+var _ Repo = (*HTTPRepo)(nil)
+
+// WithHTTPClient 设置 HTTP 客户端
+func WithHTTPClient(client *http.Client) func(opt *HTTPOption) {
+	return func(opt *HTTPOption) {
+		opt.Client = client
+	}
+}
+
+// WithHTTPMaxIdleConns 设置每个主机保持的最大空闲连接数
+func WithHTTPMaxIdleConns(n int) func(opt *HTTPOption) {
+	return func(opt *HTTPOption) {
+		opt.MaxIdleConns = n
+	}
+}
+
+// WithHTTPRetries 设置出错时的最多重试次数和第一次重试前的等待时长，retries 为0时不重试
+func WithHTTPRetries(retries int, backoff time.Duration) func(opt *HTTPOption) {
+	return func(opt *HTTPOption) {
+		opt.Retries = retries
+		opt.Backoff = backoff
+	}
+}
+
+// NewHTTPRepo 创建 HTTP 存储库，baseURL 是缓存服务的地址，例如 http://cache.internal:8080
+func NewHTTPRepo(baseURL string, optFns ...func(opt *HTTPOption)) *HTTPRepo {
+	opt := HTTPOption{MaxIdleConns: 64, Retries: 2, Backoff: 50 * time.Millisecond}
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	client := opt.Client
+	if client == nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConns = opt.MaxIdleConns
+		transport.MaxIdleConnsPerHost = opt.MaxIdleConns
+		client = &http.Client{Transport: transport}
+	}
+	return &HTTPRepo{baseURL: strings.TrimSuffix(baseURL, "/"), client: client, retries: opt.Retries, backoff: opt.Backoff}
+}
+
+// Get 获取缓存，缓存不存在时返回 nil, nil，存在时返回字节切片
+func (r *HTTPRepo) Get(ctx context.Context, key string) (interface{}, error) {
+	var data []byte
+	found, err := r.do(ctx, http.MethodGet, key, nil, nil, func(resp *http.Response) (err error) {
+		data, err = io.ReadAll(resp.Body)
+		return err
+	})
+	if err != nil || !found {
+		return nil, err
+	}
+	return data, nil
+}
+
+// Set 保存缓存。字符串和字节切片原样保存，其他类型保存为 JSON
+func (r *HTTPRepo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	data, err := peerEncode(value)
+	if err != nil {
+		return err
+	}
+	header := http.Header{HTTPTTLHeader: {strconv.FormatInt(expire.Milliseconds(), 10)}}
+	_, err = r.do(ctx, http.MethodPut, key, header, data, nil)
+	return err
+}
+
+// Del 删除缓存，每个缓存键一个请求
+func (r *HTTPRepo) Del(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		if _, err := r.do(ctx, http.MethodDelete, key, nil, nil, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// do 发送请求，出错时重试。404 时返回 false，2xx 时调用 read 读取响应
+func (r *HTTPRepo) do(ctx context.Context, method, key string, header http.Header, body []byte, read func(resp *http.Response) error) (bool, error) {
+	u := r.baseURL + "/cache/" + url.PathEscape(key)
+	backoff := r.backoff
+	for attempt := 0; ; attempt++ {
+		found, retry, err := r.once(ctx, method, u, header, body, read)
+		if !retry || attempt >= r.retries {
+			return found, err
+		}
+		timer := time.NewTimer(backoff)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return false, err
+		case <-timer.C:
+		}
+		backoff *= 2
+	}
+}
+
+// once 发送一次请求，retry 表示错误可以重试
+func (r *HTTPRepo) once(ctx context.Context, method, u string, header http.Header, body []byte, read func(resp *http.Response) error) (found, retry bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return false, false, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return false, ctx.Err() == nil, err
+	}
+	defer func() {
+		//读完响应体才能复用连接
+		_, _ = io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}()
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return false, false, nil
+	case resp.StatusCode/100 == 2:
+		if read != nil {
+			if err := read(resp); err != nil {
+				return false, true, err
+			}
+		}
+		return true, false, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	retry = resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+	return false, retry, fmt.Errorf("缓存服务返回 %s：%s", resp.Status, bytes.TrimSpace(msg))
+}
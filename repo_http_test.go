@@ -0,0 +1,93 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cachertest"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newCacheServer 基于 MemoryRepo 的 REST 缓存服务，前 fails 个请求返回 503
+func newCacheServer(t *testing.T, fails int32) (*httptest.Server, *int32) {
+	repo := cacher.NewMemoryRepo()
+	var requests int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= fails {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		key, err := url.PathUnescape(strings.TrimPrefix(req.URL.EscapedPath(), "/cache/"))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ctx := req.Context()
+		switch req.Method {
+		case http.MethodGet:
+			value, _ := repo.Get(ctx, key)
+			if value == nil {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write(value.([]byte))
+		case http.MethodPut:
+			ms, _ := strconv.ParseInt(req.Header.Get(cacher.HTTPTTLHeader), 10, 64)
+			data, _ := io.ReadAll(req.Body)
+			_ = repo.Set(ctx, key, data, time.Duration(ms)*time.Millisecond)
+			w.WriteHeader(http.StatusNoContent)
+		case http.MethodDelete:
+			_ = repo.Del(ctx, key)
+			w.WriteHeader(http.StatusNoContent)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestHTTPRepo_Suite(t *testing.T) {
+	cachertest.RunRepoSuite(t, func(t *testing.T) cacher.Repo {
+		srv, _ := newCacheServer(t, 0)
+		return cacher.NewHTTPRepo(srv.URL)
+	})
+}
+
+func TestHTTPRepo_Retry(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		fails    int32
+		retries  int
+		wantErr  bool
+		requests int32
+	}{
+		{name: "重试后成功", fails: 2, retries: 2, requests: 3},
+		{name: "重试次数用完", fails: 3, retries: 2, wantErr: true, requests: 3},
+		{name: "不重试", fails: 1, retries: 0, wantErr: true, requests: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, requests := newCacheServer(t, tt.fails)
+			repo := cacher.NewHTTPRepo(srv.URL, cacher.WithHTTPRetries(tt.retries, time.Millisecond))
+			err := repo.Set(ctx, "a/b c", "v", time.Minute)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Set() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got := atomic.LoadInt32(requests); got != tt.requests {
+				t.Errorf("requests = %d, want %d", got, tt.requests)
+			}
+			if err == nil {
+				if got, err := repo.Get(ctx, "a/b c"); err != nil || string(got.([]byte)) != "v" {
+					t.Errorf("Get() = %#v, %v", got, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,669 @@
+package cacher
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// errWrongType 缓存键已保存了其他类型的数据
+var errWrongType = errors.New("缓存键已保存了其他类型的数据")
+
+// memoryShards 未设置最大缓存数量时的默认分片数
+const memoryShards = 16
+
+type (
+	// MemoryRepo 进程内存储库，缓存键按哈希值分到多个分片，每个分片一把锁，减少并发访问的锁竞争。
+	// 过期的数据在访问时删除，设置了 JanitorInterval 时还会在后台定期清理。设置了最大缓存数量或进程内存接近软限制时，按优先级从低到高、
+	// 同一优先级内按最近最少使用淘汰，固定的缓存键不淘汰，见 WithMaxEntries、WithMemoryLimit、Option.Priority 和 Cacher.Pin。
+	// 后台清理删除过期数据时发布 EventExpire 键事件，进程内存接近软限制淘汰数据时发布 EventEvict 键事件，在访问时删除的过期数据和超出最大缓存数量淘汰的数据不发布。
+	// 实现了 Repo、NXRepo、TTLRepo、GetWithTTLRepo、PriorityRepo、PinRepo、MultiSetRepo、HashRepo、ListRepo、SetRepo、ZRepo 和 KeyEventSource，适合测试和示例，不需要外部存储
+	MemoryRepo struct {
+		shards []*memoryShard
+		hash   HashFunc      //缓存键的哈希函数，决定所在的分片
+		done   chan struct{} //关闭时停止后台清理
+		closed sync.Once
+		events eventBus //键事件的订阅者
+	}
+	// memoryShard 一个分片，淘汰在分片内进行
+	memoryShard struct {
+		mu         sync.Mutex
+		entries    map[string]memoryEntry
+		lru        [priorityLevels]list.List //每个优先级的缓存键，头部为最近使用的，不包括固定的缓存键
+		pinned     map[string]bool           //固定的缓存键，不会被淘汰
+		maxEntries int                       //最大缓存数量，小于等于0时不限制
+	}
+	memoryEntry struct {
+		value    interface{}   //缓存数据，哈希表为 memHash，列表为 memList（下标0为头部），集合为 memSet，有序集合为 *localZSet
+		expireAt time.Time     //过期时间，零值表示不过期
+		priority Priority      //淘汰优先级
+		elem     *list.Element //在 lru 中的位置，固定的缓存键为 nil
+	}
+	// memHash、memList、memSet 哈希表、列表、集合的数据，与 Set 保存的普通 map、切片区分
+	memHash map[string]string
+	memList []string
+	memSet  map[string]struct{}
+	// MemoryOption 进程内存储库的选项
+	MemoryOption struct {
+		MaxEntries      int           //最大缓存数量，超出时淘汰，小于等于0时不限制
+		Shards          int           //分片数，默认为16，设置了 MaxEntries 时默认为1。多个分片时每个分片最多保存 MaxEntries/Shards 个，淘汰是近似的
+		JanitorInterval time.Duration //后台清理过期数据的间隔，小于等于0时只在访问时删除。设置后需要调用 Close 停止清理
+		MemoryLimit     int64         //进程内存的软限制（字节），接近时主动淘汰数据，见 WithMemoryLimit。0 表示不检查，小于0时使用 debug.SetMemoryLimit 设置的限制
+		MemoryInterval  time.Duration //检查进程内存的间隔，默认为1秒
+		Hash            HashFunc      //缓存键分到分片使用的哈希函数，默认为 FNV-1a
+	}
+)
+
+var (
+	_ Repo         = (*MemoryRepo)(nil)
+	_ NXRepo       = (*MemoryRepo)(nil)
+	_ TTLRepo      = (*MemoryRepo)(nil)
+	_ PriorityRepo = (*MemoryRepo)(nil)
+	_ PinRepo      = (*MemoryRepo)(nil)
+	_ MultiSetRepo = (*MemoryRepo)(nil)
+	_ HashRepo     = (*MemoryRepo)(nil)
+	_ ListRepo     = (*MemoryRepo)(nil)
+	_ SetRepo      = (*MemoryRepo)(nil)
+	_ ZRepo        = (*MemoryRepo)(nil)
+
+	_ KeyEventSource = (*MemoryRepo)(nil)
+)
+
+// WithMaxEntries 设置最大缓存数量
+func WithMaxEntries(n int) func(opt *MemoryOption) {
+	return func(opt *MemoryOption) {
+		opt.MaxEntries = n
+	}
+}
+
+// WithShards 设置分片数
+func WithShards(n int) func(opt *MemoryOption) {
+	return func(opt *MemoryOption) {
+		opt.Shards = n
+	}
+}
+
+// WithJanitor 设置后台清理过期数据的间隔
+func WithJanitor(interval time.Duration) func(opt *MemoryOption) {
+	return func(opt *MemoryOption) {
+		opt.JanitorInterval = interval
+	}
+}
+
+// WithHash 设置缓存键分到分片使用的哈希函数
+func WithHash(hash HashFunc) func(opt *MemoryOption) {
+	return func(opt *MemoryOption) {
+		opt.Hash = hash
+	}
+}
+
+// NewMemoryRepo 创建进程内存储库
+func NewMemoryRepo(optFns ...func(opt *MemoryOption)) *MemoryRepo {
+	var opt MemoryOption
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	if opt.Shards <= 0 {
+		opt.Shards = memoryShards
+		if opt.MaxEntries > 0 {
+			opt.Shards = 1
+		}
+	}
+	maxEntries := opt.MaxEntries
+	if maxEntries > 0 {
+		//向上取整，总数不少于 MaxEntries
+		maxEntries = (maxEntries + opt.Shards - 1) / opt.Shards
+	}
+	if opt.Hash == nil {
+		opt.Hash = fnv32a
+	}
+	r := &MemoryRepo{shards: make([]*memoryShard, opt.Shards), hash: opt.Hash, done: make(chan struct{})}
+	for i := range r.shards {
+		r.shards[i] = &memoryShard{entries: make(map[string]memoryEntry), pinned: make(map[string]bool), maxEntries: maxEntries}
+	}
+	if opt.JanitorInterval > 0 {
+		go r.janitor(opt.JanitorInterval)
+	}
+	if opt.MemoryLimit != 0 {
+		if opt.MemoryInterval <= 0 {
+			opt.MemoryInterval = time.Second
+		}
+		go r.watchMemory(opt.MemoryLimit, opt.MemoryInterval)
+	}
+	return r
+}
+
+// shard 缓存键所在的分片
+func (r *MemoryRepo) shard(key string) *memoryShard {
+	return r.shards[r.index(key)]
+}
+
+// index 缓存键所在分片的下标
+func (r *MemoryRepo) index(key string) int {
+	if len(r.shards) == 1 {
+		return 0
+	}
+	return int(r.hash(key) % uint32(len(r.shards)))
+}
+
+// janitor 定期删除所有分片中过期的数据，直到 Close
+func (r *MemoryRepo) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			for _, s := range r.shards {
+				r.publish(EventExpire, s.deleteExpired())
+			}
+		}
+	}
+}
+
+// Close 停止后台清理和内存检查，可以多次调用
+func (r *MemoryRepo) Close() error {
+	r.closed.Do(func() {
+		close(r.done)
+	})
+	return nil
+}
+
+// Len 保存的数据数量，包括已过期但还没有删除的数据
+func (r *MemoryRepo) Len() int {
+	n := 0
+	for _, s := range r.shards {
+		s.mu.Lock()
+		n += len(s.entries)
+		s.mu.Unlock()
+	}
+	return n
+}
+
+// SubscribeKeyEvents 订阅后台清理删除过期数据、进程内存接近软限制淘汰数据的键事件，阻塞直到 ctx 结束
+func (r *MemoryRepo) SubscribeKeyEvents(ctx context.Context, fn func(Event)) error {
+	cancel := r.events.subscribe(fn)
+	defer cancel()
+	<-ctx.Done()
+	return ctx.Err()
+}
+
+// publish 发布键事件。需要在释放分片的锁后调用，订阅者可以访问存储库
+func (r *MemoryRepo) publish(typ EventType, keys []string) {
+	for _, key := range keys {
+		r.events.publish(Event{Type: typ, Key: key})
+	}
+}
+
+// deleteExpired 删除过期的数据，返回删除的缓存键
+func (s *memoryShard) deleteExpired() (keys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for key, entry := range s.entries {
+		if !entry.expireAt.IsZero() && now.After(entry.expireAt) {
+			s.remove(key)
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// get 获取未过期的数据，并标记为最近使用。调用方需要持有锁
+func (s *memoryShard) get(key string) (interface{}, bool) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !entry.expireAt.IsZero() && time.Now().After(entry.expireAt) {
+		s.remove(key)
+		return nil, false
+	}
+	s.touch(entry)
+	return entry.value, true
+}
+
+// put 保存数据，expire 大于0时设置保留时长，否则保留原来的过期时间和优先级。调用方需要持有锁
+func (s *memoryShard) put(key string, value interface{}, expire time.Duration) {
+	entry, ok := s.entries[key]
+	if !ok {
+		s.set(key, value, expire, PriorityNormal)
+		return
+	}
+	entry.value = value
+	if expire > 0 {
+		entry.expireAt = time.Now().Add(expire)
+	}
+	s.entries[key] = entry
+	s.touch(entry)
+}
+
+// touch 标记为最近使用。调用方需要持有锁
+func (s *memoryShard) touch(entry memoryEntry) {
+	if entry.elem != nil {
+		s.lru[entry.priority.level()].MoveToFront(entry.elem)
+	}
+}
+
+// set 替换数据，expire 小于等于0时不过期。超出最大缓存数量时淘汰。调用方需要持有锁
+func (s *memoryShard) set(key string, value interface{}, expire time.Duration, priority Priority) {
+	s.remove(key)
+	entry := memoryEntry{value: value, priority: priority}
+	if expire > 0 {
+		entry.expireAt = time.Now().Add(expire)
+	}
+	if !s.pinned[key] {
+		entry.elem = s.lru[priority.level()].PushFront(key)
+	}
+	s.entries[key] = entry
+	s.evict()
+}
+
+// remove 删除数据。调用方需要持有锁
+func (s *memoryShard) remove(key string) {
+	entry, ok := s.entries[key]
+	if !ok {
+		return
+	}
+	if entry.elem != nil {
+		s.lru[entry.priority.level()].Remove(entry.elem)
+	}
+	delete(s.entries, key)
+}
+
+// Pin 固定缓存键，不会被淘汰
+func (r *MemoryRepo) Pin(ctx context.Context, key string) error {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pinned[key] = true
+	if entry, ok := s.entries[key]; ok && entry.elem != nil {
+		s.lru[entry.priority.level()].Remove(entry.elem)
+		entry.elem = nil
+		s.entries[key] = entry
+	}
+	return nil
+}
+
+// Unpin 取消固定缓存键，超出最大缓存数量时可能立即被淘汰
+func (r *MemoryRepo) Unpin(ctx context.Context, key string) error {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.pinned, key)
+	if entry, ok := s.entries[key]; ok && entry.elem == nil {
+		entry.elem = s.lru[entry.priority.level()].PushFront(key)
+		s.entries[key] = entry
+		s.evict()
+	}
+	return nil
+}
+
+// evict 超出最大缓存数量时，从最低优先级开始淘汰最近最少使用的数据。调用方需要持有锁
+func (s *memoryShard) evict() {
+	if s.maxEntries <= 0 {
+		return
+	}
+	for level := range s.lru {
+		for len(s.entries) > s.maxEntries && s.lru[level].Len() > 0 {
+			s.remove(s.lru[level].Back().Value.(string))
+		}
+	}
+}
+
+func (r *MemoryRepo) Get(ctx context.Context, key string) (interface{}, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, _ := s.get(key)
+	switch value.(type) {
+	case memHash, memList, memSet, *localZSet:
+		return nil, errWrongType
+	}
+	return value, nil
+}
+
+// Set 保存缓存，expire 小于等于0时不过期
+func (r *MemoryRepo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key, value, expire, PriorityNormal)
+	return nil
+}
+
+// SetPriority 按优先级保存缓存，超出最大缓存数量时优先淘汰低优先级的缓存
+func (r *MemoryRepo) SetPriority(ctx context.Context, key string, value interface{}, expire time.Duration, priority Priority) error {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key, value, expire, priority)
+	return nil
+}
+
+// SetMulti 原子地保存多个缓存
+func (r *MemoryRepo) SetMulti(ctx context.Context, entries []RepoEntry) error {
+	//按分片顺序加锁，避免与其他 SetMulti 死锁
+	locked := make([]bool, len(r.shards))
+	for _, entry := range entries {
+		locked[r.index(entry.Key)] = true
+	}
+	for i, s := range r.shards {
+		if locked[i] {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+		}
+	}
+	for _, entry := range entries {
+		r.shard(entry.Key).set(entry.Key, entry.Value, entry.Expire, PriorityNormal)
+	}
+	return nil
+}
+
+func (r *MemoryRepo) SetNX(ctx context.Context, key string, value interface{}, expire time.Duration) (bool, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.get(key); ok {
+		return false, nil
+	}
+	s.set(key, value, expire, PriorityNormal)
+	return true, nil
+}
+
+// TTL 查询剩余保留时长，缓存不存在时返回 -2，不过期时返回 -1
+func (r *MemoryRepo) TTL(ctx context.Context, key string) (time.Duration, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.get(key); !ok {
+		return -2, nil
+	}
+	expireAt := s.entries[key].expireAt
+	if expireAt.IsZero() {
+		return -1, nil
+	}
+	return time.Until(expireAt), nil
+}
+
+// GetWithTTL 读取缓存和剩余保留时长，缓存不存在时剩余保留时长为 -2，不过期时为 -1
+func (r *MemoryRepo) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	if !ok {
+		return nil, -2, nil
+	}
+	switch value.(type) {
+	case memHash, memList, memSet, *localZSet:
+		return nil, 0, errWrongType
+	}
+	expireAt := s.entries[key].expireAt
+	if expireAt.IsZero() {
+		return value, -1, nil
+	}
+	return value, time.Until(expireAt), nil
+}
+
+func (r *MemoryRepo) Del(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		s := r.shard(key)
+		s.mu.Lock()
+		s.remove(key)
+		s.mu.Unlock()
+	}
+	return nil
+}
+
+func (r *MemoryRepo) HSet(ctx context.Context, key string, fields map[string]string, expire time.Duration) error {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	hash, isHash := value.(memHash)
+	if ok && !isHash {
+		return errWrongType
+	}
+	if hash == nil {
+		hash = make(memHash, len(fields))
+	}
+	for f, v := range fields {
+		hash[f] = v
+	}
+	s.put(key, hash, expire)
+	return nil
+}
+
+func (r *MemoryRepo) HGet(ctx context.Context, key string, fields ...string) (map[string]string, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	hash, isHash := value.(memHash)
+	if ok && !isHash {
+		return nil, errWrongType
+	}
+	m := make(map[string]string, len(fields))
+	for _, f := range fields {
+		if v, ok := hash[f]; ok {
+			m[f] = v
+		}
+	}
+	return m, nil
+}
+
+func (r *MemoryRepo) HGetAll(ctx context.Context, key string) (map[string]string, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	hash, isHash := value.(memHash)
+	if ok && !isHash {
+		return nil, errWrongType
+	}
+	m := make(map[string]string, len(hash))
+	for f, v := range hash {
+		m[f] = v
+	}
+	return m, nil
+}
+
+func (r *MemoryRepo) LPush(ctx context.Context, key string, expire time.Duration, values ...string) error {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	list, isList := value.(memList)
+	if ok && !isList {
+		return errWrongType
+	}
+	pushed := make([]string, 0, len(list)+len(values))
+	for i := len(values) - 1; i >= 0; i-- {
+		pushed = append(pushed, values[i])
+	}
+	s.put(key, memList(append(pushed, list...)), expire)
+	return nil
+}
+
+func (r *MemoryRepo) RPop(ctx context.Context, key string) (string, bool, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	list, isList := value.(memList)
+	if ok && !isList {
+		return "", false, errWrongType
+	}
+	if len(list) == 0 {
+		return "", false, nil
+	}
+	last := list[len(list)-1]
+	if len(list) == 1 {
+		s.remove(key)
+	} else {
+		s.put(key, list[:len(list)-1], 0)
+	}
+	return last, true, nil
+}
+
+func (r *MemoryRepo) LRange(ctx context.Context, key string, start, stop int64) ([]string, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	list, isList := value.(memList)
+	if ok && !isList {
+		return nil, errWrongType
+	}
+	start, stop, ok = rangeIndex(int64(len(list)), start, stop)
+	if !ok {
+		return nil, nil
+	}
+	return append([]string(nil), list[start:stop+1]...), nil
+}
+
+func (r *MemoryRepo) LTrim(ctx context.Context, key string, start, stop int64) error {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	list, isList := value.(memList)
+	if ok && !isList {
+		return errWrongType
+	}
+	start, stop, ok = rangeIndex(int64(len(list)), start, stop)
+	if !ok {
+		s.remove(key)
+		return nil
+	}
+	s.put(key, memList(append([]string(nil), list[start:stop+1]...)), 0)
+	return nil
+}
+
+func (r *MemoryRepo) SAdd(ctx context.Context, key string, expire time.Duration, members ...string) (int64, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	set, isSet := value.(memSet)
+	if ok && !isSet {
+		return 0, errWrongType
+	}
+	if set == nil {
+		set = make(memSet, len(members))
+	}
+	var added int64
+	for _, m := range members {
+		if _, ok := set[m]; !ok {
+			set[m] = struct{}{}
+			added++
+		}
+	}
+	s.put(key, set, expire)
+	return added, nil
+}
+
+func (r *MemoryRepo) SIsMember(ctx context.Context, key string, member string) (bool, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	set, isSet := value.(memSet)
+	if ok && !isSet {
+		return false, errWrongType
+	}
+	_, ok = set[member]
+	return ok, nil
+}
+
+func (r *MemoryRepo) SRem(ctx context.Context, key string, members ...string) error {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	set, isSet := value.(memSet)
+	if ok && !isSet {
+		return errWrongType
+	}
+	for _, m := range members {
+		delete(set, m)
+	}
+	return nil
+}
+
+func (r *MemoryRepo) ZAdd(ctx context.Context, key string, members ...ZMember) error {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	set, err := s.zset(key)
+	if err != nil {
+		return err
+	}
+	for _, m := range members {
+		set.set(m.Member, m.Score)
+	}
+	return nil
+}
+
+func (r *MemoryRepo) ZIncrBy(ctx context.Context, key string, member string, incr float64) (float64, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	set, err := s.zset(key)
+	if err != nil {
+		return 0, err
+	}
+	score := set.scores[member] + incr
+	set.set(member, score)
+	return score, nil
+}
+
+func (r *MemoryRepo) ZRange(ctx context.Context, key string, start, stop int64, desc bool) ([]ZMember, error) {
+	s := r.shard(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key)
+	set, isZSet := value.(*localZSet)
+	if ok && !isZSet {
+		return nil, errWrongType
+	}
+	if set == nil {
+		return nil, nil
+	}
+	return set.rangeByRank(start, stop, desc), nil
+}
+
+// zset 获取有序集合，不存在时创建。调用方需要持有锁
+func (s *memoryShard) zset(key string) (*localZSet, error) {
+	value, ok := s.get(key)
+	set, isZSet := value.(*localZSet)
+	if ok && !isZSet {
+		return nil, errWrongType
+	}
+	if set == nil {
+		set = newLocalZSet()
+		s.put(key, set, 0)
+	}
+	return set, nil
+}
+
+// rangeIndex 将 [start, stop] 转换为有效的下标范围，负数表示倒数。范围为空时 ok 为 false
+func rangeIndex(length, start, stop int64) (_, _ int64, ok bool) {
+	if start < 0 {
+		start += length
+	}
+	if stop < 0 {
+		stop += length
+	}
+	if start < 0 {
+		start = 0
+	}
+	if stop >= length {
+		stop = length - 1
+	}
+	return start, stop, start <= stop
+}
@@ -0,0 +1,85 @@
+package cacher
+
+import (
+	"runtime/metrics"
+	"time"
+)
+
+const (
+	// memoryHighWater 进程内存达到软限制的该比例时开始淘汰
+	memoryHighWater = 0.9
+	// memoryShedRatio 每次淘汰每个分片中未固定数据的比例
+	memoryShedRatio = 0.25
+)
+
+// memoryMetrics 计算进程内存使用的指标，与 Go 运行时的软内存限制统计的范围一致
+var memoryMetrics = []string{"/memory/classes/total:bytes", "/memory/classes/heap/released:bytes", "/gc/cycles/total:gc-cycles"}
+
+// WithMemoryLimit 设置进程内存的软限制（字节）：进程内存达到限制的90%时，每个分片淘汰1/4未固定的数据，
+// 淘汰后等待一次垃圾回收再重新检查，避免回收前的内存统计导致连续淘汰。用于容器中避免 OOM。
+// limit 小于0时使用 debug.SetMemoryLimit（GOMEMLIMIT）设置的限制，运行时没有设置限制时不淘汰
+func WithMemoryLimit(limit int64) func(opt *MemoryOption) {
+	return func(opt *MemoryOption) {
+		opt.MemoryLimit = limit
+	}
+}
+
+// watchMemory 定期检查进程内存，接近软限制时淘汰数据，直到 Close
+func (r *MemoryRepo) watchMemory(limit int64, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	samples := make([]metrics.Sample, len(memoryMetrics))
+	for i, name := range memoryMetrics {
+		samples[i].Name = name
+	}
+	var shedCycle uint64
+	shed := false
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+		}
+		metrics.Read(samples)
+		used := samples[0].Value.Uint64() - samples[1].Value.Uint64()
+		cycles := samples[2].Value.Uint64()
+		if shed && cycles == shedCycle {
+			//淘汰的数据还没有被回收
+			continue
+		}
+		max := limit
+		if max < 0 {
+			max = runtimeMemoryLimit()
+		}
+		if max <= 0 || float64(used) < float64(max)*memoryHighWater {
+			continue
+		}
+		for _, s := range r.shards {
+			r.publish(EventExpire, s.deleteExpired())
+			r.publish(EventEvict, s.shed(memoryShedRatio))
+		}
+		shed, shedCycle = true, cycles
+	}
+}
+
+// shed 按优先级从低到高、同一优先级内按最近最少使用，淘汰 ratio 比例的未固定数据，至少淘汰1个，返回淘汰的缓存键
+func (s *memoryShard) shed(ratio float64) (keys []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for level := range s.lru {
+		n += s.lru[level].Len()
+	}
+	n = int(float64(n)*ratio + 0.5)
+	if n == 0 {
+		n = 1
+	}
+	for level := range s.lru {
+		for ; n > 0 && s.lru[level].Len() > 0; n-- {
+			key := s.lru[level].Back().Value.(string)
+			s.remove(key)
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,181 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"runtime"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestMemoryRepo(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(cacher.NewMemoryRepo(), 20*time.Millisecond, func(opt *cacher.Option) {
+		opt.Jitter = -1
+	})
+	calls := 0
+	query := func() (interface{}, error) {
+		calls++
+		return "v", nil
+	}
+	var v string
+	for _, wantCache := range []bool{false, true} {
+		if useCache, err := c.Get(ctx, "k", query, &v); err != nil || useCache != wantCache || v != "v" {
+			t.Fatalf("Get() = %v, %v, v = %v", useCache, err, v)
+		}
+	}
+	time.Sleep(30 * time.Millisecond)
+	if useCache, err := c.Get(ctx, "k", query, &v); err != nil || useCache {
+		t.Errorf("Get() after expire = %v, %v", useCache, err)
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestMemoryRepo_Collections(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, 10*time.Second)
+
+	q := c.Queue("q")
+	if err := q.Push(ctx, 1, 2, 3); err != nil {
+		t.Fatal(err)
+	}
+	var items []int
+	if err := q.Range(ctx, 0, -1, &items); err != nil || !reflect.DeepEqual(items, []int{3, 2, 1}) {
+		t.Errorf("Range() = %v, %v", items, err)
+	}
+	var item int
+	if ok, err := q.Pop(ctx, &item); !ok || err != nil || item != 1 {
+		t.Errorf("Pop() = %v, %v, item = %v", ok, err, item)
+	}
+
+	h := c.Hash("h")
+	if err := h.SetField(ctx, "f", 1); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := h.GetField(ctx, "f", &item); !ok || err != nil || item != 1 {
+		t.Errorf("GetField() = %v, %v, item = %v", ok, err, item)
+	}
+
+	board := c.SortedSet("z")
+	if _, err := board.IncrBy(ctx, "a", 2); err != nil {
+		t.Fatal(err)
+	}
+	if top, err := board.Top(ctx, 1); err != nil || !reflect.DeepEqual(top, []cacher.ZMember{{Member: "a", Score: 2}}) {
+		t.Errorf("Top() = %v, %v", top, err)
+	}
+
+	//类型不一致
+	if _, err := c.Set("h").Add(ctx, 1); err == nil {
+		t.Errorf("Set.Add() on hash key want error")
+	}
+	if _, err := repo.Get(ctx, "q"); err == nil {
+		t.Errorf("Get() on list key want error")
+	}
+}
+
+func TestMemoryRepo_Shards(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		optFns  []func(opt *cacher.MemoryOption)
+		wantMax int
+	}{
+		{name: "默认分片", wantMax: 1000},
+		{name: "单个分片精确淘汰", optFns: []func(opt *cacher.MemoryOption){cacher.WithMaxEntries(100)}, wantMax: 100},
+		{name: "多个分片近似淘汰", optFns: []func(opt *cacher.MemoryOption){cacher.WithMaxEntries(100), cacher.WithShards(4)}, wantMax: 100},
+		{name: "自定义哈希函数", optFns: []func(opt *cacher.MemoryOption){cacher.WithMaxEntries(100), cacher.WithShards(4), cacher.WithHash(func(string) uint32 { return 0 })}, wantMax: 25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := cacher.NewMemoryRepo(tt.optFns...)
+			for i := 0; i < 1000; i++ {
+				if err := repo.Set(ctx, "k"+strconv.Itoa(i), i, time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if n := repo.Len(); n > tt.wantMax || n < tt.wantMax*3/4 {
+				t.Errorf("Len() = %d, want about %d", n, tt.wantMax)
+			}
+			//最近写入的数据没有被淘汰
+			if v, _ := repo.Get(ctx, "k999"); v != 999 {
+				t.Errorf("Get(k999) = %v, want 999", v)
+			}
+		})
+	}
+}
+
+func TestMemoryRepo_Janitor(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo(cacher.WithJanitor(5 * time.Millisecond))
+	defer repo.Close()
+	_ = repo.Set(ctx, "short", "v", 10*time.Millisecond)
+	_ = repo.Set(ctx, "long", "v", time.Minute)
+	deadline := time.Now().Add(time.Second)
+	for repo.Len() > 1 && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	//没有读取，过期的数据也被清理
+	if n := repo.Len(); n != 1 {
+		t.Errorf("Len() = %d, want 1", n)
+	}
+	if err := repo.Close(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestMemoryRepo_SubscribeKeyEvents(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	repo := cacher.NewMemoryRepo(cacher.WithJanitor(5 * time.Millisecond))
+	defer repo.Close()
+	got := make(chan cacher.Event, 1)
+	done := make(chan error)
+	go func() { done <- repo.SubscribeKeyEvents(ctx, func(ev cacher.Event) { got <- ev }) }()
+
+	time.Sleep(10 * time.Millisecond)
+	_ = repo.Set(ctx, "short", "v", 10*time.Millisecond)
+	select {
+	case ev := <-got:
+		if ev.Type != cacher.EventExpire || ev.Key != "short" {
+			t.Errorf("event = %+v, want expire of short", ev)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expire not delivered")
+	}
+	cancel()
+	if err := <-done; err != context.Canceled {
+		t.Errorf("SubscribeKeyEvents() = %v, want context.Canceled", err)
+	}
+}
+
+func TestMemoryRepo_MemoryLimit(t *testing.T) {
+	ctx := context.Background()
+	//限制为1字节，每次检查都超过限制
+	repo := cacher.NewMemoryRepo(cacher.WithMemoryLimit(1), func(opt *cacher.MemoryOption) {
+		opt.MemoryInterval = time.Millisecond
+	})
+	defer repo.Close()
+	if err := repo.Pin(ctx, "pinned"); err != nil {
+		t.Fatal(err)
+	}
+	_ = repo.Set(ctx, "pinned", "v", 0)
+	for i := 0; i < 100; i++ {
+		_ = repo.Set(ctx, strconv.Itoa(i), "v", 0)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for repo.Len() > 1 && time.Now().Before(deadline) {
+		//淘汰后等待垃圾回收再继续淘汰
+		runtime.GC()
+		time.Sleep(2 * time.Millisecond)
+	}
+	if n := repo.Len(); n != 1 {
+		t.Errorf("Len() = %d, want only pinned key left", n)
+	}
+	if v, _ := repo.Get(ctx, "pinned"); v != "v" {
+		t.Errorf("pinned = %v, want v", v)
+	}
+}
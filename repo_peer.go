@@ -0,0 +1,321 @@
+package cacher
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// peerReplicas 一致性哈希环上每个实例的默认虚拟节点数
+const peerReplicas = 50
+
+type (
+	// PeerRepo 对等节点存储库：多个应用实例组成一致性哈希环，每个缓存键由哈希环上的一个实例（所有者）保存在本地存储库中，
+	// 其他实例通过 HTTP 读写所有者，不需要独立的缓存服务器。每个实例需要把 PeerRepo 注册为 HTTP 处理器，
+	// 并通过 SetPeers 设置相同的实例列表。
+	// PeerRepo 只转发读写，不转发查询：非所有者实例上的 Cacher.Get 未命中时在本实例调用查询方法，再把结果写入所有者，
+	// 多个实例同时未命中同一个缓存键时各自查询一次，Get 的 singleflight 只在单个实例内合并查询。
+	// 实现了 NXRepo，需要所有实例只查询一次时使用 Cacher.GetOrLease，租约由所有者保存，同一时间只有一个实例拿到租约
+	PeerRepo struct {
+		self     string       //本实例的地址，与 SetPeers 中的地址格式相同
+		local    Repo         //本实例的存储库，保存本实例拥有的缓存键
+		client   *http.Client //访问其他实例的客户端
+		basePath string       //HTTP 处理器的路径
+		replicas int          //每个实例的虚拟节点数
+		hash     HashFunc     //一致性哈希环使用的哈希函数
+
+		mu   sync.RWMutex
+		ring *hashRing
+	}
+	// PeerOption 对等节点存储库的选项
+	PeerOption struct {
+		BasePath string       //HTTP 处理器的路径，默认为 /_cacher/
+		Client   *http.Client //访问其他实例的客户端，默认为 http.DefaultClient
+		Replicas int          //一致性哈希环上每个实例的虚拟节点数，默认为50
+		Hash     HashFunc     //一致性哈希环使用的哈希函数，默认为 CRC-32（IEEE）。所有实例必须使用相同的哈希函数
+	}
+	// hashRing 一致性哈希环
+	hashRing struct {
+		hashes []uint32          //虚拟节点的哈希值，升序
+		nodes  map[uint32]string //虚拟节点的哈希值对应的实例
+		hash   HashFunc          //虚拟节点和缓存键的哈希函数
+	}
+)
+
+var (
+	_ Repo         = (*PeerRepo)(nil)
+	_ NXRepo       = (*PeerRepo)(nil)
+	_ http.Handler = (*PeerRepo)(nil)
+)
+
+// WithPeerBasePath 设置 HTTP 处理器的路径
+func WithPeerBasePath(path string) func(opt *PeerOption) {
+	return func(opt *PeerOption) {
+		opt.BasePath = path
+	}
+}
+
+// WithPeerClient 设置访问其他实例的客户端
+func WithPeerClient(client *http.Client) func(opt *PeerOption) {
+	return func(opt *PeerOption) {
+		opt.Client = client
+	}
+}
+
+// WithPeerHash 设置一致性哈希环使用的哈希函数
+func WithPeerHash(hash HashFunc) func(opt *PeerOption) {
+	return func(opt *PeerOption) {
+		opt.Hash = hash
+	}
+}
+
+// NewPeerRepo 创建对等节点存储库。self 是本实例的地址，例如 http://10.0.0.1:8080，local 保存本实例拥有的缓存键，
+// 通常是设置了最大缓存数量的 MemoryRepo
+func NewPeerRepo(self string, local Repo, optFns ...func(opt *PeerOption)) *PeerRepo {
+	opt := PeerOption{BasePath: "/_cacher/", Client: http.DefaultClient, Replicas: peerReplicas, Hash: ringHash}
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	if opt.Hash == nil {
+		opt.Hash = ringHash
+	}
+	r := &PeerRepo{self: self, local: local, client: opt.Client, basePath: opt.BasePath, replicas: opt.Replicas, hash: opt.Hash}
+	r.SetPeers(self)
+	return r
+}
+
+// SetPeers 设置所有实例的地址（包括本实例），实例增减时重新设置。只有部分缓存键的所有者会改变
+func (r *PeerRepo) SetPeers(peers ...string) {
+	ring := newHashRing(r.hash, r.replicas, peers...)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.ring = ring
+}
+
+// owner 缓存键的所有者，没有其他实例时为本实例
+func (r *PeerRepo) owner(key string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if peer := r.ring.get(key); peer != "" {
+		return peer
+	}
+	return r.self
+}
+
+// Get 从所有者读取缓存，缓存不存在时返回 nil, nil，由调用方在本实例查询
+func (r *PeerRepo) Get(ctx context.Context, key string) (interface{}, error) {
+	peer := r.owner(key)
+	if peer == r.self {
+		return r.local.Get(ctx, key)
+	}
+	resp, err := r.do(ctx, http.MethodGet, peer, url.Values{"key": {key}}, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// Set 保存到所有者。字符串和字节切片原样保存，其他类型保存为 JSON，读取时都返回字节切片，
+// 结构体等类型需要通过 RegisterType 注册转换器，或开启 Option.CodecFallback
+func (r *PeerRepo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	_, err := r.set(ctx, key, value, expire, false)
+	return err
+}
+
+// SetNX 所有者中缓存键不存在时保存
+func (r *PeerRepo) SetNX(ctx context.Context, key string, value interface{}, expire time.Duration) (bool, error) {
+	return r.set(ctx, key, value, expire, true)
+}
+
+func (r *PeerRepo) set(ctx context.Context, key string, value interface{}, expire time.Duration, nx bool) (bool, error) {
+	//本实例拥有的缓存键同样编码后保存，读取结果与所有者无关
+	data, err := peerEncode(value)
+	if err != nil {
+		return false, err
+	}
+	peer := r.owner(key)
+	if peer == r.self {
+		return r.setLocal(ctx, key, data, expire, nx)
+	}
+	query := url.Values{"key": {key}, "expire": {strconv.FormatInt(expire.Milliseconds(), 10)}}
+	if nx {
+		query.Set("nx", "1")
+	}
+	resp, err := r.do(ctx, http.MethodPut, peer, query, data)
+	if err != nil {
+		return false, err
+	}
+	resp.Body.Close()
+	return resp.StatusCode != http.StatusConflict, nil
+}
+
+// setLocal 保存到本实例的存储库，本实例的存储库没有实现 NXRepo 时不支持 nx
+func (r *PeerRepo) setLocal(ctx context.Context, key string, value interface{}, expire time.Duration, nx bool) (bool, error) {
+	if !nx {
+		return true, r.local.Set(ctx, key, value, expire)
+	}
+	nxRepo, ok := r.local.(NXRepo)
+	if !ok {
+		return false, fmt.Errorf("本实例的存储库 %T 没有实现 NXRepo", r.local)
+	}
+	return nxRepo.SetNX(ctx, key, value, expire)
+}
+
+// Del 按所有者分组删除缓存
+func (r *PeerRepo) Del(ctx context.Context, keys ...string) error {
+	groups := make(map[string][]string)
+	for _, key := range keys {
+		peer := r.owner(key)
+		groups[peer] = append(groups[peer], key)
+	}
+	for peer, keys := range groups {
+		if peer == r.self {
+			if err := r.local.Del(ctx, keys...); err != nil {
+				return err
+			}
+			continue
+		}
+		resp, err := r.do(ctx, http.MethodDelete, peer, url.Values{"key": keys}, nil)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+	}
+	return nil
+}
+
+// do 发送请求到其他实例，状态码不是 2xx、404、409 时返回错误
+func (r *PeerRepo) do(ctx context.Context, method, peer string, query url.Values, body []byte) (*http.Response, error) {
+	u := strings.TrimSuffix(peer, "/") + r.basePath + "?" + query.Encode()
+	req, err := http.NewRequestWithContext(ctx, method, u, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case resp.StatusCode/100 == 2, resp.StatusCode == http.StatusNotFound, resp.StatusCode == http.StatusConflict:
+		return resp, nil
+	}
+	msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+	resp.Body.Close()
+	return nil, fmt.Errorf("实例 %s 返回 %s：%s", peer, resp.Status, bytes.TrimSpace(msg))
+}
+
+// ServeHTTP 处理其他实例的读写请求，只访问本实例的存储库
+func (r *PeerRepo) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := req.Context()
+	query := req.URL.Query()
+	key := query.Get("key")
+	if key == "" {
+		http.Error(w, "缺少缓存键 key", http.StatusBadRequest)
+		return
+	}
+	switch req.Method {
+	case http.MethodGet:
+		value, err := r.local.Get(ctx, key)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if value == nil {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, err := peerEncode(value)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write(data)
+	case http.MethodPut:
+		ms, err := strconv.ParseInt(query.Get("expire"), 10, 64)
+		if err != nil {
+			http.Error(w, "保留时长 expire 格式错误", http.StatusBadRequest)
+			return
+		}
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		ok, err := r.setLocal(ctx, key, data, time.Duration(ms)*time.Millisecond, query.Get("nx") == "1")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		if !ok {
+			w.WriteHeader(http.StatusConflict)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case http.MethodDelete:
+		if err := r.local.Del(ctx, query["key"]...); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.Header().Set("Allow", "GET, PUT, DELETE")
+		http.Error(w, "不支持的请求方法", http.StatusMethodNotAllowed)
+	}
+}
+
+// peerEncode 编码缓存数据，字符串和字节切片原样返回，其他类型编码为 JSON
+func peerEncode(value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case string:
+		return []byte(v), nil
+	case []byte:
+		return v, nil
+	}
+	return json.Marshal(value)
+}
+
+// newHashRing 创建一致性哈希环，每个实例有 replicas 个虚拟节点
+func newHashRing(hash HashFunc, replicas int, peers ...string) *hashRing {
+	ring := &hashRing{nodes: make(map[uint32]string, replicas*len(peers)), hash: hash}
+	for _, peer := range peers {
+		for i := 0; i < replicas; i++ {
+			h := hash(strconv.Itoa(i) + peer)
+			ring.hashes = append(ring.hashes, h)
+			ring.nodes[h] = peer
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool { return ring.hashes[i] < ring.hashes[j] })
+	return ring
+}
+
+// get 缓存键所属的实例，哈希环为空时返回空字符串
+func (h *hashRing) get(key string) string {
+	if len(h.hashes) == 0 {
+		return ""
+	}
+	hash := h.hash(key)
+	i := sort.Search(len(h.hashes), func(i int) bool { return h.hashes[i] >= hash })
+	if i == len(h.hashes) {
+		i = 0
+	}
+	return h.nodes[h.hashes[i]]
+}
+
+func ringHash(s string) uint32 {
+	return crc32.ChecksumIEEE([]byte(s))
+}
@@ -0,0 +1,131 @@
+package cacher_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/cachertest"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// newPeers 创建 n 个互为对等节点的实例
+func newPeers(t *testing.T, n int, optFns ...func(opt *cacher.PeerOption)) ([]*cacher.PeerRepo, []*cacher.MemoryRepo) {
+	var (
+		repos  []*cacher.PeerRepo
+		locals []*cacher.MemoryRepo
+		addrs  []string
+	)
+	for i := 0; i < n; i++ {
+		local := cacher.NewMemoryRepo()
+		mux := http.NewServeMux()
+		srv := httptest.NewServer(mux)
+		t.Cleanup(srv.Close)
+		repo := cacher.NewPeerRepo(srv.URL, local, optFns...)
+		mux.Handle("/_cacher/", repo)
+		repos = append(repos, repo)
+		locals = append(locals, local)
+		addrs = append(addrs, srv.URL)
+	}
+	for _, repo := range repos {
+		repo.SetPeers(addrs...)
+	}
+	return repos, locals
+}
+
+func TestPeerRepo(t *testing.T) {
+	ctx := context.Background()
+	repos, locals := newPeers(t, 3)
+
+	//每个缓存键只保存在所有者中，任意实例都能读取
+	owners := make(map[int]int)
+	for i := 0; i < 30; i++ {
+		key := "k" + strconv.Itoa(i)
+		if err := repos[i%3].Set(ctx, key, i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+		saved := 0
+		for j, local := range locals {
+			if data, _ := local.Get(ctx, key); data != nil {
+				saved++
+				owners[j]++
+			}
+		}
+		if saved != 1 {
+			t.Errorf("%s saved in %d instances, want 1", key, saved)
+		}
+		for j, repo := range repos {
+			data, err := repo.Get(ctx, key)
+			if err != nil || fmt.Sprintf("%s", data) != strconv.Itoa(i) {
+				t.Errorf("instance %d Get(%s) = %#v, %v", j, key, data, err)
+			}
+		}
+	}
+	if len(owners) != 3 {
+		t.Errorf("keys owned by %d instances, want 3", len(owners))
+	}
+
+	//删除和 SetNX 发送到所有者
+	if err := repos[0].Del(ctx, "k1", "k2", "k3"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"k1", "k2", "k3"} {
+		if data, err := repos[1].Get(ctx, key); data != nil || err != nil {
+			t.Errorf("Get(%s) after Del = %#v, %v", key, data, err)
+		}
+	}
+	for i, want := range []bool{true, false} {
+		if ok, err := repos[i].SetNX(ctx, "nx", "v", time.Minute); ok != want || err != nil {
+			t.Errorf("SetNX() #%d = %v, %v, want %v", i, ok, err, want)
+		}
+	}
+}
+
+func TestPeerRepo_Hash(t *testing.T) {
+	ctx := context.Background()
+	//所有虚拟节点和缓存键的哈希值相同，所有缓存键属于同一个实例
+	repos, locals := newPeers(t, 3, cacher.WithPeerHash(func(string) uint32 { return 0 }))
+	for i := 0; i < 10; i++ {
+		if err := repos[i%3].Set(ctx, "k"+strconv.Itoa(i), i, time.Minute); err != nil {
+			t.Fatal(err)
+		}
+	}
+	counts := make([]int, 0, len(locals))
+	for _, local := range locals {
+		counts = append(counts, local.Len())
+	}
+	if counts[0]+counts[1]+counts[2] != 10 || (counts[0] != 10 && counts[1] != 10 && counts[2] != 10) {
+		t.Errorf("entries per instance = %v, want all in one instance", counts)
+	}
+}
+
+func TestPeerRepo_Cacher(t *testing.T) {
+	ctx := context.Background()
+	repos, _ := newPeers(t, 2)
+	loads := 0
+	query := func() (interface{}, error) {
+		loads++
+		return "v", nil
+	}
+	for i, repo := range repos {
+		c := cacher.New(repo, time.Minute)
+		var v string
+		useCache, err := c.Get(ctx, "shared", query, &v)
+		if err != nil || v != "v" || useCache != (i == 1) {
+			t.Errorf("instance %d Get() = %v, %v, %q", i, useCache, err, v)
+		}
+	}
+	if loads != 1 {
+		t.Errorf("loads = %d, want 1 across instances", loads)
+	}
+}
+
+func TestPeerRepo_Suite(t *testing.T) {
+	cachertest.RunRepoSuite(t, func(t *testing.T) cacher.Repo {
+		repos, _ := newPeers(t, 3)
+		return repos[0]
+	})
+}
@@ -0,0 +1,180 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// quorumTombstone 删除时写入的墓碑的保留时长，期间落后的副本读取时被修复为墓碑
+const quorumTombstone = 10 * time.Minute
+
+// QuorumRepo 多副本仲裁读写的存储库。
+// 写入时将数据装入 Envelope 并编码为字节切片写到所有副本，字符串和字节切片之外的数据读取时为 JSON 字节切片；读取时同时读取所有副本，返回写入时间最新的数据，
+// 并在后台把最新的数据修复到落后的副本。删除时写入墓碑而不是直接删除，删除失败的副本上的旧数据不会被修复回其他副本
+type QuorumRepo struct {
+	repos  []Repo
+	quorum int //读写成功的最少副本数
+}
+
+var _ Repo = (*QuorumRepo)(nil)
+
+// quorumResult 单个副本的读取结果
+type quorumResult struct {
+	env   Envelope
+	exist bool
+	err   error
+}
+
+// NewQuorumRepo 创建仲裁读写的存储库，quorum 为读写成功的最少副本数
+func NewQuorumRepo(quorum int, repos ...Repo) *QuorumRepo {
+	if len(repos) == 0 {
+		panic(errors.New("存储库 repos 不能为空"))
+	}
+	if quorum <= 0 || quorum > len(repos) {
+		panic(fmt.Errorf("仲裁数 quorum 必须在 1 到 %d 之间", len(repos)))
+	}
+	return &QuorumRepo{repos: repos, quorum: quorum}
+}
+
+// Get 读取所有副本，成功数达到仲裁数时，返回写入时间最新的数据
+func (r *QuorumRepo) Get(ctx context.Context, key string) (interface{}, error) {
+	results := make([]quorumResult, len(r.repos))
+	r.each(func(i int, repo Repo) error {
+		data, err := repo.Get(ctx, key)
+		if err != nil {
+			results[i].err = err
+			return err
+		}
+		if data != nil {
+			//无法解码的数据视为读取失败，不参与比较新旧
+			if results[i].env, err = openEnvelope(data); err != nil {
+				results[i].err = err
+				return err
+			}
+			results[i].exist = true
+		}
+		return nil
+	})
+
+	var (
+		succeed int
+		latest  = -1
+		lastErr error
+	)
+	for i, res := range results {
+		if res.err != nil {
+			lastErr = res.err
+			continue
+		}
+		succeed++
+		if res.exist && (latest < 0 || res.env.CreatedAt.After(results[latest].env.CreatedAt)) {
+			latest = i
+		}
+	}
+	if succeed < r.quorum {
+		return nil, fmt.Errorf("仲裁读失败，成功 %d 个，需要 %d 个: %w", succeed, r.quorum, lastErr)
+	}
+	if latest < 0 {
+		return nil, nil
+	}
+	r.repair(key, results, latest)
+	if results[latest].env.Deleted {
+		return nil, nil
+	}
+	return results[latest].env.Value, nil
+}
+
+// repair 在后台把最新的数据（包括墓碑）写入落后的副本
+func (r *QuorumRepo) repair(key string, results []quorumResult, latest int) {
+	env := results[latest].env
+	ttl := env.ttl()
+	if ttl <= 0 {
+		//剩余时长未知时，无法修复
+		return
+	}
+	var laggards []Repo
+	for i, res := range results {
+		if res.err == nil && (!res.exist && !env.Deleted || res.exist && res.env.CreatedAt.Before(env.CreatedAt)) {
+			laggards = append(laggards, r.repos[i])
+		}
+	}
+	if len(laggards) == 0 {
+		return
+	}
+	data, err := env.MarshalBinary()
+	if err != nil {
+		return
+	}
+	go func() {
+		for _, repo := range laggards {
+			_ = repo.Set(context.Background(), key, data, ttl)
+		}
+	}()
+}
+
+// Set 将数据装入信封写入所有副本，成功数达到仲裁数时返回成功
+func (r *QuorumRepo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	data, err := newEnvelope(value, expire).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return r.quorumDo("写", func(repo Repo) error {
+		return repo.Set(ctx, key, data, expire)
+	})
+}
+
+// Del 在所有副本写入墓碑，成功数达到仲裁数时返回成功
+func (r *QuorumRepo) Del(ctx context.Context, keys ...string) error {
+	data, err := newTombstone(quorumTombstone).MarshalBinary()
+	if err != nil {
+		return err
+	}
+	return r.quorumDo("删除", func(repo Repo) error {
+		for _, key := range keys {
+			if err := repo.Set(ctx, key, data, quorumTombstone); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// quorumDo 在所有副本执行 fn，成功数未达到仲裁数时返回错误
+func (r *QuorumRepo) quorumDo(op string, fn func(repo Repo) error) error {
+	errs := r.each(func(_ int, repo Repo) error {
+		return fn(repo)
+	})
+	var (
+		succeed int
+		lastErr error
+	)
+	for _, err := range errs {
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		succeed++
+	}
+	if succeed < r.quorum {
+		return fmt.Errorf("仲裁%s失败，成功 %d 个，需要 %d 个: %w", op, succeed, r.quorum, lastErr)
+	}
+	return nil
+}
+
+// each 并发地在所有副本执行 fn，返回每个副本的执行结果
+func (r *QuorumRepo) each(fn func(i int, repo Repo) error) []error {
+	errs := make([]error, len(r.repos))
+	var wg sync.WaitGroup
+	wg.Add(len(r.repos))
+	for i, repo := range r.repos {
+		go func(i int, repo Repo) {
+			defer wg.Done()
+			errs[i] = fn(i, repo)
+		}(i, repo)
+	}
+	wg.Wait()
+	return errs
+}
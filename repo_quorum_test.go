@@ -0,0 +1,209 @@
+package cacher_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+// repoJSON 像 redis 等存储库一样序列化数据的测试存储库：字符串、字节切片原样保存，其他类型保存为 JSON，读取时都返回字节切片
+type repoJSON struct {
+	*repoMap
+}
+
+func (r repoJSON) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	var data []byte
+	switch v := value.(type) {
+	case string:
+		data = []byte(v)
+	case []byte:
+		data = v
+	default:
+		var err error
+		if data, err = json.Marshal(v); err != nil {
+			return err
+		}
+	}
+	return r.repoMap.Set(ctx, key, data, expire)
+}
+
+// envelopeOf 读取副本中的信封
+func envelopeOf(t *testing.T, repo cacher.Repo, key string) (cacher.Envelope, bool) {
+	t.Helper()
+	data, err := repo.Get(context.Background(), key)
+	if err != nil || data == nil {
+		return cacher.Envelope{}, false
+	}
+	var env cacher.Envelope
+	var raw []byte
+	switch v := data.(type) {
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	}
+	if err := env.UnmarshalBinary(raw); err != nil {
+		t.Fatalf("replica data %v: %v", data, err)
+	}
+	return env, true
+}
+
+// waitEnvelope 等待后台修复，直到副本中的信封满足 ok
+func waitEnvelope(t *testing.T, repo cacher.Repo, key string, ok func(env cacher.Envelope) bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for {
+		if env, exist := envelopeOf(t, repo, key); exist && ok(env) {
+			return
+		}
+		if time.Now().After(deadline) {
+			env, _ := envelopeOf(t, repo, key)
+			t.Fatalf("replica not repaired: %+v", env)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestQuorumRepo(t *testing.T) {
+	tests := []struct {
+		name    string
+		replica func() (cacher.Repo, *repoMap)
+	}{
+		{name: "保存 Go 值的存储库", replica: func() (cacher.Repo, *repoMap) {
+			m := newRepoMap(nil)
+			return m, m
+		}},
+		{name: "序列化数据的存储库", replica: func() (cacher.Repo, *repoMap) {
+			m := newRepoMap(nil)
+			return repoJSON{repoMap: m}, m
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			a, am := tt.replica()
+			b, bm := tt.replica()
+			c, _ := tt.replica()
+			repo := cacher.NewQuorumRepo(2, a, b, c)
+			isValue := func(want string) func(env cacher.Envelope) bool {
+				return func(env cacher.Envelope) bool { return !env.Deleted && env.Value == want }
+			}
+
+			if err := repo.Set(ctx, "k", "v1", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			//模拟副本 c 落后：持有更早写入的数据
+			old, _ := cacher.Envelope{Value: "v0", CreatedAt: time.Now().Add(-time.Hour), ExpireAt: time.Now().Add(time.Minute)}.MarshalBinary()
+			if err := c.Set(ctx, "k", old, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			//模拟副本 b 丢失数据
+			if err := b.Del(ctx, "k"); err != nil {
+				t.Fatal(err)
+			}
+
+			val, err := repo.Get(ctx, "k")
+			if err != nil || val != "v1" {
+				t.Fatalf("Get() = %v, %v, want v1", val, err)
+			}
+			//后台修复落后的副本
+			waitEnvelope(t, b, "k", isValue("v1"))
+			waitEnvelope(t, c, "k", isValue("v1"))
+
+			//字符串、字节切片之外的数据保存为 JSON
+			if err := repo.Set(ctx, "p", map[string]int{"a": 1}, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if val, err := repo.Get(ctx, "p"); err != nil || fmtValue(val) != `b:{"a":1}` {
+				t.Errorf("Get(p) = %v, %v, want JSON", val, err)
+			}
+
+			//成功数未达到仲裁数
+			am.getErr = errors.New("down")
+			bm.getErr = errors.New("down")
+			if _, err := repo.Get(ctx, "k"); err == nil {
+				t.Errorf("Get() want quorum error")
+			}
+			am.getErr, bm.getErr = nil, nil
+
+			//删除后读取不到
+			if err := repo.Del(ctx, "k"); err != nil {
+				t.Fatal(err)
+			}
+			if val, err := repo.Get(ctx, "k"); err != nil || val != nil {
+				t.Errorf("Get() after Del = %v, %v, want nil", val, err)
+			}
+
+			//模拟副本 c 删除失败，仍持有删除前写入的数据，不会被修复回其他副本
+			stale, _ := cacher.Envelope{Value: "v1", CreatedAt: time.Now().Add(-time.Second), ExpireAt: time.Now().Add(time.Minute)}.MarshalBinary()
+			if err := c.Set(ctx, "k", stale, time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			if val, err := repo.Get(ctx, "k"); err != nil || val != nil {
+				t.Errorf("Get() with stale replica = %v, %v, want nil", val, err)
+			}
+			isTombstone := func(env cacher.Envelope) bool { return env.Deleted }
+			waitEnvelope(t, c, "k", isTombstone)
+			waitEnvelope(t, a, "k", isTombstone)
+
+			//无法解码的数据视为读取失败
+			for _, r := range []cacher.Repo{a, b} {
+				if err := r.Set(ctx, "bad", "v", time.Minute); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if _, err := repo.Get(ctx, "bad"); err == nil {
+				t.Errorf("Get() of non-envelope data want error")
+			}
+		})
+	}
+}
+
+func TestEnvelope_MarshalBinary(t *testing.T) {
+	now := time.Unix(0, time.Now().UnixNano())
+	tests := []struct {
+		name string
+		env  cacher.Envelope
+		want cacher.Envelope
+	}{
+		{name: "字符串", env: cacher.Envelope{Value: "v", CreatedAt: now, ExpireAt: now.Add(time.Minute)}, want: cacher.Envelope{Value: "v", CreatedAt: now, ExpireAt: now.Add(time.Minute)}},
+		{name: "字节切片", env: cacher.Envelope{Value: []byte("b"), CreatedAt: now}, want: cacher.Envelope{Value: []byte("b"), CreatedAt: now}},
+		{name: "其他类型", env: cacher.Envelope{Value: 5, CreatedAt: now}, want: cacher.Envelope{Value: []byte("5"), CreatedAt: now}},
+		{name: "墓碑", env: cacher.Envelope{CreatedAt: now, Deleted: true}, want: cacher.Envelope{CreatedAt: now, Deleted: true}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := tt.env.MarshalBinary()
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got cacher.Envelope
+			if err := got.UnmarshalBinary(data); err != nil {
+				t.Fatal(err)
+			}
+			if !got.CreatedAt.Equal(tt.want.CreatedAt) || !got.ExpireAt.Equal(tt.want.ExpireAt) || got.Deleted != tt.want.Deleted || fmtValue(got.Value) != fmtValue(tt.want.Value) {
+				t.Errorf("UnmarshalBinary() = %+v, want %+v", got, tt.want)
+			}
+			//前缀之后的固定长度部分不完整
+			if err := got.UnmarshalBinary(data[:8]); err == nil {
+				t.Errorf("UnmarshalBinary() of truncated data want error")
+			}
+		})
+	}
+}
+
+// fmtValue 以字符串比较信封中的数据，区分字符串和字节切片
+func fmtValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return "s:" + v
+	case []byte:
+		return "b:" + string(v)
+	}
+	return "?"
+}
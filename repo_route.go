@@ -0,0 +1,45 @@
+package cacher
+
+import (
+	"context"
+	"time"
+)
+
+// RouteRepo 读写分离的存储库：读请求发送到就近的只读副本，写请求发送到主库。
+// 适用于按可用区部署 Redis 副本的场景
+type RouteRepo struct {
+	writer  Repo   //主库，处理写请求
+	readers []Repo //只读副本，按就近程度排序
+}
+
+var _ Repo = (*RouteRepo)(nil)
+
+// NewRouteRepo 创建读写分离的存储库。
+// readers 按优先级排列，读取时依次尝试，全部失败时回退到 writer；没有 readers 时读写都使用 writer
+func NewRouteRepo(writer Repo, readers ...Repo) *RouteRepo {
+	return &RouteRepo{writer: writer, readers: readers}
+}
+
+// Get 从就近的副本读取，副本错误时尝试下一个副本，最后回退到主库
+func (r *RouteRepo) Get(ctx context.Context, key string) (interface{}, error) {
+	for _, reader := range r.readers {
+		val, err := reader.Get(ctx, key)
+		if err == nil {
+			return val, nil
+		}
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+	}
+	return r.writer.Get(ctx, key)
+}
+
+// Set 写入主库
+func (r *RouteRepo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	return r.writer.Set(ctx, key, value, expire)
+}
+
+// Del 从主库删除
+func (r *RouteRepo) Del(ctx context.Context, keys ...string) error {
+	return r.writer.Del(ctx, keys...)
+}
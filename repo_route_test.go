@@ -0,0 +1,48 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestRouteRepo(t *testing.T) {
+	ctx := context.Background()
+	primary := newRepoMap(map[string]interface{}{"k": "primary"})
+	replicaA := newRepoMap(map[string]interface{}{"k": "replica-a"})
+	replicaB := newRepoMap(map[string]interface{}{"k": "replica-b"})
+	repo := cacher.NewRouteRepo(primary, replicaA, replicaB)
+
+	if val, err := repo.Get(ctx, "k"); err != nil || val != "replica-a" {
+		t.Errorf("Get() = %v, %v, want replica-a", val, err)
+	}
+	//就近副本错误时，读取下一个副本
+	replicaA.getErr = errors.New("replica down")
+	if val, err := repo.Get(ctx, "k"); err != nil || val != "replica-b" {
+		t.Errorf("Get() = %v, %v, want replica-b", val, err)
+	}
+	//全部副本错误时，回退到主库
+	replicaB.getErr = errors.New("replica down")
+	if val, err := repo.Get(ctx, "k"); err != nil || val != "primary" {
+		t.Errorf("Get() = %v, %v, want primary", val, err)
+	}
+
+	//写请求只发送到主库
+	if err := repo.Set(ctx, "w", "v", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := primary.data["w"]; !ok {
+		t.Errorf("Set() did not write primary")
+	}
+	if _, ok := replicaA.data["w"]; ok {
+		t.Errorf("Set() wrote replica")
+	}
+	if err := repo.Del(ctx, "w"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := primary.data["w"]; ok {
+		t.Errorf("Del() did not delete from primary")
+	}
+}
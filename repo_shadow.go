@@ -0,0 +1,142 @@
+package cacher
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"time"
+)
+
+// shadowInFlight 同时进行的影子存储库操作的最大数量，超过时跳过，不影响主存储库的响应
+const shadowInFlight = 64
+
+type (
+	// ShadowRepo 影子读取的存储库：读写都由主存储库完成并返回结果，同时在后台从影子存储库读取同一个缓存键并比较，
+	// 结果不一致时调用 report。用于切换到新的存储库或序列化格式之前，用真实流量验证新的存储库
+	ShadowRepo struct {
+		primary Repo
+		shadow  Repo
+		report  func(d ShadowDiff)
+		opt     ShadowOption
+		slots   chan struct{}
+	}
+	// ShadowOption ShadowRepo 的选项
+	ShadowOption struct {
+		SampleRate   float64                                //影子读取的比例，取值范围 (0,1]，默认为1
+		MirrorWrites bool                                   //是否在后台把写入和删除同步到影子存储库，默认为 true
+		Timeout      time.Duration                          //影子存储库每次操作的超时时间，默认为1秒
+		Equal        func(primary, shadow interface{}) bool //比较两个存储库的缓存数据，默认把两者编码为字节后比较
+	}
+	// ShadowDiff 主存储库和影子存储库读取结果不一致
+	ShadowDiff struct {
+		Key     string
+		Primary interface{} //主存储库的缓存数据，缓存不存在时为 nil
+		Shadow  interface{} //影子存储库的缓存数据，缓存不存在时为 nil
+		Err     error       //影子存储库的读取错误
+	}
+)
+
+var _ Repo = (*ShadowRepo)(nil)
+
+// WithShadowSampleRate 设置影子读取的比例
+func WithShadowSampleRate(rate float64) func(opt *ShadowOption) {
+	return func(opt *ShadowOption) {
+		opt.SampleRate = rate
+	}
+}
+
+// WithShadowMirrorWrites 设置是否把写入和删除同步到影子存储库，影子存储库由其他方式写入时关闭
+func WithShadowMirrorWrites(mirror bool) func(opt *ShadowOption) {
+	return func(opt *ShadowOption) {
+		opt.MirrorWrites = mirror
+	}
+}
+
+// WithShadowEqual 设置比较缓存数据的方法，例如影子存储库使用新的序列化格式时，解码后再比较
+func WithShadowEqual(equal func(primary, shadow interface{}) bool) func(opt *ShadowOption) {
+	return func(opt *ShadowOption) {
+		opt.Equal = equal
+	}
+}
+
+// NewShadowRepo 创建影子读取的存储库，report 在后台调用，需要并发安全
+func NewShadowRepo(primary, shadow Repo, report func(d ShadowDiff), optFns ...func(opt *ShadowOption)) *ShadowRepo {
+	opt := ShadowOption{SampleRate: 1, MirrorWrites: true, Timeout: time.Second, Equal: shadowEqual}
+	for _, optFn := range optFns {
+		optFn(&opt)
+	}
+	return &ShadowRepo{primary: primary, shadow: shadow, report: report, opt: opt, slots: make(chan struct{}, shadowInFlight)}
+}
+
+// Get 从主存储库读取，按比例在后台从影子存储库读取并比较
+func (r *ShadowRepo) Get(ctx context.Context, key string) (interface{}, error) {
+	data, err := r.primary.Get(ctx, key)
+	if err != nil || rand.Float64() >= r.opt.SampleRate {
+		return data, err
+	}
+	r.background(ctx, func(ctx context.Context) {
+		shadow, err := r.shadow.Get(ctx, key)
+		if err != nil {
+			r.report(ShadowDiff{Key: key, Primary: data, Err: err})
+			return
+		}
+		if (data == nil) != (shadow == nil) || (data != nil && !r.opt.Equal(data, shadow)) {
+			r.report(ShadowDiff{Key: key, Primary: data, Shadow: shadow})
+		}
+	})
+	return data, nil
+}
+
+// Set 保存到主存储库，成功后在后台保存到影子存储库
+func (r *ShadowRepo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	if err := r.primary.Set(ctx, key, value, expire); err != nil {
+		return err
+	}
+	if r.opt.MirrorWrites {
+		r.background(ctx, func(ctx context.Context) {
+			_ = r.shadow.Set(ctx, key, value, expire)
+		})
+	}
+	return nil
+}
+
+// Del 从主存储库删除，成功后在后台从影子存储库删除
+func (r *ShadowRepo) Del(ctx context.Context, keys ...string) error {
+	if err := r.primary.Del(ctx, keys...); err != nil {
+		return err
+	}
+	if r.opt.MirrorWrites {
+		r.background(ctx, func(ctx context.Context) {
+			_ = r.shadow.Del(ctx, keys...)
+		})
+	}
+	return nil
+}
+
+// background 在后台访问影子存储库，不受调用方 ctx 结束的影响。进行中的操作过多时跳过，跳过写入时之后的比较可能报告不一致
+func (r *ShadowRepo) background(ctx context.Context, fn func(ctx context.Context)) {
+	select {
+	case r.slots <- struct{}{}:
+	default:
+		return
+	}
+	go func() {
+		defer func() { <-r.slots }()
+		ctx, cancel := context.WithTimeout(detach(ctx), r.opt.Timeout)
+		defer cancel()
+		fn(ctx)
+	}()
+}
+
+// shadowEqual 把缓存数据编码为字节后比较，存储库返回字符串或字节切片时都能比较
+func shadowEqual(primary, shadow interface{}) bool {
+	p, err := peerEncode(primary)
+	if err != nil {
+		return false
+	}
+	s, err := peerEncode(shadow)
+	if err != nil {
+		return false
+	}
+	return bytes.Equal(p, s)
+}
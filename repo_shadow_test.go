@@ -0,0 +1,58 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestShadowRepo(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name     string
+		setup    func(shadow *cacher.MemoryRepo)
+		wantDiff bool
+	}{
+		{name: "一致", setup: func(*cacher.MemoryRepo) {}},
+		{name: "影子存储库数据不同", setup: func(shadow *cacher.MemoryRepo) {
+			_ = shadow.Set(ctx, "k", "other", time.Minute)
+		}, wantDiff: true},
+		{name: "影子存储库缺少缓存", setup: func(shadow *cacher.MemoryRepo) {
+			_ = shadow.Del(ctx, "k")
+		}, wantDiff: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			primary, shadow := cacher.NewMemoryRepo(), cacher.NewMemoryRepo()
+			diffs := make(chan cacher.ShadowDiff, 1)
+			repo := cacher.NewShadowRepo(primary, shadow, func(d cacher.ShadowDiff) { diffs <- d })
+			if err := repo.Set(ctx, "k", "v", time.Minute); err != nil {
+				t.Fatal(err)
+			}
+			//等待后台同步写入
+			deadline := time.Now().Add(time.Second)
+			for v, _ := shadow.Get(ctx, "k"); v == nil && time.Now().Before(deadline); v, _ = shadow.Get(ctx, "k") {
+				time.Sleep(time.Millisecond)
+			}
+			tt.setup(shadow)
+
+			got, err := repo.Get(ctx, "k")
+			if err != nil || got != "v" {
+				t.Fatalf("Get() = %#v, %v, want primary data", got, err)
+			}
+			select {
+			case d := <-diffs:
+				if !tt.wantDiff {
+					t.Errorf("unexpected diff %+v", d)
+				} else if d.Key != "k" || d.Primary != "v" {
+					t.Errorf("diff = %+v", d)
+				}
+			case <-time.After(100 * time.Millisecond):
+				if tt.wantDiff {
+					t.Error("diff not reported")
+				}
+			}
+		})
+	}
+}
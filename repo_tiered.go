@@ -0,0 +1,116 @@
+package cacher
+
+import (
+	"context"
+	"time"
+)
+
+type (
+	// GetWithTTLRepo 可选的存储库接口，读取缓存的同时返回剩余保留时长，TieredRepo 用它计算一级缓存的保留时长
+	GetWithTTLRepo interface {
+		// GetWithTTL 读取缓存和剩余保留时长，缓存不存在时返回 nil, -2, nil，不过期时剩余保留时长为 -1，与 TTLRepo 一致
+		GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, error)
+	}
+	// TieredRepo 两级存储库：一级缓存（通常是 MemoryRepo）保存二级缓存（通常是 Redis）中数据的副本。
+	// 一级缓存的保留时长由二级缓存的剩余保留时长决定，不超过 maxL1TTL，
+	// 因此一级缓存不会在二级缓存中的数据过期后继续返回该数据
+	TieredRepo struct {
+		l1       Repo
+		l2       Repo
+		maxL1TTL time.Duration
+	}
+)
+
+var (
+	_ Repo           = (*TieredRepo)(nil)
+	_ GetWithTTLRepo = (*TieredRepo)(nil)
+	_ GetWithTTLRepo = (*MemoryRepo)(nil)
+)
+
+// NewTieredRepo 创建两级存储库，maxL1TTL 为一级缓存的最长保留时长，限制其他实例修改数据后本实例读到旧数据的时间
+func NewTieredRepo(l1, l2 Repo, maxL1TTL time.Duration) *TieredRepo {
+	return &TieredRepo{l1: l1, l2: l2, maxL1TTL: maxL1TTL}
+}
+
+// Get 先读一级缓存，不存在时读二级缓存并写入一级缓存
+func (r *TieredRepo) Get(ctx context.Context, key string) (interface{}, error) {
+	val, _, err := r.GetWithTTL(ctx, key)
+	return val, err
+}
+
+// GetWithTTL 同 Get，同时返回剩余保留时长。一级缓存命中时返回一级缓存的剩余保留时长，不会超过二级缓存的剩余保留时长
+func (r *TieredRepo) GetWithTTL(ctx context.Context, key string) (interface{}, time.Duration, error) {
+	val, ttl, err := getWithTTL(ctx, r.l1, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	if val != nil {
+		return val, ttl, nil
+	}
+	val, ttl, err = getWithTTL(ctx, r.l2, key)
+	if err != nil || val == nil {
+		return val, ttl, err
+	}
+	if l1TTL := r.l1TTL(ttl); l1TTL > 0 {
+		if err := r.l1.Set(ctx, key, val, l1TTL); err != nil {
+			return nil, 0, err
+		}
+	}
+	return val, ttl, nil
+}
+
+// Set 写入二级缓存后写入一级缓存
+func (r *TieredRepo) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	if err := r.l2.Set(ctx, key, value, expire); err != nil {
+		return err
+	}
+	ttl := expire
+	if ttl <= 0 {
+		ttl = -1
+	}
+	if l1TTL := r.l1TTL(ttl); l1TTL > 0 {
+		return r.l1.Set(ctx, key, value, l1TTL)
+	}
+	return r.l1.Del(ctx, key)
+}
+
+// Del 从两级缓存中删除
+func (r *TieredRepo) Del(ctx context.Context, keys ...string) error {
+	if err := r.l2.Del(ctx, keys...); err != nil {
+		return err
+	}
+	return r.l1.Del(ctx, keys...)
+}
+
+// l1TTL 根据二级缓存的剩余保留时长 ttl 计算一级缓存的保留时长，小于等于0时不写入一级缓存。
+// ttl 为 -1（不过期）时使用 maxL1TTL；ttl 未知（二级缓存不支持查询剩余保留时长）时为 maxL1TTL
+func (r *TieredRepo) l1TTL(ttl time.Duration) time.Duration {
+	if ttl == -1 || ttl > r.maxL1TTL {
+		return r.maxL1TTL
+	}
+	return ttl
+}
+
+// getWithTTL 读取缓存和剩余保留时长。存储库没有实现 GetWithTTLRepo 时通过 TTLRepo 查询，都没有实现时剩余保留时长视为不过期
+func getWithTTL(ctx context.Context, repo Repo, key string) (interface{}, time.Duration, error) {
+	if r, ok := repo.(GetWithTTLRepo); ok {
+		return r.GetWithTTL(ctx, key)
+	}
+	val, err := repo.Get(ctx, key)
+	if err != nil || val == nil {
+		return val, -2, err
+	}
+	r, ok := repo.(TTLRepo)
+	if !ok {
+		return val, -1, nil
+	}
+	ttl, err := r.TTL(ctx, key)
+	if err != nil {
+		return nil, 0, err
+	}
+	if ttl == -2 {
+		//读取后过期
+		return nil, -2, nil
+	}
+	return val, ttl, nil
+}
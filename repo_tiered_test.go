@@ -0,0 +1,88 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+// repoTTLOnly 只实现 Repo 和 TTLRepo，没有实现 GetWithTTLRepo
+type repoTTLOnly struct {
+	mem *cacher.MemoryRepo
+}
+
+func (r repoTTLOnly) Get(ctx context.Context, key string) (interface{}, error) {
+	return r.mem.Get(ctx, key)
+}
+
+func (r repoTTLOnly) Set(ctx context.Context, key string, value interface{}, expire time.Duration) error {
+	return r.mem.Set(ctx, key, value, expire)
+}
+
+func (r repoTTLOnly) Del(ctx context.Context, keys ...string) error {
+	return r.mem.Del(ctx, keys...)
+}
+
+func (r repoTTLOnly) TTL(ctx context.Context, key string) (time.Duration, error) {
+	return r.mem.TTL(ctx, key)
+}
+
+func TestTieredRepo(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name    string
+		l2      cacher.Repo
+		l2TTL   time.Duration
+		wantMin time.Duration
+		wantMax time.Duration
+	}{
+		{name: "二级缓存剩余时长较短", l2: cacher.NewMemoryRepo(), l2TTL: 2 * time.Second, wantMin: time.Second, wantMax: 2 * time.Second},
+		{name: "二级缓存剩余时长较长", l2: cacher.NewMemoryRepo(), l2TTL: time.Hour, wantMin: time.Minute - time.Second, wantMax: time.Minute},
+		{name: "二级缓存不过期", l2: cacher.NewMemoryRepo(), wantMin: time.Minute - time.Second, wantMax: time.Minute},
+		{name: "二级缓存只实现 TTLRepo", l2: repoTTLOnly{cacher.NewMemoryRepo()}, l2TTL: 2 * time.Second, wantMin: time.Second, wantMax: 2 * time.Second},
+		{name: "二级缓存不支持查询剩余时长", l2: newRepoMap(nil), l2TTL: 2 * time.Second, wantMin: time.Minute - time.Second, wantMax: time.Minute},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l1 := cacher.NewMemoryRepo()
+			repo := cacher.NewTieredRepo(l1, tt.l2, time.Minute)
+			if err := tt.l2.Set(ctx, "k", "v", tt.l2TTL); err != nil {
+				t.Fatal(err)
+			}
+			if v, err := repo.Get(ctx, "k"); v != "v" || err != nil {
+				t.Fatalf("Get() = %v, %v", v, err)
+			}
+			if ttl, _ := l1.TTL(ctx, "k"); ttl < tt.wantMin || ttl > tt.wantMax {
+				t.Errorf("l1 TTL = %v, want [%v, %v]", ttl, tt.wantMin, tt.wantMax)
+			}
+			if err := repo.Del(ctx, "k"); err != nil {
+				t.Fatal(err)
+			}
+			if v, _ := repo.Get(ctx, "k"); v != nil {
+				t.Errorf("Get() after Del = %v", v)
+			}
+		})
+	}
+}
+
+func TestTieredRepo_Set(t *testing.T) {
+	ctx := context.Background()
+	l1, l2 := cacher.NewMemoryRepo(), cacher.NewMemoryRepo()
+	repo := cacher.NewTieredRepo(l1, l2, time.Minute)
+	if err := repo.Set(ctx, "short", "v", time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Set(ctx, "long", "v", time.Hour); err != nil {
+		t.Fatal(err)
+	}
+	if ttl, _ := l1.TTL(ctx, "short"); ttl <= 0 || ttl > time.Second {
+		t.Errorf("l1 TTL(short) = %v", ttl)
+	}
+	if ttl, _ := l1.TTL(ctx, "long"); ttl <= 0 || ttl > time.Minute {
+		t.Errorf("l1 TTL(long) = %v", ttl)
+	}
+	if v, ttl, err := repo.GetWithTTL(ctx, "long"); v != "v" || ttl > time.Minute || err != nil {
+		t.Errorf("GetWithTTL() = %v, %v, %v", v, ttl, err)
+	}
+}
@@ -0,0 +1,41 @@
+package cacher
+
+import "reflect"
+
+// Result 单次读取的结果信息，见 WithResult
+type Result struct {
+	Key  string //存储库中的缓存键，已加上命名空间
+	Hit  bool   //是否命中缓存，同 GetWithOption 的返回值
+	Size int    //读取或写入的缓存数据的字节数，缓存数据不是字符串或字节切片且没有开启 Option.MeasureSize 时为0
+}
+
+// WithResult 读取完成后把结果信息写入 r，用于找出过大的缓存数据、跟踪缓存数据大小的变化。
+// 读取出错时 r 可能只有部分字段有值
+func WithResult(r *Result) func(opt *Option) {
+	return func(opt *Option) {
+		opt.result = r
+	}
+}
+
+// report 记录单次读取的结果信息
+func (o Option) report(key string, hit bool, size int) {
+	if o.result != nil {
+		*o.result = Result{Key: key, Hit: hit, Size: size}
+	}
+}
+
+// measure 缓存数据的字节数。字符串、字节切片直接取长度；
+// 开启 Option.MeasureSize 时，其他类型按编解码器编码后的字节数计算，与存储库实际保存的字节数可能略有差异
+func (c *Cacher) measure(v reflect.Value, opt Option) int {
+	if size := sizeOf(v); size > 0 || !opt.MeasureSize || !v.IsValid() {
+		return size
+	}
+	if v.Kind() == reflect.String || isBytes(v.Type()) {
+		return 0
+	}
+	data, err := opt.codecFor(v.Type()).Marshal(v.Interface())
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
@@ -0,0 +1,51 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestWithResult(t *testing.T) {
+	ctx := context.Background()
+	type item struct {
+		Name string `json:"name"`
+	}
+	tests := []struct {
+		name    string
+		cached  map[string]interface{}
+		data    interface{}
+		measure bool
+		want    cacher.Result
+	}{
+		{name: "未命中", data: "hello", want: cacher.Result{Key: "k", Size: 5}},
+		{name: "命中", cached: map[string]interface{}{"k": []byte("cached")}, want: cacher.Result{Key: "k", Hit: true, Size: 6}},
+		{name: "结构体不统计", data: item{Name: "a"}, want: cacher.Result{Key: "k"}},
+		{name: "按编解码器统计", data: item{Name: "a"}, measure: true, want: cacher.Result{Key: "k", Size: len(`{"name":"a"}`)}},
+		{name: "命中时按编解码器统计", cached: map[string]interface{}{"k": item{Name: "bb"}}, measure: true, want: cacher.Result{Key: "k", Hit: true, Size: len(`{"name":"bb"}`)}},
+	}
+	for _, tt := range tests {
+		c := cacher.New(newRepoMap(tt.cached), time.Minute, func(opt *cacher.Option) {
+			opt.MeasureSize = tt.measure
+		})
+		var setSize int
+		c.OnEvent(func(e cacher.Event) {
+			if e.Type == cacher.EventSet {
+				setSize = e.Size
+			}
+		})
+		var r cacher.Result
+		var v interface{}
+		if _, err := c.GetWithOption(ctx, "k", func() (interface{}, error) { return tt.data, nil }, &v, cacher.WithResult(&r)); err != nil {
+			t.Errorf("%s: GetWithOption() = %v", tt.name, err)
+			continue
+		}
+		if r != tt.want {
+			t.Errorf("%s: Result = %+v, want %+v", tt.name, r, tt.want)
+		}
+		if !tt.want.Hit && setSize != tt.want.Size {
+			t.Errorf("%s: EventSet.Size = %d, want %d", tt.name, setSize, tt.want.Size)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package cacher
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"time"
+)
+
+// revalidateAsync 按 Option.Revalidate 的比例，异步重新查询并与缓存数据比较
+func (c *Cacher) revalidateAsync(ctx context.Context, key string, cached reflect.Value, queryFunc func() (interface{}, error), toType reflect.Type, opt Option) {
+	if opt.Revalidate <= 0 || toType == nil || rand.Float64() >= opt.Revalidate {
+		return
+	}
+	ctx = detach(ctx)
+	c.goBackground(func() {
+		c.revalidate(ctx, key, cached, queryFunc, toType, opt)
+	})
+}
+
+// revalidate 重新查询并与缓存数据比较，不一致时触发 EventMismatch 事件并更正缓存。
+// 查询出错时放弃本次校验，错误通过 EventError 事件发布
+func (c *Cacher) revalidate(ctx context.Context, key string, cached reflect.Value, queryFunc func() (interface{}, error), toType reflect.Type, opt Option) {
+	fresh, err := queryFunc()
+	if err != nil {
+		c.emitError("revalidate", key, err)
+		return
+	}
+	if fresh != nil && opt.Transform != nil {
+		if fresh, err = opt.Transform(fresh); err != nil {
+			c.emitError("revalidate", key, err)
+			return
+		}
+	}
+	//缓存数据和查询数据都转换为目标类型后比较
+	cachedTo := reflect.New(toType).Elem()
+	if err := c.assign(cached, cachedTo, toType, opt); err != nil {
+		c.emitError("revalidate", key, err)
+		return
+	}
+	freshTo := reflect.New(toType).Elem()
+	if fresh != nil {
+		if err := c.assign(reflect.ValueOf(fresh), freshTo, toType, opt); err != nil {
+			c.emitError("revalidate", key, err)
+			return
+		}
+	}
+	if reflect.DeepEqual(cachedTo.Interface(), freshTo.Interface()) {
+		return
+	}
+	c.emit(Event{Type: EventMismatch, Key: key})
+	if fresh == nil {
+		//数据已不存在，删除缓存，下次读取时按空数据处理
+		c.flights.del(key)
+		if err := c.repo.Del(ctx, key); err != nil {
+			c.emitError("revalidate", key, err)
+			return
+		}
+		c.emit(Event{Type: EventDel, Key: key})
+		return
+	}
+	if err := c.store(ctx, key, fresh, opt.withJitter(opt.Expire), opt); err != nil {
+		c.emitError("revalidate", key, err)
+		return
+	}
+	c.invalidateWritten(ctx, key, opt)
+}
+
+// detachedContext 保留 ctx 中的值，但不随 ctx 取消，用于请求结束后继续执行的异步任务
+type detachedContext struct {
+	parent context.Context
+}
+
+// detach 创建不随 ctx 取消的 context
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (d detachedContext) Value(key interface{}) interface{} { return d.parent.Value(key) }
@@ -0,0 +1,74 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_Revalidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		cached       interface{}
+		fresh        interface{}
+		wantMismatch bool
+		wantCache    interface{}
+	}{
+		{name: "一致", cached: []byte("v1"), fresh: "v1", wantMismatch: false, wantCache: []byte("v1")},
+		{name: "不一致，更正缓存", cached: []byte("v1"), fresh: "v2", wantMismatch: true, wantCache: "v2"},
+		{name: "数据已不存在，删除缓存", cached: []byte("v1"), fresh: nil, wantMismatch: true, wantCache: nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepoMap(map[string]interface{}{"k": tt.cached})
+			c := cacher.New(repo, 10*time.Second)
+			done := make(chan cacher.EventType, 4)
+			c.OnEvent(func(ev cacher.Event) {
+				if ev.Type != cacher.EventHit {
+					done <- ev.Type
+				}
+			})
+			var v string
+			useCache, err := c.GetWithOption(context.Background(), "k", func() (interface{}, error) {
+				return tt.fresh, nil
+			}, &v, func(opt *cacher.Option) {
+				opt.Revalidate = 1
+			})
+			if err != nil || !useCache || v != "v1" {
+				t.Fatalf("GetWithOption() = %v, %v, v = %v", useCache, err, v)
+			}
+			select {
+			case ev := <-done:
+				if !tt.wantMismatch {
+					t.Fatalf("unexpected event %v", ev)
+				}
+				if ev != cacher.EventMismatch {
+					t.Fatalf("event = %v, want mismatch", ev)
+				}
+				//等待更正缓存的事件
+				<-done
+			case <-time.After(50 * time.Millisecond):
+				if tt.wantMismatch {
+					t.Fatal("mismatch event not received")
+				}
+			}
+			repo.mu.Lock()
+			defer repo.mu.Unlock()
+			if got := repo.data["k"]; string(toBytes(got)) != string(toBytes(tt.wantCache)) {
+				t.Errorf("cache = %v, want %v", got, tt.wantCache)
+			}
+		})
+	}
+}
+
+func toBytes(v interface{}) []byte {
+	switch v := v.(type) {
+	case string:
+		return []byte(v)
+	case []byte:
+		return v
+	}
+	return nil
+}
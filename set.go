@@ -0,0 +1,127 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"time"
+)
+
+// ErrSetUnsupported 存储库未实现 SetRepo
+var ErrSetUnsupported = errors.New("存储库不支持集合，需要实现 SetRepo 接口")
+
+// SetRepo 可选的存储库接口，提供集合操作，用于去重、是否出现过等判断
+type SetRepo interface {
+	// SAdd 添加成员，expire 大于0时同时设置集合的保留时长。返回新添加的成员数，不包括已存在的成员
+	SAdd(ctx context.Context, key string, expire time.Duration, members ...string) (int64, error)
+	// SIsMember 判断成员是否存在
+	SIsMember(ctx context.Context, key string, member string) (bool, error)
+	// SRem 删除成员
+	SRem(ctx context.Context, key string, members ...string) error
+}
+
+// Set 集合门面，成员的编码方式同 Hash 的字段
+type Set struct {
+	c      *Cacher
+	key    string
+	expire time.Duration
+	repo   SetRepo
+	err    error //缓存键不符合 Option.KeyPolicy 时的错误
+}
+
+// Set 获取缓存键 key 对应的集合，optFns 中的 Expire 为集合的保留时长，等于0时使用 Cacher 的默认保留时长。
+// 存储库未实现 SetRepo 时，各方法返回 ErrSetUnsupported
+func (c *Cacher) Set(key string, optFns ...func(opt *Option)) *Set {
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if opt.Expire == 0 {
+		opt.Expire = c.defaultExpire()
+	}
+	repo, _ := c.repo.(SetRepo)
+	key, err := repoKey(key, opt)
+	return &Set{c: c, key: key, expire: opt.Expire, repo: repo, err: err}
+}
+
+// valid 缓存键不符合 Option.KeyPolicy 时返回 *KeyError，存储库未实现 SetRepo 时返回 ErrSetUnsupported
+func (s *Set) valid() error {
+	if s.err != nil {
+		return s.err
+	}
+	if s.repo == nil {
+		return ErrSetUnsupported
+	}
+	return nil
+}
+
+// Add 添加成员，并刷新集合的保留时长。返回新添加的成员数
+func (s *Set) Add(ctx context.Context, members ...interface{}) (int64, error) {
+	if err := s.valid(); err != nil {
+		return 0, err
+	}
+	if len(members) == 0 {
+		return 0, nil
+	}
+	texts, err := s.texts(members)
+	if err != nil {
+		return 0, err
+	}
+	return s.repo.SAdd(ctx, s.key, s.expire, texts...)
+}
+
+// Seen 添加成员，返回成员在添加前是否已存在，用于判断是否出现过
+func (s *Set) Seen(ctx context.Context, member interface{}) (bool, error) {
+	added, err := s.Add(ctx, member)
+	return err == nil && added == 0, err
+}
+
+// Contains 判断成员是否存在
+func (s *Set) Contains(ctx context.Context, member interface{}) (bool, error) {
+	if err := s.valid(); err != nil {
+		return false, err
+	}
+	text, err := s.c.encodeText(reflect.ValueOf(member))
+	if err != nil {
+		return false, err
+	}
+	return s.repo.SIsMember(ctx, s.key, text)
+}
+
+// Remove 删除成员
+func (s *Set) Remove(ctx context.Context, members ...interface{}) error {
+	if err := s.valid(); err != nil {
+		return err
+	}
+	if len(members) == 0 {
+		return nil
+	}
+	texts, err := s.texts(members)
+	if err != nil {
+		return err
+	}
+	return s.repo.SRem(ctx, s.key, texts...)
+}
+
+// Del 删除集合
+func (s *Set) Del(ctx context.Context) error {
+	if s.err != nil {
+		return s.err
+	}
+	return s.c.del(ctx, s.key)
+}
+
+// texts 成员编码为文本
+func (s *Set) texts(members []interface{}) ([]string, error) {
+	texts := make([]string, len(members))
+	for i, m := range members {
+		text, err := s.c.encodeText(reflect.ValueOf(m))
+		if err != nil {
+			return nil, err
+		}
+		texts[i] = text
+	}
+	return texts, nil
+}
@@ -0,0 +1,66 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestSet(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(cacher.NewMemoryRepo(), 10*time.Second)
+	seen := c.Set("seen")
+	tests := []struct {
+		member   interface{}
+		wantSeen bool
+	}{
+		{member: "a", wantSeen: false},
+		{member: 1, wantSeen: false},
+		{member: "a", wantSeen: true},
+		{member: "1", wantSeen: true},
+	}
+	for _, tt := range tests {
+		got, err := seen.Seen(ctx, tt.member)
+		if err != nil || got != tt.wantSeen {
+			t.Errorf("Seen(%v) = %v, %v, want %v", tt.member, got, err, tt.wantSeen)
+		}
+	}
+	if err := seen.Remove(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := seen.Contains(ctx, "a"); ok || err != nil {
+		t.Errorf("Contains(a) after Remove = %v, %v", ok, err)
+	}
+	if ok, err := seen.Contains(ctx, 1); !ok || err != nil {
+		t.Errorf("Contains(1) = %v, %v", ok, err)
+	}
+	if err := seen.Del(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := seen.Contains(ctx, 1); ok || err != nil {
+		t.Errorf("Contains(1) after Del = %v, %v", ok, err)
+	}
+}
+
+func TestSet_Expire(t *testing.T) {
+	ctx := context.Background()
+	s := cacher.New(cacher.NewMemoryRepo(), 10*time.Second).Set("seen", func(opt *cacher.Option) {
+		opt.Expire = 20 * time.Millisecond
+	})
+	if _, err := s.Add(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if ok, err := s.Contains(ctx, "a"); ok || err != nil {
+		t.Errorf("Contains(a) after expire = %v, %v", ok, err)
+	}
+}
+
+func TestSet_Unsupported(t *testing.T) {
+	s := cacher.New(newRepoMap(nil), 10*time.Second).Set("k")
+	if _, err := s.Add(context.Background(), 1); !errors.Is(err, cacher.ErrSetUnsupported) {
+		t.Errorf("Add() error = %v, want ErrSetUnsupported", err)
+	}
+}
@@ -0,0 +1,164 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// ErrStagedDone 两阶段写入已提交或已放弃
+var ErrStagedDone = errors.New("两阶段写入已提交或已放弃")
+
+type (
+	// MultiSetRepo 可选的存储库接口，支持原子地保存多个缓存，读取方不会看到只保存了一部分的状态
+	MultiSetRepo interface {
+		// SetMulti 原子地保存多个缓存，RepoEntry.Expire 的含义同 Repo.Set，为 0 时永不过期
+		SetMulti(ctx context.Context, entries []RepoEntry) error
+	}
+	// RepoEntry 存储库中的一个缓存
+	RepoEntry struct {
+		Key    string        //缓存键
+		Value  interface{}   //缓存数据
+		Expire time.Duration //保留时长，为 0 时永不过期
+	}
+	// StagedSet 两阶段写入：先通过 Set 准备多个缓存，再通过 Commit 一起写入，见 Cacher.PrepareSet
+	StagedSet struct {
+		c       *Cacher
+		mu      sync.Mutex
+		entries []stagedEntry
+		done    bool
+	}
+	// stagedEntry 已准备的缓存
+	stagedEntry struct {
+		key    string //缓存键，已加上命名空间
+		value  interface{}
+		expire time.Duration
+		opt    Option
+	}
+)
+
+// PrepareSet 开始两阶段写入，用于一起更新相关的多个缓存（例如实体和它的索引），避免并发的读取方看到只更新了一部分的状态。
+// 存储库实现了 MultiSetRepo 时原子地写入，否则在 Commit 时一次（BatchRepo）或依次写入，只缩短不一致的时间
+func (c *Cacher) PrepareSet() *StagedSet {
+	return &StagedSet{c: c}
+}
+
+// Set 准备一个缓存：计算缓存键、转换数据和保留时长，但不写入存储库。同一个缓存键准备多次时，提交最后一次
+func (s *StagedSet) Set(ctx context.Context, key string, value interface{}, optFns ...func(opt *Option)) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	if value == nil {
+		return errors.New("缓存数据 value 不能为空")
+	}
+	opt := s.c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if err := opt.Valid(); err != nil {
+		return err
+	}
+	key, err := s.c.buildKey(ctx, key, opt)
+	if err != nil {
+		return err
+	}
+	if opt.Transform != nil {
+		if value, err = opt.Transform(value); err != nil {
+			return err
+		}
+	}
+	if opt.Expire == 0 {
+		opt.Expire = s.c.typeExpire(reflect.TypeOf(value))
+	}
+	if value, err = encodeValue(value, opt); err != nil {
+		return err
+	}
+	entry := stagedEntry{key: key, value: value, expire: opt.withJitter(opt.Expire), opt: opt}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return ErrStagedDone
+	}
+	for i := range s.entries {
+		if s.entries[i].key == key {
+			s.entries[i] = entry
+			return nil
+		}
+	}
+	s.entries = append(s.entries, entry)
+	return nil
+}
+
+// Commit 写入所有准备的缓存。超出命名空间配额的缓存不写入，缓存被 Disable 关闭时删除准备的缓存键。
+// 存储库没有实现 MultiSetRepo 时通过 BatchRepo 一次写入或依次写入，出错时已写入的缓存不回滚
+func (s *StagedSet) Commit(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.done {
+		return ErrStagedDone
+	}
+	s.done = true
+	c := s.c
+	if c.Disabled() {
+		//同 store，缓存关闭时不写入，删除旧的缓存
+		keys := make([]string, len(s.entries))
+		for i, entry := range s.entries {
+			keys[i] = entry.key
+		}
+		return c.repo.Del(ctx, keys...)
+	}
+	entries := make([]stagedEntry, 0, len(s.entries))
+	for _, entry := range s.entries {
+		ok, err := c.checkQuota(ctx, entry.key, entry.value, entry.expire, entry.opt)
+		if err != nil {
+			return err
+		}
+		if ok {
+			entries = append(entries, entry)
+		}
+	}
+	if multi, ok := c.repo.(MultiSetRepo); ok {
+		repoEntries := make([]RepoEntry, len(entries))
+		for i, entry := range entries {
+			repoEntries[i] = RepoEntry{Key: entry.key, Value: entry.value, Expire: entry.expire}
+		}
+		if err := multi.SetMulti(ctx, repoEntries); err != nil {
+			return err
+		}
+	} else if batch, ok := c.repo.(BatchRepo); ok {
+		repoEntries := make([]RepoEntry, len(entries))
+		for i, entry := range entries {
+			repoEntries[i] = RepoEntry{Key: entry.key, Value: entry.value, Expire: entry.expire}
+		}
+		if err := batch.MSet(ctx, repoEntries...); err != nil {
+			return err
+		}
+	} else {
+		for _, entry := range entries {
+			if err := c.set(ctx, entry.key, entry.value, entry.expire, entry.opt.Priority); err != nil {
+				return err
+			}
+		}
+	}
+	var firstErr error
+	for _, entry := range entries {
+		if err := c.stored(ctx, entry.key, entry.value, entry.expire, entry.opt); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		c.invalidateWritten(ctx, entry.key, entry.opt)
+	}
+	return firstErr
+}
+
+// Abort 放弃所有准备的缓存，存储库不受影响
+func (s *StagedSet) Abort() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = true
+	s.entries = nil
+}
@@ -0,0 +1,77 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestCacher_PrepareSet(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		repo  func() cacher.Repo
+		abort bool
+		want  map[string]interface{}
+	}{
+		{name: "原子写入", repo: func() cacher.Repo { return cacher.NewMemoryRepo() }, want: map[string]interface{}{"user:1": "tom", "user:name:tom": 1}},
+		{name: "依次写入", repo: func() cacher.Repo { return newRepoMap(nil) }, want: map[string]interface{}{"user:1": "tom", "user:name:tom": 1}},
+		{name: "放弃", repo: func() cacher.Repo { return cacher.NewMemoryRepo() }, abort: true, want: map[string]interface{}{}},
+	}
+	for _, tt := range tests {
+		repo := tt.repo()
+		c := cacher.New(repo, time.Minute)
+		var sets int
+		c.OnEvent(func(e cacher.Event) {
+			if e.Type == cacher.EventSet {
+				sets++
+			}
+		})
+		st := c.PrepareSet()
+		if err := st.Set(ctx, "user:1", "jerry"); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.Set(ctx, "user:1", "tom"); err != nil {
+			t.Fatal(err)
+		}
+		if err := st.Set(ctx, "user:name:tom", 1); err != nil {
+			t.Fatal(err)
+		}
+		//提交前不可见
+		if data, _ := repo.Get(ctx, "user:1"); data != nil {
+			t.Errorf("%s: staged value visible before Commit: %v", tt.name, data)
+		}
+		var err error
+		if tt.abort {
+			st.Abort()
+			err = st.Commit(ctx)
+			if !errors.Is(err, cacher.ErrStagedDone) {
+				t.Errorf("%s: Commit() after Abort = %v, want ErrStagedDone", tt.name, err)
+			}
+		} else if err = st.Commit(ctx); err != nil {
+			t.Errorf("%s: Commit() = %v", tt.name, err)
+		}
+		for _, key := range []string{"user:1", "user:name:tom"} {
+			data, _ := repo.Get(ctx, key)
+			if data != tt.want[key] {
+				t.Errorf("%s: %s = %#v, want %#v", tt.name, key, data, tt.want[key])
+			}
+		}
+		if sets != len(tt.want) {
+			t.Errorf("%s: EventSet count = %d, want %d", tt.name, sets, len(tt.want))
+		}
+		if err := st.Set(ctx, "user:2", "x"); !errors.Is(err, cacher.ErrStagedDone) {
+			t.Errorf("%s: Set() after done = %v, want ErrStagedDone", tt.name, err)
+		}
+	}
+
+	st := cacher.New(newRepoMap(nil), time.Minute).PrepareSet()
+	if err := st.Set(ctx, "", "v"); err == nil {
+		t.Errorf("Set() with empty key want error")
+	}
+	if err := st.Set(ctx, "k", nil); err == nil {
+		t.Errorf("Set() with nil value want error")
+	}
+}
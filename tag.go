@@ -0,0 +1,132 @@
+package cacher
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// codecs 按名称注册的编解码器
+	codecs = struct {
+		sync.RWMutex
+		m map[string]Codec
+	}{m: map[string]Codec{"json": JSON, "canonical-json": CanonicalJSON}}
+	// typeTags 已解析的类型标签
+	typeTags sync.Map
+)
+
+// typeTag 类型声明的缓存行为，来自结构体中空白字段的 cache 标签，例如：
+//
+//	type Person struct {
+//		_    struct{} `cache:"codec=msgpack,ttl=5m"`
+//		Name string
+//	}
+type typeTag struct {
+	codec Codec         //编解码器，没有声明时为 nil
+	ttl   time.Duration //缓存保留时长，没有声明时为0
+	err   error         //标签格式错误
+}
+
+// RegisterCodec 按名称注册编解码器，供类型标签 cache:"codec=<name>" 引用。内置 json 和 canonical-json 编解码器
+func RegisterCodec(name string, codec Codec) {
+	codecs.Lock()
+	defer codecs.Unlock()
+	codecs.m[name] = codec
+}
+
+// codecByName 按名称获取已注册的编解码器
+func codecByName(name string) (Codec, bool) {
+	codecs.RLock()
+	defer codecs.RUnlock()
+	codec, ok := codecs.m[name]
+	return codec, ok
+}
+
+// typeTagOf 获取类型声明的缓存行为，非结构体类型或没有声明时返回零值
+func typeTagOf(t reflect.Type) typeTag {
+	if t == nil || t.Kind() != reflect.Struct {
+		return typeTag{}
+	}
+	if tag, ok := typeTags.Load(t); ok {
+		return tag.(typeTag)
+	}
+	tag := parseTypeTag(t)
+	//编解码器可能在类型第一次使用后才注册，引用未注册编解码器的标签不缓存
+	if tag.err == nil {
+		typeTags.Store(t, tag)
+	}
+	return tag
+}
+
+// parseTypeTag 解析结构体中空白字段的 cache 标签
+func parseTypeTag(t reflect.Type) (tag typeTag) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.Name != "_" {
+			continue
+		}
+		value, ok := sf.Tag.Lookup("cache")
+		if !ok {
+			continue
+		}
+		for _, item := range strings.Split(value, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			k, v, _ := strings.Cut(item, "=")
+			switch strings.TrimSpace(k) {
+			case "codec":
+				codec, ok := codecByName(strings.TrimSpace(v))
+				if !ok {
+					tag.err = fmt.Errorf("类型 %v 的 cache 标签引用了未注册的编解码器：%s", t, v)
+					return tag
+				}
+				tag.codec = codec
+			case "ttl":
+				ttl, err := time.ParseDuration(strings.TrimSpace(v))
+				if err != nil || ttl <= 0 {
+					tag.err = fmt.Errorf("类型 %v 的 cache 标签 ttl 格式错误：%s", t, v)
+					return tag
+				}
+				tag.ttl = ttl
+			default:
+				tag.err = fmt.Errorf("类型 %v 的 cache 标签不支持的配置：%s", t, item)
+				return tag
+			}
+		}
+	}
+	return tag
+}
+
+// checkTypeTag 检查目标类型的 cache 标签，格式错误或引用了未注册的编解码器时返回 *OptionError，
+// 避免标签被忽略后静默使用默认的编解码器和缓存保留时长
+func checkTypeTag(t reflect.Type) error {
+	if t == nil {
+		return nil
+	}
+	t, _ = indirectType(t)
+	if err := typeTagOf(t).err; err != nil {
+		return &OptionError{Field: "cache 标签", Reason: err.Error()}
+	}
+	return nil
+}
+
+// typeCodec 获取目标类型使用的编解码器，优先使用类型标签声明的编解码器，其次使用默认编解码器
+func (c *Cacher) typeCodec(t reflect.Type) Codec {
+	return c.options().codecFor(t)
+}
+
+// codecFor 获取目标类型使用的编解码器，优先使用类型标签声明的编解码器，其次使用选项中的编解码器
+func (o Option) codecFor(t reflect.Type) Codec {
+	if t != nil {
+		t, _ = indirectType(t)
+	}
+	if tag := typeTagOf(t); tag.codec != nil {
+		return tag.codec
+	}
+	return o.codec()
+}
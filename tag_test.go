@@ -0,0 +1,125 @@
+package cacher_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+// prefixCodec 在 JSON 数据前加前缀的测试编解码器
+type prefixCodec struct{}
+
+func (prefixCodec) Marshal(v interface{}) ([]byte, error) {
+	data, err := cacher.JSON.Marshal(v)
+	return append([]byte("tag:"), data...), err
+}
+
+func (prefixCodec) Unmarshal(data []byte, v interface{}) error {
+	if !bytes.HasPrefix(data, []byte("tag:")) {
+		return errors.New("missing prefix")
+	}
+	return cacher.JSON.Unmarshal(data[len("tag:"):], v)
+}
+
+type (
+	taggedPerson struct {
+		_    struct{} `cache:"codec=prefix,ttl=5m"`
+		Name string   `json:"name"`
+	}
+	badCodecPerson struct {
+		_    struct{} `cache:"codec=unknown"`
+		Name string
+	}
+	badTTLPerson struct {
+		_    struct{} `cache:"ttl=5"`
+		Name string
+	}
+)
+
+func init() {
+	cacher.RegisterCodec("prefix", prefixCodec{})
+}
+
+func TestTypeTag(t *testing.T) {
+	ctx := context.Background()
+	repo := &repoExpire{repoMap: newRepoMap(map[string]interface{}{"tagged": taggedPerson{Name: "n"}}), expires: make(map[string]time.Duration)}
+	c := cacher.New(repo, 10*time.Second, func(opt *cacher.Option) {
+		opt.Jitter = -1
+	})
+	if err := cacher.RegisterType[taggedPerson](c); err != nil {
+		t.Fatal(err)
+	}
+
+	var bs []byte
+	if ok, err := c.Peek(ctx, "tagged", &bs); !ok || err != nil {
+		t.Fatalf("Peek() = %v, %v", ok, err)
+	}
+	if string(bs) != `tag:{"name":"n"}` {
+		t.Errorf("encoded = %s, want tag codec", bs)
+	}
+
+	var p taggedPerson
+	if _, err := c.Get(ctx, "tagged-load", func() (interface{}, error) { return taggedPerson{Name: "m"}, nil }, &p); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.expires["tagged-load"]; got != 5*time.Minute {
+		t.Errorf("expire = %v, want 5m from tag", got)
+	}
+	c.SetTypeTTL(taggedPerson{}, time.Hour)
+	if _, err := c.Get(ctx, "tagged-override", func() (interface{}, error) { return taggedPerson{Name: "m"}, nil }, &p); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.expires["tagged-override"]; got != time.Hour {
+		t.Errorf("expire = %v, want SetTypeTTL to override tag", got)
+	}
+
+	if err := cacher.RegisterType[badCodecPerson](c); err == nil {
+		t.Errorf("RegisterType[badCodecPerson]() want error")
+	}
+	if err := cacher.RegisterType[badTTLPerson](c); err == nil {
+		t.Errorf("RegisterType[badTTLPerson]() want error")
+	}
+}
+
+func TestTypeTag_Invalid(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	calls := 0
+	query := func() (interface{}, error) {
+		calls++
+		return nil, nil
+	}
+	tests := []struct {
+		name string
+		get  func() error
+	}{
+		{name: "Get codec", get: func() error {
+			var v badCodecPerson
+			_, err := c.Get(ctx, "bad", query, &v)
+			return err
+		}},
+		{name: "Get ttl", get: func() error {
+			var v *badTTLPerson
+			_, err := c.Get(ctx, "bad", query, &v)
+			return err
+		}},
+		{name: "GetMulti", get: func() error {
+			var v map[string]badCodecPerson
+			return c.GetMulti(ctx, []string{"bad"}, func([]string) (map[string]interface{}, error) { return nil, nil }, &v)
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var optErr *cacher.OptionError
+			if err := tt.get(); !errors.As(err, &optErr) || !errors.Is(err, cacher.ErrInvalidOption) {
+				t.Errorf("err = %v, want *OptionError", err)
+			}
+		})
+	}
+	if calls != 0 {
+		t.Errorf("calls = %d, want 0", calls)
+	}
+}
@@ -0,0 +1,66 @@
+package cacher
+
+import (
+	"reflect"
+	"strconv"
+)
+
+// encodeText 编码为文本：字符串、字节切片、布尔、数值类型直接转换，其他类型使用类型对应的编解码器
+func (c *Cacher) encodeText(v reflect.Value) (string, error) {
+	v = indirect(v)
+	switch v.Kind() {
+	case reflect.Invalid:
+		return "", nil
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, v.Type().Bits()), nil
+	}
+	if isBytes(v.Type()) {
+		return string(v.Bytes()), nil
+	}
+	data, err := c.typeCodec(v.Type()).Marshal(v.Interface())
+	return string(data), err
+}
+
+// decodeText 文本解码到 v，是 encodeText 的逆过程
+func (c *Cacher) decodeText(s string, v reflect.Value) error {
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		return c.decodeText(s, v.Elem())
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+		return nil
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		v.SetBool(b)
+		return err
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		v.SetInt(n)
+		return err
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		v.SetUint(n)
+		return err
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		v.SetFloat(f)
+		return err
+	}
+	if isBytes(v.Type()) {
+		v.SetBytes([]byte(s))
+		return nil
+	}
+	return c.typeCodec(v.Type()).Unmarshal([]byte(s), v.Addr().Interface())
+}
@@ -0,0 +1,58 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+)
+
+// 跟踪的操作名称，见 Tracer
+const (
+	SpanGet  = "cache.get"  //GetWithOption 的一次调用，包括读取、查询和保存
+	SpanLoad = "cache.load" //调用查询方法
+	SpanSet  = "cache.set"  //保存缓存
+	SpanDel  = "cache.del"  //删除缓存，包括级联删除的依赖
+)
+
+type (
+	// Tracer 链路跟踪的钩子，通过 SetTracer 设置，OpenTelemetry 适配见 trace/oteltrace。
+	// 设置后 Get、GetWithOption、Del 等调用在链路中显示为 SpanGet、SpanLoad、SpanSet、SpanDel 操作
+	Tracer interface {
+		// Start 开始操作 name，返回的 ctx 用于该操作内的存储库调用和子操作
+		Start(ctx context.Context, name string, info SpanInfo) (context.Context, Span)
+	}
+	// Span 一次操作
+	Span interface {
+		// End 结束操作。hit 仅对 SpanGet 有意义，表示是否命中缓存；err 为操作返回的错误
+		End(hit bool, err error)
+	}
+	// SpanInfo 操作的属性
+	SpanInfo struct {
+		Keys    []string //存储库中的缓存键，已加上命名空间。批量查询时为未命中的缓存键
+		Backend string   //存储库的类型，例如 *cacher.MemoryRepo
+	}
+)
+
+// SetTracer 设置链路跟踪的钩子，需要在使用 Cacher 之前设置，为 nil 时不跟踪
+func (c *Cacher) SetTracer(t Tracer) {
+	c.tracer = t
+}
+
+// startSpan 开始操作 name，没有设置 Tracer 时返回 nil，nil 的 *span 可以直接调用 end
+func (c *Cacher) startSpan(ctx context.Context, name string, keys ...string) (context.Context, *span) {
+	if c.tracer == nil {
+		return ctx, nil
+	}
+	ctx, s := c.tracer.Start(ctx, name, SpanInfo{Keys: keys, Backend: fmt.Sprintf("%T", c.repo)})
+	return ctx, &span{s}
+}
+
+// span 包装 Span，允许在没有设置 Tracer 时调用
+type span struct {
+	Span
+}
+
+func (s *span) end(hit bool, err error) {
+	if s != nil {
+		s.End(hit, err)
+	}
+}
@@ -0,0 +1,72 @@
+// Package oteltrace 把缓存操作记录为 OpenTelemetry span，通过 Cacher.SetTracer 设置：
+//
+//	c.SetTracer(oteltrace.New(otel.GetTracerProvider()))
+//
+// span 名称为 cache.get、cache.load、cache.set、cache.del，属性包括缓存键、是否命中和存储库类型
+package oteltrace
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// InstrumentationName 创建 trace.Tracer 使用的名称
+const InstrumentationName = "github.com/carteruu/cacher"
+
+// span 属性名
+const (
+	AttrKey     = attribute.Key("cache.key")     //缓存键，只有一个缓存键时设置
+	AttrKeys    = attribute.Key("cache.keys")    //缓存键，有多个缓存键时设置
+	AttrHit     = attribute.Key("cache.hit")     //是否命中缓存，仅 cache.get
+	AttrBackend = attribute.Key("cache.backend") //存储库类型
+)
+
+type (
+	// Tracer 实现 cacher.Tracer
+	Tracer struct {
+		tracer trace.Tracer
+	}
+	// span 实现 cacher.Span
+	span struct {
+		span trace.Span
+		name string
+	}
+)
+
+var _ cacher.Tracer = (*Tracer)(nil)
+
+// New 创建 Tracer，provider 为空时使用 otel.GetTracerProvider 的全局 TracerProvider
+func New(provider trace.TracerProvider) *Tracer {
+	if provider == nil {
+		provider = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: provider.Tracer(InstrumentationName)}
+}
+
+// Start 实现 cacher.Tracer
+func (t *Tracer) Start(ctx context.Context, name string, info cacher.SpanInfo) (context.Context, cacher.Span) {
+	attrs := []attribute.KeyValue{AttrBackend.String(info.Backend)}
+	if len(info.Keys) == 1 {
+		attrs = append(attrs, AttrKey.String(info.Keys[0]))
+	} else if len(info.Keys) > 1 {
+		attrs = append(attrs, AttrKeys.StringSlice(info.Keys))
+	}
+	ctx, s := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	return ctx, &span{span: s, name: name}
+}
+
+// End 实现 cacher.Span
+func (s *span) End(hit bool, err error) {
+	if s.name == cacher.SpanGet {
+		s.span.SetAttributes(AttrHit.Bool(hit))
+	}
+	if err != nil {
+		s.span.RecordError(err)
+		s.span.SetStatus(codes.Error, err.Error())
+	}
+	s.span.End()
+}
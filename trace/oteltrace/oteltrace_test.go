@@ -0,0 +1,76 @@
+package oteltrace_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"github.com/carteruu/cacher/trace/oteltrace"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"testing"
+	"time"
+)
+
+func TestTracer(t *testing.T) {
+	ctx := context.Background()
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	c := cacher.New(cacher.NewMemoryRepo(), time.Minute)
+	c.SetTracer(oteltrace.New(provider))
+
+	var s string
+	query := func() (interface{}, error) { return "v", nil }
+	_, _ = c.Get(ctx, "a", query, &s)
+	_, _ = c.Get(ctx, "a", query, &s)
+	_, _ = c.Get(ctx, "err", func() (interface{}, error) { return nil, errors.New("query failed") }, &s)
+	_ = c.Del(ctx, "a")
+
+	type want struct {
+		name   string
+		hit    *bool
+		parent string
+		err    bool
+	}
+	yes, no := true, false
+	wants := []want{
+		{name: cacher.SpanLoad, parent: cacher.SpanGet},
+		{name: cacher.SpanSet, parent: cacher.SpanGet},
+		{name: cacher.SpanGet, hit: &no},
+		{name: cacher.SpanGet, hit: &yes},
+		{name: cacher.SpanLoad, parent: cacher.SpanGet, err: true},
+		{name: cacher.SpanGet, hit: &no, err: true},
+		{name: cacher.SpanDel},
+	}
+	spans := recorder.Ended()
+	if len(spans) != len(wants) {
+		t.Fatalf("spans = %d, want %d", len(spans), len(wants))
+	}
+	names := make(map[string]string)
+	for _, s := range spans {
+		names[s.SpanContext().SpanID().String()] = s.Name()
+	}
+	for i, w := range wants {
+		s := spans[i]
+		if s.Name() != w.name {
+			t.Errorf("span %d name = %s, want %s", i, s.Name(), w.name)
+		}
+		if parent := names[s.Parent().SpanID().String()]; parent != w.parent {
+			t.Errorf("span %d parent = %q, want %q", i, parent, w.parent)
+		}
+		attrs := attribute.NewSet(s.Attributes()...)
+		if v, ok := attrs.Value(oteltrace.AttrBackend); !ok || v.AsString() != "*cacher.MemoryRepo" {
+			t.Errorf("span %d backend = %v", i, v.AsString())
+		}
+		if _, ok := attrs.Value(oteltrace.AttrKey); !ok {
+			t.Errorf("span %d has no key", i)
+		}
+		if v, ok := attrs.Value(oteltrace.AttrHit); (w.hit != nil) != ok || (ok && v.AsBool() != *w.hit) {
+			t.Errorf("span %d hit = %v, %v, want %v", i, v.AsBool(), ok, w.hit)
+		}
+		if (s.Status().Code == codes.Error) != w.err {
+			t.Errorf("span %d status = %v, want error %v", i, s.Status().Code, w.err)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordTracer 记录开始和结束的操作
+type recordTracer struct {
+	spans []string
+}
+
+type recordSpan struct {
+	t    *recordTracer
+	name string
+}
+
+func (t *recordTracer) Start(ctx context.Context, name string, info cacher.SpanInfo) (context.Context, cacher.Span) {
+	t.spans = append(t.spans, "start "+name+" "+strings.Join(info.Keys, ","))
+	return ctx, recordSpan{t: t, name: name}
+}
+
+func (s recordSpan) End(hit bool, err error) {
+	if hit {
+		s.t.spans = append(s.t.spans, "end "+s.name+" hit")
+		return
+	}
+	s.t.spans = append(s.t.spans, "end "+s.name)
+}
+
+func TestCacher_SetTracer(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	tracer := &recordTracer{}
+	c.SetTracer(tracer)
+	var s string
+	for i := 0; i < 2; i++ {
+		if _, err := c.Get(ctx, "a", func() (interface{}, error) { return "v", nil }, &s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.Del(ctx, "a", "b"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"start cache.get a", "start cache.load a", "end cache.load", "start cache.set a", "end cache.set", "end cache.get",
+		"start cache.get a", "end cache.get hit",
+		"start cache.del a,b", "end cache.del",
+	}
+	if !reflect.DeepEqual(tracer.spans, want) {
+		t.Errorf("spans = %q\nwant %q", tracer.spans, want)
+	}
+}
@@ -0,0 +1,87 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// tunables 运行时可调整的缓存保留时长和默认选项，整体原子替换，读取方总是看到一致的组合
+type tunables struct {
+	expire   time.Duration //缓存保留时长
+	defaults Option        //默认选项
+}
+
+// options 当前的默认选项
+func (c *Cacher) options() Option {
+	return c.tunables.Load().(*tunables).defaults
+}
+
+// defaultExpire 当前的缓存保留时长
+func (c *Cacher) defaultExpire() time.Duration {
+	return c.tunables.Load().(*tunables).expire
+}
+
+// Tune 在当前默认选项的基础上修改默认选项，可以与读取并发调用，例如调整保留时长随机比例、旧数据保留时长，不需要重新创建 Cacher。
+// 修改后的选项错误时返回 *OptionError，默认选项保持不变。正在进行的调用继续使用修改前的选项。
+// KeyHMAC、Namespace 等影响缓存键的选项修改后，原来的缓存不再被读取
+func (c *Cacher) Tune(optFns ...func(opt *Option)) error {
+	//与其他修改并发时重试，避免覆盖其他修改
+	for {
+		cur := c.tunables.Load().(*tunables)
+		defaults := cur.defaults.clone()
+		for _, optFn := range optFns {
+			if optFn != nil {
+				optFn(&defaults)
+			}
+		}
+		if err := defaults.Valid(); err != nil {
+			return err
+		}
+		if c.tunables.CompareAndSwap(cur, &tunables{expire: cur.expire, defaults: defaults}) {
+			return nil
+		}
+	}
+}
+
+// SetExpire 修改缓存保留时长，expire 必须大于0。只影响之后写入的缓存
+func (c *Cacher) SetExpire(expire time.Duration) error {
+	if expire <= 0 {
+		return errors.New("缓存保存时长 expire 必须大于0")
+	}
+	for {
+		cur := c.tunables.Load().(*tunables)
+		if c.tunables.CompareAndSwap(cur, &tunables{expire: expire, defaults: cur.defaults}) {
+			return nil
+		}
+	}
+}
+
+// Reload 按配置替换缓存保留时长和默认选项：在 New 设置的默认选项的基础上应用配置，KeyHMAC、KeyPolicy 等未在配置中设置的选项保持 New 时的值，
+// 之前 Tune 和 Reload 的修改被替换，cfg.Name 被忽略。配置错误时返回错误，原来的设置保持不变
+func (c *Cacher) Reload(cfg Config) error {
+	defaults, err := cfg.apply(c.base)
+	if err != nil {
+		return err
+	}
+	c.tunables.Store(&tunables{expire: cfg.Expire, defaults: defaults})
+	return nil
+}
+
+// WatchConfig 监听配置源，每收到一个配置调用 Reload，用于在线调整参数（例如故障时缩短保留时长）。
+// 配置错误时触发 EventError 事件并保留原来的设置。阻塞直到 ctx 结束或 updates 关闭
+func (c *Cacher) WatchConfig(ctx context.Context, updates <-chan Config) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case cfg, ok := <-updates:
+			if !ok {
+				return nil
+			}
+			if err := c.Reload(cfg); err != nil {
+				c.emitError("reload", "", err)
+			}
+		}
+	}
+}
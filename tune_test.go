@@ -0,0 +1,138 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestCacher_Tune(t *testing.T) {
+	ctx := context.Background()
+	repo := cacher.NewMemoryRepo()
+	c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.Jitter = -1
+	})
+	load := func() (interface{}, error) { return "v", nil }
+	var v string
+
+	steps := []struct {
+		name    string
+		tune    func() error
+		wantErr bool
+		wantTTL time.Duration
+	}{
+		{name: "初始设置", tune: func() error { return nil }, wantTTL: time.Minute},
+		{name: "修改保留时长", tune: func() error { return c.SetExpire(time.Hour) }, wantTTL: time.Hour},
+		{name: "保留时长错误", tune: func() error { return c.SetExpire(0) }, wantErr: true, wantTTL: time.Hour},
+		{name: "修改默认选项", tune: func() error { return c.Tune(func(opt *cacher.Option) { opt.Expire = 2 * time.Hour }) }, wantTTL: 2 * time.Hour},
+		{name: "选项错误", tune: func() error { return c.Tune(func(opt *cacher.Option) { opt.Expire = -1 }) }, wantErr: true, wantTTL: 2 * time.Hour},
+		{name: "按配置替换", tune: func() error {
+			return c.Reload(cacher.Config{Expire: 30 * time.Second, Options: []func(opt *cacher.Option){func(opt *cacher.Option) { opt.Jitter = -1 }}})
+		}, wantTTL: 30 * time.Second},
+		{name: "配置错误", tune: func() error { return c.Reload(cacher.Config{}) }, wantErr: true, wantTTL: 30 * time.Second},
+	}
+	for _, step := range steps {
+		if err := step.tune(); (err != nil) != step.wantErr {
+			t.Errorf("%s: error = %v, wantErr %v", step.name, err, step.wantErr)
+		}
+		if err := c.Del(ctx, "k"); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := c.Get(ctx, "k", load, &v); err != nil {
+			t.Fatal(err)
+		}
+		ttl, _ := repo.TTL(ctx, "k")
+		if ttl > step.wantTTL || ttl < step.wantTTL-time.Second {
+			t.Errorf("%s: TTL = %v, want %v", step.name, ttl, step.wantTTL)
+		}
+	}
+}
+
+func TestCacher_Reload_KeepsNewOptions(t *testing.T) {
+	ctx := context.Background()
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+		opt.KeyHMAC = []byte("secret")
+	})
+	load := func() (interface{}, error) { return "v", nil }
+	var v string
+	if _, err := c.Get(ctx, "k", load, &v); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Del(ctx, "k"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Reload(cacher.Config{Expire: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.Get(ctx, "k", load, &v); err != nil {
+		t.Fatal(err)
+	}
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if _, ok := repo.data["k"]; ok || len(repo.data) != 1 {
+		t.Errorf("repo keys = %v, want only the HMAC of k after Reload", repo.data)
+	}
+}
+
+func TestCacher_Tune_Concurrent(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				_ = c.Tune(func(opt *cacher.Option) { opt.Tags = append(opt.Tags, "t") })
+				var v string
+				_, _ = c.Get(context.Background(), "k", func() (interface{}, error) { return "v", nil }, &v)
+			}
+		}(i)
+	}
+	wg.Wait()
+	e, err := c.Explain(context.Background(), "k", new(string))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := len(e.Options.Tags); got != 400 {
+		t.Errorf("len(Tags) = %d, want 400, concurrent Tune must not lose updates", got)
+	}
+}
+
+func TestCacher_WatchConfig(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	errs := make(chan error, 1)
+	c.OnEvent(func(e cacher.Event) {
+		if e.Type == cacher.EventError {
+			errs <- e.Err
+		}
+	})
+	updates := make(chan cacher.Config)
+	done := make(chan error)
+	go func() {
+		done <- c.WatchConfig(context.Background(), updates)
+	}()
+	updates <- cacher.Config{Expire: time.Second, Options: []func(opt *cacher.Option){func(opt *cacher.Option) { opt.Namespace = "ns" }}}
+	updates <- cacher.Config{}
+	if err := <-errs; err == nil {
+		t.Errorf("invalid config want EventError")
+	}
+	close(updates)
+	if err := <-done; err != nil {
+		t.Errorf("WatchConfig() = %v", err)
+	}
+	e, err := c.Explain(context.Background(), "k", new(string))
+	if err != nil || e.Options.Namespace != "ns" || e.Expire != time.Second {
+		t.Errorf("Explain() after WatchConfig = %+v, %v", e, err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := c.WatchConfig(ctx, make(chan cacher.Config)); !errors.Is(err, context.Canceled) {
+		t.Errorf("WatchConfig() = %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,47 @@
+package cacher
+
+import (
+	"reflect"
+	"sync"
+	"time"
+)
+
+// typeTTLs 目标类型的默认缓存保留时长
+type typeTTLs struct {
+	mu sync.RWMutex
+	m  map[reflect.Type]time.Duration
+}
+
+// SetTypeTTL 设置目标类型的默认缓存保留时长，例如 c.SetTypeTTL(Person{}, time.Hour)。
+// 指针类型按其指向的类型设置。Option.Expire 等于0时，优先使用目标类型的保留时长，其次使用 Cacher 的默认保留时长；
+// expire 小于等于0时删除该类型的设置
+func (c *Cacher) SetTypeTTL(typ interface{}, expire time.Duration) {
+	t, _ := indirectType(reflect.TypeOf(typ))
+	c.typeTTLs.mu.Lock()
+	defer c.typeTTLs.mu.Unlock()
+	if expire <= 0 {
+		delete(c.typeTTLs.m, t)
+		return
+	}
+	if c.typeTTLs.m == nil {
+		c.typeTTLs.m = make(map[reflect.Type]time.Duration)
+	}
+	c.typeTTLs.m[t] = expire
+}
+
+// typeExpire 获取目标类型的默认缓存保留时长，依次使用 SetTypeTTL 的设置、类型标签声明的 ttl、Cacher 的默认保留时长
+func (c *Cacher) typeExpire(t reflect.Type) time.Duration {
+	if t == nil {
+		return c.defaultExpire()
+	}
+	c.typeTTLs.mu.RLock()
+	expire, ok := c.typeTTLs.m[t]
+	c.typeTTLs.mu.RUnlock()
+	if ok {
+		return expire
+	}
+	if tag := typeTagOf(t); tag.ttl > 0 {
+		return tag.ttl
+	}
+	return c.defaultExpire()
+}
@@ -0,0 +1,57 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestCacher_SetTypeTTL(t *testing.T) {
+	repo := &repoExpire{repoMap: newRepoMap(nil), expires: make(map[string]time.Duration)}
+	c := cacher.New(repo, 10*time.Second, func(opt *cacher.Option) {
+		opt.Jitter = -1
+	})
+	c.SetTypeTTL(&person{}, time.Hour)
+	if err := cacher.RegisterType[person](c); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		key    string
+		v      interface{}
+		expire time.Duration
+		want   time.Duration
+	}{
+		{name: "类型保留时长", key: "person", v: &person{}, want: time.Hour},
+		{name: "指针目标", key: "person-ptr", v: new(*person), want: time.Hour},
+		{name: "调用时指定优先", key: "person-call", v: &person{}, expire: time.Minute, want: time.Minute},
+		{name: "其他类型使用默认保留时长", key: "string", v: new(string), want: 10 * time.Second},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.GetWithOption(context.Background(), tt.key, func() (interface{}, error) {
+				if _, ok := tt.v.(*string); ok {
+					return "s", nil
+				}
+				return personObj, nil
+			}, tt.v, func(opt *cacher.Option) {
+				opt.Expire = tt.expire
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := repo.expires[tt.key]; got != tt.want {
+				t.Errorf("expire = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	c.SetTypeTTL(person{}, 0)
+	if _, err := c.Get(context.Background(), "person-reset", func() (interface{}, error) { return personObj, nil }, &person{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := repo.expires["person-reset"]; got != 10*time.Second {
+		t.Errorf("expire after reset = %v, want 10s", got)
+	}
+}
@@ -0,0 +1,78 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+)
+
+// GetTyped 同 Cacher.GetWithOption，查询方法和返回值都使用类型 T，不需要传入 &v 和在查询方法中返回 interface{}，
+// 查询方法返回的类型错误在编译时就能发现。例如：
+//
+//	p, useCache, err := cacher.GetTyped(ctx, c, key, func() (Person, error) { return repo.FindPerson(id) })
+//
+// 存储库以字符串或字节切片保存缓存时，结构体等类型需要通过 RegisterType 注册转换器，或开启 Option.CodecFallback。
+// T 的零值是 nil（接口）或类型化的 nil（指针）时，与 Get 的目标变量为 nil 一样返回 ErrInvalidTarget，使用 T 指向的类型
+func GetTyped[T any](ctx context.Context, c *Cacher, key string, queryFn func() (T, error), optFns ...func(opt *Option)) (T, bool, error) {
+	var v T
+	if toType := reflect.TypeOf(&v).Elem(); toType.Kind() == reflect.Interface || toType.Kind() == reflect.Ptr {
+		return v, false, &ConversionError{To: toType, Err: fmt.Errorf("%w: 类型参数不能是接口或指针", ErrInvalidTarget)}
+	}
+	if queryFn == nil {
+		//交给 GetWithOption 返回参数错误
+		useCache, err := c.GetWithOption(ctx, key, nil, &v, optFns...)
+		return v, useCache, err
+	}
+	useCache, err := c.GetWithOption(ctx, key, func() (interface{}, error) {
+		return queryFn()
+	}, &v, optFns...)
+	return v, useCache, err
+}
+
+// GetMultiTyped 同 Cacher.GetMulti，缓存键和数据使用类型 K、V，返回命中或查询到的数据和没有数据的缓存键。
+// missing 按 keys 中首次出现的顺序排列，不包含重复的缓存键；queryFn 的 missing 同样按 keys 的顺序排列。例如：
+//
+//	users, missing, err := cacher.GetMultiTyped(ctx, c, ids, func(missing []UserKey) (map[UserKey]User, error) { return repo.FindUsers(missing) })
+func GetMultiTyped[K ~string, V any](
+	ctx context.Context,
+	c *Cacher,
+	keys []K,
+	queryFn func(missing []K) (map[K]V, error),
+	optFns ...func(opt *Option)) (map[K]V, []K, error) {
+	if queryFn == nil {
+		return nil, nil, ErrNilQueryFunc
+	}
+	strKeys := make([]string, len(keys))
+	for i, key := range keys {
+		strKeys[i] = string(key)
+	}
+	m := make(map[K]V, len(keys))
+	err := c.GetMulti(ctx, strKeys, func(missing []string) (map[string]interface{}, error) {
+		typedMissing := make([]K, len(missing))
+		for i, key := range missing {
+			typedMissing[i] = K(key)
+		}
+		queried, err := queryFn(typedMissing)
+		if err != nil {
+			return nil, err
+		}
+		data := make(map[string]interface{}, len(queried))
+		for key, v := range queried {
+			data[string(key)] = v
+		}
+		return data, nil
+	}, &m, optFns...)
+	if err != nil {
+		return nil, nil, err
+	}
+	var missing []K
+	seen := make(map[K]bool, len(keys))
+	for _, key := range keys {
+		if _, ok := m[key]; ok || seen[key] {
+			continue
+		}
+		seen[key] = true
+		missing = append(missing, key)
+	}
+	return m, missing, nil
+}
@@ -0,0 +1,44 @@
+package cacher
+
+import (
+	"errors"
+	"fmt"
+)
+
+// TypedOption 带类型参数的选项，NilData、ShouldCache、Transform 都使用目标类型 T，
+// 避免空缓存数据与目标类型不一致。通过 WithTyped 转换为 Option 选项函数
+type TypedOption[T any] struct {
+	NilData     *T                   //空缓存数据，为 nil 时使用 T 的零值
+	ShouldCache func(v T) bool       //判断查询数据是否需要保存缓存，为空时都保存
+	Transform   func(v T) (T, error) //保存缓存前转换查询数据
+}
+
+// errTypedMismatch 查询数据的类型与 TypedOption 的类型参数不一致
+var errTypedMismatch = errors.New("查询数据的类型与 TypedOption 的类型参数不一致")
+
+// WithTyped 将 TypedOption 转换为选项函数，例如：
+//
+//	c.GetWithOption(ctx, key, queryFn, &p, cacher.WithTyped(cacher.TypedOption[Person]{NilData: &Person{}}))
+func WithTyped[T any](o TypedOption[T]) func(opt *Option) {
+	return func(opt *Option) {
+		if o.NilData != nil {
+			opt.NilData = *o.NilData
+		}
+		if o.ShouldCache != nil {
+			opt.ShouldCache = func(v interface{}) bool {
+				t, ok := v.(T)
+				//类型不一致时交给 Transform 或类型转换处理，保持默认的保存行为
+				return !ok || o.ShouldCache(t)
+			}
+		}
+		if o.Transform != nil {
+			opt.Transform = func(v interface{}) (interface{}, error) {
+				t, ok := v.(T)
+				if !ok {
+					return nil, fmt.Errorf("%w: %T", errTypedMismatch, v)
+				}
+				return o.Transform(t)
+			}
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithTyped(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name      string
+		opt       cacher.TypedOption[string]
+		queryData interface{}
+		wantData  string
+		wantCache bool
+		wantErr   bool
+	}{
+		{
+			name:      "转换后保存",
+			opt:       cacher.TypedOption[string]{Transform: func(v string) (string, error) { return strings.ToUpper(v), nil }},
+			queryData: "abc",
+			wantData:  "ABC",
+			wantCache: true,
+		}, {
+			name:      "不保存空字符串",
+			opt:       cacher.TypedOption[string]{ShouldCache: func(v string) bool { return v != "" }},
+			queryData: "",
+			wantData:  "",
+			wantCache: false,
+		}, {
+			name:      "查询数据类型不一致",
+			opt:       cacher.TypedOption[string]{Transform: func(v string) (string, error) { return v, nil }},
+			queryData: 1,
+			wantErr:   true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepoMap(nil)
+			c := cacher.New(repo, 10*time.Second)
+			var v string
+			_, err := c.GetWithOption(ctx, "k", func() (interface{}, error) {
+				return tt.queryData, nil
+			}, &v, cacher.WithTyped(tt.opt))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("GetWithOption() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if v != tt.wantData {
+				t.Errorf("v = %q, want %q", v, tt.wantData)
+			}
+			if _, ok := repo.data["k"]; ok != tt.wantCache {
+				t.Errorf("cached = %v, want %v", ok, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestWithTyped_NilData(t *testing.T) {
+	repo := newRepoMap(nil)
+	c := cacher.New(repo, 10*time.Second)
+	nilData := "none"
+	var v string
+	_, err := c.GetWithOption(context.Background(), "k", func() (interface{}, error) {
+		return nil, nil
+	}, &v, cacher.WithNilCache(time.Second, nil), cacher.WithTyped(cacher.TypedOption[string]{NilData: &nilData}))
+	if err != nil || v != "none" || repo.data["k"] != "none" {
+		t.Errorf("GetWithOption() error = %v, v = %q, cache = %v", err, v, repo.data["k"])
+	}
+}
+
+func TestOption_NilDataMismatch(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	var v person
+	_, err := c.GetWithOption(context.Background(), "k", func() (interface{}, error) {
+		return nil, nil
+	}, &v, cacher.WithNilCache(time.Second, 1))
+	if !errors.Is(err, cacher.ErrInvalidOption) {
+		t.Errorf("GetWithOption() error = %v, want ErrInvalidOption", err)
+	}
+}
@@ -0,0 +1,98 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGetTyped(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(cacher.NewMemoryRepo(), time.Minute)
+	queryErr := errors.New("query failed")
+	tests := []struct {
+		name         string
+		key          string
+		query        func() (person, error)
+		want         person
+		wantUseCache bool
+		wantErr      error
+	}{
+		{name: "查询", key: "p", query: func() (person, error) { return person{Name: "a", Age: 1}, nil }, want: person{Name: "a", Age: 1}},
+		{name: "命中缓存", key: "p", query: func() (person, error) { return person{}, queryErr }, want: person{Name: "a", Age: 1}, wantUseCache: true},
+		{name: "查询出错", key: "q", query: func() (person, error) { return person{}, queryErr }, wantErr: queryErr},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, useCache, err := cacher.GetTyped(ctx, c, tt.key, tt.query)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("GetTyped() error = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want || useCache != tt.wantUseCache {
+				t.Errorf("GetTyped() = %+v, %v, want %+v, %v", got, useCache, tt.want, tt.wantUseCache)
+			}
+		})
+	}
+	if _, _, err := cacher.GetTyped[int](ctx, c, "k", nil); err == nil {
+		t.Error("GetTyped() with nil queryFn should fail")
+	}
+}
+
+func TestGetTyped_InvalidTarget(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(cacher.NewMemoryRepo(), time.Minute)
+	queried := false
+	tests := []struct {
+		name string
+		call func() error
+	}{
+		{name: "接口，零值为 nil", call: func() error {
+			_, _, err := cacher.GetTyped(ctx, c, "k", func() (interface{}, error) { queried = true; return 1, nil })
+			return err
+		}},
+		{name: "指针，零值为类型化的 nil", call: func() error {
+			_, _, err := cacher.GetTyped(ctx, c, "k", func() (*person, error) { queried = true; return &person{}, nil })
+			return err
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.call(); !errors.Is(err, cacher.ErrInvalidTarget) {
+				t.Errorf("GetTyped() error = %v, want ErrInvalidTarget", err)
+			}
+			if queried {
+				t.Error("GetTyped() called queryFn for an invalid target")
+			}
+		})
+	}
+}
+
+func TestGetMultiTyped(t *testing.T) {
+	type userKey string
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(map[string]interface{}{"u:1": 1}), time.Minute)
+	var queried []userKey
+	m, missing, err := cacher.GetMultiTyped(ctx, c, []userKey{"u:3", "u:1", "u:2", "u:3", "u:4"}, func(missing []userKey) (map[userKey]int, error) {
+		queried = missing
+		return map[userKey]int{"u:2": 2}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 2 || m["u:1"] != 1 || m["u:2"] != 2 {
+		t.Errorf("GetMultiTyped() m = %v", m)
+	}
+	if !reflect.DeepEqual(missing, []userKey{"u:3", "u:4"}) {
+		t.Errorf("GetMultiTyped() missing = %v, want [u:3 u:4]", missing)
+	}
+	if !reflect.DeepEqual(queried, []userKey{"u:3", "u:2", "u:4"}) {
+		t.Errorf("queryFn missing = %v, want keys order", queried)
+	}
+
+	if _, _, err := cacher.GetMultiTyped[userKey, int](ctx, c, nil, nil); err == nil {
+		t.Error("GetMultiTyped() with nil query should fail")
+	}
+}
@@ -0,0 +1,52 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestOption_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		cached       interface{}
+		wantUseCache bool
+		wantData     person
+	}{
+		{
+			name:         "缓存数据校验通过",
+			cached:       personObjBs,
+			wantUseCache: true,
+			wantData:     personObj,
+		}, {
+			name:         "缓存数据缺少字段，重新查询",
+			cached:       []byte(`{"name":"name-1"}`),
+			wantUseCache: false,
+			wantData:     personObj1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := newRepoMap(map[string]interface{}{"p": tt.cached})
+			c := cacher.New(repo, 10*time.Second)
+			if err := cacher.RegisterType[person](c); err != nil {
+				t.Fatal(err)
+			}
+			var v person
+			useCache, err := c.GetWithOption(context.Background(), "p", func() (interface{}, error) {
+				return personObj1, nil
+			}, &v, func(opt *cacher.Option) {
+				opt.Validate = func(v interface{}) bool {
+					return v.(person).Age > 0
+				}
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if useCache != tt.wantUseCache || v != tt.wantData {
+				t.Errorf("GetWithOption() useCache = %v, v = %+v, want %v, %+v", useCache, v, tt.wantUseCache, tt.wantData)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// VerifyStatus 缓存校验结果
+type VerifyStatus int
+
+const (
+	VerifyMatch    VerifyStatus = iota //缓存数据与查询数据一致
+	VerifyMismatch                     //缓存数据与查询数据不一致
+	VerifyMissing                      //没有缓存
+	VerifyError                        //查询或转换出错
+)
+
+func (s VerifyStatus) String() string {
+	switch s {
+	case VerifyMatch:
+		return "match"
+	case VerifyMismatch:
+		return "mismatch"
+	case VerifyMissing:
+		return "missing"
+	case VerifyError:
+		return "error"
+	}
+	return "unknown"
+}
+
+type (
+	// VerifyResult 单个缓存键的校验结果
+	VerifyResult struct {
+		Key    string       //缓存键
+		Status VerifyStatus //校验结果
+		Diff   string       //不一致时的差异摘要
+		Err    error        //出错时的错误
+	}
+	// VerifyReport 校验报告
+	VerifyReport struct {
+		Checked    int            //校验的缓存键数
+		Matched    int            //一致的缓存键数
+		Mismatched int            //不一致的缓存键数
+		Missing    int            //没有缓存的缓存键数
+		Errors     int            //出错的缓存键数
+		Results    []VerifyResult //一致以外的校验结果
+	}
+)
+
+// StaleRatio 不一致的比例，不包括没有缓存和出错的缓存键
+func (r VerifyReport) StaleRatio() float64 {
+	if n := r.Matched + r.Mismatched; n > 0 {
+		return float64(r.Mismatched) / float64(n)
+	}
+	return 0
+}
+
+// maxDiffLen 差异摘要的最大长度
+const maxDiffLen = 512
+
+// Verify 逐个比较缓存数据与 loader 查询的数据，不修改缓存，可以在定时任务中统计缓存的陈旧程度。
+// 缓存数据转换为 Option.TargetType 后比较，没有设置时转换为查询数据的类型
+func (c *Cacher) Verify(ctx context.Context, keys []string, loader func(ctx context.Context, key string) (interface{}, error), optFns ...func(opt *Option)) (VerifyReport, error) {
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if err := opt.Valid(); err != nil {
+		return VerifyReport{}, err
+	}
+	var report VerifyReport
+	for _, key := range keys {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		result := c.verify(ctx, key, loader, opt)
+		report.Checked++
+		switch result.Status {
+		case VerifyMatch:
+			report.Matched++
+			continue
+		case VerifyMismatch:
+			report.Mismatched++
+		case VerifyMissing:
+			report.Missing++
+		case VerifyError:
+			report.Errors++
+		}
+		report.Results = append(report.Results, result)
+	}
+	return report, nil
+}
+
+// verify 校验单个缓存键
+func (c *Cacher) verify(ctx context.Context, key string, loader func(ctx context.Context, key string) (interface{}, error), opt Option) VerifyResult {
+	result := VerifyResult{Key: key}
+	fail := func(err error) VerifyResult {
+		result.Status = VerifyError
+		result.Err = err
+		return result
+	}
+	cacheKey, err := c.buildKey(ctx, key, opt)
+	if err != nil {
+		return fail(err)
+	}
+	cached, err := c.repo.Get(ctx, cacheKey)
+	if err != nil {
+		return fail(err)
+	}
+	if cached == nil {
+		result.Status = VerifyMissing
+		return result
+	}
+	fresh, err := loader(ctx, key)
+	if err != nil {
+		return fail(err)
+	}
+	if err := cachedError(cacheKey, cached); err != nil {
+		result.Status = VerifyMismatch
+		result.Diff = "缓存了查询错误: " + err.Error()
+		return result
+	}
+	if isNilMarker(reflect.ValueOf(cached)) {
+		//空缓存与数据不存在一致
+		if fresh == nil {
+			result.Status = VerifyMatch
+			return result
+		}
+		result.Status = VerifyMismatch
+		result.Diff = "缓存的是空数据"
+		return result
+	}
+	if fresh == nil {
+		result.Status = VerifyMismatch
+		result.Diff = "数据已不存在"
+		return result
+	}
+
+	toType := reflect.TypeOf(fresh)
+	if opt.TargetType != nil {
+		toType = reflect.TypeOf(opt.TargetType)
+	}
+	cachedTo := reflect.New(toType).Elem()
+	if err := c.assign(reflect.ValueOf(cached), cachedTo, toType, opt); err != nil {
+		return fail(err)
+	}
+	freshTo := reflect.New(toType).Elem()
+	if err := c.assign(reflect.ValueOf(fresh), freshTo, toType, opt); err != nil {
+		return fail(err)
+	}
+	if reflect.DeepEqual(cachedTo.Interface(), freshTo.Interface()) {
+		result.Status = VerifyMatch
+		return result
+	}
+	result.Status = VerifyMismatch
+	result.Diff = diff(cachedTo, freshTo)
+	return result
+}
+
+// diff 差异摘要：结构体列出不一致的字段，其他类型列出两个值
+func diff(cached, fresh reflect.Value) string {
+	var b strings.Builder
+	c, f := indirect(cached), indirect(fresh)
+	if c.Kind() == reflect.Struct && f.Kind() == reflect.Struct {
+		for i := 0; i < c.NumField(); i++ {
+			sf := c.Type().Field(i)
+			if sf.PkgPath != "" {
+				continue
+			}
+			cv, fv := c.Field(i).Interface(), f.Field(i).Interface()
+			if !reflect.DeepEqual(cv, fv) {
+				if b.Len() > 0 {
+					b.WriteString("; ")
+				}
+				fmt.Fprintf(&b, "%s: 缓存 %v, 查询 %v", sf.Name, cv, fv)
+			}
+		}
+	}
+	if b.Len() == 0 {
+		fmt.Fprintf(&b, "缓存 %v, 查询 %v", cached.Interface(), fresh.Interface())
+	}
+	s := b.String()
+	if len(s) > maxDiffLen {
+		s = s[:maxDiffLen] + "..."
+	}
+	return s
+}
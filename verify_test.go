@@ -0,0 +1,76 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCacher_Verify(t *testing.T) {
+	ctx := context.Background()
+	stale := personObj
+	stale.Age = 99
+	repo := newRepoMap(map[string]interface{}{
+		"same":  personObj,
+		"stale": stale,
+		"gone":  personObj,
+		"err":   personObj,
+	})
+	c := cacher.New(repo, 10*time.Second)
+	loadErr := errors.New("load error")
+	source := map[string]interface{}{"same": personObj, "stale": personObj, "missing": personObj}
+	report, err := c.Verify(ctx, []string{"same", "stale", "gone", "missing", "err"}, func(ctx context.Context, key string) (interface{}, error) {
+		if key == "err" {
+			return nil, loadErr
+		}
+		return source[key], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Checked != 5 || report.Matched != 1 || report.Mismatched != 2 || report.Missing != 1 || report.Errors != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if ratio := report.StaleRatio(); ratio != 2.0/3 {
+		t.Errorf("StaleRatio() = %v", ratio)
+	}
+	want := map[string]cacher.VerifyStatus{"stale": cacher.VerifyMismatch, "gone": cacher.VerifyMismatch, "missing": cacher.VerifyMissing, "err": cacher.VerifyError}
+	for _, r := range report.Results {
+		if r.Status != want[r.Key] {
+			t.Errorf("%s status = %v, want %v", r.Key, r.Status, want[r.Key])
+		}
+	}
+	if diff := report.Results[0].Diff; !strings.Contains(diff, "Age") || strings.Contains(diff, "Name") {
+		t.Errorf("stale diff = %q", diff)
+	}
+	if !errors.Is(report.Results[3].Err, loadErr) {
+		t.Errorf("err result = %+v", report.Results[3])
+	}
+}
+
+func TestCacher_Verify_NilCache(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), 10*time.Second, cacher.WithNilCache(time.Minute, nil))
+	for _, key := range []string{"nil", "created"} {
+		if _, err := c.Get(ctx, key, func() (interface{}, error) { return nil, nil }, new(person)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	source := map[string]interface{}{"created": personObj}
+	report, err := c.Verify(ctx, []string{"nil", "created"}, func(ctx context.Context, key string) (interface{}, error) {
+		return source[key], nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	//空缓存与数据不存在一致，数据已创建时不一致
+	if report.Matched != 1 || report.Mismatched != 1 {
+		t.Fatalf("report = %+v", report)
+	}
+	if r := report.Results[0]; r.Key != "created" || r.Status != cacher.VerifyMismatch {
+		t.Errorf("result = %+v, want created mismatch", r)
+	}
+}
@@ -0,0 +1,39 @@
+package cacher
+
+import (
+	"context"
+	"fmt"
+	"golang.org/x/sync/errgroup"
+)
+
+// WarmGroup 并发预热多个缓存，用于发布后批量加载热点数据。loaderFor 返回缓存键对应的查询方法，
+// 每个缓存键按 GetWithOption 的流程读取：已有缓存时不调用查询方法，与其他调用共享平滑查询合并。
+// concurrency 是最大并发数，小于等于0时不限制。任意一个缓存键出错时取消其他预热，返回第一个错误
+func (c *Cacher) WarmGroup(
+	ctx context.Context,
+	keys []string,
+	loaderFor func(key string) func() (interface{}, error),
+	concurrency int,
+	optFns ...func(opt *Option)) error {
+	if loaderFor == nil {
+		return ErrNilQueryFunc
+	}
+	g, ctx := errgroup.WithContext(ctx)
+	if concurrency > 0 {
+		g.SetLimit(concurrency)
+	}
+	for _, key := range keys {
+		key := key
+		g.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			var v interface{}
+			if _, err := c.GetWithOption(ctx, key, loaderFor(key), &v, optFns...); err != nil {
+				return fmt.Errorf("预热缓存 %s 失败：%w", key, err)
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
@@ -0,0 +1,72 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestCacher_WarmGroup(t *testing.T) {
+	ctx := context.Background()
+	loadErr := errors.New("load error")
+	tests := []struct {
+		name        string
+		keys        []string
+		cached      map[string]interface{}
+		fail        string
+		concurrency int
+		wantErr     error
+		wantLoads   int32
+	}{
+		{name: "预热所有缓存", keys: []string{"a", "b", "c"}, concurrency: 2, wantLoads: 3},
+		{name: "已有缓存时不查询", keys: []string{"a", "b"}, cached: map[string]interface{}{"a": "cached"}, wantLoads: 1},
+		{name: "不限制并发数", keys: []string{"a", "b", "c", "d"}, wantLoads: 4},
+		{name: "查询出错", keys: []string{"a"}, fail: "a", concurrency: 1, wantErr: loadErr, wantLoads: 1},
+	}
+	for _, tt := range tests {
+		repo := newRepoMap(tt.cached)
+		c := cacher.New(repo, time.Minute)
+		var loads, running, maxRunning int32
+		var mu sync.Mutex
+		err := c.WarmGroup(ctx, tt.keys, func(key string) func() (interface{}, error) {
+			return func() (interface{}, error) {
+				atomic.AddInt32(&loads, 1)
+				n := atomic.AddInt32(&running, 1)
+				defer atomic.AddInt32(&running, -1)
+				mu.Lock()
+				if n > maxRunning {
+					maxRunning = n
+				}
+				mu.Unlock()
+				time.Sleep(5 * time.Millisecond)
+				if key == tt.fail {
+					return nil, loadErr
+				}
+				return "v:" + key, nil
+			}
+		}, tt.concurrency)
+		if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+			t.Errorf("%s: WarmGroup() = %v, want %v", tt.name, err, tt.wantErr)
+		}
+		if loads != tt.wantLoads {
+			t.Errorf("%s: loads = %d, want %d", tt.name, loads, tt.wantLoads)
+		}
+		if tt.concurrency > 0 && maxRunning > int32(tt.concurrency) {
+			t.Errorf("%s: max concurrency = %d, want <= %d", tt.name, maxRunning, tt.concurrency)
+		}
+		if tt.wantErr == nil {
+			for _, key := range tt.keys {
+				if _, ok := repo.data[key]; !ok {
+					t.Errorf("%s: %s not warmed", tt.name, key)
+				}
+			}
+		}
+	}
+	if err := cacher.New(newRepoMap(nil), time.Minute).WarmGroup(ctx, []string{"a"}, nil, 1); err == nil {
+		t.Errorf("WarmGroup() with nil loaderFor want error")
+	}
+}
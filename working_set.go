@@ -0,0 +1,108 @@
+package cacher
+
+import (
+	"hash/maphash"
+	"sort"
+	"sync"
+	"time"
+)
+
+// windowSlots 每个时间窗口的时间片数量，时间片长度为窗口的1/windowSlots。
+// 多保留一个时间片记录当前正在进行的时间片，估计值覆盖的时长在 [窗口, 窗口+时间片) 之间
+const windowSlots = 4
+
+type (
+	// workingSet 估计滑动时间窗口内访问过的不同缓存键数量
+	workingSet struct {
+		seed    maphash.Seed
+		windows []*windowSketch //按窗口从小到大排列
+	}
+	// windowSketch 一个时间窗口的环形缓冲区，每个时间片一个 HyperLogLog
+	windowSketch struct {
+		mu       sync.Mutex
+		window   time.Duration
+		slot     time.Duration //时间片长度
+		sketches [windowSlots + 1]hyperLogLog
+		slots    [windowSlots + 1]int64 //每个 HyperLogLog 所属的时间片序号，用于判断是否过期
+	}
+	// WorkingSetEstimate 时间窗口内访问过的不同缓存键数量的估计值，误差约为3%
+	WorkingSetEstimate struct {
+		Window time.Duration `json:"window"` //时间窗口
+		Keys   uint64        `json:"keys"`   //不同缓存键的数量
+	}
+)
+
+// TrackWorkingSet 开始估计 windows 内访问过的不同缓存键的数量（工作集大小），通过 DebugState 查看，
+// 用于设置进程内存储库的容量或 Redis 的 maxmemory。每个窗口占用约5KB内存。
+// 再次调用时替换原来的窗口并重新统计；没有窗口时停止统计
+func (c *Cacher) TrackWorkingSet(windows ...time.Duration) {
+	var sorted []time.Duration
+	for _, w := range windows {
+		if w >= windowSlots {
+			sorted = append(sorted, w)
+		}
+	}
+	if len(sorted) == 0 {
+		c.workingSet.Store((*workingSet)(nil))
+		return
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	ws := &workingSet{seed: maphash.MakeSeed()}
+	for _, w := range sorted {
+		ws.windows = append(ws.windows, &windowSketch{window: w, slot: w / windowSlots})
+	}
+	c.workingSet.Store(ws)
+}
+
+// trackKey 记录访问的缓存键
+func (c *Cacher) trackKey(key string) {
+	if ws, _ := c.workingSet.Load().(*workingSet); ws != nil {
+		ws.add(key, time.Now())
+	}
+}
+
+// add 记录在 now 访问的缓存键
+func (ws *workingSet) add(key string, now time.Time) {
+	var h maphash.Hash
+	h.SetSeed(ws.seed)
+	_, _ = h.WriteString(key)
+	hash := h.Sum64()
+	for _, w := range ws.windows {
+		w.add(hash, now)
+	}
+}
+
+// estimates 估计每个时间窗口内的不同缓存键数量
+func (ws *workingSet) estimates(now time.Time) []WorkingSetEstimate {
+	result := make([]WorkingSetEstimate, 0, len(ws.windows))
+	for _, w := range ws.windows {
+		result = append(result, WorkingSetEstimate{Window: w.window, Keys: w.estimate(now)})
+	}
+	return result
+}
+
+func (w *windowSketch) add(hash uint64, now time.Time) {
+	slot := now.UnixNano() / int64(w.slot)
+	i := int(slot % int64(len(w.sketches)))
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.slots[i] != slot {
+		//环形缓冲区中的旧时间片
+		w.sketches[i].reset()
+		w.slots[i] = slot
+	}
+	w.sketches[i].add(hash)
+}
+
+func (w *windowSketch) estimate(now time.Time) uint64 {
+	current := now.UnixNano() / int64(w.slot)
+	var merged hyperLogLog
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for i, slot := range w.slots {
+		if slot > current-int64(len(w.sketches)) && slot <= current {
+			merged.merge(&w.sketches[i])
+		}
+	}
+	return merged.estimate()
+}
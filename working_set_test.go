@@ -0,0 +1,58 @@
+package cacher_test
+
+import (
+	"context"
+	"github.com/carteruu/cacher"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestCacher_TrackWorkingSet(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	if ws := c.DebugState().WorkingSet; ws != nil {
+		t.Errorf("WorkingSet = %v, want nil before tracking", ws)
+	}
+	c.TrackWorkingSet(time.Hour, 200*time.Millisecond)
+	get := func(from, to int) {
+		var v string
+		for i := from; i < to; i++ {
+			if _, err := c.Get(ctx, "k"+strconv.Itoa(i), func() (interface{}, error) { return "v", nil }, &v); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	near := func(got, want uint64) bool {
+		return float64(got) > float64(want)*0.9 && float64(got) < float64(want)*1.1
+	}
+
+	//重复访问的缓存键只计一次
+	get(0, 2000)
+	get(0, 2000)
+	ws := c.DebugState().WorkingSet
+	if len(ws) != 2 || ws[0].Window != 200*time.Millisecond || ws[1].Window != time.Hour {
+		t.Fatalf("WorkingSet = %+v, want windows sorted", ws)
+	}
+	for _, e := range ws {
+		if !near(e.Keys, 2000) {
+			t.Errorf("window %v keys = %d, want about 2000", e.Window, e.Keys)
+		}
+	}
+
+	//较短的窗口滑过后，只统计新访问的缓存键
+	time.Sleep(300 * time.Millisecond)
+	get(2000, 2500)
+	ws = c.DebugState().WorkingSet
+	if !near(ws[0].Keys, 500) {
+		t.Errorf("window %v keys = %d, want about 500", ws[0].Window, ws[0].Keys)
+	}
+	if !near(ws[1].Keys, 2500) {
+		t.Errorf("window %v keys = %d, want about 2500", ws[1].Window, ws[1].Keys)
+	}
+
+	c.TrackWorkingSet()
+	if ws := c.DebugState().WorkingSet; ws != nil {
+		t.Errorf("WorkingSet = %v, want nil after stop", ws)
+	}
+}
@@ -0,0 +1,96 @@
+package cacher
+
+import (
+	"context"
+	"errors"
+	"reflect"
+)
+
+// WriteMode Cacher.Write 的写入模式，决定修改数据源时如何处理缓存
+type WriteMode int
+
+const (
+	// WriteAround 只写数据源，成功后删除缓存，由下一次读取重新查询。缓存永远不会保存未经查询方法确认的数据，是默认模式
+	WriteAround WriteMode = iota
+	// WriteThrough 先写数据源，成功后用写入的数据更新缓存。读多写少且写入的数据就是读取的数据时，可以避免一次缓存未命中
+	WriteThrough
+	// WriteBehind 先用写入的数据更新缓存，再异步写数据源，Write 不等待数据源写入完成。
+	// 数据源写入失败时删除缓存，错误通过 EventError 事件发布。适合能容忍短暂不一致、对写入延迟敏感的场景
+	WriteBehind
+)
+
+func (m WriteMode) String() string {
+	switch m {
+	case WriteAround:
+		return "write-around"
+	case WriteThrough:
+		return "write-through"
+	case WriteBehind:
+		return "write-behind"
+	}
+	return "unknown"
+}
+
+// Write 修改数据源并按 Option.WriteMode 处理 key 对应的缓存。
+// writeFn 写数据源；value 是写入的数据，WriteThrough、WriteBehind 模式下保存为缓存，WriteAround 模式下不使用
+func (c *Cacher) Write(ctx context.Context, key string, value interface{}, writeFn func(ctx context.Context) error, optFns ...func(opt *Option)) error {
+	if key == "" {
+		return ErrEmptyKey
+	}
+	if writeFn == nil {
+		return errors.New("写入方法 writeFn 不能为空")
+	}
+	opt := c.options().clone()
+	for _, optFn := range optFns {
+		if optFn != nil {
+			optFn(&opt)
+		}
+	}
+	if err := opt.Valid(); err != nil {
+		return err
+	}
+	key, err := c.buildKey(ctx, key, opt)
+	if err != nil {
+		return err
+	}
+	var valueType reflect.Type
+	if value != nil {
+		valueType, _ = indirectType(reflect.TypeOf(value))
+	}
+	if opt.Expire == 0 {
+		opt.Expire = c.typeExpire(valueType)
+	}
+
+	switch opt.WriteMode {
+	case WriteThrough:
+		if err := writeFn(ctx); err != nil {
+			return err
+		}
+		c.flights.del(key)
+		if _, err := c.save(ctx, key, value, valueType, opt); err != nil {
+			return err
+		}
+		c.invalidateWritten(ctx, key, opt)
+		return nil
+	case WriteBehind:
+		c.flights.del(key)
+		if _, err := c.save(ctx, key, value, valueType, opt); err != nil {
+			return err
+		}
+		c.invalidateWritten(ctx, key, opt)
+		ctx = detach(ctx)
+		c.goBackground(func() {
+			if err := writeFn(ctx); err != nil {
+				c.emitError("write-behind", key, err)
+				if err := c.del(ctx, key); err != nil {
+					c.emitError("write-behind", key, err)
+				}
+			}
+		})
+		return nil
+	}
+	if err := writeFn(ctx); err != nil {
+		return err
+	}
+	return c.del(ctx, key)
+}
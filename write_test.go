@@ -0,0 +1,90 @@
+package cacher_test
+
+import (
+	"context"
+	"errors"
+	"github.com/carteruu/cacher"
+	"testing"
+	"time"
+)
+
+func TestCacher_Write(t *testing.T) {
+	writeErr := errors.New("write error")
+	tests := []struct {
+		name      string
+		mode      cacher.WriteMode
+		writeErr  error
+		wantErr   error
+		wantCache interface{} //写入后的缓存数据，nil 表示缓存已删除
+	}{
+		{name: "write-around：删除缓存", mode: cacher.WriteAround, wantCache: nil},
+		{name: "write-around：写入失败保留缓存", mode: cacher.WriteAround, writeErr: writeErr, wantErr: writeErr, wantCache: "old"},
+		{name: "write-through：更新缓存", mode: cacher.WriteThrough, wantCache: "new"},
+		{name: "write-through：写入失败保留缓存", mode: cacher.WriteThrough, writeErr: writeErr, wantErr: writeErr, wantCache: "old"},
+		{name: "write-behind：更新缓存", mode: cacher.WriteBehind, wantCache: "new"},
+		{name: "write-behind：写入失败删除缓存", mode: cacher.WriteBehind, writeErr: writeErr, wantCache: nil},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			repo := newRepoMap(map[string]interface{}{"k": "old"})
+			c := cacher.New(repo, time.Minute, func(opt *cacher.Option) {
+				opt.WriteMode = tt.mode
+			})
+			written := make(chan struct{})
+			errEvents := make(chan error, 2)
+			deleted := make(chan struct{}, 1)
+			c.OnEvent(func(ev cacher.Event) {
+				switch ev.Type {
+				case cacher.EventError:
+					errEvents <- ev.Err
+				case cacher.EventDel:
+					deleted <- struct{}{}
+				}
+			})
+			err := c.Write(ctx, "k", "new", func(ctx context.Context) error {
+				close(written)
+				return tt.writeErr
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Write() = %v, want %v", err, tt.wantErr)
+			}
+			<-written
+			if tt.mode == cacher.WriteBehind && tt.writeErr != nil {
+				select {
+				case got := <-errEvents:
+					if !errors.Is(got, tt.writeErr) {
+						t.Errorf("error event = %v, want %v", got, tt.writeErr)
+					}
+				case <-time.After(time.Second):
+					t.Fatal("error event not emitted")
+				}
+				<-deleted
+			}
+			got, _ := repo.Get(ctx, "k")
+			if got != tt.wantCache {
+				t.Errorf("cache = %v, want %v", got, tt.wantCache)
+			}
+		})
+	}
+}
+
+func TestCacher_Write_Option(t *testing.T) {
+	c := cacher.New(newRepoMap(nil), time.Minute)
+	noop := func(ctx context.Context) error { return nil }
+	if err := c.Write(context.Background(), "", nil, noop); err == nil {
+		t.Errorf("Write() with empty key want error")
+	}
+	if err := c.Write(context.Background(), "k", nil, nil); err == nil {
+		t.Errorf("Write() without writeFn want error")
+	}
+	var optErr *cacher.OptionError
+	err := c.Write(context.Background(), "k", nil, noop, func(opt *cacher.Option) { opt.WriteMode = 9 })
+	if !errors.As(err, &optErr) || optErr.Field != "WriteMode" {
+		t.Errorf("Write() = %v, want WriteMode OptionError", err)
+	}
+	if s := cacher.WriteBehind.String(); s != "write-behind" {
+		t.Errorf("String() = %v", s)
+	}
+}
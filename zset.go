@@ -0,0 +1,193 @@
+package cacher
+
+import (
+	"context"
+	"sync"
+)
+
+type (
+	// ZMember 有序集合成员
+	ZMember struct {
+		Member string  //成员
+		Score  float64 //分数
+	}
+	// ZRepo 可选的存储库接口，存储库实现该接口后，Cacher.SortedSet 使用存储库保存有序集合，
+	// 否则使用进程内的有序集合
+	ZRepo interface {
+		// ZAdd 添加成员，成员已存在时更新分数
+		ZAdd(ctx context.Context, key string, members ...ZMember) error
+		// ZIncrBy 成员的分数增加 incr，成员不存在时以 incr 为分数添加。返回增加后的分数
+		ZIncrBy(ctx context.Context, key string, member string, incr float64) (float64, error)
+		// ZRange 按排名获取 [start, stop] 的成员，desc 为 true 时按分数降序排名。
+		// 排名从0开始，负数表示倒数，同 Redis 的 ZRANGE
+		ZRange(ctx context.Context, key string, start, stop int64, desc bool) ([]ZMember, error)
+	}
+)
+
+// SortedSet 有序集合门面，用于排行榜等场景
+type SortedSet struct {
+	c    *Cacher
+	key  string
+	repo ZRepo
+	err  error //缓存键不符合 Option.KeyPolicy 时的错误
+}
+
+// SortedSet 获取缓存键 key 对应的有序集合。
+// 存储库未实现 ZRepo 时，使用进程内的有序集合，数据只在当前进程可见
+func (c *Cacher) SortedSet(key string) *SortedSet {
+	repo, ok := c.repo.(ZRepo)
+	if !ok {
+		repo = &c.zsets
+	}
+	key, err := repoKey(key, c.options())
+	return &SortedSet{c: c, key: key, repo: repo, err: err}
+}
+
+// Add 添加成员，成员已存在时更新分数
+func (s *SortedSet) Add(ctx context.Context, members ...ZMember) error {
+	if s.err != nil || len(members) == 0 {
+		return s.err
+	}
+	return s.repo.ZAdd(ctx, s.key, members...)
+}
+
+// IncrBy 成员的分数增加 incr，返回增加后的分数
+func (s *SortedSet) IncrBy(ctx context.Context, member string, incr float64) (float64, error) {
+	if s.err != nil {
+		return 0, s.err
+	}
+	return s.repo.ZIncrBy(ctx, s.key, member, incr)
+}
+
+// Range 按分数升序获取排名 [start, stop] 的成员
+func (s *SortedSet) Range(ctx context.Context, start, stop int64) ([]ZMember, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.repo.ZRange(ctx, s.key, start, stop, false)
+}
+
+// RevRange 按分数降序获取排名 [start, stop] 的成员
+func (s *SortedSet) RevRange(ctx context.Context, start, stop int64) ([]ZMember, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.repo.ZRange(ctx, s.key, start, stop, true)
+}
+
+// Top 获取分数最高的 n 个成员
+func (s *SortedSet) Top(ctx context.Context, n int64) ([]ZMember, error) {
+	if n <= 0 {
+		return nil, nil
+	}
+	return s.RevRange(ctx, 0, n-1)
+}
+
+// Del 删除有序集合
+func (s *SortedSet) Del(ctx context.Context) error {
+	if s.err != nil {
+		return s.err
+	}
+	if local, ok := s.repo.(*localZSets); ok {
+		local.del(s.key)
+		return nil
+	}
+	return s.c.del(ctx, s.key)
+}
+
+type (
+	// localZSets 进程内的有序集合，存储库未实现 ZRepo 时使用
+	localZSets struct {
+		mu   sync.Mutex
+		sets map[string]*localZSet
+	}
+	localZSet struct {
+		scores map[string]float64
+		list   *skiplist
+	}
+)
+
+func (l *localZSets) ZAdd(ctx context.Context, key string, members ...ZMember) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	set := l.get(key)
+	for _, m := range members {
+		set.set(m.Member, m.Score)
+	}
+	return nil
+}
+
+func (l *localZSets) ZIncrBy(ctx context.Context, key string, member string, incr float64) (float64, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	set := l.get(key)
+	score := set.scores[member] + incr
+	set.set(member, score)
+	return score, nil
+}
+
+func (l *localZSets) ZRange(ctx context.Context, key string, start, stop int64, desc bool) ([]ZMember, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	set, ok := l.sets[key]
+	if !ok {
+		return nil, nil
+	}
+	return set.rangeByRank(start, stop, desc), nil
+}
+
+// get 获取有序集合，不存在时创建
+func (l *localZSets) get(key string) *localZSet {
+	if l.sets == nil {
+		l.sets = make(map[string]*localZSet)
+	}
+	set, ok := l.sets[key]
+	if !ok {
+		set = newLocalZSet()
+		l.sets[key] = set
+	}
+	return set
+}
+
+// del 删除有序集合
+func (l *localZSets) del(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.sets, key)
+}
+
+func newLocalZSet() *localZSet {
+	return &localZSet{scores: make(map[string]float64), list: newSkiplist()}
+}
+
+// rangeByRank 按排名获取 [start, stop] 的成员，desc 为 true 时按分数降序排名
+func (s *localZSet) rangeByRank(start, stop int64, desc bool) []ZMember {
+	length := int64(s.list.length)
+	start, stop, ok := rangeIndex(length, start, stop)
+	if !ok {
+		return nil
+	}
+	members := make([]ZMember, 0, stop-start+1)
+	if desc {
+		for x := s.list.byRank(int(length - start)); x != nil && int64(len(members)) <= stop-start; x = x.backward {
+			members = append(members, ZMember{Member: x.member, Score: x.score})
+		}
+	} else {
+		for x := s.list.byRank(int(start + 1)); x != nil && int64(len(members)) <= stop-start; x = x.levels[0].forward {
+			members = append(members, ZMember{Member: x.member, Score: x.score})
+		}
+	}
+	return members
+}
+
+// set 设置成员的分数
+func (s *localZSet) set(member string, score float64) {
+	if old, ok := s.scores[member]; ok {
+		if old == score {
+			return
+		}
+		s.list.delete(old, member)
+	}
+	s.scores[member] = score
+	s.list.insert(score, member)
+}
@@ -0,0 +1,95 @@
+package cacher_test
+
+import (
+	"context"
+	"fmt"
+	"github.com/carteruu/cacher"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestSortedSet_Local(t *testing.T) {
+	ctx := context.Background()
+	c := cacher.New(newRepoMap(nil), 10*time.Second)
+	board := c.SortedSet("board")
+	if err := board.Add(ctx, cacher.ZMember{Member: "a", Score: 3}, cacher.ZMember{Member: "b", Score: 1}, cacher.ZMember{Member: "c", Score: 2}); err != nil {
+		t.Fatal(err)
+	}
+	if score, err := board.IncrBy(ctx, "b", 5); err != nil || score != 6 {
+		t.Fatalf("IncrBy() = %v, %v", score, err)
+	}
+	tests := []struct {
+		name        string
+		start, stop int64
+		desc        bool
+		want        []string
+	}{
+		{name: "升序全部", start: 0, stop: -1, want: []string{"c", "a", "b"}},
+		{name: "降序前两名", start: 0, stop: 1, desc: true, want: []string{"b", "a"}},
+		{name: "倒数", start: -2, stop: -1, want: []string{"a", "b"}},
+		{name: "超出范围", start: 5, stop: 10, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var members []cacher.ZMember
+			var err error
+			if tt.desc {
+				members, err = board.RevRange(ctx, tt.start, tt.stop)
+			} else {
+				members, err = board.Range(ctx, tt.start, tt.stop)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, m := range members {
+				got = append(got, m.Member)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("range = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if err := board.Del(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if members, _ := board.Top(ctx, 10); len(members) != 0 {
+		t.Errorf("Top() after Del = %v", members)
+	}
+}
+
+func TestSortedSet_LocalRandom(t *testing.T) {
+	ctx := context.Background()
+	board := cacher.New(newRepoMap(nil), 10*time.Second).SortedSet("board")
+	r := rand.New(rand.NewSource(1))
+	scores := make(map[string]float64)
+	for i := 0; i < 2000; i++ {
+		member := fmt.Sprint(r.Intn(200))
+		incr := float64(r.Intn(100) - 50)
+		if _, err := board.IncrBy(ctx, member, incr); err != nil {
+			t.Fatal(err)
+		}
+		scores[member] += incr
+	}
+	want := make([]cacher.ZMember, 0, len(scores))
+	for member, score := range scores {
+		want = append(want, cacher.ZMember{Member: member, Score: score})
+	}
+	sort.Slice(want, func(i, j int) bool {
+		if want[i].Score != want[j].Score {
+			return want[i].Score > want[j].Score
+		}
+		return want[i].Member > want[j].Member
+	})
+	got, err := board.RevRange(ctx, 10, 59)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want[10:60]) {
+		t.Errorf("RevRange() = %v, want %v", got, want[10:60])
+	}
+}